@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -63,6 +64,9 @@ func (a *ChangeStatusAction) Execute() error {
 		if err := item.Save(); err != nil {
 			return err
 		}
+		if _, err := backlog.ReleaseDependents(backlog.NewBacklogsStructure(filepath.Dir(a.backlogDir)), item); err != nil {
+			return err
+		}
 	}
 	return nil
 }
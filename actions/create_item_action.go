@@ -79,7 +79,10 @@ func (a *CreateItemAction) Execute() error {
 	item.SetDescription(newItemTemplate)
 
 	if !a.simulate {
-		return item.Save()
+		if err := item.Save(); err != nil {
+			return err
+		}
+		return backlog.AssignItemID(backlog.NewBacklogsStructure(filepath.Dir(a.rootDir)), item)
 	}
 
 	itemPath, _ = filepath.Abs(itemPath)
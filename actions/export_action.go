@@ -0,0 +1,37 @@
+package actions
+
+import (
+	"bytes"
+	"os"
+
+	"github.com/mreider/agilemarkdown/backlog"
+)
+
+type ExportAction struct {
+	root     string
+	format   string
+	backlogs []string
+	outPath  string
+}
+
+func NewExportAction(root, format string, backlogs []string, outPath string) *ExportAction {
+	if format == "" {
+		format = backlog.ExportFormatCsv
+	}
+	return &ExportAction{root: root, format: format, backlogs: backlogs, outPath: outPath}
+}
+
+// Execute writes the export to outPath, or to stdout when outPath is
+// empty or "-". A file is only replaced once the whole export is built,
+// so a bad format or backlog name leaves it as it was.
+func (a *ExportAction) Execute() error {
+	root := backlog.NewBacklogsStructure(a.root)
+	if a.outPath == "" || a.outPath == "-" {
+		return backlog.ExportBacklogs(root, a.backlogs, a.format, os.Stdout)
+	}
+	var buf bytes.Buffer
+	if err := backlog.ExportBacklogs(root, a.backlogs, a.format, &buf); err != nil {
+		return err
+	}
+	return backlog.WriteFilesAtomically(map[string][]byte{a.outPath: buf.Bytes()})
+}
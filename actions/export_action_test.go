@@ -0,0 +1,37 @@
+package actions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportKeepsOutputOnError(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "product"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeItem(t, filepath.Join(root, "product"), "login", "unstarted")
+	out := filepath.Join(root, "existing.csv")
+	if err := os.WriteFile(out, []byte("keep me\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NewExportAction(root, "bogus", nil, out).Execute(); err == nil {
+		t.Fatal("bogus format exported")
+	}
+	if err := NewExportAction(root, "csv", []string{"nosuch"}, out).Execute(); err == nil || !strings.Contains(err.Error(), "nosuch") {
+		t.Fatalf("unknown backlog: %v", err)
+	}
+	if data, _ := os.ReadFile(out); string(data) != "keep me\n" {
+		t.Fatalf("failed export touched the file: %q", data)
+	}
+
+	if err := NewExportAction(root, "csv", []string{"Product"}, out).Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(out); !strings.Contains(string(data), "login") {
+		t.Fatalf("export not written: %q", data)
+	}
+}
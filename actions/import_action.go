@@ -8,16 +8,28 @@ import (
 	"strings"
 )
 
+const (
+	ImportFormatCsv     = "csv"
+	ImportFormatPivotal = "pivotal"
+)
+
 type ImportAction struct {
 	backlogDir string
 	csvPaths   []string
+	format     string
 }
 
-func NewImportAction(backlogDir string, csvPaths []string) *ImportAction {
-	return &ImportAction{backlogDir: backlogDir, csvPaths: csvPaths}
+func NewImportAction(backlogDir string, csvPaths []string, format string) *ImportAction {
+	if format == "" {
+		format = ImportFormatCsv
+	}
+	return &ImportAction{backlogDir: backlogDir, csvPaths: csvPaths, format: strings.ToLower(format)}
 }
 
 func (a *ImportAction) Execute() error {
+	if a.format != ImportFormatCsv && a.format != ImportFormatPivotal {
+		return fmt.Errorf("unknown import format '%s' (want %s or %s)", a.format, ImportFormatCsv, ImportFormatPivotal)
+	}
 	for _, csvPath := range a.csvPaths {
 		csvPath, err := filepath.Abs(csvPath)
 		if err != nil {
@@ -30,6 +42,17 @@ func (a *ImportAction) Execute() error {
 			continue
 		}
 		ext := strings.ToLower(filepath.Ext(csvPath))
+		if a.format == ImportFormatPivotal {
+			if ext != ".csv" && ext != ".json" {
+				fmt.Printf("The file '%s' should be a Pivotal Tracker CSV or JSON export\n", csvPath)
+				continue
+			}
+			err = backlog.NewPivotalImporter(csvPath, a.backlogDir).Import()
+			if err != nil {
+				fmt.Printf("Import of the Pivotal export '%s' failed: %v\n", csvPath, err)
+			}
+			continue
+		}
 		if ext != ".csv" {
 			fmt.Printf("The file '%s' should be a CSV file\n", csvPath)
 			continue
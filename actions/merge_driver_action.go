@@ -0,0 +1,166 @@
+package actions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/git"
+)
+
+// gitAttributesComment heads the driver's lines in .gitattributes and
+// marks the file as opted in, so sync adds a line for each new backlog.
+const gitAttributesComment = "# agilemarkdown: semantic merge of items and _priority.md/_icebox.md (am merge-driver)"
+
+// legacyAttributesLine is the catch-all line earlier versions wrote. It
+// sent READMEs and docs pages through the item merge, so it is replaced
+// by the per-backlog lines.
+var legacyAttributesLine = fmt.Sprintf("*.md merge=%s", git.MergeDriverName)
+
+// gitAttributesLines routes the markdown files of each backlog under
+// rootDir through the merge driver, one line per backlog.
+func gitAttributesLines(rootDir string) ([]string, error) {
+	dirs, err := backlog.NewBacklogsStructure(rootDir).BacklogDirs()
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, dir := range dirs {
+		if _, ok := backlog.FindOverviewFileInRootDirectory(dir); !ok {
+			continue
+		}
+		pattern := filepath.Base(dir) + "/**/*.md"
+		if strings.ContainsAny(pattern, " \t\"\\") {
+			pattern = strconv.Quote(pattern)
+		}
+		lines = append(lines, fmt.Sprintf("%s merge=%s", pattern, git.MergeDriverName))
+	}
+	return lines, nil
+}
+
+type MergeDriverAction struct {
+	basePath   string
+	oursPath   string
+	theirsPath string
+	repoPath   string
+}
+
+// NewMergeDriverAction takes the files git passes to a merge driver: the
+// ancestor (%O), ours (%A, also the output), theirs (%B) and the path of
+// the file in the repository (%P).
+func NewMergeDriverAction(basePath, oursPath, theirsPath, repoPath string) *MergeDriverAction {
+	return &MergeDriverAction{basePath: basePath, oursPath: oursPath, theirsPath: theirsPath, repoPath: repoPath}
+}
+
+// Execute writes the merge result over ours and returns the number of
+// conflicts left in it. Only files inside a backlog folder get the item
+// and order-file merges; anything else is merged as text.
+func (a *MergeDriverAction) Execute() (int, error) {
+	base, err := os.ReadFile(a.basePath)
+	if err != nil {
+		return 0, err
+	}
+	ours, err := os.ReadFile(a.oursPath)
+	if err != nil {
+		return 0, err
+	}
+	theirs, err := os.ReadFile(a.theirsPath)
+	if err != nil {
+		return 0, err
+	}
+
+	name := filepath.Base(a.repoPath)
+	if !inBacklogFolder(a.repoPath) {
+		return git.MergeFile(a.oursPath, a.basePath, a.theirsPath)
+	}
+	if name == "_priority.md" || name == "_icebox.md" {
+		merged := backlog.MergeOrderFile(string(base), string(ours), string(theirs))
+		return 0, os.WriteFile(a.oursPath, []byte(merged), 0644)
+	}
+	if strings.HasSuffix(name, ".md") && hasFrontmatter(base) && hasFrontmatter(ours) && hasFrontmatter(theirs) {
+		merged, conflicts, err := backlog.MergeItem(string(base), string(ours), string(theirs))
+		if err == nil {
+			return conflicts, os.WriteFile(a.oursPath, []byte(merged), 0644)
+		}
+		// unparseable frontmatter (e.g. leftover markers): merge as text
+	}
+	return git.MergeFile(a.oursPath, a.basePath, a.theirsPath)
+}
+
+// inBacklogFolder reports whether repoPath, relative to the top of the
+// repository (where git runs merge drivers), lies in a backlog folder:
+// its first segment has a `<name>.md` overview next to it.
+func inBacklogFolder(repoPath string) bool {
+	rel := filepath.Clean(filepath.FromSlash(repoPath))
+	if filepath.IsAbs(rel) {
+		return false
+	}
+	top, rest, ok := strings.Cut(rel, string(filepath.Separator))
+	if !ok || rest == "" || top == ".." || strings.HasPrefix(top, ".") {
+		return false
+	}
+	_, ok = backlog.FindOverviewFileInRootDirectory(top)
+	return ok
+}
+
+func hasFrontmatter(data []byte) bool {
+	s := strings.TrimPrefix(string(data), "\ufeff")
+	return strings.HasPrefix(s, "---\n") || strings.HasPrefix(s, "---\r\n")
+}
+
+// InstallMergeDriver adds a driver line to rootDir/.gitattributes for
+// each backlog that lacks one, drops the old catch-all `*.md` line, and
+// registers the driver in the local git config. Returns true when
+// .gitattributes was written.
+func InstallMergeDriver(rootDir string) (bool, error) {
+	path := filepath.Join(rootDir, ".gitattributes")
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	want, err := gitAttributesLines(rootDir)
+	if err != nil {
+		return false, err
+	}
+	var lines []string
+	if content := strings.TrimSuffix(string(data), "\n"); content != "" {
+		lines = strings.Split(content, "\n")
+	}
+	have := make(map[string]bool)
+	kept := lines[:0]
+	changed := false
+	for _, line := range lines {
+		if strings.TrimSpace(line) == legacyAttributesLine {
+			changed = true
+			continue
+		}
+		have[strings.TrimSpace(line)] = true
+		kept = append(kept, line)
+	}
+	if !have[gitAttributesComment] {
+		kept = append(kept, gitAttributesComment)
+		changed = true
+	}
+	for _, line := range want {
+		if !have[line] {
+			kept = append(kept, line)
+			changed = true
+		}
+	}
+	if changed {
+		if err := os.WriteFile(path, []byte(strings.Join(kept, "\n")+"\n"), 0644); err != nil {
+			return false, err
+		}
+	}
+	return changed, git.ConfigureMergeDriver(rootDir)
+}
+
+// mergeDriverInstalled reports whether `am init` opted the repository
+// into the merge driver.
+func mergeDriverInstalled(rootDir string) bool {
+	data, err := os.ReadFile(filepath.Join(rootDir, ".gitattributes"))
+	return err == nil && (strings.Contains(string(data), gitAttributesComment) || strings.Contains(string(data), "merge="+git.MergeDriverName))
+}
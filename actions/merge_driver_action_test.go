@@ -0,0 +1,84 @@
+package actions
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstallMergeDriverScopesToBacklogs(t *testing.T) {
+	root := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", root).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v %s", err, out)
+	}
+	for _, dir := range []string{"product", "docs"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "product.md"), []byte("# product\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".gitattributes"), []byte("*.png binary\n"+legacyAttributesLine+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	written, err := InstallMergeDriver(root)
+	if err != nil || !written {
+		t.Fatalf("install: written=%v err=%v", written, err)
+	}
+	data, _ := os.ReadFile(filepath.Join(root, ".gitattributes"))
+	want := "*.png binary\n" + gitAttributesComment + "\nproduct/**/*.md merge=agilemarkdown\n"
+	if string(data) != want {
+		t.Fatalf(".gitattributes =\n%s\nwant\n%s", data, want)
+	}
+	if written, _ := InstallMergeDriver(root); written {
+		t.Fatal("second install rewrote .gitattributes")
+	}
+	if !mergeDriverInstalled(root) {
+		t.Fatal("installed driver not detected")
+	}
+}
+
+func TestMergeDriverLeavesNonBacklogFilesToText(t *testing.T) {
+	root := t.TempDir()
+	t.Chdir(root)
+	for _, dir := range []string{"docs", "product"} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile("product.md", []byte("# product\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Neighbouring keys changed on each side: a key-by-key merge is
+	// clean, a text merge conflicts.
+	merge := func(repoPath string) (int, string) {
+		t.Helper()
+		write := func(name, content string) string {
+			t.Helper()
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			return path
+		}
+		base := write("base", "---\ntitle: Guide\nstatus: unstarted\n---\n\nIntro.\n")
+		ours := write("ours", "---\ntitle: User guide\nstatus: unstarted\n---\n\nIntro.\n")
+		theirs := write("theirs", "---\ntitle: Guide\nstatus: started\n---\n\nIntro.\n")
+		conflicts, err := NewMergeDriverAction(base, ours, theirs, repoPath).Execute()
+		if err != nil {
+			t.Fatal(err)
+		}
+		merged, _ := os.ReadFile(ours)
+		return conflicts, string(merged)
+	}
+
+	if conflicts, merged := merge("product/guide.md"); conflicts != 0 || !strings.Contains(merged, "User guide") || !strings.Contains(merged, "started") {
+		t.Errorf("backlog item not merged by key: conflicts=%d\n%s", conflicts, merged)
+	}
+	if conflicts, merged := merge("docs/guide.md"); conflicts == 0 || !strings.Contains(merged, "<<<<<<<") {
+		t.Errorf("docs page went through the item merge: conflicts=%d\n%s", conflicts, merged)
+	}
+}
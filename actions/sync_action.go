@@ -14,13 +14,17 @@ import (
 const AttemptCount = 10
 
 type SyncAction struct {
-	root     *backlog.BacklogsStructure
-	author   string
-	testMode bool
+	root      *backlog.BacklogsStructure
+	author    string
+	perAuthor bool
+	testMode  bool
 }
 
-func NewSyncAction(rootDir, author string, testMode bool) *SyncAction {
-	return &SyncAction{root: backlog.NewBacklogsStructure(rootDir), author: author, testMode: testMode}
+// NewSyncAction builds the sync pipeline. perAuthor splits item changes
+// into one commit per attributed user; `sync.commit_per_author` in the
+// config turns it on as well.
+func NewSyncAction(rootDir, author string, perAuthor, testMode bool) *SyncAction {
+	return &SyncAction{root: backlog.NewBacklogsStructure(rootDir), author: author, perAuthor: perAuthor, testMode: testMode}
 }
 
 func (a *SyncAction) Execute() error {
@@ -61,6 +65,16 @@ func (a *SyncAction) Execute() error {
 			return err
 		}
 
+		err = NewSyncIDsStep(a.root).Execute()
+		if err != nil {
+			return err
+		}
+
+		err = NewSyncDependenciesStep(a.root).Execute()
+		if err != nil {
+			return err
+		}
+
 		err = NewSyncPriorityStep(a.root).Execute()
 		if err != nil {
 			return err
@@ -91,12 +105,27 @@ func (a *SyncAction) Execute() error {
 			return err
 		}
 
+		err = NewSyncEpicsStep(a.root, cfg).Execute()
+		if err != nil {
+			return err
+		}
+
+		err = NewSyncSearchIndexStep(a.root).Execute()
+		if err != nil {
+			return err
+		}
+
+		err = NewSyncFiltersStep(a.root, userList).Execute()
+		if err != nil {
+			return err
+		}
+
 		if a.testMode {
 			fmt.Println("OK")
 			return nil
 		}
 
-		ok, err := a.syncToGit()
+		ok, err := a.syncToGit(userList, a.perAuthor || cfg.Sync.CommitPerAuthor)
 		if err != nil {
 			return err
 		}
@@ -108,13 +137,20 @@ func (a *SyncAction) Execute() error {
 	return errors.New("can't sync: too many failed attempts")
 }
 
-func (a *SyncAction) syncToGit() (bool, error) {
+func (a *SyncAction) syncToGit(userList *backlog.UserList, perAuthor bool) (bool, error) {
+	if mergeDriverInstalled(a.root.Root()) {
+		// Give new backlogs their .gitattributes line, and make sure this
+		// clone's .git/config knows the driver before merging.
+		if _, err := InstallMergeDriver(a.root.Root()); err != nil {
+			return false, err
+		}
+	}
 	err := git.AddAll()
 	if err != nil {
 		return false, err
 	}
 	fmt.Println("git commit")
-	err = git.Commit("sync", a.author) // TODO commit message
+	err = a.commit(userList, perAuthor)
 	if err != nil {
 		return false, fmt.Errorf("can't commit: %v", err)
 	}
@@ -172,3 +208,56 @@ func (a *SyncAction) syncToGit() (bool, error) {
 	}
 	return true, nil
 }
+
+// commit writes the staged tree as one structured sync commit, or, with
+// perAuthor, as one commit per attributed user followed by a commit of
+// everything left (order files, derived files, unattributed items).
+func (a *SyncAction) commit(userList *backlog.UserList, perAuthor bool) error {
+	files, err := git.StagedChanges(a.root.Root())
+	if err != nil {
+		return err
+	}
+	set, err := ClassifySyncChanges(a.root, files)
+	if err != nil {
+		return err
+	}
+	if !perAuthor {
+		return git.Commit(set.Message(), a.author)
+	}
+
+	var authors []string
+	byAuthor := make(map[string][]SyncChange)
+	var rest []SyncChange
+	for _, c := range set.Changes {
+		user := userList.User(c.Author)
+		if c.Author == "" || user == nil || user.PrimaryEmail() == "" {
+			rest = append(rest, c)
+			continue
+		}
+		author := fmt.Sprintf("%s <%s>", user.Name(), user.PrimaryEmail())
+		if _, ok := byAuthor[author]; !ok {
+			authors = append(authors, author)
+		}
+		byAuthor[author] = append(byAuthor[author], c)
+	}
+	for _, author := range authors {
+		changes := byAuthor[author]
+		var paths []string
+		seen := make(map[string]bool)
+		for _, c := range changes {
+			for _, p := range c.Paths {
+				if !seen[p] {
+					seen[p] = true
+					paths = append(paths, p)
+				}
+			}
+		}
+		if err := git.CommitPaths(a.root.Root(), syncCommitMessage(changes, 0), author, paths); err != nil {
+			return err
+		}
+	}
+	if len(rest) == 0 && len(set.Derived) == 0 {
+		return nil
+	}
+	return git.Commit(syncCommitMessage(rest, len(set.Derived)), a.author)
+}
@@ -0,0 +1,309 @@
+package actions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/git"
+)
+
+// Kinds of change a sync commit reports. Order here is the order they are
+// listed in the commit message body.
+const (
+	SyncChangeNew      = "new"
+	SyncChangeStatus   = "status"
+	SyncChangeEstimate = "estimate"
+	SyncChangeAssign   = "assign"
+	SyncChangeComment  = "comment"
+	SyncChangeRank     = "rank"
+	SyncChangeArchive  = "archive"
+	SyncChangeRemove   = "remove"
+	SyncChangeEdit     = "edit"
+)
+
+var syncChangeOrder = []string{SyncChangeNew, SyncChangeStatus, SyncChangeEstimate, SyncChangeAssign, SyncChangeComment, SyncChangeRank, SyncChangeArchive, SyncChangeRemove, SyncChangeEdit}
+
+// SyncChange is one classified line of a sync commit message. Paths lists
+// every repository path the line accounts for (two for renames) so the
+// per-author mode can commit exactly those paths. Author is the user the
+// change is attributed to, or empty when it cannot be attributed.
+type SyncChange struct {
+	Kind   string
+	Line   string
+	Author string
+	Paths  []string
+}
+
+// SyncChangeSet is the classified view of the staged tree.
+type SyncChangeSet struct {
+	Changes []SyncChange
+	Derived []string
+}
+
+// ClassifySyncChanges turns staged file changes into item-level changes by
+// comparing each item's frontmatter with its HEAD version. Anything that
+// is not an item or an order file is reported as a derived file.
+func ClassifySyncChanges(root *backlog.BacklogsStructure, files []git.FileChange) (SyncChangeSet, error) {
+	var set SyncChangeSet
+	backlogs, err := root.BacklogNames()
+	if err != nil {
+		return set, err
+	}
+
+	for _, f := range files {
+		kind := backlog.ClassifyRepoPath(backlogs, f.Path)
+		if f.Status == "R" && kind == backlog.RepoPathItem && backlog.ClassifyRepoPath(backlogs, f.OldPath) == backlog.RepoPathItem {
+			set.Changes = append(set.Changes, classifyItemMove(root.Root(), f))
+			continue
+		}
+		switch kind {
+		case backlog.RepoPathItem:
+			change, ok := classifyItemChange(root.Root(), f)
+			if ok {
+				set.Changes = append(set.Changes, change...)
+			}
+		case backlog.RepoPathOrder:
+			if change, ok := classifyOrderChange(root.Root(), f); ok {
+				set.Changes = append(set.Changes, change)
+			}
+		default:
+			set.Derived = append(set.Derived, f.Path)
+		}
+	}
+	return set, nil
+}
+
+func loadSyncItem(rootDir, path string, fromHead bool) *backlog.BacklogItem {
+	name := strings.TrimSuffix(filepath.Base(path), ".md")
+	if fromHead {
+		src, err := git.RepoVersion(rootDir, filepath.ToSlash(path))
+		if err != nil {
+			return nil
+		}
+		return backlog.NewBacklogItem(name, src)
+	}
+	data, err := os.ReadFile(filepath.Join(rootDir, path))
+	if err != nil {
+		return nil
+	}
+	return backlog.NewBacklogItem(name, string(data))
+}
+
+func classifyItemMove(rootDir string, f git.FileChange) SyncChange {
+	item := loadSyncItem(rootDir, f.Path, false)
+	change := SyncChange{Kind: SyncChangeEdit, Paths: []string{f.OldPath, f.Path}}
+	if item != nil {
+		change.Author = syncItemOwner(item)
+	}
+	if strings.Contains(filepath.ToSlash(f.Path), "/archive/") && !strings.Contains(filepath.ToSlash(f.OldPath), "/archive/") {
+		change.Kind = SyncChangeArchive
+		change.Line = fmt.Sprintf("archive: %s", f.OldPath)
+		return change
+	}
+	change.Line = fmt.Sprintf("edit: %s -> %s", f.OldPath, f.Path)
+	return change
+}
+
+func classifyItemChange(rootDir string, f git.FileChange) ([]SyncChange, bool) {
+	paths := []string{f.Path}
+	switch f.Status {
+	case "A":
+		item := loadSyncItem(rootDir, f.Path, false)
+		if item == nil {
+			return nil, false
+		}
+		detail := item.Type()
+		if detail == "" {
+			detail = "feature"
+		}
+		if est := strings.TrimSpace(item.Estimate()); est != "" {
+			detail += ", " + est + " pts"
+		}
+		author := strings.TrimSpace(item.Author())
+		if author == "" {
+			author = syncItemOwner(item)
+		}
+		return []SyncChange{{Kind: SyncChangeNew, Line: fmt.Sprintf("new: %s %q (%s)", f.Path, item.Title(), detail), Author: author, Paths: paths}}, true
+	case "D":
+		return []SyncChange{{Kind: SyncChangeRemove, Line: fmt.Sprintf("remove: %s", f.Path), Paths: paths}}, true
+	}
+
+	before := loadSyncItem(rootDir, f.Path, true)
+	after := loadSyncItem(rootDir, f.Path, false)
+	if before == nil || after == nil {
+		return []SyncChange{{Kind: SyncChangeEdit, Line: fmt.Sprintf("edit: %s", f.Path), Paths: paths}}, true
+	}
+	owner := syncItemOwner(after)
+	var out []SyncChange
+	add := func(kind, line string) {
+		out = append(out, SyncChange{Kind: kind, Line: line, Author: owner, Paths: paths})
+	}
+	comments := 0
+	for _, c := range backlog.CompareItems(before, after) {
+		switch c.Kind {
+		case backlog.ChangeStatus:
+			add(SyncChangeStatus, fmt.Sprintf("status: %s %s -> %s", f.Path, syncValue(c.From), syncValue(c.To)))
+		case backlog.ChangeEstimate:
+			add(SyncChangeEstimate, fmt.Sprintf("estimate: %s %s -> %s", f.Path, syncValue(c.From), syncValue(c.To)))
+		case backlog.ChangeOwners:
+			add(SyncChangeAssign, fmt.Sprintf("assign: %s %s -> %s", f.Path, syncValue(c.From), syncValue(c.To)))
+		case backlog.ChangeComment:
+			comments++
+		case backlog.ChangeArchived:
+			add(SyncChangeArchive, fmt.Sprintf("archive: %s", f.Path))
+		}
+	}
+	if comments > 0 {
+		add(SyncChangeComment, fmt.Sprintf("comment: %s +%d", f.Path, comments))
+	}
+	if len(out) == 0 {
+		add(SyncChangeEdit, fmt.Sprintf("edit: %s", f.Path))
+	}
+	return out, true
+}
+
+func classifyOrderChange(rootDir string, f git.FileChange) (SyncChange, bool) {
+	var oldPaths []string
+	if f.Status != "A" {
+		if src, err := git.RepoVersion(rootDir, filepath.ToSlash(f.Path)); err == nil {
+			oldPaths = orderPaths(src)
+		}
+	}
+	var newPaths []string
+	if data, err := os.ReadFile(filepath.Join(rootDir, f.Path)); err == nil {
+		newPaths = orderPaths(string(data))
+	}
+	oldIndex := make(map[string]int, len(oldPaths))
+	for i, p := range oldPaths {
+		oldIndex[p] = i
+	}
+	newIndex := make(map[string]int, len(newPaths))
+	for i, p := range newPaths {
+		newIndex[p] = i
+	}
+	moved := backlog.MovedEntries(oldPaths, newPaths)
+	var added, removed []string
+	for _, p := range newPaths {
+		if _, ok := oldIndex[p]; !ok {
+			added = append(added, p)
+		}
+	}
+	for _, p := range oldPaths {
+		if _, ok := newIndex[p]; !ok {
+			removed = append(removed, p)
+		}
+	}
+	if len(added) == 0 && len(removed) == 0 && len(moved) == 0 {
+		return SyncChange{}, false
+	}
+	var parts []string
+	if len(moved) > 0 {
+		parts = append(parts, fmt.Sprintf("moved %s", strings.Join(moved, ", ")))
+	}
+	if len(added) > 0 {
+		parts = append(parts, fmt.Sprintf("added %s", strings.Join(added, ", ")))
+	}
+	if len(removed) > 0 {
+		parts = append(parts, fmt.Sprintf("removed %s", strings.Join(removed, ", ")))
+	}
+	return SyncChange{Kind: SyncChangeRank, Line: fmt.Sprintf("rank: %s %s", f.Path, strings.Join(parts, "; ")), Paths: []string{f.Path}}, true
+}
+
+func orderPaths(src string) []string {
+	var out []string
+	for _, e := range backlog.ParseOrderEntries(src) {
+		out = append(out, e.Path)
+	}
+	return out
+}
+
+// syncItemOwner attributes an item change to its first assignee, falling
+// back to the item author.
+func syncItemOwner(item *backlog.BacklogItem) string {
+	if assignees := item.Assignees(); len(assignees) > 0 {
+		return assignees[0]
+	}
+	return strings.TrimSpace(item.Author())
+}
+
+func syncValue(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+// Message renders a conventional multi-line commit message: a subject
+// with per-kind counts, then one line per change, then a count of
+// regenerated files.
+func (set SyncChangeSet) Message() string {
+	return syncCommitMessage(set.Changes, len(set.Derived))
+}
+
+func syncCommitMessage(changes []SyncChange, derived int) string {
+	if len(changes) == 0 {
+		if derived == 0 {
+			return "sync"
+		}
+		return fmt.Sprintf("sync: regenerate %d derived %s", derived, plural(derived, "file", "files"))
+	}
+	counts := make(map[string]int)
+	for _, c := range changes {
+		counts[c.Kind]++
+	}
+	var summary []string
+	for _, kind := range syncChangeOrder {
+		if n := counts[kind]; n > 0 {
+			summary = append(summary, syncKindSummary(kind, n))
+		}
+	}
+	sorted := append([]SyncChange(nil), changes...)
+	rank := make(map[string]int, len(syncChangeOrder))
+	for i, kind := range syncChangeOrder {
+		rank[kind] = i
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return rank[sorted[i].Kind] < rank[sorted[j].Kind] })
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "sync: %s\n\n", strings.Join(summary, ", "))
+	for _, c := range sorted {
+		fmt.Fprintf(&b, "%s\n", c.Line)
+	}
+	if derived > 0 {
+		fmt.Fprintf(&b, "\nregenerated %d derived %s\n", derived, plural(derived, "file", "files"))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func syncKindSummary(kind string, n int) string {
+	switch kind {
+	case SyncChangeNew:
+		return fmt.Sprintf("%d new %s", n, plural(n, "item", "items"))
+	case SyncChangeStatus:
+		return fmt.Sprintf("%d status %s", n, plural(n, "change", "changes"))
+	case SyncChangeEstimate:
+		return fmt.Sprintf("%d estimate %s", n, plural(n, "change", "changes"))
+	case SyncChangeAssign:
+		return fmt.Sprintf("%d %s", n, plural(n, "reassignment", "reassignments"))
+	case SyncChangeComment:
+		return fmt.Sprintf("%d commented %s", n, plural(n, "item", "items"))
+	case SyncChangeRank:
+		return fmt.Sprintf("%d %s", n, plural(n, "re-rank", "re-ranks"))
+	case SyncChangeArchive:
+		return fmt.Sprintf("%d archived", n)
+	case SyncChangeRemove:
+		return fmt.Sprintf("%d removed", n)
+	}
+	return fmt.Sprintf("%d %s", n, plural(n, "edit", "edits"))
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
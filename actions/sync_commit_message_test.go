@@ -0,0 +1,92 @@
+package actions
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/git"
+)
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+func writeSyncFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+	path := filepath.Join(root, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func syncItem(title, status, estimate, assigned string) string {
+	return "---\ntitle: " + title + "\nstatus: " + status + "\ntype: feature\nestimate: " + estimate +
+		"\nassigned: " + assigned + "\nauthor: alice\n---\n\nBody.\n"
+}
+
+func TestSyncCommitMessage(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	runGit(t, root, "init", "-q")
+	writeSyncFile(t, root, "product/login.md", syncItem("Login", "unstarted", "3", "bob"))
+	writeSyncFile(t, root, "product/signup.md", syncItem("Signup", "started", "2", "bob"))
+	writeSyncFile(t, root, "product/_priority.md", "# Priority\n\n- [Login](login.md)\n- [Signup](signup.md)\n")
+	writeSyncFile(t, root, "index.md", "# Index\n")
+	runGit(t, root, "add", "-A")
+	runGit(t, root, "commit", "-q", "-m", "init")
+
+	writeSyncFile(t, root, "product/login.md", syncItem("Login", "started", "5", "carol"))
+	writeSyncFile(t, root, "product/search.md", syncItem("Search", "unstarted", "1", ""))
+	writeSyncFile(t, root, "product/_priority.md", "# Priority\n\n- [Signup](signup.md)\n- [Login](login.md)\n- [Search](search.md)\n")
+	writeSyncFile(t, root, "index.md", "# Index\n\nregenerated\n")
+	runGit(t, root, "add", "-A")
+
+	files, err := git.StagedChanges(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := ClassifySyncChanges(backlog.NewBacklogsStructure(root), files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := set.Message()
+	lines := strings.Split(msg, "\n")
+	wantSubject := "sync: 1 new item, 1 status change, 1 estimate change, 1 reassignment, 1 re-rank"
+	if lines[0] != wantSubject {
+		t.Fatalf("subject = %q, want %q", lines[0], wantSubject)
+	}
+	for _, want := range []string{
+		`new: product/search.md "Search" (feature, 1 pts)`,
+		"status: product/login.md unstarted -> started",
+		"estimate: product/login.md 3 -> 5",
+		"assign: product/login.md bob -> carol",
+		"rank: product/_priority.md moved login.md; added search.md",
+		"regenerated 1 derived file",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message missing %q:\n%s", want, msg)
+		}
+	}
+	for _, c := range set.Changes {
+		if c.Kind == SyncChangeStatus && c.Author != "carol" {
+			t.Errorf("status change attributed to %q, want carol", c.Author)
+		}
+		if c.Kind == SyncChangeNew && c.Author != "alice" {
+			t.Errorf("new item attributed to %q, want alice", c.Author)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package actions
+
+import (
+	"github.com/mreider/agilemarkdown/backlog"
+)
+
+type SyncDependenciesStep struct {
+	root *backlog.BacklogsStructure
+}
+
+func NewSyncDependenciesStep(root *backlog.BacklogsStructure) *SyncDependenciesStep {
+	return &SyncDependenciesStep{root: root}
+}
+
+// Execute mirrors blocked_by onto blocks (and back), and drops edges
+// whose blocker was accepted since the last sync.
+func (s *SyncDependenciesStep) Execute() error {
+	return backlog.SyncDependencies(s.root)
+}
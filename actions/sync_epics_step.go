@@ -0,0 +1,44 @@
+package actions
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/config"
+)
+
+type SyncEpicsStep struct {
+	root *backlog.BacklogsStructure
+	cfg  *config.Config
+}
+
+func NewSyncEpicsStep(root *backlog.BacklogsStructure, cfg *config.Config) *SyncEpicsStep {
+	return &SyncEpicsStep{root: root, cfg: cfg}
+}
+
+// Execute regenerates the Progress section of every epics/<slug>.md. The
+// frontmatter and description above it are left alone.
+func (s *SyncEpicsStep) Execute() error {
+	epics, err := backlog.LoadEpics(s.root)
+	if err != nil || len(epics) == 0 {
+		return err
+	}
+	refs, err := backlog.LoadItemIndex(s.root)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Generating epic pages")
+	now := time.Now().In(s.cfg.IterationLocation())
+	for _, epic := range epics {
+		items, err := backlog.EpicMembers(s.root, epic.Slug())
+		if err != nil {
+			return err
+		}
+		epic.SetProgress(backlog.EpicProgressSection(s.root, items, refs, s.cfg, now))
+		if err := epic.Save(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package actions
+
+import (
+	"fmt"
+
+	"github.com/mreider/agilemarkdown/backlog"
+)
+
+type SyncFiltersStep struct {
+	root     *backlog.BacklogsStructure
+	userList *backlog.UserList
+}
+
+func NewSyncFiltersStep(root *backlog.BacklogsStructure, userList *backlog.UserList) *SyncFiltersStep {
+	return &SyncFiltersStep{root: root, userList: userList}
+}
+
+// Execute renders filters/<name>.md for each saved search in
+// .am/filters.yaml (or the default panels when the file is absent).
+func (s *SyncFiltersStep) Execute() error {
+	n, err := backlog.SyncFilterPages(s.root, s.userList)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		fmt.Println("Generating filter pages")
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package actions
+
+import (
+	"fmt"
+
+	"github.com/mreider/agilemarkdown/backlog"
+)
+
+type SyncIDsStep struct {
+	root *backlog.BacklogsStructure
+}
+
+func NewSyncIDsStep(root *backlog.BacklogsStructure) *SyncIDsStep {
+	return &SyncIDsStep{root: root}
+}
+
+// Execute numbers items that have no ID yet (or share one after a merge).
+func (s *SyncIDsStep) Execute() error {
+	assigned, err := backlog.AssignIDs(s.root)
+	if err != nil {
+		return err
+	}
+	if len(assigned) > 0 {
+		fmt.Printf("Assigning IDs to %d stories\n", len(assigned))
+	}
+	return nil
+}
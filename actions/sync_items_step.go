@@ -125,14 +125,9 @@ func (s *SyncItemsStep) updateItemsFileNames() error {
 		return err
 	}
 	for _, backlogDir := range backlogDirs {
-		overviewPath, ok := backlog.FindOverviewFileInRootDirectory(backlogDir)
-		if !ok {
+		if _, ok := backlog.FindOverviewFileInRootDirectory(backlogDir); !ok {
 			return fmt.Errorf("the overview file isn't found for %s", backlogDir)
 		}
-		overview, err := backlog.LoadBacklogOverview(overviewPath)
-		if err != nil {
-			return err
-		}
 
 		bck, err := backlog.LoadBacklog(backlogDir)
 		if err != nil {
@@ -144,16 +139,12 @@ func (s *SyncItemsStep) updateItemsFileNames() error {
 			if currentItemName != expectedItemName {
 				newItemPath := filepath.Join(filepath.Dir(item.Path()), expectedItemName)
 				if _, err := os.Stat(newItemPath); os.IsNotExist(err) {
-					err := os.Rename(item.Path(), newItemPath)
+					err := backlog.MoveItemFile(s.root, item, newItemPath)
 					if err == nil {
 						err := git.Add(newItemPath)
 						if err != nil {
 							return err
 						}
-						err = overview.UpdateItemLinkInOverviewFile(item.Path(), newItemPath)
-						if err != nil {
-							return err
-						}
 					}
 				}
 			}
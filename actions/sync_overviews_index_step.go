@@ -37,6 +37,18 @@ func (s *SyncOverviewsAndIndexStep) Execute() error {
 			return err
 		}
 	}
+	// Items move between active and archive first, so the rows link
+	// #IDs to where every item ends up.
+	for _, backlogDir := range backlogDirs {
+		err = s.moveItemsToActiveAndArchiveDirectory(backlogDir)
+		if err != nil {
+			return err
+		}
+	}
+	refs, err := backlog.LoadItemIndex(s.root)
+	if err != nil {
+		return err
+	}
 	overviews := make([]*backlog.BacklogOverview, 0, len(backlogDirs))
 	archives := make([]*backlog.BacklogOverview, 0, len(backlogDirs))
 	for _, backlogDir := range backlogDirs {
@@ -45,15 +57,11 @@ func (s *SyncOverviewsAndIndexStep) Execute() error {
 			return fmt.Errorf("the overview file isn't found for %s", backlogDir)
 		}
 
-		err = s.moveItemsToActiveAndArchiveDirectory(backlogDir)
-		if err != nil {
-			return err
-		}
-
 		overview, err := backlog.LoadBacklogOverview(overviewPath)
 		if err != nil {
 			return err
 		}
+		overview.SetItemIndex(refs)
 		bck, err := backlog.LoadBacklog(backlogDir)
 		if err != nil {
 			return err
@@ -65,6 +73,7 @@ func (s *SyncOverviewsAndIndexStep) Execute() error {
 			return err
 		}
 		archive.SetHideEmptyGroups(true)
+		archive.SetItemIndex(refs)
 
 		overviews = append(overviews, overview)
 		archives = append(archives, archive)
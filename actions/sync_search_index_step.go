@@ -0,0 +1,20 @@
+package actions
+
+import (
+	"github.com/mreider/agilemarkdown/backlog"
+)
+
+type SyncSearchIndexStep struct {
+	root *backlog.BacklogsStructure
+}
+
+func NewSyncSearchIndexStep(root *backlog.BacklogsStructure) *SyncSearchIndexStep {
+	return &SyncSearchIndexStep{root: root}
+}
+
+// Execute refreshes the search index in .am/cache for the files sync
+// just wrote. The cache ignores itself, so it never reaches a commit.
+func (s *SyncSearchIndexStep) Execute() error {
+	_, err := backlog.UpdateSearchIndex(s.root)
+	return err
+}
@@ -29,6 +29,16 @@ func (s *SyncValidateStep) Execute() error {
 			allErrs = append(allErrs, backlog.ValidateItem(item)...)
 		}
 	}
+	epicErrs, err := backlog.ValidateEpicRefs(s.root)
+	if err != nil {
+		return err
+	}
+	allErrs = append(allErrs, epicErrs...)
+	depErrs, err := backlog.ValidateDependencies(s.root)
+	if err != nil {
+		return err
+	}
+	allErrs = append(allErrs, depErrs...)
 
 	if len(allErrs) == 0 {
 		return nil
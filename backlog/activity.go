@@ -0,0 +1,462 @@
+package backlog
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mreider/agilemarkdown/git"
+)
+
+// Kinds of item change, shared by the activity feed and the sync commit
+// message.
+const (
+	ChangeCreated  = "created"
+	ChangeStatus   = "status"
+	ChangeEstimate = "estimate"
+	ChangeOwners   = "owners"
+	ChangeComment  = "comment"
+	ChangeRank     = "rank"
+	ChangeArchived = "archived"
+	ChangeMoved    = "moved"
+	ChangeDeleted  = "deleted"
+	ChangeEdited   = "edited"
+)
+
+// ItemChange is one frontmatter-level difference between two versions of
+// an item. From/To carry the old and new values; for comments To is the
+// first line of the new comment.
+type ItemChange struct {
+	Kind string
+	From string
+	To   string
+}
+
+// CompareItems lists what changed between two versions of one item:
+// status, estimate, owners, added comments and archiving. Returns nil
+// when none of those changed.
+func CompareItems(before, after *BacklogItem) []ItemChange {
+	var out []ItemChange
+	if old, cur := strings.ToLower(before.Status()), strings.ToLower(after.Status()); old != cur {
+		out = append(out, ItemChange{Kind: ChangeStatus, From: old, To: cur})
+	}
+	if old, cur := strings.TrimSpace(before.Estimate()), strings.TrimSpace(after.Estimate()); old != cur {
+		out = append(out, ItemChange{Kind: ChangeEstimate, From: old, To: cur})
+	}
+	if old, cur := before.Assignees(), after.Assignees(); !equalFoldStrings(old, cur) {
+		out = append(out, ItemChange{Kind: ChangeOwners, From: strings.Join(old, ", "), To: strings.Join(cur, ", ")})
+	}
+	seen := make(map[string]int)
+	for _, c := range before.Comments() {
+		seen[strings.Join(c.Text, "\n")]++
+	}
+	for _, c := range after.Comments() {
+		key := strings.Join(c.Text, "\n")
+		if seen[key] > 0 {
+			seen[key]--
+			continue
+		}
+		text := ""
+		for _, line := range c.Text {
+			if m := commentDatePrefixRe.FindStringSubmatch(line); m != nil {
+				line = m[2]
+			}
+			if line = strings.TrimSpace(line); line != "" {
+				text = line
+				break
+			}
+		}
+		out = append(out, ItemChange{Kind: ChangeComment, From: strings.Join(c.Users, ", "), To: text})
+	}
+	if !before.Archived() && after.Archived() {
+		out = append(out, ItemChange{Kind: ChangeArchived})
+	}
+	return out
+}
+
+func equalFoldStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// MovedEntries returns the entries of cur that changed position relative
+// to the other shared entries between old and cur: everything outside a
+// longest common subsequence of the two orders. Entries only one side has
+// are ignored.
+func MovedEntries(old, cur []string) []string {
+	inOld, inCur := toSet(old), toSet(cur)
+	var a, b []string
+	for _, p := range old {
+		if inCur[p] {
+			a = append(a, p)
+		}
+	}
+	for _, p := range cur {
+		if inOld[p] {
+			b = append(b, p)
+		}
+	}
+	// lcs[i][j] = LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	stay := make(map[string]bool)
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			stay[a[i]] = true
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	var moved []string
+	for _, p := range b {
+		if !stay[p] {
+			moved = append(moved, p)
+		}
+	}
+	return moved
+}
+
+// RepoPathKind classifies a repository-relative path.
+type RepoPathKind int
+
+const (
+	RepoPathOther RepoPathKind = iota
+	RepoPathItem
+	RepoPathOrder
+)
+
+// ClassifyRepoPath tells items (`<backlog>/<name>.md`, also under
+// archive/) and order files (`<backlog>/_priority.md`, `_icebox.md`) from
+// everything else. backlogs holds the backlog folder names.
+func ClassifyRepoPath(backlogs map[string]bool, path string) RepoPathKind {
+	parts := strings.Split(filepath.ToSlash(path), "/")
+	if len(parts) < 2 || !backlogs[parts[0]] || !strings.HasSuffix(path, ".md") {
+		return RepoPathOther
+	}
+	name := strings.TrimSuffix(parts[len(parts)-1], ".md")
+	if len(parts) == 2 && (parts[1] == priorityFileName || parts[1] == iceboxFileName) {
+		return RepoPathOrder
+	}
+	if len(parts) == 2 || (len(parts) == 3 && parts[1] == archiveDirectoryName) {
+		if !IsForbiddenItemName(name) {
+			return RepoPathItem
+		}
+	}
+	return RepoPathOther
+}
+
+// BacklogNames returns the folder names of the project's backlogs.
+func (s *BacklogsStructure) BacklogNames() (map[string]bool, error) {
+	dirs, err := s.BacklogDirs()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(dirs))
+	for _, d := range dirs {
+		names[filepath.Base(d)] = true
+	}
+	return names, nil
+}
+
+// ActivityEntry is one line of the activity feed: who did what to which
+// item, when, and in which commit.
+type ActivityEntry struct {
+	When    time.Time `json:"when"`
+	Author  string    `json:"author"`
+	Email   string    `json:"email,omitempty"`
+	Commit  string    `json:"commit"`
+	Backlog string    `json:"backlog"`
+	Path    string    `json:"path"`
+	Title   string    `json:"title"`
+	Kind    string    `json:"kind"`
+	From    string    `json:"from,omitempty"`
+	To      string    `json:"to,omitempty"`
+	Text    string    `json:"text"`
+}
+
+// ActivityFilter narrows the feed. Zero values do not filter. User matches
+// the commit author's name, email or email local part, or any of those
+// for the project user it resolves to.
+type ActivityFilter struct {
+	Since   time.Time
+	User    string
+	Backlog string
+	Limit   int
+}
+
+// Activity reconstructs a Pivotal-style activity feed from the git history
+// of item and order files: items added, status transitions, estimate and
+// owner changes, comments, re-ranks, archiving and deletions. Entries are
+// newest first.
+func Activity(root *BacklogsStructure, filter ActivityFilter) ([]ActivityEntry, error) {
+	backlogs, err := root.BacklogNames()
+	if err != nil {
+		return nil, err
+	}
+	since := ""
+	if !filter.Since.IsZero() {
+		since = filter.Since.Format(time.RFC3339)
+	}
+	log, err := git.LogChanges(root.Root(), since)
+	if err != nil {
+		return nil, err
+	}
+	blobs, err := git.NewBlobReader(root.Root())
+	if err != nil {
+		return nil, err
+	}
+	defer blobs.Close()
+
+	matchUser := activityUserMatcher(root, filter.User)
+	var feed []ActivityEntry
+	for _, commit := range log {
+		if !matchUser(commit.Author, commit.Email) {
+			continue
+		}
+		entries, err := commitActivity(commit, backlogs, blobs)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if filter.Backlog != "" && !strings.EqualFold(e.Backlog, filter.Backlog) {
+				continue
+			}
+			feed = append(feed, e)
+		}
+		if filter.Limit > 0 && len(feed) >= filter.Limit {
+			feed = feed[:filter.Limit]
+			break
+		}
+	}
+	return feed, nil
+}
+
+func activityUserMatcher(root *BacklogsStructure, user string) func(name, email string) bool {
+	user = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(user, "@")))
+	if user == "" {
+		return func(string, string) bool { return true }
+	}
+	wanted := map[string]bool{user: true}
+	if u := NewUserList(root.UsersDirectory()).User(user); u != nil {
+		wanted[strings.ToLower(u.Name())] = true
+		wanted[strings.ToLower(u.Nickname())] = true
+		for _, e := range u.Emails() {
+			wanted[strings.ToLower(e)] = true
+		}
+	}
+	return func(name, email string) bool {
+		email = strings.ToLower(email)
+		local := strings.SplitN(email, "@", 2)[0]
+		return wanted[strings.ToLower(name)] || wanted[email] || wanted[local]
+	}
+}
+
+func commitActivity(commit git.LogEntry, backlogs map[string]bool, blobs *git.BlobReader) ([]ActivityEntry, error) {
+	var out []ActivityEntry
+	add := func(path, title, kind, from, to string) {
+		e := ActivityEntry{
+			When: commit.When, Author: commit.Author, Email: commit.Email, Commit: commit.Hash,
+			Backlog: strings.SplitN(filepath.ToSlash(path), "/", 2)[0], Path: path, Title: title,
+			Kind: kind, From: from, To: to,
+		}
+		e.Text = activityText(e)
+		out = append(out, e)
+	}
+	load := func(path, blob string) (*BacklogItem, error) {
+		src, err := blobs.Read(blob)
+		if err != nil || src == "" {
+			return nil, err
+		}
+		return NewBacklogItem(strings.TrimSuffix(filepath.Base(path), ".md"), src), nil
+	}
+
+	created := make(map[string]bool)
+	orders := make(map[string]map[string][2][]OrderEntry) // backlog -> file -> {old, new}
+	for _, f := range commit.Files {
+		switch ClassifyRepoPath(backlogs, f.Path) {
+		case RepoPathOrder:
+			oldSrc, err := blobs.Read(f.OldBlob)
+			if err != nil {
+				return nil, err
+			}
+			newSrc, err := blobs.Read(f.NewBlob)
+			if err != nil {
+				return nil, err
+			}
+			dir, file := filepath.Split(filepath.ToSlash(f.Path))
+			dir = strings.TrimSuffix(dir, "/")
+			if orders[dir] == nil {
+				orders[dir] = make(map[string][2][]OrderEntry)
+			}
+			orders[dir][file] = [2][]OrderEntry{ParseOrderEntries(oldSrc), ParseOrderEntries(newSrc)}
+		case RepoPathItem:
+			oldPath := f.OldPath
+			if oldPath == "" {
+				oldPath = f.Path
+			}
+			before, err := load(oldPath, f.OldBlob)
+			if err != nil {
+				return nil, err
+			}
+			after, err := load(f.Path, f.NewBlob)
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case before == nil && after != nil:
+				created[filepath.Base(f.Path)] = true
+				add(f.Path, after.Title(), ChangeCreated, "", after.Type())
+			case after == nil && before != nil:
+				add(f.Path, before.Title(), ChangeDeleted, "", "")
+			case before != nil && after != nil:
+				renamed := f.OldPath != "" && f.OldPath != f.Path
+				if renamed {
+					archivedNow := strings.Contains(filepath.ToSlash(f.Path), "/"+archiveDirectoryName+"/") &&
+						!strings.Contains(filepath.ToSlash(f.OldPath), "/"+archiveDirectoryName+"/")
+					if archivedNow {
+						add(f.Path, after.Title(), ChangeArchived, "", "")
+					} else {
+						add(f.Path, after.Title(), ChangeMoved, f.OldPath, f.Path)
+					}
+				}
+				changes := CompareItems(before, after)
+				for _, c := range changes {
+					if c.Kind == ChangeArchived && renamed {
+						continue
+					}
+					add(f.Path, after.Title(), c.Kind, c.From, c.To)
+				}
+				if len(changes) == 0 && !renamed {
+					add(f.Path, after.Title(), ChangeEdited, "", "")
+				}
+			}
+		}
+	}
+
+	backlogNames := make([]string, 0, len(orders))
+	for name := range orders {
+		backlogNames = append(backlogNames, name)
+	}
+	sort.Strings(backlogNames)
+	for _, name := range backlogNames {
+		files := orders[name]
+		pri, ice := files[priorityFileName], files[iceboxFileName]
+		priOld, priNew := orderEntryPaths(pri[0]), orderEntryPaths(pri[1])
+		iceOld, iceNew := orderEntryPaths(ice[0]), orderEntryPaths(ice[1])
+		titles := make(map[string]string)
+		for _, list := range [][]OrderEntry{pri[0], pri[1], ice[0], ice[1]} {
+			for _, e := range list {
+				titles[e.Path] = e.Title
+			}
+		}
+		_, hasIce := files[iceboxFileName]
+		_, hasPri := files[priorityFileName]
+		for _, p := range priNew {
+			if created[p] || toSet(priOld)[p] {
+				continue
+			}
+			if hasIce && toSet(iceOld)[p] && !toSet(iceNew)[p] {
+				add(name+"/"+p, titles[p], ChangeRank, "icebox", "priority")
+			}
+		}
+		for _, p := range iceNew {
+			if created[p] || toSet(iceOld)[p] {
+				continue
+			}
+			if hasPri && toSet(priOld)[p] && !toSet(priNew)[p] {
+				add(name+"/"+p, titles[p], ChangeRank, "priority", "icebox")
+			}
+		}
+		newIndex := make(map[string]int, len(priNew))
+		for i, p := range priNew {
+			newIndex[p] = i
+		}
+		for _, p := range MovedEntries(priOld, priNew) {
+			add(name+"/"+p, titles[p], ChangeRank, "", fmt.Sprintf("#%d", newIndex[p]+1))
+		}
+	}
+	return out, nil
+}
+
+func orderEntryPaths(entries []OrderEntry) []string {
+	out := make([]string, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, e.Path)
+	}
+	return out
+}
+
+// activityText renders an entry the way Tracker's activity panel does:
+// actor, verb, quoted story title.
+func activityText(e ActivityEntry) string {
+	title := fmt.Sprintf("%q", e.Title)
+	switch e.Kind {
+	case ChangeCreated:
+		if e.To != "" {
+			return fmt.Sprintf("%s added this %s: %s", e.Author, e.To, title)
+		}
+		return fmt.Sprintf("%s added %s", e.Author, title)
+	case ChangeStatus:
+		if e.To == UnstartedStatus.Name {
+			return fmt.Sprintf("%s unstarted %s", e.Author, title)
+		}
+		return fmt.Sprintf("%s %s %s", e.Author, e.To, title)
+	case ChangeEstimate:
+		if e.From == "" {
+			return fmt.Sprintf("%s estimated %s as %s points", e.Author, title, e.To)
+		}
+		if e.To == "" {
+			return fmt.Sprintf("%s removed the estimate of %s", e.Author, title)
+		}
+		return fmt.Sprintf("%s changed the estimate of %s from %s to %s points", e.Author, title, e.From, e.To)
+	case ChangeOwners:
+		if e.To == "" {
+			return fmt.Sprintf("%s removed the owners of %s", e.Author, title)
+		}
+		return fmt.Sprintf("%s set the owners of %s to %s", e.Author, title, e.To)
+	case ChangeComment:
+		return fmt.Sprintf("%s added a comment to %s: %q", e.Author, title, e.To)
+	case ChangeRank:
+		switch {
+		case e.To == "icebox":
+			return fmt.Sprintf("%s moved %s into the icebox", e.Author, title)
+		case e.From == "icebox":
+			return fmt.Sprintf("%s moved %s out of the icebox", e.Author, title)
+		}
+		return fmt.Sprintf("%s moved %s to %s in priority", e.Author, title, e.To)
+	case ChangeArchived:
+		return fmt.Sprintf("%s archived %s", e.Author, title)
+	case ChangeMoved:
+		return fmt.Sprintf("%s moved %s from %s", e.Author, title, e.From)
+	case ChangeDeleted:
+		return fmt.Sprintf("%s deleted %s", e.Author, title)
+	}
+	return fmt.Sprintf("%s edited %s", e.Author, title)
+}
@@ -0,0 +1,100 @@
+package backlog
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// commitAs writes files and commits them as author. A non-empty when
+// (RFC3339) backdates the commit.
+func commitAs(t *testing.T, dir, author, when string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{{"add", "-A"}, {"commit", "-q", "-m", "change"}} {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=" + author, "-c", "user.email=" + strings.ToLower(author) + "@example.com"}, args...)...)
+		cmd.Dir = dir
+		if when != "" {
+			cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+when, "GIT_COMMITTER_DATE="+when)
+		}
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+}
+
+func activityItem(status, estimate string, comments ...string) string {
+	body := "---\ntitle: Login\nstatus: " + status + "\ntype: feature\nestimate: \"" + estimate + "\"\n---\n\nBody.\n"
+	if len(comments) > 0 {
+		body += "\n## Comments\n"
+		for _, c := range comments {
+			body += "\n@bob 2026-01-02\n" + c + "\n"
+		}
+	}
+	return body
+}
+
+func TestActivityFeed(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	other := "---\ntitle: Signup\nstatus: unstarted\n---\n"
+	commitAs(t, dir, "Alice", "", map[string]string{
+		"product/login.md":     activityItem("unstarted", "1"),
+		"product/signup.md":    other,
+		"product/_priority.md": "# Priority\n\n- [Login](login.md)\n- [Signup](signup.md)\n",
+	})
+	commitAs(t, dir, "Bob", "", map[string]string{
+		"product/login.md": activityItem("started", "3", "on it"),
+	})
+	commitAs(t, dir, "Alice", "", map[string]string{
+		"product/_priority.md": "# Priority\n\n- [Signup](signup.md)\n- [Login](login.md)\n",
+	})
+
+	feed, err := Activity(NewBacklogsStructure(dir), ActivityFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var texts []string
+	for _, e := range feed {
+		texts = append(texts, e.Text)
+	}
+	want := []string{
+		`Alice moved "Login" to #2 in priority`,
+		`Bob started "Login"`,
+		`Bob changed the estimate of "Login" from 1 to 3 points`,
+		`Bob added a comment to "Login": "on it"`,
+		`Alice added this feature: "Login"`,
+	}
+	got := strings.Join(texts, "\n")
+	for _, w := range want {
+		if !strings.Contains(got, w) {
+			t.Errorf("feed missing %q:\n%s", w, got)
+		}
+	}
+	if feed[0].Kind != ChangeRank {
+		t.Errorf("want newest first, got %s first", feed[0].Kind)
+	}
+
+	bobs, err := Activity(NewBacklogsStructure(dir), ActivityFilter{User: "bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bobs) != 3 {
+		t.Errorf("want 3 entries for bob, got %d", len(bobs))
+	}
+}
@@ -0,0 +1,60 @@
+package backlog
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// WriteFilesAtomically writes every file or none of them. New contents
+// go to temporary files next to their targets first; only when all of
+// those are written are they renamed into place, and a failed rename
+// puts back the files already replaced.
+func WriteFilesAtomically(files map[string][]byte) error {
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	type original struct {
+		data   []byte
+		exists bool
+	}
+	originals := make(map[string]original, len(paths))
+	var tmps []string
+	cleanup := func() {
+		for _, t := range tmps {
+			_ = os.Remove(t)
+		}
+	}
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil && !os.IsNotExist(err) {
+			cleanup()
+			return err
+		}
+		originals[p] = original{data: data, exists: err == nil}
+		tmp := p + ".am-tmp"
+		if err := os.WriteFile(tmp, files[p], 0644); err != nil {
+			cleanup()
+			return err
+		}
+		tmps = append(tmps, tmp)
+	}
+	for i, p := range paths {
+		if err := os.Rename(tmps[i], p); err != nil {
+			for _, done := range paths[:i] {
+				if o := originals[done]; o.exists {
+					_ = os.WriteFile(done, o.data, 0644)
+				} else {
+					_ = os.Remove(done)
+				}
+			}
+			tmps = tmps[i:]
+			cleanup()
+			return fmt.Errorf("%s: %w (earlier files restored)", p, err)
+		}
+	}
+	return nil
+}
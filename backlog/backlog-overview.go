@@ -18,6 +18,7 @@ var (
 
 type BacklogOverview struct {
 	markdown *markdown.Content
+	refs     *ItemIndex
 }
 
 func LoadBacklogOverview(overviewPath string) (*BacklogOverview, error) {
@@ -29,7 +30,7 @@ func LoadBacklogOverview(overviewPath string) (*BacklogOverview, error) {
 }
 
 func NewBacklogOverview(content *markdown.Content) *BacklogOverview {
-	return &BacklogOverview{content}
+	return &BacklogOverview{markdown: content}
 }
 
 func (overview *BacklogOverview) Save() error {
@@ -47,6 +48,11 @@ func (overview *BacklogOverview) Title() string {
 	return overview.markdown.Title()
 }
 
+// SetItemIndex makes Update link the #IDs in the rows it generates.
+func (overview *BacklogOverview) SetItemIndex(refs *ItemIndex) {
+	overview.refs = refs
+}
+
 func (overview *BacklogOverview) SetTitle(title string) {
 	overview.markdown.SetTitle(title)
 }
@@ -94,6 +100,11 @@ func (overview *BacklogOverview) Update(items []*BacklogItem, sorter *BacklogIte
 			}
 			rootDir := filepath.Dir(overview.markdown.ContentPath())
 			newLines := BacklogView{}.WriteMarkdownItems(items, status, rootDir, filepath.Join(rootDir, TagsDirectoryName), userList)
+			if overview.refs != nil {
+				for i, line := range newLines {
+					newLines[i] = LinkItemRefs(line, rootDir, overview.refs)
+				}
+			}
 			group.ReplaceLines(newLines)
 		}
 	}
@@ -12,6 +12,7 @@ import (
 )
 
 const (
+	itemKeyID       = "id"
 	itemKeyTitle    = "title"
 	itemKeyProject  = "project"
 	itemKeyStatus   = "status"
@@ -33,6 +34,8 @@ const (
 	itemKeyReleaseDate = "release_date"
 	itemKeyBlocked       = "blocked"
 	itemKeyBlockedReason = "blocked_reason"
+	itemKeyBlockedBy     = "blocked_by"
+	itemKeyBlocks        = "blocks"
 
 	timelineKeyStart = "start"
 	timelineKeyEnd   = "end"
@@ -52,7 +55,13 @@ type BacklogItem struct {
 }
 
 func LoadBacklogItem(itemPath string) (*BacklogItem, error) {
-	f, err := markdown.LoadFrontmatter(itemPath)
+	var f *markdown.FrontmatterFile
+	var err error
+	if itemCache != nil {
+		f, err = itemCache.load(itemPath)
+	} else {
+		f, err = markdown.LoadFrontmatter(itemPath)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -69,6 +78,14 @@ func (item *BacklogItem) Save() error                { return item.file.Save() }
 func (item *BacklogItem) Name() string               { return item.name }
 func (item *BacklogItem) Path() string               { return item.file.Path() }
 func (item *BacklogItem) Content() []byte            { return item.file.Bytes() }
+// ID is the project-wide number sync assigns (shown as #ID); 0 until then.
+func (item *BacklogItem) ID() int {
+	id, _ := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(item.file.GetString(itemKeyID)), "#"))
+	return id
+}
+
+func (item *BacklogItem) SetID(id int) { item.file.SetInt(itemKeyID, id) }
+
 func (item *BacklogItem) Title() string              { return item.file.GetString(itemKeyTitle) }
 func (item *BacklogItem) SetTitle(title string)      { item.file.SetString(itemKeyTitle, title) }
 func (item *BacklogItem) Project() string            { return item.file.GetString(itemKeyProject) }
@@ -238,6 +255,23 @@ func (item *BacklogItem) SetBlocked(blocked bool, reason string) {
 	item.file.SetString(itemKeyBlockedReason, "")
 }
 
+// BlockedBy lists the stories this one waits on, as written in the
+// frontmatter: paths relative to the project root, or bare file names
+// for stories in the same backlog.
+func (item *BacklogItem) BlockedBy() []string { return item.file.GetStringSlice(itemKeyBlockedBy) }
+
+func (item *BacklogItem) SetBlockedBy(refs []string) {
+	item.file.SetStringSlice(itemKeyBlockedBy, cleanDependencyRefs(refs))
+}
+
+// Blocks lists the stories waiting on this one. It mirrors blocked_by on
+// the other side; sync keeps the two in step.
+func (item *BacklogItem) Blocks() []string { return item.file.GetStringSlice(itemKeyBlocks) }
+
+func (item *BacklogItem) SetBlocks(refs []string) {
+	item.file.SetStringSlice(itemKeyBlocks, cleanDependencyRefs(refs))
+}
+
 func (item *BacklogItem) Comments() []*Comment {
 	return parseBodyComments(item.file.Body())
 }
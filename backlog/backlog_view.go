@@ -167,7 +167,11 @@ func (bv BacklogView) WriteMarkdownItems(items []*BacklogItem, status *BacklogIt
 		if assignedUser != nil {
 			assignedLink = MakeUserLink(assignedUser, assigned, baseDir)
 		}
-		line := fmt.Sprintf("| %s | %s | %s | %s |", assignedLink, MakeItemLink(item, baseDir), item.Estimate(), MakeTagLinks(item.Tags(), tagsDir, baseDir))
+		title := MakeItemLink(item, baseDir)
+		if item.ID() > 0 {
+			title += fmt.Sprintf(" (#%d)", item.ID())
+		}
+		line := fmt.Sprintf("| %s | %s | %s | %s |", assignedLink, title, item.Estimate(), MakeTagLinks(item.Tags(), tagsDir, baseDir))
 		result = append(result, line)
 	}
 	if bv.needTotalPoints(status) && len(items) > 0 {
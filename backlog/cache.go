@@ -0,0 +1,289 @@
+package backlog
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/mreider/agilemarkdown/markdown"
+)
+
+// cacheSettleTime is how old a file's modification time must be before
+// its parse is cached; see ItemCache.load.
+const cacheSettleTime = 2 * time.Second
+
+// itemCache, once enabled, keeps parsed items in memory keyed by path so
+// a long-running process (the MCP server) doesn't reparse every YAML
+// file on every call. Nil means no caching, which is what the CLI wants.
+var itemCache *ItemCache
+
+// ItemCache holds one parsed copy of each item file it has loaded. An
+// entry is only used while the file's modification time and size still
+// match, so an edit the watcher hasn't noticed yet is never served stale.
+type ItemCache struct {
+	mu       sync.Mutex
+	items    map[string]cachedItem
+	snapshot *Snapshot
+}
+
+type cachedItem struct {
+	modTime time.Time
+	size    int64
+	file    *markdown.FrontmatterFile
+}
+
+// EnableItemCache turns on the process-wide item cache and returns it.
+// Calling it again returns the same cache.
+func EnableItemCache() *ItemCache {
+	if itemCache == nil {
+		itemCache = &ItemCache{items: map[string]cachedItem{}}
+	}
+	return itemCache
+}
+
+// Len is the number of cached items.
+func (c *ItemCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+// Invalidate drops the entries for paths.
+func (c *ItemCache) Invalidate(paths ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, p := range paths {
+		delete(c.items, p)
+	}
+}
+
+func (c *ItemCache) lastSnapshot() *Snapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.snapshot
+}
+
+// setLastSnapshot keeps snap so the next journal snapshot only reads
+// files that changed since.
+func (c *ItemCache) setLastSnapshot(snap *Snapshot) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.snapshot = snap
+}
+
+// load returns a private copy of the parsed file at path, parsing it only
+// when the cached copy is missing or out of date.
+func (c *ItemCache) load(path string) (*markdown.FrontmatterFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		c.Invalidate(path)
+		return markdown.LoadFrontmatter(path)
+	}
+	c.mu.Lock()
+	entry, ok := c.items[path]
+	c.mu.Unlock()
+	if ok && entry.modTime.Equal(info.ModTime()) && entry.size == info.Size() {
+		return entry.file.Clone(), nil
+	}
+	f, err := markdown.LoadFrontmatter(path)
+	if err != nil {
+		return nil, err
+	}
+	// Like git's racy-clean check: a file modified moments ago may be
+	// rewritten again within the filesystem's timestamp granularity at
+	// the same size, so it is only cached once it has settled.
+	if time.Since(info.ModTime()) > cacheSettleTime {
+		c.mu.Lock()
+		c.items[path] = cachedItem{modTime: info.ModTime(), size: info.Size(), file: f.Clone()}
+		c.mu.Unlock()
+	}
+	return f, nil
+}
+
+// Watcher follows the files am writes (see journalScope) through
+// fsnotify, drops changed markdown and YAML files from the item cache
+// and reports them. Folders that appear later, like a new backlog or
+// archive, are watched as they appear.
+type Watcher struct {
+	root *BacklogsStructure
+	fsw  *fsnotify.Watcher
+}
+
+// watchSettle is how long Run waits after the last event before it
+// reports a batch: one editor save or git pull is several events.
+const watchSettle = 100 * time.Millisecond
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func (s fileStamp) equal(o fileStamp) bool {
+	return s.modTime.Equal(o.modTime) && s.size == o.size
+}
+
+// NewWatcher starts watching the project root, its backlog folders and
+// .am. Run reports what changes from then on.
+func NewWatcher(root *BacklogsStructure) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w := &Watcher{root: root, fsw: fsw}
+	dirs, _, err := journalScope(root)
+	if err == nil {
+		err = fsw.Add(root.Root())
+	}
+	for _, dir := range dirs {
+		if err != nil {
+			break
+		}
+		_, err = w.addTree(filepath.Join(root.Root(), dir))
+	}
+	if err != nil {
+		fsw.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Run reports changes until done is closed, passing each batch of
+// changed paths, relative to the root with forward slashes, to notify.
+// It closes the watcher on return.
+func (w *Watcher) Run(done <-chan struct{}, notify func([]string)) {
+	defer w.fsw.Close()
+	pending := map[string]bool{}
+	settle := time.NewTimer(watchSettle)
+	settle.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case ev, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			for _, rel := range w.handle(ev) {
+				pending[rel] = true
+			}
+			if len(pending) > 0 {
+				settle.Reset(watchSettle)
+			}
+		case _, ok := <-w.fsw.Errors:
+			// A dropped event is only a late notification: the item
+			// cache checks each file's stamp before serving it.
+			if !ok {
+				return
+			}
+		case <-settle.C:
+			changed := make([]string, 0, len(pending))
+			for rel := range pending {
+				changed = append(changed, rel)
+			}
+			clear(pending)
+			sort.Strings(changed)
+			if itemCache != nil {
+				for _, rel := range changed {
+					itemCache.Invalidate(filepath.Join(w.root.Root(), filepath.FromSlash(rel)))
+				}
+			}
+			notify(changed)
+		}
+	}
+}
+
+// handle returns the files one event changed. A new folder in scope is
+// watched, and the files already in it count as changed.
+func (w *Watcher) handle(ev fsnotify.Event) []string {
+	if ev.Op == fsnotify.Chmod {
+		return nil
+	}
+	rel, err := filepath.Rel(w.root.Root(), ev.Name)
+	if err != nil {
+		return nil
+	}
+	rel = filepath.ToSlash(rel)
+	if ev.Has(fsnotify.Create) {
+		top, nested := rel, false
+		if i := strings.Index(rel, "/"); i >= 0 {
+			top, nested = rel[:i], true
+		}
+		if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+			if nested || w.inScope(top) {
+				found, _ := w.addTree(ev.Name)
+				return found
+			}
+			return nil
+		}
+		// A new overview puts the folder next to it in scope.
+		if name, ok := strings.CutSuffix(rel, ".md"); ok && !nested && w.inScope(name) {
+			if info, err := os.Stat(filepath.Join(w.root.Root(), name)); err == nil && info.IsDir() && !slices.Contains(w.fsw.WatchList(), filepath.Join(w.root.Root(), name)) {
+				found, _ := w.addTree(filepath.Join(w.root.Root(), name))
+				return append(found, rel)
+			}
+		}
+	}
+	if !watchedFile(rel) {
+		return nil
+	}
+	return []string{rel}
+}
+
+// inScope reports whether the top-level folder name holds files am
+// writes, as journalScope decides.
+func (w *Watcher) inScope(name string) bool {
+	if name == ".am" || IsForbiddenBacklogName(name) {
+		return true
+	}
+	if strings.HasPrefix(name, ".") || untrackedDirs[name] {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(w.root.Root(), name+".md"))
+	return err == nil
+}
+
+// addTree watches dir and every folder under it, returning the files
+// already there.
+func (w *Watcher) addTree(dir string) ([]string, error) {
+	var found []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(w.root.Root(), path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if untrackedDirs[rel] {
+				return filepath.SkipDir
+			}
+			return w.fsw.Add(path)
+		}
+		if watchedFile(rel) {
+			found = append(found, rel)
+		}
+		return nil
+	})
+	return found, err
+}
+
+func watchedFile(rel string) bool {
+	for dir := range untrackedDirs {
+		if strings.HasPrefix(rel, dir+"/") {
+			return false
+		}
+	}
+	ext := strings.ToLower(filepath.Ext(rel))
+	return ext == ".md" || ext == ".yaml" || ext == ".yml"
+}
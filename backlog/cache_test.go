@@ -0,0 +1,123 @@
+package backlog
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestItemCacheAndWatcher(t *testing.T) {
+	dir := t.TempDir()
+	root := NewBacklogsStructure(dir)
+	if err := os.MkdirAll(filepath.Join(dir, "product"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "product", "login.md")
+	old := time.Now().Add(-time.Minute)
+	write := func(text string, modTime time.Time) {
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("---\ntitle: Login\nestimate: \"3\"\n---\n", old)
+	if err := os.WriteFile(filepath.Join(dir, "product.md"), []byte("# product\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewWatcher(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batches := make(chan []string, 16)
+	done := make(chan struct{})
+	defer close(done)
+	go w.Run(done, func(changed []string) { batches <- changed })
+	// wait collects batches until they add up to want.
+	wait := func(want string) {
+		t.Helper()
+		got := map[string]bool{}
+		timeout := time.After(5 * time.Second)
+		for {
+			var paths []string
+			for p := range got {
+				paths = append(paths, p)
+			}
+			sort.Strings(paths)
+			if strings.Join(paths, ",") == want {
+				return
+			}
+			select {
+			case batch := <-batches:
+				for _, p := range batch {
+					got[p] = true
+				}
+			case <-timeout:
+				t.Fatalf("changed = %v, want %s", paths, want)
+			}
+		}
+	}
+
+	cache := EnableItemCache()
+	defer func() { itemCache = nil }()
+	item, err := LoadBacklogItem(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cache.Len() != 1 {
+		t.Fatalf("cache has %d items after one load", cache.Len())
+	}
+	// A caller's unsaved edits never leak into the cached copy.
+	item.SetEstimate("8")
+	if again, _ := LoadBacklogItem(path); again.Estimate() != "3" {
+		t.Errorf("cached item was mutated: estimate %q", again.Estimate())
+	}
+
+	// An edit at the same size but a new modification time is reparsed
+	// even before the watcher runs.
+	write("---\ntitle: Login\nestimate: \"5\"\n---\n", old.Add(time.Second))
+	if again, _ := LoadBacklogItem(path); again.Estimate() != "5" {
+		t.Errorf("stale cache hit: estimate %q", again.Estimate())
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "product", "signup.md"), []byte("---\ntitle: Signup\n---\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wait("product/login.md,product/signup.md")
+	if cache.Len() != 0 {
+		t.Errorf("watcher left %d changed items cached", cache.Len())
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	wait("product/login.md")
+
+	// Files outside the backlog folders are not watched; a new backlog
+	// is, once its overview is there.
+	if err := os.MkdirAll(filepath.Join(dir, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "src", "notes.md"), []byte("x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "platform"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "platform.md"), []byte("# platform\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wait("platform.md")
+	if err := os.WriteFile(filepath.Join(dir, "platform", "setup.md"), []byte("---\ntitle: Setup\n---\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wait("platform/setup.md")
+	select {
+	case batch := <-batches:
+		t.Errorf("quiet project reported %v", batch)
+	case <-time.After(3 * watchSettle):
+	}
+}
@@ -0,0 +1,169 @@
+package backlog
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mreider/agilemarkdown/git"
+)
+
+// CFD bands an item can be in at a point of its git history.
+const (
+	cfdNone = iota
+	cfdBacklog
+	cfdInFlight
+	cfdAccepted
+)
+
+type cfdEvent struct {
+	when  time.Time
+	state int
+}
+
+type iceboxEvent struct {
+	when  time.Time
+	items map[string]bool
+}
+
+// CFDRowsFromGit builds the same per-day cumulative flow as CFDRows, but
+// from every committed revision of each item instead of the timestamps
+// the item carries today. A story rejected and restarted goes back to in
+// flight on the day it happened, a deleted story leaves the chart, and a
+// story sitting in _icebox.md is kept out of the backlog band until it is
+// moved to priority. Uncommitted edits are not seen.
+func CFDRowsFromGit(root *BacklogsStructure, start, end time.Time) ([]CFDRow, error) {
+	if !end.After(start) {
+		return nil, nil
+	}
+	backlogs, err := root.BacklogNames()
+	if err != nil {
+		return nil, err
+	}
+	log, err := git.LogChanges(root.Root(), "")
+	if err != nil {
+		return nil, err
+	}
+	blobs, err := git.NewBlobReader(root.Root())
+	if err != nil {
+		return nil, err
+	}
+	defer blobs.Close()
+
+	// item key (backlog/file.md, archive folded in) -> state changes,
+	// backlog -> icebox contents, both oldest first
+	items := make(map[string][]cfdEvent)
+	icebox := make(map[string][]iceboxEvent)
+	for i := len(log) - 1; i >= 0; i-- {
+		commit := log[i]
+		for _, f := range commit.Files {
+			switch ClassifyRepoPath(backlogs, f.Path) {
+			case RepoPathOrder:
+				if filepath.Base(f.Path) != iceboxFileName {
+					continue
+				}
+				src, err := blobs.Read(f.NewBlob)
+				if err != nil {
+					return nil, err
+				}
+				set := make(map[string]bool)
+				for _, e := range ParseOrderEntries(src) {
+					set[e.Path] = true
+				}
+				name := cfdBacklogName(f.Path)
+				icebox[name] = append(icebox[name], iceboxEvent{when: commit.When, items: set})
+			case RepoPathItem:
+				if f.OldPath != "" && f.OldPath != f.Path && cfdItemKey(f.OldPath) != cfdItemKey(f.Path) {
+					old := cfdItemKey(f.OldPath)
+					items[old] = append(items[old], cfdEvent{when: commit.When, state: cfdNone})
+				}
+				src, err := blobs.Read(f.NewBlob)
+				if err != nil {
+					return nil, err
+				}
+				state := cfdNone
+				if src != "" {
+					state = cfdState(NewBacklogItem(strings.TrimSuffix(filepath.Base(f.Path), ".md"), src))
+				}
+				key := cfdItemKey(f.Path)
+				items[key] = append(items[key], cfdEvent{when: commit.When, state: state})
+			}
+		}
+	}
+
+	days := int(end.Sub(start).Hours()/24 + 0.5)
+	if days <= 0 {
+		days = 1
+	}
+	rows := make([]CFDRow, days)
+	for d := 0; d < days; d++ {
+		rows[d].Day = start.AddDate(0, 0, d)
+	}
+	keys := make([]string, 0, len(items))
+	for k := range items {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		events := items[key]
+		name := cfdBacklogName(key)
+		file := filepath.Base(key)
+		for d := range rows {
+			cutoff := rows[d].Day.AddDate(0, 0, 1)
+			state := cfdNone
+			for _, e := range events {
+				if e.when.After(cutoff) {
+					break
+				}
+				state = e.state
+			}
+			switch state {
+			case cfdAccepted:
+				rows[d].Accepted++
+			case cfdInFlight:
+				rows[d].InFlight++
+			case cfdBacklog:
+				if !iceboxedAt(icebox[name], file, cutoff) {
+					rows[d].Backlog++
+				}
+			}
+		}
+	}
+	return rows, nil
+}
+
+// cfdState maps an item revision to its CFD band. Rejected stories are
+// back in flight; releases are date markers and never counted.
+func cfdState(item *BacklogItem) int {
+	if item.Type() == "release" {
+		return cfdNone
+	}
+	switch strings.ToLower(item.Status()) {
+	case AcceptedStatus.Name:
+		return cfdAccepted
+	case StartedStatus.Name, FinishedStatus.Name, DeliveredStatus.Name, RejectedStatus.Name:
+		return cfdInFlight
+	}
+	return cfdBacklog
+}
+
+func iceboxedAt(events []iceboxEvent, file string, cutoff time.Time) bool {
+	in := false
+	for _, e := range events {
+		if e.when.After(cutoff) {
+			break
+		}
+		in = e.items[file]
+	}
+	return in
+}
+
+// cfdItemKey identifies an item across archive moves: backlog/file.md.
+func cfdItemKey(path string) string {
+	return cfdBacklogName(path) + "/" + filepath.Base(path)
+}
+
+func cfdBacklogName(path string) string {
+	return strings.SplitN(filepath.ToSlash(path), "/", 2)[0]
+}
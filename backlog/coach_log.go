@@ -0,0 +1,126 @@
+package backlog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mreider/agilemarkdown/utils"
+)
+
+// CoachOverride is one line of `.am/coach-log.jsonl`: someone went ahead
+// with an action the coach refused or nudged against, and said why. The
+// log is committed with the backlog and left out of the undo journal, so
+// an override cannot be quietly taken back.
+type CoachOverride struct {
+	Time   string `json:"time"`
+	Author string `json:"author,omitempty"`
+	Source string `json:"source"`
+	Rule   string `json:"rule"`
+	Action string `json:"action"`
+	Item   string `json:"item,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// AppendCoachOverride stamps o with the current time and adds it to the
+// log.
+func AppendCoachOverride(root *BacklogsStructure, o CoachOverride) (CoachOverride, error) {
+	if strings.TrimSpace(o.Reason) == "" {
+		return o, fmt.Errorf("an override needs a reason")
+	}
+	o.Time = utils.GetCurrentTimestamp()
+	var line bytes.Buffer
+	enc := json.NewEncoder(&line)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(o); err != nil {
+		return o, err
+	}
+	if err := os.MkdirAll(filepath.Dir(root.CoachLogFile()), 0755); err != nil {
+		return o, err
+	}
+	f, err := os.OpenFile(root.CoachLogFile(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return o, err
+	}
+	if _, err := f.Write(line.Bytes()); err != nil {
+		f.Close()
+		return o, err
+	}
+	return o, f.Close()
+}
+
+// LoadCoachOverrides returns the log, oldest first. A missing log is
+// empty; a line that does not parse is skipped, since a bad merge should
+// not hide every other override.
+func LoadCoachOverrides(root *BacklogsStructure) ([]CoachOverride, error) {
+	f, err := os.Open(root.CoachLogFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	var out []CoachOverride
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1<<20)
+	for sc.Scan() {
+		var o CoachOverride
+		if json.Unmarshal(sc.Bytes(), &o) == nil && o.Rule != "" {
+			out = append(out, o)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time < out[j].Time })
+	return out, nil
+}
+
+// CoachOverrideSummary is one rule's overrides, for the retro.
+type CoachOverrideSummary struct {
+	Rule   string        `json:"rule"`
+	Recent int           `json:"recent" jsonschema:"overrides since the given moment"`
+	Total  int           `json:"total"`
+	Last   CoachOverride `json:"last"`
+}
+
+// SummarizeCoachOverrides groups overrides by rule, the most overridden
+// since since first.
+func SummarizeCoachOverrides(overrides []CoachOverride, since time.Time) []CoachOverrideSummary {
+	byRule := map[string]*CoachOverrideSummary{}
+	var out []*CoachOverrideSummary
+	for _, o := range overrides {
+		s := byRule[o.Rule]
+		if s == nil {
+			s = &CoachOverrideSummary{Rule: o.Rule}
+			byRule[o.Rule] = s
+			out = append(out, s)
+		}
+		s.Total++
+		if at, err := utils.ParseTimestamp(o.Time); err == nil && !at.Before(since) {
+			s.Recent++
+		}
+		s.Last = o
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Recent != out[j].Recent {
+			return out[i].Recent > out[j].Recent
+		}
+		if out[i].Total != out[j].Total {
+			return out[i].Total > out[j].Total
+		}
+		return out[i].Rule < out[j].Rule
+	})
+	result := make([]CoachOverrideSummary, len(out))
+	for i, s := range out {
+		result[i] = *s
+	}
+	return result
+}
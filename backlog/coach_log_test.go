@@ -0,0 +1,44 @@
+package backlog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCoachOverrideLog(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".am"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	root := NewBacklogsStructure(dir)
+	snap, err := TakeSnapshot(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rule := range []string{"8-point-hard-cap", "bugs-are-tax", "8-point-hard-cap"} {
+		if _, err := AppendCoachOverride(root, CoachOverride{Source: "cli", Rule: rule, Action: "set_estimate", Reason: "spike"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := AppendCoachOverride(root, CoachOverride{Rule: "bugs-are-tax"}); err == nil {
+		t.Error("an override without a reason should fail")
+	}
+	// The log stays out of the undo journal.
+	if e, err := RecordJournal(root, snap, JournalEntry{Source: "cli", Command: "coach-check"}); err != nil || e != nil {
+		t.Errorf("journal entry for an override: %+v, %v", e, err)
+	}
+
+	overrides, err := LoadCoachOverrides(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := SummarizeCoachOverrides(overrides, time.Now().Add(-time.Hour))
+	if len(sum) != 2 || sum[0].Rule != "8-point-hard-cap" || sum[0].Recent != 2 || sum[1].Total != 1 {
+		t.Fatalf("summary = %+v", sum)
+	}
+	if sum = SummarizeCoachOverrides(overrides, time.Now().Add(time.Hour)); sum[0].Recent != 0 || sum[0].Total != 2 {
+		t.Fatalf("summary with nothing recent = %+v", sum)
+	}
+}
@@ -177,28 +177,10 @@ func (imp *CsvImporter) createItemIfNotExists(line []string, userList *UserList)
 	assigned := imp.cellValue(line, "owned by")
 	description := imp.cellValue(line, "description")
 
-	// Pivotal's CSV exports use either "Mon 2, 2006" or ISO-ish forms.
-	// Normalize to our timestamp.
-	parseDate := func(s string) string {
-		s = strings.TrimSpace(s)
-		if s == "" {
-			return ""
-		}
-		layouts := []string{"Jan 2, 2006", "2006-01-02", "2006-01-02T15:04:05Z", time.RFC3339}
-		for _, layout := range layouts {
-			if t, err := time.Parse(layout, s); err == nil {
-				if layout == "Jan 2, 2006" || layout == "2006-01-02" {
-					t = t.Add(12 * time.Hour)
-				}
-				return utils.GetTimestamp(t)
-			}
-		}
-		return ""
-	}
-	created = parseDate(created)
-	acceptedAt := parseDate(imp.cellValue(line, "accepted at"))
-	finishedAt := parseDate(imp.cellValue(line, "started at"))
-	deliveredAt := parseDate(imp.cellValue(line, "delivered at"))
+	created = parseImportDate(created)
+	acceptedAt := parseImportDate(imp.cellValue(line, "accepted at"))
+	finishedAt := parseImportDate(imp.cellValue(line, "started at"))
+	deliveredAt := parseImportDate(imp.cellValue(line, "delivered at"))
 
 	description += `
 
@@ -244,9 +226,34 @@ func (imp *CsvImporter) createItemIfNotExists(line []string, userList *UserList)
 	return item.Save()
 }
 
+// parseImportDate normalizes the date forms found in Pivotal exports
+// ("Mon 2, 2006", ISO dates, RFC3339) to our timestamp. Date-only values
+// land at noon UTC so they stay on the same day in any timezone.
+func parseImportDate(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ""
+	}
+	layouts := []string{"Jan 2, 2006", "2006-01-02", "Jan 2, 2006 3:04 PM", "2006-01-02T15:04:05Z", time.RFC3339}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			if layout == "Jan 2, 2006" || layout == "2006-01-02" {
+				t = t.Add(12 * time.Hour)
+			}
+			return utils.GetTimestamp(t)
+		}
+	}
+	return ""
+}
+
 func (imp *CsvImporter) resolveUnknownUsers(line []string, userList *UserList) error {
-	allAssigned := imp.cellValues(line, "owned by")
-	for _, assigned := range allAssigned {
+	return resolveImportUsers(imp.cellValues(line, "owned by"), userList)
+}
+
+// resolveImportUsers makes sure every name has a user page, matching git
+// authors first and auto-creating the rest.
+func resolveImportUsers(names []string, userList *UserList) error {
+	for _, assigned := range names {
 		user := userList.User(assigned)
 		if user == nil {
 			unresolvedUsers, err := userList.ResolveGitUsers([]string{assigned})
@@ -0,0 +1,546 @@
+package backlog
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mreider/agilemarkdown/utils"
+)
+
+// Story dependencies. An item names the stories it waits on in
+// `blocked_by:` and the ones it holds up in `blocks:`. Entries are paths
+// relative to the project root ("product/login.md"); a bare file name
+// means the same backlog. One side is enough to declare an edge: sync
+// writes the mirror entry, and drops both once the blocker is accepted.
+
+// DependencyEdge says Blocked cannot start until Blocker is accepted.
+// Both ends are dependency keys ("backlog/file.md").
+type DependencyEdge struct {
+	Blocker string
+	Blocked string
+}
+
+// DependencyGraph holds every blocked_by/blocks edge in the project.
+// Items are keyed by backlog and file name, so references survive an
+// item moving into archive/.
+type DependencyGraph struct {
+	root     *BacklogsStructure
+	items    map[string]*BacklogItem
+	blockers map[string][]string
+	dangling []ItemValidationError
+}
+
+// LoadDependencyGraph reads every item (archived ones included, so
+// accepted blockers still resolve) and collects both sides of each edge.
+func LoadDependencyGraph(root *BacklogsStructure) (*DependencyGraph, error) {
+	g := &DependencyGraph{root: root, items: map[string]*BacklogItem{}, blockers: map[string][]string{}}
+	dirs, err := root.BacklogDirs()
+	if err != nil {
+		return nil, err
+	}
+	var all []*BacklogItem
+	for _, d := range dirs {
+		bck, err := LoadBacklog(d)
+		if err != nil {
+			return nil, err
+		}
+		for _, it := range bck.AllItems() {
+			g.items[g.Key(it)] = it
+			all = append(all, it)
+		}
+	}
+	for _, it := range all {
+		key := g.Key(it)
+		for _, ref := range it.BlockedBy() {
+			if other, ok := g.resolve(it, itemKeyBlockedBy, ref); ok {
+				g.addEdge(other, key)
+			}
+		}
+		for _, ref := range it.Blocks() {
+			if other, ok := g.resolve(it, itemKeyBlocks, ref); ok {
+				g.addEdge(key, other)
+			}
+		}
+	}
+	return g, nil
+}
+
+func (g *DependencyGraph) resolve(from *BacklogItem, field, ref string) (string, bool) {
+	key := dependencyRefKey(g.Key(from), ref)
+	if key == "" || key == g.Key(from) {
+		return "", false
+	}
+	if _, ok := g.items[key]; !ok {
+		g.dangling = append(g.dangling, ItemValidationError{
+			Path:    from.Path(),
+			Key:     field,
+			Message: fmt.Sprintf("no story %q", strings.TrimSpace(ref)),
+		})
+		return "", false
+	}
+	return key, true
+}
+
+func (g *DependencyGraph) addEdge(blocker, blocked string) {
+	for _, k := range g.blockers[blocked] {
+		if k == blocker {
+			return
+		}
+	}
+	g.blockers[blocked] = append(g.blockers[blocked], blocker)
+	sort.Strings(g.blockers[blocked])
+}
+
+// refKey resolves a reference given on the command line or to a tool:
+// "#1234" by ID, anything else as a blocked_by entry written on fromKey.
+func (g *DependencyGraph) refKey(fromKey, ref string) string {
+	if id := ParseItemRef(ref); id > 0 {
+		for key, it := range g.items {
+			if it.ID() == id {
+				return key
+			}
+		}
+	}
+	return dependencyRefKey(fromKey, ref)
+}
+
+// Key returns the dependency key for an item: "backlog/file.md".
+func (g *DependencyGraph) Key(item *BacklogItem) string {
+	rel, err := filepath.Rel(g.root.Root(), item.Path())
+	if err != nil {
+		return filepath.Base(item.Path())
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	return parts[0] + "/" + parts[len(parts)-1]
+}
+
+// Item returns the item behind a key, or nil.
+func (g *DependencyGraph) Item(key string) *BacklogItem { return g.items[key] }
+
+// Blockers returns the keys key waits on, sorted.
+func (g *DependencyGraph) Blockers(key string) []string { return g.blockers[key] }
+
+// Dependents returns the keys waiting on key, sorted.
+func (g *DependencyGraph) Dependents(key string) []string {
+	var out []string
+	for blocked, bs := range g.blockers {
+		for _, b := range bs {
+			if b == key {
+				out = append(out, blocked)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// OpenBlockers returns the stories item waits on that are not accepted
+// yet. An empty result means the story is free to start.
+func (g *DependencyGraph) OpenBlockers(item *BacklogItem) []*BacklogItem {
+	var out []*BacklogItem
+	for _, k := range g.blockers[g.Key(item)] {
+		if b := g.items[k]; b != nil && !isAcceptedItem(b) {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// Edges returns every edge touching the backlog and/or epic given (both
+// empty means the whole project), sorted by blocked then blocker.
+func (g *DependencyGraph) Edges(backlogName, epic string) []DependencyEdge {
+	inScope := func(key string) bool {
+		it := g.items[key]
+		if it == nil {
+			return false
+		}
+		if backlogName != "" && !strings.HasPrefix(key, backlogName+"/") {
+			return false
+		}
+		if epic != "" && !strings.EqualFold(it.Epic(), epic) {
+			return false
+		}
+		return true
+	}
+	var out []DependencyEdge
+	for blocked, bs := range g.blockers {
+		for _, b := range bs {
+			if inScope(blocked) || inScope(b) {
+				out = append(out, DependencyEdge{Blocker: b, Blocked: blocked})
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Blocked != out[j].Blocked {
+			return out[i].Blocked < out[j].Blocked
+		}
+		return out[i].Blocker < out[j].Blocker
+	})
+	return out
+}
+
+// Cycles returns one blocked-by chain per loop in the graph, each
+// starting and ending on the same key ("a waits on b waits on a").
+func (g *DependencyGraph) Cycles() [][]string {
+	const (
+		white = iota
+		grey
+		black
+	)
+	color := map[string]int{}
+	seen := map[string]bool{}
+	var stack []string
+	var cycles [][]string
+	var visit func(key string)
+	visit = func(key string) {
+		color[key] = grey
+		stack = append(stack, key)
+		for _, next := range g.blockers[key] {
+			switch color[next] {
+			case white:
+				visit(next)
+			case grey:
+				start := len(stack) - 1
+				for stack[start] != next {
+					start--
+				}
+				loop := append([]string(nil), stack[start:]...)
+				id := canonicalCycle(loop)
+				if !seen[id] {
+					seen[id] = true
+					cycles = append(cycles, append(loop, next))
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		color[key] = black
+	}
+	keys := make([]string, 0, len(g.blockers))
+	for k := range g.blockers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if color[k] == white {
+			visit(k)
+		}
+	}
+	return cycles
+}
+
+// canonicalCycle rotates a loop to start at its smallest key so the same
+// loop found from two entry points is reported once.
+func canonicalCycle(loop []string) string {
+	min := 0
+	for i := range loop {
+		if loop[i] < loop[min] {
+			min = i
+		}
+	}
+	return strings.Join(append(append([]string(nil), loop[min:]...), loop[:min]...), " ")
+}
+
+// WaitsOn reports whether from transitively waits on to.
+func (g *DependencyGraph) WaitsOn(from, to string) bool {
+	seen := map[string]bool{}
+	queue := []string{from}
+	for len(queue) > 0 {
+		k := queue[0]
+		queue = queue[1:]
+		for _, b := range g.blockers[k] {
+			if b == to {
+				return true
+			}
+			if !seen[b] {
+				seen[b] = true
+				queue = append(queue, b)
+			}
+		}
+	}
+	return false
+}
+
+// ValidateDependencies reports blocked_by/blocks entries that name no
+// story, and every blocked-by cycle.
+func ValidateDependencies(root *BacklogsStructure) ([]ItemValidationError, error) {
+	g, err := LoadDependencyGraph(root)
+	if err != nil {
+		return nil, err
+	}
+	errs := append([]ItemValidationError(nil), g.dangling...)
+	for _, c := range g.Cycles() {
+		errs = append(errs, ItemValidationError{
+			Path:    g.items[c[0]].Path(),
+			Key:     itemKeyBlockedBy,
+			Message: "dependency cycle: " + strings.Join(c, " -> "),
+		})
+	}
+	return errs, nil
+}
+
+// AddBlocker records that item waits on the story at blockerRef (a path
+// relative to the project root) on both sides, and saves both files.
+// Refuses self-references, unknown stories and edges that close a cycle.
+func AddBlocker(root *BacklogsStructure, item *BacklogItem, blockerRef string) error {
+	g, err := LoadDependencyGraph(root)
+	if err != nil {
+		return err
+	}
+	key := g.Key(item)
+	bkey := g.refKey(key, blockerRef)
+	blocker := g.items[bkey]
+	switch {
+	case bkey == key:
+		return fmt.Errorf("%s cannot block itself", key)
+	case blocker == nil:
+		return fmt.Errorf("no story %q", blockerRef)
+	case g.WaitsOn(bkey, key):
+		return fmt.Errorf("%s already waits on %s; adding this would make a cycle", bkey, key)
+	}
+	if !hasDependencyRef(key, item.BlockedBy(), bkey) {
+		item.SetBlockedBy(append(item.BlockedBy(), bkey))
+		item.SetModified(utils.GetCurrentTimestamp())
+	}
+	if !hasDependencyRef(bkey, blocker.Blocks(), key) {
+		blocker.SetBlocks(append(blocker.Blocks(), key))
+	}
+	if err := item.Save(); err != nil {
+		return err
+	}
+	return blocker.Save()
+}
+
+// RemoveBlocker drops the edge between item and the story at blockerRef
+// from both files.
+func RemoveBlocker(root *BacklogsStructure, item *BacklogItem, blockerRef string) error {
+	g, err := LoadDependencyGraph(root)
+	if err != nil {
+		return err
+	}
+	key := g.Key(item)
+	bkey := g.refKey(key, blockerRef)
+	if hasDependencyRef(key, item.BlockedBy(), bkey) {
+		item.SetBlockedBy(withoutDependencyRef(key, item.BlockedBy(), bkey))
+		item.SetModified(utils.GetCurrentTimestamp())
+	}
+	if err := item.Save(); err != nil {
+		return err
+	}
+	if blocker := g.items[bkey]; blocker != nil {
+		blocker.SetBlocks(withoutDependencyRef(bkey, blocker.Blocks(), key))
+		return blocker.Save()
+	}
+	return nil
+}
+
+// ReleaseDependents is called once item has been accepted: it removes
+// item from the blocked_by list of every story waiting on it, clears its
+// own blocks list, and returns the stories it released. Items in any
+// other status are left alone.
+func ReleaseDependents(root *BacklogsStructure, item *BacklogItem) ([]*BacklogItem, error) {
+	if !isAcceptedItem(item) {
+		return nil, nil
+	}
+	g, err := LoadDependencyGraph(root)
+	if err != nil {
+		return nil, err
+	}
+	key := g.Key(item)
+	var released []*BacklogItem
+	for _, d := range g.Dependents(key) {
+		dep := g.items[d]
+		dep.SetBlockedBy(withoutDependencyRef(d, dep.BlockedBy(), key))
+		if err := dep.Save(); err != nil {
+			return nil, err
+		}
+		released = append(released, dep)
+	}
+	if self := g.items[key]; self != nil && len(self.Blocks()) > 0 {
+		self.SetBlocks(nil)
+		if err := self.Save(); err != nil {
+			return nil, err
+		}
+	}
+	return released, nil
+}
+
+// SyncDependencies writes the mirror side of every edge and drops edges
+// whose blocker has been accepted, catching status edits made by hand.
+func SyncDependencies(root *BacklogsStructure) error {
+	g, err := LoadDependencyGraph(root)
+	if err != nil {
+		return err
+	}
+	touched := map[string]*BacklogItem{}
+	for _, e := range g.Edges("", "") {
+		blocker, blocked := g.items[e.Blocker], g.items[e.Blocked]
+		if isAcceptedItem(blocker) {
+			if hasDependencyRef(e.Blocked, blocked.BlockedBy(), e.Blocker) {
+				blocked.SetBlockedBy(withoutDependencyRef(e.Blocked, blocked.BlockedBy(), e.Blocker))
+				touched[e.Blocked] = blocked
+			}
+			if hasDependencyRef(e.Blocker, blocker.Blocks(), e.Blocked) {
+				blocker.SetBlocks(withoutDependencyRef(e.Blocker, blocker.Blocks(), e.Blocked))
+				touched[e.Blocker] = blocker
+			}
+			continue
+		}
+		if !hasDependencyRef(e.Blocked, blocked.BlockedBy(), e.Blocker) {
+			blocked.SetBlockedBy(append(blocked.BlockedBy(), e.Blocker))
+			touched[e.Blocked] = blocked
+		}
+		if !hasDependencyRef(e.Blocker, blocker.Blocks(), e.Blocked) {
+			blocker.SetBlocks(append(blocker.Blocks(), e.Blocked))
+			touched[e.Blocker] = blocker
+		}
+	}
+	for _, it := range touched {
+		if err := it.Save(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dependencyRefKey resolves a blocked_by/blocks entry written on the item
+// keyed fromKey. Bare file names stay in the same backlog; longer paths
+// keep their first (backlog) and last (file) segments.
+func dependencyRefKey(fromKey, ref string) string {
+	ref = strings.TrimSpace(filepath.ToSlash(ref))
+	for strings.HasPrefix(ref, "./") || strings.HasPrefix(ref, "../") {
+		ref = ref[strings.Index(ref, "/")+1:]
+	}
+	if ref == "" {
+		return ""
+	}
+	if !strings.HasSuffix(ref, ".md") {
+		ref += ".md"
+	}
+	parts := strings.Split(ref, "/")
+	if len(parts) == 1 {
+		return strings.SplitN(fromKey, "/", 2)[0] + "/" + ref
+	}
+	return parts[0] + "/" + parts[len(parts)-1]
+}
+
+func hasDependencyRef(fromKey string, refs []string, key string) bool {
+	for _, r := range refs {
+		if dependencyRefKey(fromKey, r) == key {
+			return true
+		}
+	}
+	return false
+}
+
+func withoutDependencyRef(fromKey string, refs []string, key string) []string {
+	var out []string
+	for _, r := range refs {
+		if dependencyRefKey(fromKey, r) != key {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func cleanDependencyRefs(refs []string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, r := range refs {
+		r = strings.TrimSpace(filepath.ToSlash(r))
+		if r == "" || seen[r] {
+			continue
+		}
+		seen[r] = true
+		out = append(out, r)
+	}
+	return out
+}
+
+func isAcceptedItem(item *BacklogItem) bool {
+	return strings.EqualFold(item.Status(), AcceptedStatus.Name)
+}
+
+// DependencyDOT renders edges as a Graphviz digraph. Nodes are labelled
+// with title and status; accepted blockers are drawn dashed.
+func DependencyDOT(g *DependencyGraph, edges []DependencyEdge) string {
+	var b strings.Builder
+	b.WriteString("digraph deps {\n  rankdir=LR;\n  node [shape=box];\n")
+	for _, k := range dependencyNodes(edges) {
+		style := ""
+		if it := g.items[k]; it != nil && isAcceptedItem(it) {
+			style = ", style=dashed"
+		}
+		label := strings.ReplaceAll(dependencyLabel(g, k, "\\n"), `"`, `\"`)
+		fmt.Fprintf(&b, "  %q [label=\"%s\"%s];\n", k, label, style)
+	}
+	for _, e := range edges {
+		fmt.Fprintf(&b, "  %q -> %q;\n", e.Blocker, e.Blocked)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// DependencyMermaid renders edges as a Mermaid flowchart.
+func DependencyMermaid(g *DependencyGraph, edges []DependencyEdge) string {
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	ids := map[string]string{}
+	for i, k := range dependencyNodes(edges) {
+		ids[k] = fmt.Sprintf("n%d", i+1)
+		label := strings.ReplaceAll(dependencyLabel(g, k, "<br/>"), `"`, "#quot;")
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", ids[k], label)
+	}
+	for _, e := range edges {
+		fmt.Fprintf(&b, "  %s --> %s\n", ids[e.Blocker], ids[e.Blocked])
+	}
+	return b.String()
+}
+
+// DependencyASCII lists each blocked story with the stories it waits on.
+func DependencyASCII(g *DependencyGraph, edges []DependencyEdge) string {
+	if len(edges) == 0 {
+		return "No dependencies.\n"
+	}
+	var b strings.Builder
+	last := ""
+	for _, e := range edges {
+		if e.Blocked != last {
+			if last != "" {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "%s\n", dependencyLabel(g, e.Blocked, "  "))
+			last = e.Blocked
+		}
+		fmt.Fprintf(&b, "  blocked by %s\n", dependencyLabel(g, e.Blocker, "  "))
+	}
+	return b.String()
+}
+
+func dependencyNodes(edges []DependencyEdge) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, e := range edges {
+		for _, k := range []string{e.Blocker, e.Blocked} {
+			if !seen[k] {
+				seen[k] = true
+				out = append(out, k)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func dependencyLabel(g *DependencyGraph, key, sep string) string {
+	it := g.items[key]
+	if it == nil {
+		return key
+	}
+	title := it.Title()
+	if title == "" {
+		title = key
+	}
+	return fmt.Sprintf("%s%s[%s] %s", title, sep, it.Status(), key)
+}
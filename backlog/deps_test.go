@@ -0,0 +1,100 @@
+package backlog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDependencyGraph(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"product", "ops"} {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write := func(path, status, extra string) {
+		body := "---\ntitle: " + strings.TrimSuffix(filepath.Base(path), ".md") + "\nstatus: " + status + "\n" + extra + "---\n\nbody\n"
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// signup waits on login (bare name, same backlog); login is held up
+	// by a cross-backlog chore declared only from the blocker's side.
+	write("product/login.md", "started", "")
+	write("product/signup.md", "unstarted", "blocked_by: [login.md]\n")
+	write("ops/certs.md", "unstarted", "blocks: [product/login.md]\n")
+
+	root := NewBacklogsStructure(dir)
+	if errs, err := ValidateDependencies(root); err != nil || len(errs) != 0 {
+		t.Fatalf("errs=%v err=%v", errs, err)
+	}
+	if err := SyncDependencies(root); err != nil {
+		t.Fatal(err)
+	}
+	login, _ := LoadBacklogItem(filepath.Join(dir, "product/login.md"))
+	if got := login.BlockedBy(); len(got) != 1 || got[0] != "ops/certs.md" {
+		t.Errorf("login blocked_by = %v, want mirrored ops/certs.md", got)
+	}
+	if got := login.Blocks(); len(got) != 1 || got[0] != "product/signup.md" {
+		t.Errorf("login blocks = %v, want mirrored product/signup.md", got)
+	}
+
+	g, err := LoadDependencyGraph(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signup := g.Item("product/signup.md")
+	if open := g.OpenBlockers(signup); len(open) != 1 || open[0].Title() != "login" {
+		t.Errorf("signup open blockers = %v", open)
+	}
+	if !g.WaitsOn("product/signup.md", "ops/certs.md") {
+		t.Error("signup should transitively wait on ops/certs.md")
+	}
+	if err := AddBlocker(root, g.Item("ops/certs.md"), "product/signup.md"); err == nil {
+		t.Error("AddBlocker accepted an edge that closes a cycle")
+	}
+
+	// Accepting login releases signup and clears login's own blocks.
+	login.SetStatus(AcceptedStatus)
+	if err := login.Save(); err != nil {
+		t.Fatal(err)
+	}
+	released, err := ReleaseDependents(root, login)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(released) != 1 || released[0].Title() != "signup" || len(released[0].BlockedBy()) != 0 {
+		t.Errorf("released = %v", released)
+	}
+
+	// A loop written by hand is reported once, from its smallest key.
+	write("product/a.md", "unstarted", "blocked_by: [b.md]\n")
+	write("product/b.md", "unstarted", "blocked_by: [a.md]\n")
+	write("product/c.md", "unstarted", "blocked_by: [missing.md, c.md]\n")
+	errs, err := ValidateDependencies(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msgs []string
+	for _, e := range errs {
+		msgs = append(msgs, e.Message)
+	}
+	got := strings.Join(msgs, "\n")
+	for _, want := range []string{
+		`no story "missing.md"`,
+		"dependency cycle: product/a.md -> product/b.md -> product/a.md",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	if len(errs) != 2 {
+		t.Errorf("want 2 errors, got:\n%s", got)
+	}
+	c, _ := LoadBacklogItem(filepath.Join(dir, "product/c.md"))
+	if errs := ValidateItem(c); len(errs) != 1 || errs[0].Key != "blocked_by" {
+		t.Errorf("self reference not flagged: %v", errs)
+	}
+}
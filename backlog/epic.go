@@ -0,0 +1,400 @@
+package backlog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mreider/agilemarkdown/config"
+	"github.com/mreider/agilemarkdown/markdown"
+	"github.com/mreider/agilemarkdown/utils"
+)
+
+const (
+	epicKeyTitle    = "title"
+	epicKeyStatus   = "status"
+	epicKeyOwner    = "owner"
+	epicKeyRelease  = "release"
+	epicKeyLabels   = "labels"
+	epicKeyCreated  = "created"
+	epicKeyModified = "modified"
+	epicKeyClosed   = "closed"
+)
+
+// Epic lifecycle states.
+const (
+	EpicStatusOpen   = "open"
+	EpicStatusClosed = "closed"
+)
+
+// epicProgressHeading starts the section `am sync` regenerates at the
+// bottom of every epic page. Everything above it is the description.
+const epicProgressHeading = "## Progress"
+
+var epicSlugRe = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Epic is one `epics/<slug>.md` file. The frontmatter carries title,
+// status, owner, target release and labels; the body is the description
+// followed by the generated Progress section.
+type Epic struct {
+	slug string
+	file *markdown.FrontmatterFile
+}
+
+func LoadEpic(path string) (*Epic, error) {
+	f, err := markdown.LoadFrontmatter(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Epic{slug: strings.TrimSuffix(filepath.Base(path), ".md"), file: f}, nil
+}
+
+func (e *Epic) Save() error             { return e.file.Save() }
+func (e *Epic) Slug() string            { return e.slug }
+func (e *Epic) Path() string            { return e.file.Path() }
+func (e *Epic) Title() string           { return e.file.GetString(epicKeyTitle) }
+func (e *Epic) SetTitle(s string)       { e.file.SetString(epicKeyTitle, s) }
+func (e *Epic) Owner() string           { return e.file.GetString(epicKeyOwner) }
+func (e *Epic) SetOwner(s string)       { e.file.SetString(epicKeyOwner, s) }
+func (e *Epic) Release() string         { return e.file.GetString(epicKeyRelease) }
+func (e *Epic) SetRelease(s string)     { e.file.SetString(epicKeyRelease, s) }
+func (e *Epic) Labels() []string        { return e.file.GetStringSlice(epicKeyLabels) }
+func (e *Epic) SetLabels(l []string)    { e.file.SetStringSlice(epicKeyLabels, l) }
+func (e *Epic) Created() time.Time      { return parseTimestamp(e.file.GetString(epicKeyCreated)) }
+func (e *Epic) SetCreated(ts string)    { e.file.SetString(epicKeyCreated, ts) }
+func (e *Epic) SetModified(ts string)   { e.file.SetString(epicKeyModified, ts) }
+func (e *Epic) Closed() time.Time       { return parseTimestamp(e.file.GetString(epicKeyClosed)) }
+func (e *Epic) SetClosed(ts string)     { e.file.SetString(epicKeyClosed, ts) }
+func (e *Epic) IsClosed() bool          { return strings.EqualFold(e.Status(), EpicStatusClosed) }
+func (e *Epic) SetStatus(status string) { e.file.SetString(epicKeyStatus, status) }
+
+// Status defaults to open when the field is missing.
+func (e *Epic) Status() string {
+	if s := strings.ToLower(strings.TrimSpace(e.file.GetString(epicKeyStatus))); s != "" {
+		return s
+	}
+	return EpicStatusOpen
+}
+
+// Description is the body above the generated Progress section.
+func (e *Epic) Description() string {
+	body := e.file.Body()
+	if i := epicProgressIndex(body); i >= 0 {
+		body = body[:i]
+	}
+	return strings.TrimSpace(body)
+}
+
+// SetDescription replaces the description and keeps the Progress section.
+func (e *Epic) SetDescription(text string) {
+	progress := ""
+	if i := epicProgressIndex(e.file.Body()); i >= 0 {
+		progress = e.file.Body()[i:]
+	}
+	e.file.SetBody(joinEpicBody(text, progress))
+}
+
+// SetProgress replaces the generated Progress section.
+func (e *Epic) SetProgress(section string) {
+	e.file.SetBody(joinEpicBody(e.Description(), section))
+}
+
+func joinEpicBody(description, progress string) string {
+	description = strings.TrimSpace(description)
+	progress = strings.TrimSpace(progress)
+	switch {
+	case description == "":
+		return progress
+	case progress == "":
+		return description
+	}
+	return description + "\n\n" + progress
+}
+
+func epicProgressIndex(body string) int {
+	if strings.HasPrefix(body, epicProgressHeading+"\n") {
+		return 0
+	}
+	if i := strings.Index(body, "\n"+epicProgressHeading+"\n"); i >= 0 {
+		return i + 1
+	}
+	if strings.HasSuffix(body, "\n"+epicProgressHeading) {
+		return len(body) - len(epicProgressHeading)
+	}
+	return -1
+}
+
+// ValidateEpicSlug reports whether slug can name an epics/<slug>.md file.
+func ValidateEpicSlug(slug string) error {
+	if !epicSlugRe.MatchString(slug) {
+		return fmt.Errorf("epic slug %q: use lowercase letters, digits and dashes", slug)
+	}
+	return nil
+}
+
+// EpicPath returns the path of the epic file for slug.
+func (s *BacklogsStructure) EpicPath(slug string) string {
+	return filepath.Join(s.EpicsDirectory(), strings.ToLower(slug)+".md")
+}
+
+// HasEpics reports whether the project keeps epic files. Epic references
+// on items are only checked once it does.
+func (s *BacklogsStructure) HasEpics() bool {
+	info, err := os.Stat(s.EpicsDirectory())
+	return err == nil && info.IsDir()
+}
+
+// LoadEpics returns every epic file, sorted by slug.
+func LoadEpics(root *BacklogsStructure) ([]*Epic, error) {
+	infos, err := os.ReadDir(root.EpicsDirectory())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var out []*Epic
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".md") || strings.HasPrefix(info.Name(), ".") {
+			continue
+		}
+		e, err := LoadEpic(filepath.Join(root.EpicsDirectory(), info.Name()))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Slug() < out[j].Slug() })
+	return out, nil
+}
+
+// FindEpic loads epics/<slug>.md, or returns an error if there is none.
+func FindEpic(root *BacklogsStructure, slug string) (*Epic, error) {
+	path := root.EpicPath(slug)
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("epic %q not found; create it with `am epic create %s`", slug, slug)
+		}
+		return nil, err
+	}
+	return LoadEpic(path)
+}
+
+// CreateEpic writes a new open epic file. Refuses to overwrite.
+func CreateEpic(root *BacklogsStructure, slug, title string) (*Epic, error) {
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	if err := ValidateEpicSlug(slug); err != nil {
+		return nil, err
+	}
+	path := root.EpicPath(slug)
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("epic %q already exists: %s", slug, path)
+	}
+	if err := os.MkdirAll(root.EpicsDirectory(), 0755); err != nil {
+		return nil, err
+	}
+	e, err := LoadEpic(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(title) == "" {
+		title = slug
+	}
+	now := utils.GetCurrentTimestamp()
+	e.SetTitle(title)
+	e.SetStatus(EpicStatusOpen)
+	e.SetCreated(now)
+	e.SetModified(now)
+	e.file.MarkDirty()
+	return e, e.Save()
+}
+
+// EpicMembers returns the active items whose `epic` is slug, across all
+// backlogs.
+func EpicMembers(root *BacklogsStructure, slug string) ([]*BacklogItem, error) {
+	dirs, err := root.BacklogDirs()
+	if err != nil {
+		return nil, err
+	}
+	var out []*BacklogItem
+	for _, d := range dirs {
+		bck, err := LoadBacklog(d)
+		if err != nil {
+			return nil, err
+		}
+		for _, it := range bck.ActiveItems() {
+			if strings.EqualFold(it.Epic(), slug) {
+				out = append(out, it)
+			}
+		}
+	}
+	return out, nil
+}
+
+// CheckEpicRef returns an error when the project keeps epic files and
+// slug does not name one. Empty slugs (clearing the field) always pass.
+func CheckEpicRef(root *BacklogsStructure, slug string) error {
+	if slug == "" || !root.HasEpics() {
+		return nil
+	}
+	if _, err := os.Stat(root.EpicPath(slug)); err != nil {
+		return fmt.Errorf("epic %q not found in %s; create it with `am epic create %s`", slug, filepath.Base(root.EpicsDirectory()), strings.ToLower(slug))
+	}
+	return nil
+}
+
+// ValidateEpicRefs checks that every item's `epic` names an existing
+// epics/<slug>.md. Projects without an epics directory keep free-form
+// slugs and are not checked.
+func ValidateEpicRefs(root *BacklogsStructure) ([]ItemValidationError, error) {
+	if !root.HasEpics() {
+		return nil, nil
+	}
+	epics, err := LoadEpics(root)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(epics))
+	for _, e := range epics {
+		known[e.Slug()] = true
+	}
+	dirs, err := root.BacklogDirs()
+	if err != nil {
+		return nil, err
+	}
+	var errs []ItemValidationError
+	for _, d := range dirs {
+		bck, err := LoadBacklog(d)
+		if err != nil {
+			return nil, err
+		}
+		for _, it := range bck.AllItems() {
+			slug := it.Epic()
+			if slug == "" || known[strings.ToLower(slug)] {
+				continue
+			}
+			errs = append(errs, ItemValidationError{
+				Path:    it.Path(),
+				Key:     itemKeyEpic,
+				Message: fmt.Sprintf("no epic %q in epics/; create it with `am epic create %s`", slug, strings.ToLower(slug)),
+			})
+		}
+	}
+	return errs, nil
+}
+
+// EpicStats sums an epic's member stories.
+type EpicStats struct {
+	Stories         int
+	AcceptedStories int
+	Points          float64
+	AcceptedPoints  float64
+}
+
+func (s EpicStats) RemainingPoints() float64 { return s.Points - s.AcceptedPoints }
+
+func (s EpicStats) PercentDone() float64 {
+	if s.Points == 0 {
+		return 0
+	}
+	return s.AcceptedPoints / s.Points * 100
+}
+
+func EpicStatsFor(items []*BacklogItem) EpicStats {
+	var s EpicStats
+	for _, it := range items {
+		if it.Type() == "release" {
+			continue
+		}
+		pts := it.estimateAsFloat()
+		s.Stories++
+		s.Points += pts
+		if strings.EqualFold(it.Status(), AcceptedStatus.Name) {
+			s.AcceptedStories++
+			s.AcceptedPoints += pts
+		}
+	}
+	return s
+}
+
+// epicBurnupIterations is how many iterations the page burnup reaches back.
+const epicBurnupIterations = 8
+
+// EpicProgressSection renders the generated Progress section of an epic
+// page: totals, a per-iteration burnup and the member stories linked
+// relative to the epics directory. With refs, their #IDs are links too.
+func EpicProgressSection(root *BacklogsStructure, items []*BacklogItem, refs *ItemIndex, cfg *config.Config, now time.Time) string {
+	stats := EpicStatsFor(items)
+	var b strings.Builder
+	b.WriteString(epicProgressHeading + "\n\n")
+	b.WriteString("_Generated by `am sync`; edits below this heading are overwritten._\n\n")
+	if stats.Stories == 0 {
+		b.WriteString("No stories carry this epic yet.\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "%d/%d stories accepted, %.0f/%.0f pts (%.0f%%), %.0f pts remaining\n\n",
+		stats.AcceptedStories, stats.Stories, stats.AcceptedPoints, stats.Points, stats.PercentDone(), stats.RemainingPoints())
+
+	// One burnup sample at the end of each iteration since the first
+	// story was created, capped at epicBurnupIterations.
+	first := now
+	for _, it := range items {
+		if c := it.Created(); !c.IsZero() && c.Before(first) {
+			first = c
+		}
+	}
+	weeks := cfg.Iteration.LengthWeeks
+	if weeks < 1 {
+		weeks = 1
+	}
+	current := IterationStartFor(now, cfg)
+	start := IterationStartFor(first, cfg)
+	if oldest := current.AddDate(0, 0, -7*weeks*(epicBurnupIterations-1)); start.Before(oldest) {
+		start = oldest
+	}
+	// Rows are dated at iteration ends (not today) so the page only
+	// changes when the stories do.
+	var rows []BurnupRow
+	for s := start; !s.After(current); s = s.AddDate(0, 0, 7*weeks) {
+		day := s.AddDate(0, 0, 7*weeks-1)
+		if r := BurnupRows(items, day, day.AddDate(0, 0, 1)); len(r) > 0 {
+			rows = append(rows, r[0])
+		}
+	}
+	b.WriteString("```\n")
+	b.WriteString(BurnupASCII(rows, start, current.AddDate(0, 0, 7*weeks)))
+	b.WriteString("```\n\n")
+
+	sorted := append([]*BacklogItem(nil), items...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ai := strings.EqualFold(sorted[i].Status(), AcceptedStatus.Name)
+		aj := strings.EqualFold(sorted[j].Status(), AcceptedStatus.Name)
+		if ai != aj {
+			return !ai // open first
+		}
+		return sorted[i].Title() < sorted[j].Title()
+	})
+	for _, it := range sorted {
+		if it.Type() == "release" {
+			continue
+		}
+		var extra []string
+		if it.ID() > 0 {
+			extra = append(extra, fmt.Sprintf("#%d", it.ID()))
+		}
+		extra = append(extra, it.Status())
+		if e := strings.TrimSpace(it.Estimate()); e != "" {
+			extra = append(extra, e+" pts")
+		}
+		line := fmt.Sprintf("- %s %s (%s)", typeMark(it.Type()), utils.MakeMarkdownLink(it.Title(), it.Path(), root.EpicsDirectory()), strings.Join(extra, ", "))
+		if refs != nil {
+			line = LinkItemRefs(line, root.EpicsDirectory(), refs)
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
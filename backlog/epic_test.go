@@ -0,0 +1,114 @@
+package backlog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mreider/agilemarkdown/config"
+)
+
+func TestEpicFilesAndRefs(t *testing.T) {
+	dir := t.TempDir()
+	product := filepath.Join(dir, "product")
+	if err := os.MkdirAll(product, 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(name, epic, status, estimate string) {
+		body := "---\ntitle: " + name + "\nstatus: " + status + "\nestimate: \"" + estimate + "\"\nepic: " + epic +
+			"\ncreated: \"2026-03-02T10:00:00Z\"\n---\n\nbody\n"
+		if err := os.WriteFile(filepath.Join(product, name+".md"), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("login", "auth", "accepted", "3")
+	write("signup", "auth", "started", "5")
+	write("search", "discovery", "unstarted", "2")
+
+	root := NewBacklogsStructure(dir)
+	// Free-form slugs are fine until the project keeps epic files.
+	if errs, err := ValidateEpicRefs(root); err != nil || len(errs) != 0 {
+		t.Fatalf("without epics/: errs=%v err=%v", errs, err)
+	}
+
+	epic, err := CreateEpic(root, "auth", "Authentication")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CreateEpic(root, "auth", "again"); err == nil {
+		t.Error("CreateEpic overwrote an existing epic")
+	}
+	if _, err := CreateEpic(root, "Bad Slug", ""); err == nil {
+		t.Error("CreateEpic accepted an invalid slug")
+	}
+	dirs, _ := root.BacklogDirs()
+	if len(dirs) != 1 {
+		t.Errorf("epics/ listed as a backlog: %v", dirs)
+	}
+
+	errs, err := ValidateEpicRefs(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || !strings.HasSuffix(errs[0].Path, "search.md") || errs[0].Key != "epic" {
+		t.Fatalf("want one epic error on search.md, got %v", errs)
+	}
+	if err := CheckEpicRef(root, "discovery"); err == nil {
+		t.Error("CheckEpicRef accepted a missing epic")
+	}
+
+	// Progress is regenerated under the description, never over it.
+	epic.SetDescription("Let people sign in.")
+	items, err := EpicMembers(root, "auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 2; i++ {
+		epic.SetProgress(EpicProgressSection(root, items, nil, config.Defaults(), now))
+		if err := epic.Save(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reloaded, err := FindEpic(root, "auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Description() != "Let people sign in." {
+		t.Errorf("description = %q", reloaded.Description())
+	}
+	data, _ := os.ReadFile(reloaded.Path())
+	page := string(data)
+	if strings.Count(page, epicProgressHeading) != 1 {
+		t.Errorf("want exactly one Progress section:\n%s", page)
+	}
+	for _, want := range []string{
+		"1/2 stories accepted, 3/8 pts (38%), 5 pts remaining",
+		"[signup](../product/signup.md) (started, 5 pts)",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("epic page missing %q:\n%s", want, page)
+		}
+	}
+}
+
+func TestBacklogNamedLikeGeneratedFolder(t *testing.T) {
+	for _, name := range []string{"epics", "filters"} {
+		dir := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		root := NewBacklogsStructure(dir)
+		if _, err := root.BacklogDirs(); err != nil {
+			t.Fatalf("generated %s/ alone: %v", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name+".md"), []byte("# "+name+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := root.BacklogDirs(); err == nil || !strings.Contains(err.Error(), "git mv "+name) {
+			t.Errorf("backlog %s/ with an overview: %v, want a rename hint", name, err)
+		}
+	}
+}
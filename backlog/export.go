@@ -0,0 +1,377 @@
+package backlog
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mreider/agilemarkdown/utils"
+)
+
+const (
+	ExportFormatCsv        = "csv"
+	ExportFormatJson       = "json"
+	ExportFormatPivotalCsv = "pivotal-csv"
+)
+
+const pivotalDateLayout = "Jan 2, 2006"
+
+var (
+	blockersHeadingRe   = regexp.MustCompile(`(?i)^#{1,3}\s+Blockers\s*$`)
+	sectionHeadingRe    = regexp.MustCompile(`^#{1,3}\s+(.+?)\s*$`)
+	commentDatePrefixRe = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})\s*(.*)$`)
+)
+
+// ExportBacklogs writes every item of the given backlogs (all backlogs when
+// names is empty) to w. The csv and json formats are a superset of the
+// Tracker export layout that also carries each item's ID, backlog, file
+// name and rank, so `am import --format pivotal` puts the items back where
+// they were; pivotal-csv sticks to the columns Tracker itself accepts.
+func ExportBacklogs(root *BacklogsStructure, names []string, format string, w io.Writer) error {
+	switch format {
+	case ExportFormatJson, ExportFormatCsv, ExportFormatPivotalCsv:
+	default:
+		return fmt.Errorf("unknown export format '%s' (want %s, %s or %s)", format, ExportFormatCsv, ExportFormatJson, ExportFormatPivotalCsv)
+	}
+	stories, err := exportStories(root, names)
+	if err != nil {
+		return err
+	}
+	if format == ExportFormatJson {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Stories []pivotalJSONStory `json:"stories"`
+		}{stories})
+	}
+	return writeExportCsv(w, stories, format == ExportFormatPivotalCsv)
+}
+
+// exportStories walks the backlogs in rank order: _priority.md first, then
+// _icebox.md, then active items in neither, then the archive.
+func exportStories(root *BacklogsStructure, names []string) ([]pivotalJSONStory, error) {
+	dirs, err := root.BacklogDirs()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		found := false
+		for _, dir := range dirs {
+			found = found || strings.EqualFold(filepath.Base(dir), name)
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown backlog '%s'", name)
+		}
+	}
+	var stories []pivotalJSONStory
+	for _, dir := range dirs {
+		name := filepath.Base(dir)
+		if len(names) > 0 && !utils.ContainsStringIgnoreCase(names, name) {
+			continue
+		}
+		bck, err := LoadBacklog(dir)
+		if err != nil {
+			return nil, err
+		}
+		pri, err := LoadPriority(dir)
+		if err != nil {
+			return nil, err
+		}
+		ice, err := LoadIcebox(dir)
+		if err != nil {
+			return nil, err
+		}
+		active := make(map[string]*BacklogItem)
+		for _, item := range bck.ActiveItems() {
+			active[filepath.Base(item.Path())] = item
+		}
+		done := make(map[string]bool)
+		add := func(item *BacklogItem, rank int, icebox bool) {
+			done[filepath.Base(item.Path())] = true
+			story := exportStory(item, icebox)
+			story.ID = item.ID()
+			story.Backlog = name
+			story.Rank = rank
+			stories = append(stories, story)
+		}
+		for i, e := range pri.Entries() {
+			if item, ok := active[e.Path]; ok && !done[e.Path] {
+				add(item, i+1, false)
+			}
+		}
+		for i, e := range ice.Entries() {
+			if item, ok := active[e.Path]; ok && !done[e.Path] {
+				add(item, i+1, true)
+			}
+		}
+		for _, item := range bck.ActiveItems() {
+			if !done[filepath.Base(item.Path())] {
+				add(item, 0, false)
+			}
+		}
+		for _, item := range bck.ArchivedItems() {
+			add(item, 0, false)
+		}
+	}
+	return stories, nil
+}
+
+func exportStory(item *BacklogItem, icebox bool) pivotalJSONStory {
+	state := strings.ToLower(strings.TrimSpace(item.Status()))
+	if icebox && state == UnstartedStatus.Name {
+		state = "unscheduled"
+	}
+	storyType := item.Type()
+	if storyType == "" {
+		storyType = "feature"
+	}
+	story := pivotalJSONStory{
+		Name:         item.Title(),
+		StoryType:    storyType,
+		CurrentState: state,
+		Description:  exportDescription(item.Body()),
+		CreatedAt:    exportTimestamp(item.Created()),
+		AcceptedAt:   exportTimestamp(item.Accepted()),
+		Deadline:     item.ReleaseDate(),
+		RequestedBy:  pivotalName(item.Author()),
+		File:         filepath.Base(item.Path()),
+		Icebox:       icebox,
+		Archived:     item.Archived() || filepath.Base(filepath.Dir(item.Path())) == archiveDirectoryName,
+		Epic:         item.Epic(),
+		StartedAt:    exportTimestamp(item.Started()),
+		FinishedAt:   exportTimestamp(item.Finished()),
+		DeliveredAt:  exportTimestamp(item.Delivered()),
+		Acceptance:   AcceptanceBulletTexts(item.Body()),
+	}
+	if est, err := strconv.ParseFloat(strings.TrimSpace(item.Estimate()), 64); err == nil {
+		story.Estimate = &est
+	}
+	for _, owner := range item.Assignees() {
+		story.Owners = append(story.Owners, pivotalName(owner))
+	}
+	for _, tag := range item.Tags() {
+		story.Labels = append(story.Labels, pivotalName(tag))
+	}
+	for _, c := range item.Comments() {
+		story.Comments = append(story.Comments, exportComment(c))
+	}
+	for _, t := range ParseTasks(item.Body()) {
+		story.Tasks = append(story.Tasks, pivotalJSONTask{Description: t.Text, Complete: t.Done})
+	}
+	for _, b := range parseBlockers(item.Body()) {
+		story.Blockers = append(story.Blockers, pivotalJSONBlocker{Description: b.Text, Resolved: b.Resolved})
+	}
+	if item.Blocked() && len(story.Blockers) == 0 {
+		reason := item.BlockedReason()
+		if reason == "" {
+			reason = "blocked"
+		}
+		story.Blockers = append(story.Blockers, pivotalJSONBlocker{Description: reason})
+	}
+	return story
+}
+
+func exportTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return utils.GetTimestamp(t)
+}
+
+// exportComment splits the "@user YYYY-MM-DD" header written by
+// AppendComment back into person, date and text.
+func exportComment(c *Comment) pivotalJSONComment {
+	text := append([]string(nil), c.Text...)
+	var date string
+	if len(text) > 0 {
+		if m := commentDatePrefixRe.FindStringSubmatch(text[0]); m != nil {
+			date = m[1]
+			if m[2] == "" {
+				text = text[1:]
+			} else {
+				text[0] = m[2]
+			}
+		}
+	}
+	return pivotalJSONComment{
+		Text:      strings.TrimSpace(strings.Join(text, "\n")),
+		Person:    pivotalName(strings.Join(c.Users, ", ")),
+		CreatedAt: date,
+	}
+}
+
+// exportDescription drops the sections that travel as separate columns
+// (Tasks, Blockers, Comments) and the empty scaffolding headings the
+// importer adds back, so a re-import does not duplicate them.
+func exportDescription(body string) string {
+	lines := strings.Split(body, "\n")
+	var out []string
+	for i := 0; i < len(lines); {
+		m := sectionHeadingRe.FindStringSubmatch(strings.TrimSpace(lines[i]))
+		if m == nil {
+			out = append(out, lines[i])
+			i++
+			continue
+		}
+		end := i + 1
+		for end < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[end]), "#") {
+			end++
+		}
+		heading := strings.ToLower(m[1])
+		empty := strings.TrimSpace(strings.Join(lines[i+1:end], "\n")) == ""
+		switch {
+		case heading == "tasks" || heading == "blockers" || heading == "comments":
+		case (heading == "possible solution" || heading == "attachments") && empty:
+		default:
+			out = append(out, lines[i:end]...)
+		}
+		i = end
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
+
+// parseBlockers reads the checkbox list under "## Blockers" written by the
+// Pivotal importer. Checked entries are resolved.
+func parseBlockers(body string) []pivotalBlocker {
+	lines := strings.Split(body, "\n")
+	var out []pivotalBlocker
+	in := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if blockersHeadingRe.MatchString(trimmed) {
+			in = true
+			continue
+		}
+		if !in {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "#") {
+			break
+		}
+		if m := taskLineRe.FindStringSubmatch(trimmed); m != nil {
+			out = append(out, pivotalBlocker{Text: m[2], Resolved: m[1] != " "})
+		}
+	}
+	return out
+}
+
+func writeExportCsv(w io.Writer, stories []pivotalJSONStory, trackerOnly bool) error {
+	var owners, blockers, comments, tasks int
+	for _, s := range stories {
+		owners = max(owners, len(s.Owners))
+		blockers = max(blockers, len(s.Blockers))
+		comments = max(comments, len(s.Comments))
+		tasks = max(tasks, len(s.Tasks))
+	}
+	date := func(ts string) string {
+		if ts == "" {
+			return ""
+		}
+		if t, err := utils.ParseTimestamp(ts); err == nil && trackerOnly {
+			return t.Format(pivotalDateLayout)
+		}
+		return ts
+	}
+
+	header := []string{"Id", "Title", "Labels", "Type", "Estimate", "Current State", "Created at", "Accepted at", "Deadline", "Requested By", "Description"}
+	if !trackerOnly {
+		header = append(header, "Backlog", "File", "Rank", "Icebox", "Archived", "Epic", "Started at", "Finished at", "Delivered at", "Acceptance")
+	}
+	header = appendRepeated(header, owners, "Owned By")
+	header = appendRepeated(header, blockers, "Blocker", "Blocker Status")
+	header = appendRepeated(header, comments, "Comment")
+	header = appendRepeated(header, tasks, "Task", "Task Status")
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, s := range stories {
+		estimate := ""
+		if s.Estimate != nil {
+			estimate = strconv.FormatFloat(*s.Estimate, 'f', -1, 64)
+		}
+		labels := make([]string, 0, len(s.Labels))
+		for _, l := range s.Labels {
+			labels = append(labels, string(l))
+		}
+		// Tracker reads a non-empty Id as "update this story", so only our
+		// own formats carry item IDs.
+		id := ""
+		if s.ID > 0 && !trackerOnly {
+			id = strconv.Itoa(s.ID)
+		}
+		row := []string{id, s.Name, strings.Join(labels, ", "), s.StoryType, estimate, s.CurrentState,
+			date(s.CreatedAt), date(s.AcceptedAt), s.Deadline, string(s.RequestedBy), s.Description}
+		if !trackerOnly {
+			rank := ""
+			if s.Rank > 0 {
+				rank = strconv.Itoa(s.Rank)
+			}
+			row = append(row, s.Backlog, s.File, rank, strconv.FormatBool(s.Icebox), strconv.FormatBool(s.Archived), s.Epic,
+				s.StartedAt, s.FinishedAt, s.DeliveredAt, strings.Join(s.Acceptance, "\n"))
+		}
+		for i := 0; i < owners; i++ {
+			row = append(row, cellAt(i < len(s.Owners), func() string { return string(s.Owners[i]) }))
+		}
+		for i := 0; i < blockers; i++ {
+			row = append(row, cellAt(i < len(s.Blockers), func() string { return s.Blockers[i].Description }))
+			row = append(row, cellAt(i < len(s.Blockers), func() string {
+				if s.Blockers[i].Resolved {
+					return "resolved"
+				}
+				return "unresolved"
+			}))
+		}
+		for i := 0; i < comments; i++ {
+			row = append(row, cellAt(i < len(s.Comments), func() string { return pivotalCommentCell(s.Comments[i]) }))
+		}
+		for i := 0; i < tasks; i++ {
+			row = append(row, cellAt(i < len(s.Tasks), func() string { return s.Tasks[i].Description }))
+			row = append(row, cellAt(i < len(s.Tasks), func() string {
+				if s.Tasks[i].Complete {
+					return "completed"
+				}
+				return "not completed"
+			}))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func appendRepeated(header []string, n int, columns ...string) []string {
+	for i := 0; i < n; i++ {
+		header = append(header, columns...)
+	}
+	return header
+}
+
+func cellAt(ok bool, value func() string) string {
+	if !ok {
+		return ""
+	}
+	return value()
+}
+
+// pivotalCommentCell renders a comment the way Tracker's CSV export does:
+// "text (Person - Jan 2, 2006)".
+func pivotalCommentCell(c pivotalJSONComment) string {
+	if c.Person == "" || c.CreatedAt == "" {
+		return c.Text
+	}
+	t, err := time.Parse(itemDateLayout, c.CreatedAt)
+	if err != nil {
+		return c.Text
+	}
+	return fmt.Sprintf("%s (%s - %s)", c.Text, c.Person, t.Format(pivotalDateLayout))
+}
@@ -0,0 +1,163 @@
+package backlog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExportRoundTripsThroughPivotalImport(t *testing.T) {
+	for _, format := range []string{ExportFormatCsv, ExportFormatJson} {
+		t.Run(format, func(t *testing.T) {
+			src := t.TempDir()
+			dir := filepath.Join(src, "product")
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			login := makeItem(t, dir, "login-form", map[string]string{
+				"title": "Login form", "status": "started", "estimate": "3",
+				"assigned": "[bob, cy]", "tags": "[auth, web]", "epic": "accounts",
+				"created": "2026-03-02T09:00:00Z", "started": "2026-03-03T09:00:00Z",
+			})
+			body := "Users sign in.\n\n## Acceptance\n\n- [ ] Shows an error on bad password\n"
+			body = AppendTask(body, "Build form")
+			body, _ = SetTaskDone(body, 1, true)
+			body = AppendComment(body, "ann", "Looks good")
+			login.SetBody(body)
+			login.SetBlocked(true, "Waiting on design")
+			if err := login.Save(); err != nil {
+				t.Fatal(err)
+			}
+			makeItem(t, dir, "dark-mode", map[string]string{"title": "Dark mode", "status": "unstarted"})
+			pri, _ := LoadPriority(dir)
+			pri.InsertBottom(OrderEntry{Title: "Login form", Path: "login-form.md"})
+			ice, _ := LoadIcebox(dir)
+			ice.InsertBottom(OrderEntry{Title: "Dark mode", Path: "dark-mode.md"})
+			if err := pri.Save(); err != nil {
+				t.Fatal(err)
+			}
+			if err := ice.Save(); err != nil {
+				t.Fatal(err)
+			}
+
+			var out bytes.Buffer
+			if err := ExportBacklogs(NewBacklogsStructure(src), nil, format, &out); err != nil {
+				t.Fatal(err)
+			}
+			dst := t.TempDir()
+			export := filepath.Join(dst, "export."+format)
+			if err := os.WriteFile(export, out.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+			dstDir := filepath.Join(dst, "product")
+			if err := os.MkdirAll(dstDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := NewPivotalImporter(export, dstDir).Import(); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := LoadBacklogItem(filepath.Join(dstDir, "login-form.md"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Status() != "started" || got.Estimate() != "3" || got.Epic() != "accounts" {
+				t.Errorf("fields lost: status=%q estimate=%q epic=%q", got.Status(), got.Estimate(), got.Epic())
+			}
+			if !got.Started().Equal(login.Started()) || !got.Created().Equal(login.Created()) {
+				t.Errorf("timestamps lost: started=%v created=%v", got.Started(), got.Created())
+			}
+			if !reflect.DeepEqual(got.Assignees(), []string{"bob", "cy"}) || !reflect.DeepEqual(got.Tags(), []string{"auth", "web"}) {
+				t.Errorf("owners/labels lost: %v %v", got.Assignees(), got.Tags())
+			}
+			if !got.Blocked() || got.BlockedReason() != "Waiting on design" {
+				t.Errorf("blocker lost: %v %q", got.Blocked(), got.BlockedReason())
+			}
+			if !reflect.DeepEqual(AcceptanceBulletTexts(got.Body()), []string{"Shows an error on bad password"}) {
+				t.Errorf("acceptance lost:\n%s", got.Body())
+			}
+			if tasks := ParseTasks(got.Body()); len(tasks) != 1 || !tasks[0].Done || tasks[0].Text != "Build form" {
+				t.Errorf("tasks lost: %+v", tasks)
+			}
+			if c := got.Comments(); len(c) != 1 || c[0].Users[0] != "ann" {
+				t.Errorf("comments lost: %+v", c)
+			}
+
+			dstPri, _ := LoadPriority(dstDir)
+			dstIce, _ := LoadIcebox(dstDir)
+			if e := dstPri.Entries(); len(e) != 1 || e[0].Path != "login-form.md" {
+				t.Errorf("priority lost: %+v", e)
+			}
+			if e := dstIce.Entries(); len(e) != 1 || e[0].Path != "dark-mode.md" {
+				t.Errorf("icebox lost: %+v", e)
+			}
+		})
+	}
+}
+
+func TestExportRoundTripsAcrossBacklogs(t *testing.T) {
+	for _, format := range []string{ExportFormatCsv, ExportFormatJson} {
+		t.Run(format, func(t *testing.T) {
+			src := t.TempDir()
+			for _, name := range []string{"platform", "product"} {
+				if err := os.MkdirAll(filepath.Join(src, name), 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			makeItem(t, filepath.Join(src, "product"), "Sign-in", map[string]string{"title": "Login form", "id": "7", "status": "unstarted"})
+			makeItem(t, filepath.Join(src, "product"), "search", map[string]string{"title": "Search box", "id": "3", "status": "unstarted"})
+			makeItem(t, filepath.Join(src, "platform"), "deploys", map[string]string{"title": "Faster deploys", "id": "5", "status": "unstarted"})
+			pri, _ := LoadPriority(filepath.Join(src, "product"))
+			pri.InsertBottom(OrderEntry{Title: "Search box", Path: "search.md"})
+			pri.InsertBottom(OrderEntry{Title: "Login form", Path: "Sign-in.md"})
+			if err := pri.Save(); err != nil {
+				t.Fatal(err)
+			}
+
+			var out bytes.Buffer
+			if err := ExportBacklogs(NewBacklogsStructure(src), nil, format, &out); err != nil {
+				t.Fatal(err)
+			}
+			dst := t.TempDir()
+			export := filepath.Join(dst, "export."+format)
+			if err := os.WriteFile(export, out.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.MkdirAll(filepath.Join(dst, "product"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := NewPivotalImporter(export, filepath.Join(dst, "product")).Import(); err == nil {
+				t.Fatal("import into a tree without the platform backlog should fail")
+			}
+			if _, err := os.Stat(filepath.Join(dst, "product", "Sign-in.md")); !os.IsNotExist(err) {
+				t.Fatalf("a failed import wrote items: %v", err)
+			}
+			if err := os.MkdirAll(filepath.Join(dst, "platform"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := NewPivotalImporter(export, filepath.Join(dst, "product")).Import(); err != nil {
+				t.Fatal(err)
+			}
+
+			for path, id := range map[string]int{"product/Sign-in.md": 7, "product/search.md": 3, "platform/deploys.md": 5} {
+				it, err := LoadBacklogItem(filepath.Join(dst, path))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if it.ID() != id {
+					t.Errorf("%s: id %d, want %d", path, it.ID(), id)
+				}
+			}
+			dstPri, _ := LoadPriority(filepath.Join(dst, "product"))
+			if e := dstPri.Entries(); len(e) != 2 || e[0].Path != "search.md" || e[1].Path != "Sign-in.md" {
+				t.Errorf("product priority lost: %+v", e)
+			}
+			platformPri, _ := LoadPriority(filepath.Join(dst, "platform"))
+			if e := platformPri.Entries(); len(e) != 1 || e[0].Path != "deploys.md" {
+				t.Errorf("platform priority lost: %+v", e)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package backlog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mreider/agilemarkdown/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// SavedFilter is one named search from `.am/filters.yaml`, in the same
+// query syntax as `am search`. "me" in owner: or author: stands for
+// whoever runs it; the page sync renders splits such filters per user.
+type SavedFilter struct {
+	Name        string `yaml:"name"`
+	Query       string `yaml:"query"`
+	Description string `yaml:"description,omitempty"`
+	Archived    bool   `yaml:"archived,omitempty"`
+}
+
+// SavedFilters is the parsed contents of `.am/filters.yaml`.
+type SavedFilters struct {
+	Filters []SavedFilter `yaml:"filters"`
+}
+
+// DefaultSavedFilters are the panels a project gets until it writes its
+// own `.am/filters.yaml`.
+func DefaultSavedFilters() *SavedFilters {
+	return &SavedFilters{Filters: []SavedFilter{
+		{Name: "My Work", Query: "owner:me -status:accepted", Description: "Stories assigned to you that are not accepted yet."},
+		{Name: "Needs estimate", Query: "type:feature estimate:none -status:accepted", Description: "Features nobody has pointed yet."},
+		{Name: "Delivered awaiting me", Query: "status:delivered author:me", Description: "Stories you requested that are waiting for your accept or reject."},
+	}}
+}
+
+// LoadSavedFilters reads `.am/filters.yaml`. A missing file yields the
+// defaults; a filter with a bad query is an error naming it.
+func LoadSavedFilters(root *BacklogsStructure) (*SavedFilters, error) {
+	data, err := os.ReadFile(root.FiltersFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return DefaultSavedFilters(), nil
+		}
+		return nil, err
+	}
+	out := &SavedFilters{}
+	if err := yaml.Unmarshal(data, out); err != nil {
+		return nil, fmt.Errorf("filters.yaml: %w", err)
+	}
+	seen := map[string]bool{}
+	for _, f := range out.Filters {
+		if strings.TrimSpace(f.Name) == "" || f.Slug() == "" {
+			return nil, fmt.Errorf("filters.yaml: filter %q needs a name", f.Query)
+		}
+		if seen[f.Slug()] {
+			return nil, fmt.Errorf("filters.yaml: duplicate filter %q", f.Name)
+		}
+		seen[f.Slug()] = true
+		if _, err := ParseSearchQuery(f.Query); err != nil {
+			return nil, fmt.Errorf("filters.yaml: %s: %w", f.Name, err)
+		}
+	}
+	return out, nil
+}
+
+// Save writes the filters to `.am/filters.yaml`.
+func (fs *SavedFilters) Save(root *BacklogsStructure) error {
+	if err := os.MkdirAll(filepath.Dir(root.FiltersFile()), 0755); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(fs)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(root.FiltersFile(), data, 0644)
+}
+
+// Find returns the filter whose name or slug matches name, ignoring case.
+func (fs *SavedFilters) Find(name string) *SavedFilter {
+	for i, f := range fs.Filters {
+		if strings.EqualFold(f.Name, strings.TrimSpace(name)) || f.Slug() == strings.ToLower(strings.TrimSpace(name)) {
+			return &fs.Filters[i]
+		}
+	}
+	return nil
+}
+
+// Slug is the filter's page name under filters/.
+func (f SavedFilter) Slug() string {
+	return strings.ToLower(utils.GetValidFileName(f.Name))
+}
+
+// Run evaluates the filter against idx, resolving "me" to handles.
+func (f SavedFilter) Run(idx *SearchIndex, handles []string) ([]SearchMatch, error) {
+	q, err := ParseSearchQuery(f.Query)
+	if err != nil {
+		return nil, err
+	}
+	q.ResolveMe(handles)
+	return idx.Search(q, f.Archived), nil
+}
+
+// SyncFilterPages renders filters/<slug>.md for every saved filter, with
+// #ID references linked, and removes pages of filters that no longer
+// exist. Returns the number of pages written.
+func SyncFilterPages(root *BacklogsStructure, users *UserList) (int, error) {
+	filters, err := LoadSavedFilters(root)
+	if err != nil {
+		return 0, err
+	}
+	idx, err := UpdateSearchIndex(root)
+	if err != nil {
+		return 0, err
+	}
+	refs, err := LoadItemIndex(root)
+	if err != nil {
+		return 0, err
+	}
+	dir := root.FiltersDirectory()
+	if len(filters.Filters) > 0 {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return 0, err
+		}
+	}
+	keep := map[string]bool{}
+	written := 0
+	for _, f := range filters.Filters {
+		page, err := filterPage(root, idx, f, users)
+		if err != nil {
+			return written, err
+		}
+		page = LinkItemRefs(page, dir, refs)
+		path := filepath.Join(dir, f.Slug()+".md")
+		keep[filepath.Base(path)] = true
+		if old, err := os.ReadFile(path); err == nil && string(old) == page {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(page), 0644); err != nil {
+			return written, err
+		}
+		written++
+	}
+	infos, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return written, err
+	}
+	for _, info := range infos {
+		if !info.IsDir() && strings.HasSuffix(info.Name(), ".md") && !keep[info.Name()] {
+			if err := os.Remove(filepath.Join(dir, info.Name())); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// filterPage renders one saved filter. A filter on "me" gets a section
+// per team member, so the page reads the same for everyone.
+func filterPage(root *BacklogsStructure, idx *SearchIndex, f SavedFilter, users *UserList) (string, error) {
+	q, err := ParseSearchQuery(f.Query)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", f.Name)
+	if f.Description != "" {
+		b.WriteString(f.Description + "\n\n")
+	}
+	fmt.Fprintf(&b, "Query: `%s`\n\n", f.Query)
+	b.WriteString("_Generated by `am sync` from `.am/filters.yaml`; edits here are overwritten._\n\n")
+	if !q.UsesMe() {
+		matches, err := f.Run(idx, nil)
+		if err != nil {
+			return "", err
+		}
+		writeFilterMatches(&b, root, matches)
+		return b.String(), nil
+	}
+	shown := 0
+	for _, u := range users.Users() {
+		matches, err := f.Run(idx, u.Handles())
+		if err != nil {
+			return "", err
+		}
+		if len(matches) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "## %s\n\n", u.Name())
+		writeFilterMatches(&b, root, matches)
+		b.WriteString("\n")
+		shown++
+	}
+	if shown == 0 {
+		b.WriteString("Nothing here for anyone.\n")
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n", nil
+}
+
+func writeFilterMatches(b *strings.Builder, root *BacklogsStructure, matches []SearchMatch) {
+	if len(matches) == 0 {
+		b.WriteString("Nothing matches.\n")
+		return
+	}
+	for _, m := range matches {
+		var extra []string
+		if m.ID > 0 {
+			extra = append(extra, fmt.Sprintf("#%d", m.ID))
+		}
+		if m.Status != "" {
+			extra = append(extra, m.Status)
+		}
+		if m.Estimate != "" {
+			extra = append(extra, m.Estimate+" pts")
+		}
+		link := utils.MakeMarkdownLink(m.Title, filepath.Join(root.Root(), filepath.FromSlash(m.Path)), root.FiltersDirectory())
+		fmt.Fprintf(b, "- %s %s (%s)\n", typeMark(m.Type), link, strings.Join(extra, ", "))
+	}
+}
@@ -0,0 +1,83 @@
+package backlog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSavedFilterPages(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "product"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(path, text string) {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("product/login.md", "---\ntitle: Login\nid: 1\nstatus: started\nassigned: alice\nestimate: 3\n---\n")
+	write("product/signup.md", "---\ntitle: Signup\nid: 2\nstatus: delivered\nassigned: bob\nauthor: alice\n---\n")
+	write("product/billing.md", "---\ntitle: Billing\nid: 3\nstatus: unstarted\ntype: feature\n---\n")
+	write(".am/filters.yaml", `filters:
+  - name: My Work
+    query: owner:me -status:accepted
+    description: "Follow-ups to #3."
+  - name: Needs estimate
+    query: type:feature,bug estimate:none
+`)
+	write("filters/stale.md", "# old\n")
+
+	root := NewBacklogsStructure(dir)
+	users := NewUserList(root.UsersDirectory())
+	users.AddUser("Alice", "alice@example.com")
+	users.AddUser("Bob", "bob@example.com")
+	if err := users.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := SyncFilterPages(root, users); err != nil {
+		t.Fatal(err)
+	}
+	myWork, err := os.ReadFile(filepath.Join(dir, "filters", "my-work.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Follow-ups to [#3](../product/billing.md).",
+		"Query: `owner:me -status:accepted`",
+		"## Alice\n\n- ★ [Login](../product/login.md) ([#1](../product/login.md), started, 3 pts)\n",
+		"## Bob\n\n- ★ [Signup](../product/signup.md) ([#2](../product/signup.md), delivered)\n",
+	} {
+		if !strings.Contains(string(myWork), want) {
+			t.Errorf("my-work.md missing %q:\n%s", want, myWork)
+		}
+	}
+	needs, _ := os.ReadFile(filepath.Join(dir, "filters", "needs-estimate.md"))
+	if !strings.Contains(string(needs), "[Billing]") || strings.Contains(string(needs), "[Signup]") {
+		t.Errorf("needs-estimate.md:\n%s", needs)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "filters", "stale.md")); !os.IsNotExist(err) {
+		t.Error("page of a removed filter was kept")
+	}
+
+	filters, _ := LoadSavedFilters(root)
+	idx, _ := UpdateSearchIndex(root)
+	f := filters.Find("my-work")
+	if f == nil {
+		t.Fatal("Find by slug failed")
+	}
+	got, _ := f.Run(idx, []string{"bob"})
+	if len(got) != 1 || got[0].Title != "Signup" {
+		t.Errorf("My Work for bob = %v", got)
+	}
+
+	write(".am/filters.yaml", "filters:\n  - name: Broken\n    query: colour:red\n")
+	if _, err := LoadSavedFilters(root); err == nil || !strings.Contains(err.Error(), "Broken") {
+		t.Errorf("bad query error = %v", err)
+	}
+}
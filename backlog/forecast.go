@@ -0,0 +1,332 @@
+package backlog
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mreider/agilemarkdown/config"
+)
+
+// Defaults for DeliveryForecastOptions.
+const (
+	DefaultForecastTrials = 10000
+	DefaultForecastSeed   = 1
+)
+
+// forecastHorizon caps a simulated future so a near-zero throughput
+// history cannot spin forever. Ten years of weekly iterations.
+const forecastHorizon = 520
+
+// ForecastPercentiles are the confidence levels reported by
+// ForecastEpic and ForecastUntil.
+var ForecastPercentiles = []int{50, 85, 95}
+
+// DeliveryForecastOptions tunes the simulation. Zero values fall back to
+// DefaultForecastTrials and DefaultForecastSeed, so the same repo state
+// always produces the same dates.
+type DeliveryForecastOptions struct {
+	Trials int
+	Seed   uint64
+}
+
+// ForecastPoint is one percentile of the simulated completion dates:
+// Percent of the simulated futures finish by the end of Iteration. Date
+// is zero when that percentile did not finish within the horizon.
+type ForecastPoint struct {
+	Percent    int
+	Iterations int
+	Iteration  int
+	Date       time.Time
+}
+
+// DeliveryForecast is the outcome of a Monte Carlo run for one target:
+// an epic, or everything ranked down to an item in _priority.md.
+type DeliveryForecast struct {
+	Target      string
+	Remaining   float64
+	Stories     int
+	Unestimated int
+	Samples     []float64
+	Trials      int
+	Seed        uint64
+	Points      []ForecastPoint
+	Unbounded   int // trials that did not finish within the horizon
+	// InitialVelocity is set when there was no accepted history and the
+	// configured initial velocity stood in as the only sample.
+	InitialVelocity bool
+}
+
+// ForecastEpic simulates delivery of every open story carrying the epic
+// slug. Throughput is sampled from the whole project's history, since an
+// epic may span backlogs.
+func ForecastEpic(root *BacklogsStructure, cfg *config.Config, now time.Time, slug string, opts DeliveryForecastOptions) (*DeliveryForecast, error) {
+	dirs, err := root.BacklogDirs()
+	if err != nil {
+		return nil, err
+	}
+	var all, open []*BacklogItem
+	found := false
+	for _, d := range dirs {
+		bck, err := LoadBacklog(d)
+		if err != nil {
+			return nil, err
+		}
+		for _, it := range bck.ActiveItems() {
+			all = append(all, it)
+			if !strings.EqualFold(it.Epic(), slug) {
+				continue
+			}
+			found = true
+			if !strings.EqualFold(it.Status(), AcceptedStatus.Name) {
+				open = append(open, it)
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("epic %q: no stories carry this epic slug", slug)
+	}
+	return forecastItems(root.Root(), "epic "+slug, open, all, cfg, now, opts)
+}
+
+// ForecastUntil simulates delivery of every open story ranked at or
+// above the item at path (relative to the project root) in its
+// backlog's _priority.md. Throughput comes from that backlog.
+func ForecastUntil(root *BacklogsStructure, cfg *config.Config, now time.Time, path string, opts DeliveryForecastOptions) (*DeliveryForecast, error) {
+	backlogDir, path, err := backlogItemPath(root, path)
+	if err != nil {
+		return nil, err
+	}
+	bck, err := LoadBacklog(backlogDir)
+	if err != nil {
+		return nil, err
+	}
+	pri, err := LoadPriority(backlogDir)
+	if err != nil {
+		return nil, err
+	}
+	items := bck.ActiveItems()
+	byPath := make(map[string]*BacklogItem, len(items))
+	for _, it := range items {
+		byPath[filepath.Base(it.Path())] = it
+	}
+	var open []*BacklogItem
+	found := false
+	for _, e := range pri.Entries() {
+		if it := byPath[e.Path]; it != nil && !strings.EqualFold(it.Status(), AcceptedStatus.Name) {
+			open = append(open, it)
+		}
+		if e.Path == filepath.Base(path) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("%s is not in %s/_priority.md", path, filepath.Dir(path))
+	}
+	return forecastItems(root.Root(), path, open, items, cfg, now, opts)
+}
+
+// backlogItemPath resolves ref ("#1234" or a path relative to the root)
+// to an item directly inside one of the project's backlogs, returning the
+// backlog folder and the item's slash path relative to the root. Anything
+// else, such as a path climbing out of the project, is refused.
+func backlogItemPath(root *BacklogsStructure, ref string) (string, string, error) {
+	abs, err := ResolveItemPath(root, ref)
+	if err != nil {
+		return "", "", err
+	}
+	rel, err := filepath.Rel(root.Root(), abs)
+	if err != nil {
+		return "", "", err
+	}
+	rel = filepath.ToSlash(rel)
+	name, file, ok := strings.Cut(rel, "/")
+	if ok && !strings.Contains(file, "/") {
+		dirs, err := root.BacklogDirs()
+		if err != nil {
+			return "", "", err
+		}
+		for _, dir := range dirs {
+			if filepath.Base(dir) == name {
+				if _, isBacklog := FindOverviewFileInRootDirectory(dir); isBacklog {
+					return dir, rel, nil
+				}
+			}
+		}
+	}
+	return "", "", fmt.Errorf("%s is not an item in one of the project's backlogs", ref)
+}
+
+func forecastItems(rootDir, target string, open, history []*BacklogItem, cfg *config.Config, now time.Time, opts DeliveryForecastOptions) (*DeliveryForecast, error) {
+	if opts.Trials <= 0 {
+		opts.Trials = DefaultForecastTrials
+	}
+	if opts.Seed == 0 {
+		opts.Seed = DefaultForecastSeed
+	}
+	overrides, _ := LoadIterationOverrides(rootDir)
+	f := &DeliveryForecast{
+		Target:  target,
+		Samples: ThroughputSamples(VelocityHistory(now, history, cfg, overrides, 0), cfg),
+		Trials:  opts.Trials,
+		Seed:    opts.Seed,
+	}
+	for _, it := range open {
+		if it.Type() == "release" {
+			continue
+		}
+		f.Stories++
+		pts := it.estimateAsFloat()
+		if pts <= 0 && (it.Type() == "" || it.Type() == "feature") {
+			f.Unestimated++
+		}
+		f.Remaining += pts
+	}
+	if !hasThroughput(f.Samples) {
+		// Same fallback as ComputeVelocity: a project with no accepted
+		// history forecasts at its configured initial velocity.
+		if cfg.Velocity.InitialVelocity <= 0 {
+			return nil, fmt.Errorf("no accepted points in the last %d iterations and no initial velocity configured", len(f.Samples))
+		}
+		f.Samples = []float64{float64(cfg.Velocity.InitialVelocity)}
+		f.InitialVelocity = true
+	}
+
+	iterStart := IterationStartFor(now, cfg)
+	weeks := cfg.Iteration.LengthWeeks
+	if weeks < 1 {
+		weeks = 1
+	}
+	current := iterationNumberFor(iterStart, cfg)
+	strength := func(i int) float64 {
+		if overrides == nil {
+			return 1
+		}
+		return overrides.StrengthFor(current + i)
+	}
+	runs := SimulateDelivery(f.Samples, f.Remaining, strength, opts.Trials, opts.Seed)
+	for _, n := range runs {
+		if n > forecastHorizon {
+			f.Unbounded++
+		}
+	}
+	for _, p := range ForecastPercentiles {
+		n := runs[percentileIndex(len(runs), p)]
+		pt := ForecastPoint{Percent: p, Iterations: n, Iteration: current + n - 1}
+		if n <= forecastHorizon {
+			pt.Date = iterStart.AddDate(0, 0, 7*weeks*n-1)
+		}
+		f.Points = append(f.Points, pt)
+	}
+	return f, nil
+}
+
+// ThroughputSamples turns velocity history into per-iteration throughput
+// at full strength and project-default length: accepted points divided by
+// team strength, scaled by default/actual length. Iterations overridden
+// to strength 0 are dropped.
+func ThroughputSamples(history []VelocityHistoryEntry, cfg *config.Config) []float64 {
+	weeks := cfg.Iteration.LengthWeeks
+	if weeks < 1 {
+		weeks = 1
+	}
+	var out []float64
+	for _, h := range history {
+		if h.TeamStrength <= 0 {
+			continue
+		}
+		length := h.LengthWeeks
+		if length < 1 {
+			length = weeks
+		}
+		out = append(out, h.Accepted/h.TeamStrength*float64(weeks)/float64(length))
+	}
+	return out
+}
+
+// SimulateDelivery runs `trials` simulated futures. Each future draws one
+// historical sample per iteration (with replacement), scales it by that
+// iteration's team strength (strength(0) is the current iteration) and
+// counts iterations until `remaining` points are burned. Returns the
+// iteration counts sorted ascending; a future that has not finished after
+// forecastHorizon iterations reports forecastHorizon+1.
+func SimulateDelivery(samples []float64, remaining float64, strength func(i int) float64, trials int, seed uint64) []int {
+	runs := make([]int, trials)
+	if remaining <= 0 || len(samples) == 0 {
+		for i := range runs {
+			runs[i] = 1
+			if remaining > 0 {
+				runs[i] = forecastHorizon + 1
+			}
+		}
+		return runs
+	}
+	rng := rand.New(rand.NewPCG(seed, seed))
+	for t := range runs {
+		done := 0.0
+		n := 0
+		for done < remaining && n <= forecastHorizon {
+			done += samples[rng.IntN(len(samples))] * strength(n)
+			n++
+		}
+		runs[t] = n
+	}
+	sort.Ints(runs)
+	return runs
+}
+
+func percentileIndex(n, p int) int {
+	i := (n*p+99)/100 - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= n {
+		i = n - 1
+	}
+	return i
+}
+
+func hasThroughput(samples []float64) bool {
+	for _, s := range samples {
+		if s > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliveryForecastASCII renders a forecast as a short report.
+func DeliveryForecastASCII(f *DeliveryForecast) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Forecast: %s\n\n", f.Target)
+	stories := "stories"
+	if f.Stories == 1 {
+		stories = "story"
+	}
+	fmt.Fprintf(&b, "  remaining:   %.0f pts in %d open %s\n", f.Remaining, f.Stories, stories)
+	if f.Unestimated > 0 {
+		fmt.Fprintf(&b, "               %d unestimated feature(s) not counted\n", f.Unestimated)
+	}
+	samples := make([]string, len(f.Samples))
+	for i, s := range f.Samples {
+		samples[i] = fmt.Sprintf("%.0f", s)
+	}
+	if f.InitialVelocity {
+		fmt.Fprintf(&b, "  throughput:  %s pts / iteration (initial velocity; no accepted history yet)\n", samples[0])
+	} else {
+		fmt.Fprintf(&b, "  throughput:  %s pts / iteration (last %d)\n", strings.Join(samples, " "), len(f.Samples))
+	}
+	fmt.Fprintf(&b, "  simulated:   %d futures, seed %d\n\n", f.Trials, f.Seed)
+	for _, p := range f.Points {
+		if p.Date.IsZero() {
+			fmt.Fprintf(&b, "  %2d%%  not within %d iterations\n", p.Percent, forecastHorizon)
+			continue
+		}
+		fmt.Fprintf(&b, "  %2d%%  by %s  (iteration %d)\n", p.Percent, p.Date.Format("Mon Jan 02 2006"), p.Iteration)
+	}
+	return b.String()
+}
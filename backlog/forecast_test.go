@@ -0,0 +1,97 @@
+package backlog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mreider/agilemarkdown/config"
+)
+
+func TestSimulateDeliveryDeterministic(t *testing.T) {
+	full := func(int) float64 { return 1 }
+	samples := []float64{2, 5, 8}
+	a := SimulateDelivery(samples, 40, full, 2000, 7)
+	b := SimulateDelivery(samples, 40, full, 2000, 7)
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("same seed gave different runs at %d: %d vs %d", i, a[i], b[i])
+		}
+	}
+	// 40 points at 2..8 per iteration: never fewer than 5, never more than 20.
+	if a[0] < 5 || a[len(a)-1] > 20 {
+		t.Errorf("runs outside [5, 20]: min %d max %d", a[0], a[len(a)-1])
+	}
+	p50, p95 := a[percentileIndex(len(a), 50)], a[percentileIndex(len(a), 95)]
+	if p50 > p95 {
+		t.Errorf("p50 %d after p95 %d", p50, p95)
+	}
+
+	// A constant sample is exact; half strength in the first two
+	// iterations costs one extra iteration.
+	if got := SimulateDelivery([]float64{10}, 30, full, 10, 1)[0]; got != 3 {
+		t.Errorf("30 pts at 10/iteration took %d iterations, want 3", got)
+	}
+	half := func(i int) float64 {
+		if i < 2 {
+			return 0.5
+		}
+		return 1
+	}
+	if got := SimulateDelivery([]float64{10}, 30, half, 10, 1)[0]; got != 4 {
+		t.Errorf("with two half-strength iterations took %d, want 4", got)
+	}
+}
+
+func TestThroughputSamplesNormalizeStrength(t *testing.T) {
+	c := config.Defaults()
+	history := []VelocityHistoryEntry{
+		{Accepted: 10, TeamStrength: 1, LengthWeeks: 1},
+		{Accepted: 5, TeamStrength: 0.5, LengthWeeks: 1},
+		{Accepted: 7, TeamStrength: 0, LengthWeeks: 1},
+		{Accepted: 20, TeamStrength: 1, LengthWeeks: 2},
+	}
+	got := ThroughputSamples(history, c)
+	want := []float64{10, 10, 10}
+	if len(got) != len(want) {
+		t.Fatalf("samples = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("samples = %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestForecastUntilStaysInBacklogs(t *testing.T) {
+	base := t.TempDir()
+	src := filepath.Join(base, "project")
+	for _, dir := range []string{"project/product", "project/docs", "outside/product"} {
+		if err := os.MkdirAll(filepath.Join(base, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(src, "product.md"), []byte("# product\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{filepath.Join(src, "product"), filepath.Join(src, "docs"), filepath.Join(base, "outside", "product")} {
+		makeItem(t, dir, "login", map[string]string{"title": "Login", "status": "unstarted", "estimate": "2"})
+		pri, _ := LoadPriority(dir)
+		pri.InsertBottom(OrderEntry{Title: "Login", Path: "login.md"})
+		if err := pri.Save(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root := NewBacklogsStructure(src)
+	cfg := config.Defaults()
+	if _, err := ForecastUntil(root, cfg, time.Now(), "product/login.md", DeliveryForecastOptions{Trials: 10}); err != nil {
+		t.Fatalf("forecast in a backlog: %v", err)
+	}
+	for _, path := range []string{"../outside/product/login.md", "docs/login.md", "/etc/passwd"} {
+		if _, err := ForecastUntil(root, cfg, time.Now(), path, DeliveryForecastOptions{Trials: 10}); err == nil {
+			t.Errorf("ForecastUntil(%q) read outside the backlogs", path)
+		}
+	}
+}
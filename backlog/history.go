@@ -8,8 +8,9 @@ import (
 )
 
 // VelocityHistoryEntry is one row of structured velocity history. `Planned`
-// is filled with accepted-or-rejected points in the iteration window since
-// agilemarkdown does not yet store a planning snapshot per iteration.
+// is filled with accepted-or-rejected points in the iteration window;
+// ApplyIterationPlans swaps in the committed planning snapshot (and sets
+// Snapshot, CarryOver and ScopeAdded) for iterations that have one.
 type VelocityHistoryEntry struct {
 	Iteration    int
 	Start        time.Time
@@ -17,6 +18,9 @@ type VelocityHistoryEntry struct {
 	Accepted     float64
 	LengthWeeks  int
 	TeamStrength float64
+	Snapshot     bool
+	CarryOver    float64
+	ScopeAdded   float64
 }
 
 // VelocityHistory returns one entry per completed iteration in the lookback
@@ -2,6 +2,7 @@ package backlog
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -222,3 +223,54 @@ func TestItemIterationIceboxAndBacklog(t *testing.T) {
 		t.Fatalf("expected numeric band for pos=7 vel=5, got (%d,%q)", num, label)
 	}
 }
+
+func TestCFDRowsFromGitReplaysRejection(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	story := func(status string) string {
+		return "---\ntitle: Login\nstatus: " + status + "\ntype: feature\n---\n"
+	}
+	commitAs(t, dir, "Alice", "2026-05-01T10:00:00Z", map[string]string{
+		"product/login.md":     story("unstarted"),
+		"product/idea.md":      "---\ntitle: Idea\nstatus: unstarted\n---\n",
+		"product/_priority.md": "# Priority\n\n- [Login](login.md)\n",
+		"product/_icebox.md":   "# Icebox\n\n- [Idea](idea.md)\n",
+	})
+	commitAs(t, dir, "Alice", "2026-05-02T10:00:00Z", map[string]string{"product/login.md": story("started")})
+	commitAs(t, dir, "Alice", "2026-05-03T10:00:00Z", map[string]string{"product/login.md": story("accepted")})
+	commitAs(t, dir, "Alice", "2026-05-04T10:00:00Z", map[string]string{"product/login.md": story("rejected")})
+	commitAs(t, dir, "Alice", "2026-05-05T10:00:00Z", map[string]string{
+		"product/_priority.md": "# Priority\n\n- [Login](login.md)\n- [Idea](idea.md)\n",
+		"product/_icebox.md":   "# Icebox\n",
+	})
+
+	day := func(s string) time.Time {
+		v, _ := time.Parse(time.RFC3339, s+"T00:00:00Z")
+		return v
+	}
+	rows, err := CFDRowsFromGit(NewBacklogsStructure(dir), day("2026-05-01"), day("2026-05-06"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []CFDRow{
+		{Backlog: 1},  // login unstarted, idea iced
+		{InFlight: 1}, // started
+		{Accepted: 1}, // accepted
+		{InFlight: 1}, // rejected: back in flight
+		{InFlight: 1, Backlog: 1},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("want %d rows, got %d", len(want), len(rows))
+	}
+	for i, w := range want {
+		r := rows[i]
+		if r.Accepted != w.Accepted || r.InFlight != w.InFlight || r.Backlog != w.Backlog {
+			t.Errorf("day %d: got accepted=%d in_flight=%d backlog=%d, want %+v", i, r.Accepted, r.InFlight, r.Backlog, w)
+		}
+	}
+}
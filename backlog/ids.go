@@ -0,0 +1,283 @@
+package backlog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Stable item IDs. Sync gives every item a project-wide `id:` taken from
+// a counter in .am/last-id that only goes up, so a number is never reused
+// even after its item is deleted. "#1234" works wherever a path does: CLI
+// arguments and MCP tool paths. Generated pages link the references
+// they show (LinkItemRefs); item files themselves are never rewritten.
+
+var (
+	itemRefRe     = regexp.MustCompile(`^#?([0-9]+)$`)
+	bodyItemRefRe = regexp.MustCompile(`\[#([0-9]+)\]\(([^)\s]*)\)|#([0-9]+)\b`)
+)
+
+// ParseItemRef returns the ID in "#1234" (or a bare "1234"), or 0 when s
+// is not an ID reference.
+func ParseItemRef(s string) int {
+	m := itemRefRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0
+	}
+	id, _ := strconv.Atoi(m[1])
+	return id
+}
+
+// ItemIndex maps IDs to items across every backlog, archives included.
+type ItemIndex struct {
+	items []*BacklogItem
+	byID  map[int]*BacklogItem
+}
+
+func LoadItemIndex(root *BacklogsStructure) (*ItemIndex, error) {
+	dirs, err := root.BacklogDirs()
+	if err != nil {
+		return nil, err
+	}
+	idx := &ItemIndex{byID: map[int]*BacklogItem{}}
+	for _, d := range dirs {
+		bck, err := LoadBacklog(d)
+		if err != nil {
+			return nil, err
+		}
+		for _, it := range bck.AllItems() {
+			idx.items = append(idx.items, it)
+			if id := it.ID(); id > 0 {
+				if prev, ok := idx.byID[id]; !ok || idOwnerBefore(it, prev) {
+					idx.byID[id] = it
+				}
+			}
+		}
+	}
+	return idx, nil
+}
+
+func (idx *ItemIndex) Items() []*BacklogItem { return idx.items }
+
+// Item returns the item carrying id, or nil. When two items share an ID
+// (a merge before sync ran) the earlier-created one owns it.
+func (idx *ItemIndex) Item(id int) *BacklogItem { return idx.byID[id] }
+
+func (idx *ItemIndex) maxID() int {
+	max := 0
+	for id := range idx.byID {
+		if id > max {
+			max = id
+		}
+	}
+	return max
+}
+
+// idOwnerBefore orders duplicate-ID claimants: earliest created, then path.
+func idOwnerBefore(a, b *BacklogItem) bool {
+	ca, cb := a.Created(), b.Created()
+	if !ca.Equal(cb) {
+		if ca.IsZero() || cb.IsZero() {
+			return !ca.IsZero()
+		}
+		return ca.Before(cb)
+	}
+	return a.Path() < b.Path()
+}
+
+// FindItemByID returns the item with the given ID.
+func FindItemByID(root *BacklogsStructure, id int) (*BacklogItem, error) {
+	idx, err := LoadItemIndex(root)
+	if err != nil {
+		return nil, err
+	}
+	if it := idx.Item(id); it != nil {
+		return it, nil
+	}
+	return nil, fmt.Errorf("no item #%d", id)
+}
+
+// ResolveItemPath turns an item reference into an absolute path: "#1234"
+// is looked up by ID, anything else is a path relative to the root.
+func ResolveItemPath(root *BacklogsStructure, ref string) (string, error) {
+	if id := ParseItemRef(ref); id > 0 {
+		it, err := FindItemByID(root, id)
+		if err != nil {
+			return "", err
+		}
+		return it.Path(), nil
+	}
+	return filepath.Join(root.Root(), ref), nil
+}
+
+func readLastID(root *BacklogsStructure) (int, error) {
+	data, err := os.ReadFile(root.LastIDFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", root.LastIDFile(), err)
+	}
+	return n, nil
+}
+
+func writeLastID(root *BacklogsStructure, id int) error {
+	if err := os.MkdirAll(filepath.Dir(root.LastIDFile()), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(root.LastIDFile(), []byte(strconv.Itoa(id)+"\n"), 0644)
+}
+
+// AssignItemID gives item the next ID and saves it. Items that already
+// have one keep it.
+func AssignItemID(root *BacklogsStructure, item *BacklogItem) error {
+	if item.ID() > 0 {
+		return nil
+	}
+	idx, err := LoadItemIndex(root)
+	if err != nil {
+		return err
+	}
+	last, err := readLastID(root)
+	if err != nil {
+		return err
+	}
+	if m := idx.maxID(); m > last {
+		last = m
+	}
+	item.SetID(last + 1)
+	if err := item.Save(); err != nil {
+		return err
+	}
+	return writeLastID(root, last+1)
+}
+
+// AssignIDs numbers every item without an ID, oldest first, and renumbers
+// all but the first claimant of a duplicated ID. Returns the items that
+// got a new number.
+func AssignIDs(root *BacklogsStructure) ([]*BacklogItem, error) {
+	idx, err := LoadItemIndex(root)
+	if err != nil {
+		return nil, err
+	}
+	last, err := readLastID(root)
+	if err != nil {
+		return nil, err
+	}
+	if m := idx.maxID(); m > last {
+		last = m
+	}
+	var pending []*BacklogItem
+	for _, it := range idx.Items() {
+		if id := it.ID(); id <= 0 || idx.Item(id) != it {
+			pending = append(pending, it)
+		}
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+	sort.SliceStable(pending, func(i, j int) bool { return idOwnerBefore(pending[i], pending[j]) })
+	for _, it := range pending {
+		last++
+		it.SetID(last)
+		if err := it.Save(); err != nil {
+			return nil, err
+		}
+	}
+	return pending, writeLastID(root, last)
+}
+
+// LinkItemRefs turns "#1234" in generated text into a markdown link to
+// that item, relative to fromDir, and re-points existing "[#1234](...)"
+// links at the item's current path. Unknown IDs, code spans, fenced
+// blocks, link labels and another tracker's numbers ("PR #12") are left
+// alone.
+func LinkItemRefs(text, fromDir string, idx *ItemIndex) string {
+	lines := strings.Split(text, "\n")
+	fenced := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fenced = !fenced
+			continue
+		}
+		if fenced {
+			continue
+		}
+		parts := strings.Split(line, "`")
+		for j := 0; j < len(parts); j += 2 {
+			parts[j] = linkItemRefsInSpan(parts[j], fromDir, idx)
+		}
+		lines[i] = strings.Join(parts, "`")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func linkItemRefsInSpan(s, fromDir string, idx *ItemIndex) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range bodyItemRefRe.FindAllStringSubmatchIndex(s, -1) {
+		start, end := m[0], m[1]
+		var idText string
+		if m[2] >= 0 {
+			idText = s[m[2]:m[3]]
+		} else {
+			// A bare #N must start a word: skip "a#1", "/#1", "&#1;", "##1".
+			if start > 0 {
+				if c := s[start-1]; isRefWordByte(c) || c == '/' || c == '&' || c == '#' || c == '[' {
+					continue
+				}
+			}
+			if inLinkLabel(s[:start]) || foreignRefWords[strings.ToLower(lastWord(s[:start]))] {
+				continue
+			}
+			idText = s[m[6]:m[7]]
+		}
+		id, _ := strconv.Atoi(idText)
+		it := idx.Item(id)
+		if it == nil {
+			continue
+		}
+		rel, err := filepath.Rel(fromDir, it.Path())
+		if err != nil {
+			continue
+		}
+		b.WriteString(s[last:start])
+		fmt.Fprintf(&b, "[#%d](%s)", id, filepath.ToSlash(rel))
+		last = end
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+func isRefWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// foreignRefWords are words that, right before a bare #N, say the number
+// belongs to some other tracker.
+var foreignRefWords = map[string]bool{"pr": true, "pull": true, "issue": true, "mr": true}
+
+// inLinkLabel reports whether the end of before is inside the label of
+// a markdown link, where another link cannot go.
+func inLinkLabel(before string) bool {
+	return strings.LastIndex(before, "[") > strings.LastIndex(before, "]")
+}
+
+func lastWord(before string) string {
+	fields := strings.Fields(before)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[len(fields)-1]
+}
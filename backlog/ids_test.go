@@ -0,0 +1,111 @@
+package backlog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestItemIDsAndRename(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "product"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(path, front, body string) {
+		text := "---\ntitle: " + strings.TrimSuffix(filepath.Base(path), ".md") + "\nstatus: unstarted\n" + front + "---\n\n" + body + "\n"
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// login and signup were merged from two branches that both took #1.
+	write("product/login.md", "id: 1\ncreated: 2024-01-01 00:00\n", "first")
+	write("product/signup.md", "id: 1\ncreated: 2024-02-01 00:00\nblocked_by: [login.md]\n", "after #1, see `#1` and [login](login.md)")
+	write("product/search.md", "created: 2024-03-01 00:00\n", "body")
+	if err := os.WriteFile(filepath.Join(dir, "product/_priority.md"), []byte("# Priority\n\n- [login](login.md)\n- [signup](signup.md)\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	root := NewBacklogsStructure(dir)
+	changed, err := AssignIDs(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 2 || changed[0].Title() != "signup" || changed[0].ID() != 2 || changed[1].ID() != 3 {
+		t.Fatalf("renumbered = %v", changed)
+	}
+
+	// Deleting the newest item must not free its number.
+	if err := os.Remove(filepath.Join(dir, "product/search.md")); err != nil {
+		t.Fatal(err)
+	}
+	write("product/export.md", "", "body")
+	export, _ := LoadBacklogItem(filepath.Join(dir, "product/export.md"))
+	if err := AssignItemID(root, export); err != nil {
+		t.Fatal(err)
+	}
+	if export.ID() != 4 {
+		t.Errorf("export id = %d, want 4", export.ID())
+	}
+
+	// References are linked where pages are generated; the item itself
+	// keeps what its author wrote.
+	idx, err := LoadItemIndex(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signup, _ := LoadBacklogItem(filepath.Join(dir, "product/signup.md"))
+	if want := "after #1, see `#1`"; !strings.Contains(signup.Body(), want) {
+		t.Errorf("signup body = %q, want it to contain %q", signup.Body(), want)
+	}
+	text := "after #1, see `#1`, GitHub PR #1 and [issue #1](https://example.com/1)"
+	want := "after [#1](login.md), see `#1`, GitHub PR #1 and [issue #1](https://example.com/1)"
+	if got := LinkItemRefs(text, filepath.Join(dir, "product"), idx); got != want {
+		t.Errorf("LinkItemRefs = %q, want %q", got, want)
+	}
+	if p, err := ResolveItemPath(root, "#4"); err != nil || p != export.Path() {
+		t.Errorf("ResolveItemPath(#4) = %q, %v", p, err)
+	}
+
+	login, _ := LoadBacklogItem(filepath.Join(dir, "product/login.md"))
+	newPath, err := RenameItem(root, login, "Sign in")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(newPath) != "Sign-in.md" || login.ID() != 1 {
+		t.Errorf("renamed to %s with id %d", newPath, login.ID())
+	}
+	priority, _ := os.ReadFile(filepath.Join(dir, "product/_priority.md"))
+	if !strings.Contains(string(priority), "- [Sign in](Sign-in.md)\n- [signup]") {
+		t.Errorf("_priority.md not updated:\n%s", priority)
+	}
+	signup, _ = LoadBacklogItem(filepath.Join(dir, "product/signup.md"))
+	if got := signup.BlockedBy(); len(got) != 1 || got[0] != "product/Sign-in.md" {
+		t.Errorf("signup blocked_by = %v", got)
+	}
+	if !strings.Contains(signup.Body(), "[login](Sign-in.md)") {
+		t.Errorf("links not rewritten: %q", signup.Body())
+	}
+
+	// A title that differs from another item's file name only in case
+	// must not move over it.
+	lower := filepath.Join(dir, "product/sign-in.md")
+	if err := os.WriteFile(lower, []byte("---\ntitle: sign in\n---\n\nother.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if a, _ := os.Stat(lower); a != nil {
+		if b, _ := os.Stat(newPath); b != nil && os.SameFile(a, b) {
+			t.Skip("case-insensitive filesystem")
+		}
+	}
+	other, _ := LoadBacklogItem(lower)
+	if _, err := RenameItem(root, other, "Sign in"); err == nil {
+		t.Fatal("rename over an item differing only in case succeeded")
+	}
+	if kept, _ := LoadBacklogItem(newPath); kept.Title() != "Sign in" || kept.ID() != 1 {
+		t.Errorf("existing item overwritten: title %q id %d", kept.Title(), kept.ID())
+	}
+	if other, _ = LoadBacklogItem(lower); other.Title() != "sign in" {
+		t.Errorf("failed rename retitled the item: %q", other.Title())
+	}
+}
@@ -0,0 +1,192 @@
+package backlog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// IterationPlan is the planning snapshot frozen by `am sprint plan
+// --commit` at iteration start and stored in `.am/iterations/<n>.yaml`.
+// One file per iteration number; each backlog commits its own slice.
+// Later edits to _priority.md or estimates do not change the snapshot,
+// which is what lets velocity history report planned vs. accepted.
+type IterationPlan struct {
+	Number   int                    `yaml:"number"`
+	Start    string                 `yaml:"start"`
+	Backlogs []IterationPlanBacklog `yaml:"backlogs"`
+}
+
+// IterationPlanBacklog is the committed slice of one backlog's
+// _priority.md: the items above the velocity line, with their estimates
+// as they were at commit time.
+type IterationPlanBacklog struct {
+	Name        string              `yaml:"name"`
+	CommittedAt string              `yaml:"committed_at"`
+	Velocity    float64             `yaml:"velocity"`
+	Items       []IterationPlanItem `yaml:"items"`
+}
+
+type IterationPlanItem struct {
+	Path     string  `yaml:"path"`
+	Title    string  `yaml:"title"`
+	Estimate float64 `yaml:"estimate"`
+}
+
+// IterationPlanOutcome compares a snapshot with what actually happened in
+// the iteration window. CarryOver is planned work not accepted by the end
+// of the window; ScopeAdded is work started or accepted in the window
+// that was not in the snapshot.
+type IterationPlanOutcome struct {
+	Planned        float64
+	Accepted       float64
+	CarryOver      float64
+	ScopeAdded     float64
+	CarryOverItems []string
+	AddedItems     []string
+}
+
+const iterationPlansDirName = ".am/iterations"
+
+// IterationPlanFile returns the absolute path of the snapshot for
+// iteration n under a project root.
+func IterationPlanFile(rootDir string, n int) string {
+	return filepath.Join(rootDir, iterationPlansDirName, strconv.Itoa(n)+".yaml")
+}
+
+// LoadIterationPlan reads `.am/iterations/<n>.yaml`. A missing file
+// returns (nil, nil): no snapshot was committed for that iteration.
+func LoadIterationPlan(rootDir string, n int) (*IterationPlan, error) {
+	data, err := os.ReadFile(IterationPlanFile(rootDir, n))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	plan := &IterationPlan{}
+	if err := yaml.Unmarshal(data, plan); err != nil {
+		return nil, fmt.Errorf("iterations/%d.yaml: %w", n, err)
+	}
+	return plan, nil
+}
+
+// Save writes the snapshot to `.am/iterations/<number>.yaml`.
+func (p *IterationPlan) Save(rootDir string) error {
+	path := IterationPlanFile(rootDir, p.Number)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Backlog returns the committed slice for the named backlog, or nil.
+func (p *IterationPlan) Backlog(name string) *IterationPlanBacklog {
+	if p == nil {
+		return nil
+	}
+	for i := range p.Backlogs {
+		if strings.EqualFold(p.Backlogs[i].Name, name) {
+			return &p.Backlogs[i]
+		}
+	}
+	return nil
+}
+
+// SetBacklog adds or replaces the slice for b.Name.
+func (p *IterationPlan) SetBacklog(b IterationPlanBacklog) {
+	if existing := p.Backlog(b.Name); existing != nil {
+		*existing = b
+		return
+	}
+	p.Backlogs = append(p.Backlogs, b)
+}
+
+// Points is the sum of the snapshot estimates.
+func (b *IterationPlanBacklog) Points() float64 {
+	var pts float64
+	for _, it := range b.Items {
+		pts += it.Estimate
+	}
+	return pts
+}
+
+// EvaluateIterationPlan scores a snapshot against the items as they are
+// now, for the window [start, end). Only points-bearing features and bugs
+// count, matching VelocityHistory.
+func EvaluateIterationPlan(b *IterationPlanBacklog, items []*BacklogItem, start, end time.Time) IterationPlanOutcome {
+	var out IterationPlanOutcome
+	if b == nil {
+		return out
+	}
+	inWindow := func(t time.Time) bool {
+		return !t.IsZero() && !t.Before(start) && t.Before(end)
+	}
+	byBase := make(map[string]*BacklogItem, len(items))
+	for _, it := range items {
+		byBase[filepath.Base(it.Path())] = it
+	}
+	planned := make(map[string]bool, len(b.Items))
+	for _, p := range b.Items {
+		planned[p.Path] = true
+		out.Planned += p.Estimate
+		it, ok := byBase[p.Path]
+		accepted := ok && strings.EqualFold(it.Status(), AcceptedStatus.Name) && !it.Accepted().IsZero() && it.Accepted().Before(end)
+		if !accepted {
+			out.CarryOver += p.Estimate
+			out.CarryOverItems = append(out.CarryOverItems, p.Path)
+		}
+	}
+	for _, it := range items {
+		typ := it.Type()
+		if typ != "" && typ != "feature" && typ != "bug" {
+			continue
+		}
+		pts := it.estimateAsFloat()
+		if pts <= 0 {
+			continue
+		}
+		accepted := strings.EqualFold(it.Status(), AcceptedStatus.Name) && inWindow(it.Accepted())
+		if accepted {
+			out.Accepted += pts
+		}
+		base := filepath.Base(it.Path())
+		if !planned[base] && (accepted || inWindow(it.Started())) {
+			out.ScopeAdded += pts
+			out.AddedItems = append(out.AddedItems, base)
+		}
+	}
+	return out
+}
+
+// ApplyIterationPlans replaces the estimated `Planned` column of velocity
+// history rows with the committed snapshot for backlogName wherever one
+// exists, and fills in carry-over and scope added for those rows.
+func ApplyIterationPlans(rows []VelocityHistoryEntry, rootDir, backlogName string, items []*BacklogItem) []VelocityHistoryEntry {
+	for i := range rows {
+		plan, err := LoadIterationPlan(rootDir, rows[i].Iteration)
+		if err != nil {
+			continue
+		}
+		b := plan.Backlog(backlogName)
+		if b == nil {
+			continue
+		}
+		end := rows[i].Start.AddDate(0, 0, 7*rows[i].LengthWeeks)
+		outcome := EvaluateIterationPlan(b, items, rows[i].Start, end)
+		rows[i].Planned = outcome.Planned
+		rows[i].CarryOver = outcome.CarryOver
+		rows[i].ScopeAdded = outcome.ScopeAdded
+		rows[i].Snapshot = true
+	}
+	return rows
+}
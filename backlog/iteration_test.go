@@ -183,3 +183,51 @@ func acceptedItem(t *testing.T, name string, points int, acceptedAt string) *Bac
 	item.SetAccepted(acceptedAt)
 	return item
 }
+
+func TestEvaluateIterationPlan(t *testing.T) {
+	dir := t.TempDir()
+	start, _ := time.Parse(time.RFC3339, "2026-05-04T00:00:00Z")
+	end := start.AddDate(0, 0, 7)
+	var items []*BacklogItem
+	// a: planned and accepted, b: planned and still started (carry-over),
+	// c: pulled in mid-iteration and accepted (scope added).
+	items = append(items, makeItem(t, dir, "a", map[string]string{
+		"title": "a", "status": "accepted", "estimate": "3",
+		"started": "2026-05-04T10:00:00Z", "accepted": "2026-05-06T10:00:00Z",
+	}))
+	items = append(items, makeItem(t, dir, "b", map[string]string{
+		"title": "b", "status": "started", "estimate": "5",
+		"started": "2026-05-05T10:00:00Z",
+	}))
+	items = append(items, makeItem(t, dir, "c", map[string]string{
+		"title": "c", "status": "accepted", "estimate": "2",
+		"started": "2026-05-07T10:00:00Z", "accepted": "2026-05-08T10:00:00Z",
+	}))
+	plan := &IterationPlanBacklog{Name: "product", Items: []IterationPlanItem{
+		{Path: "a.md", Title: "a", Estimate: 3},
+		{Path: "b.md", Title: "b", Estimate: 5},
+	}}
+	got := EvaluateIterationPlan(plan, items, start, end)
+	if got.Planned != 8 || got.Accepted != 5 || got.CarryOver != 5 || got.ScopeAdded != 2 {
+		t.Fatalf("outcome = %+v, want planned=8 accepted=5 carry_over=5 scope_added=2", got)
+	}
+	if len(got.CarryOverItems) != 1 || got.CarryOverItems[0] != "b.md" {
+		t.Errorf("carry-over items = %v", got.CarryOverItems)
+	}
+
+	root := t.TempDir()
+	snapshot := &IterationPlan{Number: 42, Start: "2026-05-04", Backlogs: []IterationPlanBacklog{*plan}}
+	if err := snapshot.Save(root); err != nil {
+		t.Fatal(err)
+	}
+	rows := ApplyIterationPlans([]VelocityHistoryEntry{
+		{Iteration: 41, Start: start.AddDate(0, 0, -7), LengthWeeks: 1, Planned: 1},
+		{Iteration: 42, Start: start, LengthWeeks: 1, Planned: 1},
+	}, root, "product", items)
+	if rows[0].Snapshot || rows[0].Planned != 1 {
+		t.Errorf("iteration without snapshot changed: %+v", rows[0])
+	}
+	if !rows[1].Snapshot || rows[1].Planned != 8 || rows[1].CarryOver != 5 || rows[1].ScopeAdded != 2 {
+		t.Errorf("snapshot not applied: %+v", rows[1])
+	}
+}
@@ -0,0 +1,442 @@
+package backlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mreider/agilemarkdown/utils"
+)
+
+const (
+	// JournalLimit is how many entries the undo journal keeps.
+	JournalLimit = 100
+	// journalMaxFileSize skips large files (attachments) when
+	// snapshotting; the journal is for backlog text.
+	journalMaxFileSize = 1 << 20
+)
+
+// untrackedDirs are left out of journal snapshots and the watcher: git
+// internals, dependencies, and am's own local state.
+var untrackedDirs = map[string]bool{".git": true, "node_modules": true, cacheDirectoryName: true, journalDirectoryName: true}
+
+// JournalEntry records one mutating command: every file it touched with
+// the bytes before and after. Before or After is nil when the file did
+// not exist on that side. Actor tells the agents of a shared MCP server
+// apart: the request's author, or its session ID.
+type JournalEntry struct {
+	Seq     int           `json:"seq"`
+	Time    string        `json:"time"`
+	Source  string        `json:"source"`
+	Command string        `json:"command"`
+	Args    string        `json:"args,omitempty"`
+	Actor   string        `json:"actor,omitempty"`
+	Undone  bool          `json:"undone,omitempty"`
+	Files   []JournalFile `json:"files"`
+}
+
+type JournalFile struct {
+	Path   string  `json:"path"`
+	Before *string `json:"before"`
+	After  *string `json:"after"`
+}
+
+// Paths lists the entry's files, relative to the root.
+func (e *JournalEntry) Paths() []string {
+	paths := make([]string, len(e.Files))
+	for i, f := range e.Files {
+		paths[i] = f.Path
+	}
+	return paths
+}
+
+// Snapshot is the content of the project's files at one moment, taken
+// before a command runs so its changes can be journaled afterwards.
+type Snapshot struct {
+	taken time.Time
+	files map[string]snapshotFile
+}
+
+type snapshotFile struct {
+	stamp fileStamp
+	data  []byte
+}
+
+// TakeSnapshot reads the files am writes (see journalScope), except
+// the ID counter (undo must never hand an ID out twice) and the coach
+// override log (nor erase an override). With the item cache on, files
+// unchanged since the previous snapshot are not read again.
+func TakeSnapshot(root *BacklogsStructure) (*Snapshot, error) {
+	var prev *Snapshot
+	if itemCache != nil {
+		prev = itemCache.lastSnapshot()
+	}
+	snap, err := takeSnapshot(root, prev)
+	if err == nil && itemCache != nil {
+		itemCache.setLastSnapshot(snap)
+	}
+	return snap, err
+}
+
+// journalScope lists the top-level folders and files a snapshot covers,
+// relative to the root: backlog folders (those with a <name>.md
+// overview next to them), the folders sync renders, .am, and the
+// Markdown files at the top. Anything else in the work tree, source
+// code included, is not am's to journal or undo.
+func journalScope(root *BacklogsStructure) (dirs, files []string, err error) {
+	infos, err := os.ReadDir(root.Root())
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, info := range infos {
+		name := info.Name()
+		switch {
+		case info.IsDir() && name == ".am":
+			dirs = append(dirs, name)
+		case info.IsDir() && strings.HasPrefix(name, "."):
+		case info.IsDir() && IsForbiddenBacklogName(name):
+			dirs = append(dirs, name)
+		case info.IsDir():
+			if _, err := os.Stat(filepath.Join(root.Root(), name+".md")); err == nil {
+				dirs = append(dirs, name)
+			}
+		case info.Type().IsRegular() && filepath.Ext(name) == ".md":
+			files = append(files, name)
+		}
+	}
+	return dirs, files, nil
+}
+
+// takeSnapshot reuses prev's bytes for a file whose size and modification
+// time are unchanged and whose modification time had already settled
+// when prev was taken; anything else is read from disk.
+func takeSnapshot(root *BacklogsStructure, prev *Snapshot) (*Snapshot, error) {
+	snap := &Snapshot{taken: time.Now(), files: map[string]snapshotFile{}}
+	dirs, files, err := journalScope(root)
+	if err != nil {
+		return nil, err
+	}
+	add := func(path, rel string, d fs.DirEntry) error {
+		if !d.Type().IsRegular() || rel == lastIDFileName || rel == coachLogFileName || strings.HasSuffix(rel, ".am-tmp") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || info.Size() > journalMaxFileSize {
+			return nil
+		}
+		stamp := fileStamp{modTime: info.ModTime(), size: info.Size()}
+		if prev != nil {
+			if old, ok := prev.files[rel]; ok && old.stamp.equal(stamp) && prev.taken.Sub(stamp.modTime) > cacheSettleTime {
+				snap.files[rel] = old
+				return nil
+			}
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		snap.files[rel] = snapshotFile{stamp: stamp, data: data}
+		return nil
+	}
+	for _, dir := range dirs {
+		err := filepath.WalkDir(filepath.Join(root.Root(), dir), func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(root.Root(), path)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			if d.IsDir() {
+				if untrackedDirs[rel] || untrackedDirs[d.Name()] {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			return add(path, rel, d)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range files {
+		info, err := os.Lstat(filepath.Join(root.Root(), name))
+		if err != nil {
+			continue
+		}
+		if err := add(filepath.Join(root.Root(), name), name, fs.FileInfoToDirEntry(info)); err != nil {
+			return nil, err
+		}
+	}
+	return snap, nil
+}
+
+// RecordJournal compares the project against before and, when anything
+// changed, appends entry with those files to the journal. Recording
+// drops any undone entries, so they can no longer be redone. Returns
+// nil when nothing changed.
+func RecordJournal(root *BacklogsStructure, before *Snapshot, entry JournalEntry) (*JournalEntry, error) {
+	after, err := takeSnapshot(root, before)
+	if err != nil {
+		return nil, err
+	}
+	if itemCache != nil {
+		itemCache.setLastSnapshot(after)
+	}
+	entry.Files = diffSnapshots(before, after)
+	if len(entry.Files) == 0 {
+		return nil, nil
+	}
+	entries, err := LoadJournal(root)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureJournalDirectory(root); err != nil {
+		return nil, err
+	}
+	var kept []*JournalEntry
+	for _, e := range entries {
+		if e.Undone {
+			if err := os.Remove(journalEntryPath(root, e.Seq)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		kept = append(kept, e)
+	}
+	if len(entries) > 0 {
+		entry.Seq = entries[len(entries)-1].Seq + 1
+	} else {
+		entry.Seq = 1
+	}
+	entry.Time = utils.GetCurrentTimestamp()
+	entry.Undone = false
+	if err := saveJournalEntry(root, &entry); err != nil {
+		return nil, err
+	}
+	for len(kept) >= JournalLimit {
+		if err := os.Remove(journalEntryPath(root, kept[0].Seq)); err != nil {
+			return nil, err
+		}
+		kept = kept[1:]
+	}
+	return &entry, nil
+}
+
+func diffSnapshots(before, after *Snapshot) []JournalFile {
+	paths := map[string]bool{}
+	for p := range before.files {
+		paths[p] = true
+	}
+	for p := range after.files {
+		paths[p] = true
+	}
+	var files []JournalFile
+	for p := range paths {
+		bf, inBefore := before.files[p]
+		af, inAfter := after.files[p]
+		b, a := bf.data, af.data
+		if inBefore == inAfter && string(a) == string(b) {
+			continue
+		}
+		f := JournalFile{Path: p}
+		if inBefore {
+			s := string(b)
+			f.Before = &s
+		}
+		if inAfter {
+			s := string(a)
+			f.After = &s
+		}
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files
+}
+
+// LoadJournal returns the journal's entries, oldest first.
+func LoadJournal(root *BacklogsStructure) ([]*JournalEntry, error) {
+	infos, err := os.ReadDir(root.JournalDirectory())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var entries []*JournalEntry
+	for _, info := range infos {
+		if info.IsDir() || filepath.Ext(info.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(root.JournalDirectory(), info.Name()))
+		if err != nil {
+			return nil, err
+		}
+		e := &JournalEntry{}
+		if err := json.Unmarshal(data, e); err != nil {
+			return nil, fmt.Errorf("journal %s: %w", info.Name(), err)
+		}
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Seq < entries[j].Seq })
+	return entries, nil
+}
+
+// UndoJournal reverts the last n entries that are not undone yet, newest
+// first, and marks them undone. It stops with an error, before touching
+// anything, if a file has changed since the entry was recorded, unless
+// force is set.
+func UndoJournal(root *BacklogsStructure, n int, force bool) ([]*JournalEntry, error) {
+	entries, err := LoadJournal(root)
+	if err != nil {
+		return nil, err
+	}
+	var todo []*JournalEntry
+	for i := len(entries) - 1; i >= 0 && len(todo) < n; i-- {
+		if !entries[i].Undone {
+			todo = append(todo, entries[i])
+		}
+	}
+	if len(todo) == 0 {
+		return nil, fmt.Errorf("nothing to undo")
+	}
+	if err := replayJournal(root, todo, true, force); err != nil {
+		return nil, err
+	}
+	return todo, nil
+}
+
+// UndoJournalEntries rolls back todo, given newest first, with the same
+// conflict check as UndoJournal. Entries in between that are not in
+// todo stay; one that changed the same files since is a conflict.
+func UndoJournalEntries(root *BacklogsStructure, todo []*JournalEntry, force bool) error {
+	if len(todo) == 0 {
+		return fmt.Errorf("nothing to undo")
+	}
+	return replayJournal(root, todo, true, force)
+}
+
+// RedoJournal reapplies the n most recently undone entries, oldest
+// first, with the same conflict check as UndoJournal.
+func RedoJournal(root *BacklogsStructure, n int, force bool) ([]*JournalEntry, error) {
+	entries, err := LoadJournal(root)
+	if err != nil {
+		return nil, err
+	}
+	var todo []*JournalEntry
+	for _, e := range entries {
+		if e.Undone && len(todo) < n {
+			todo = append(todo, e)
+		}
+	}
+	if len(todo) == 0 {
+		return nil, fmt.Errorf("nothing to redo")
+	}
+	if err := replayJournal(root, todo, false, force); err != nil {
+		return nil, err
+	}
+	return todo, nil
+}
+
+// replayJournal moves the files of todo, in order, to their before
+// (undo) or after (redo) contents. Every entry is checked against the
+// files as the entries before it in todo leave them, so a conflict
+// anywhere stops the whole batch before anything is written. Files that
+// should exist are then written all-or-nothing; files that should not
+// are removed afterwards, along with directories that became empty.
+func replayJournal(root *BacklogsStructure, todo []*JournalEntry, undo, force bool) error {
+	verb := "redo"
+	if undo {
+		verb = "undo"
+	}
+	state := map[string]*string{}
+	var paths []string
+	for _, e := range todo {
+		for _, f := range e.Files {
+			want, from := f.After, f.Before
+			if undo {
+				want, from = f.Before, f.After
+			}
+			cur, ok := state[f.Path]
+			if !ok {
+				paths = append(paths, f.Path)
+				data, err := os.ReadFile(filepath.Join(root.Root(), filepath.FromSlash(f.Path)))
+				switch {
+				case err == nil:
+					s := string(data)
+					cur = &s
+				case !os.IsNotExist(err):
+					return err
+				}
+			}
+			if !force && ((cur == nil) != (from == nil) || (cur != nil && *cur != *from)) {
+				return fmt.Errorf("can't %s #%d (%s): %s has changed since; use --force to overwrite", verb, e.Seq, e.Command, f.Path)
+			}
+			state[f.Path] = want
+		}
+	}
+	writes := map[string][]byte{}
+	var removes []string
+	for _, rel := range paths {
+		path := filepath.Join(root.Root(), filepath.FromSlash(rel))
+		if state[rel] == nil {
+			removes = append(removes, path)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		writes[path] = []byte(*state[rel])
+	}
+	if err := WriteFilesAtomically(writes); err != nil {
+		return err
+	}
+	for _, path := range removes {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		for dir := filepath.Dir(path); dir != root.Root(); dir = filepath.Dir(dir) {
+			if os.Remove(dir) != nil {
+				break
+			}
+		}
+	}
+	for _, e := range todo {
+		e.Undone = undo
+		if err := saveJournalEntry(root, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ensureJournalDirectory(root *BacklogsStructure) error {
+	dir := root.JournalDirectory()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	// Prior file contents are local history, not something to commit.
+	ignore := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(ignore); os.IsNotExist(err) {
+		return os.WriteFile(ignore, []byte("*\n"), 0644)
+	}
+	return nil
+}
+
+func journalEntryPath(root *BacklogsStructure, seq int) string {
+	return filepath.Join(root.JournalDirectory(), fmt.Sprintf("%06d.json", seq))
+}
+
+func saveJournalEntry(root *BacklogsStructure, e *JournalEntry) error {
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(journalEntryPath(root, e.Seq), data, 0644)
+}
@@ -0,0 +1,114 @@
+package backlog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJournalUndoRedo(t *testing.T) {
+	dir := t.TempDir()
+	root := NewBacklogsStructure(dir)
+	write := func(rel, text string) {
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(rel string) string {
+		data, err := os.ReadFile(filepath.Join(dir, rel))
+		if err != nil {
+			return "<missing>"
+		}
+		return string(data)
+	}
+	record := func(command string, change func()) *JournalEntry {
+		snap, err := TakeSnapshot(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		change()
+		e, err := RecordJournal(root, snap, JournalEntry{Source: "cli", Command: command})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return e
+	}
+	write("product.md", "# product")
+	write("product/login.md", "v1")
+
+	if e := record("am show login", func() {}); e != nil {
+		t.Errorf("a command that changes nothing was journaled: %+v", e)
+	}
+	// Only what am writes is journaled, not the code next to it.
+	if e := record("am show login", func() { write("src/main.go", "package main"); write("notes/todo.md", "x") }); e != nil {
+		t.Errorf("a change outside the backlog was journaled: %+v", e)
+	}
+	record("am estimate login 3", func() { write("product/login.md", "v2") })
+	e := record("am create-backlog platform", func() { write("platform.md", "# platform"); write("platform/setup.md", "new") })
+	if e.Seq != 2 || strings.Join(e.Paths(), ",") != "platform.md,platform/setup.md" || e.Files[0].Before != nil {
+		t.Fatalf("entry = %+v", e)
+	}
+
+	if _, err := UndoJournal(root, 2, false); err != nil {
+		t.Fatal(err)
+	}
+	if read("product/login.md") != "v1" || read("platform/setup.md") != "<missing>" {
+		t.Errorf("undo left login=%q setup=%q", read("product/login.md"), read("platform/setup.md"))
+	}
+	if _, err := os.Stat(filepath.Join(dir, "platform")); !os.IsNotExist(err) {
+		t.Errorf("undo left the empty platform/ directory")
+	}
+	if _, err := UndoJournal(root, 1, false); err == nil {
+		t.Errorf("undo past the start of the journal should fail")
+	}
+
+	if _, err := RedoJournal(root, 1, false); err != nil {
+		t.Fatal(err)
+	}
+	if read("product/login.md") != "v2" {
+		t.Errorf("redo: login = %q", read("product/login.md"))
+	}
+
+	// A hand edit since the change blocks undo unless forced.
+	write("product/login.md", "edited")
+	if _, err := UndoJournal(root, 1, false); err == nil || !strings.Contains(err.Error(), "product/login.md has changed") {
+		t.Errorf("undo over a hand edit = %v", err)
+	}
+	if read("product/login.md") != "edited" {
+		t.Errorf("refused undo wrote login.md")
+	}
+
+	// A new change drops what could still be redone.
+	record("am estimate login 5", func() { write("product/login.md", "v3") })
+	if _, err := RedoJournal(root, 1, false); err == nil {
+		t.Errorf("redo after a new change should have nothing to redo")
+	}
+	entries, _ := LoadJournal(root)
+	if len(entries) != 2 || entries[1].Seq != 3 {
+		t.Errorf("journal = %v", entries)
+	}
+
+	// A conflict on the second of two entries leaves the first one
+	// alone too.
+	write("product/signup.md", "s1")
+	record("am estimate signup 2", func() { write("product/signup.md", "s2") })
+	write("product/login.md", "edited")
+	if _, err := UndoJournal(root, 2, false); err == nil || !strings.Contains(err.Error(), "product/login.md has changed") {
+		t.Errorf("two-entry undo over a hand edit = %v", err)
+	}
+	if read("product/signup.md") != "s2" {
+		t.Errorf("refused two-entry undo reverted signup.md")
+	}
+	write("product/login.md", "v3")
+	if _, err := UndoJournal(root, 2, false); err != nil {
+		t.Fatal(err)
+	}
+	if read("product/signup.md") != "s1" || read("product/login.md") != "edited" {
+		t.Errorf("two-entry undo left signup=%q login=%q", read("product/signup.md"), read("product/login.md"))
+	}
+}
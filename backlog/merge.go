@@ -0,0 +1,386 @@
+package backlog
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mreider/agilemarkdown/markdown"
+)
+
+// Three-way merges used by `am merge-driver`. Git hands the driver the
+// common ancestor, our version and their version of one file; the driver
+// writes the result over ours and reports whether conflicts remain.
+//
+// Items merge their frontmatter key by key (tags and assignees as sets),
+// their `## Comments` and `## Tasks` sections entry by entry, and any
+// other body section as a whole. Order files merge as ranked lists and
+// never conflict. Conflict markers are only written where both sides set
+// the same scalar, or the same free-text section, to different values.
+
+const (
+	mergeOursLabel   = "ours"
+	mergeTheirsLabel = "theirs"
+)
+
+var itemMergeOptions = markdown.MergeOptions{
+	SetKeys:    []string{itemKeyTags, itemKeyAssigned},
+	LatestKeys: []string{itemKeyModified},
+}
+
+var commentStartRe = regexp.MustCompile(`^\s*@[\w.\-]+`)
+
+// MergeItem merges three versions of an item file. conflicts is the
+// number of conflict blocks written into merged.
+func MergeItem(base, ours, theirs string) (merged string, conflicts int, err error) {
+	b, err := markdown.ParseFrontmatter(base)
+	if err != nil {
+		return "", 0, fmt.Errorf("base: %w", err)
+	}
+	o, err := markdown.ParseFrontmatter(ours)
+	if err != nil {
+		return "", 0, fmt.Errorf("ours: %w", err)
+	}
+	t, err := markdown.ParseFrontmatter(theirs)
+	if err != nil {
+		return "", 0, fmt.Errorf("theirs: %w", err)
+	}
+	f, fmConflicts := markdown.MergeFrontmatter(b, o, t, itemMergeOptions)
+	body, bodyConflicts := mergeItemBody(b.Body(), o.Body(), t.Body())
+	f.SetBody(body)
+	return string(f.BytesWithConflicts(fmConflicts, mergeOursLabel, mergeTheirsLabel)), len(fmConflicts) + bodyConflicts, nil
+}
+
+// bodySection is a heading line and everything up to the next heading.
+// The preamble before the first heading has an empty key.
+type bodySection struct {
+	key  string
+	text string
+}
+
+func splitBodySections(body string) []bodySection {
+	lines := strings.Split(body, "\n")
+	sections := []bodySection{{}}
+	var cur []string
+	flush := func() {
+		sections[len(sections)-1].text = strings.Join(cur, "\n")
+		cur = nil
+	}
+	for _, line := range lines {
+		if m := sectionHeadingRe.FindStringSubmatch(line); m != nil {
+			flush()
+			sections = append(sections, bodySection{key: strings.ToLower(m[1])})
+		}
+		cur = append(cur, line)
+	}
+	flush()
+	return sections
+}
+
+func mergeItemBody(base, ours, theirs string) (string, int) {
+	switch {
+	case ours == theirs, theirs == base:
+		return ours, 0
+	case ours == base:
+		return theirs, 0
+	}
+	bSecs, oSecs, tSecs := splitBodySections(base), splitBodySections(ours), splitBodySections(theirs)
+	find := func(secs []bodySection, key string) (string, bool) {
+		for _, s := range secs {
+			if s.key == key {
+				return s.text, true
+			}
+		}
+		return "", false
+	}
+
+	var keys []string
+	for _, s := range oSecs {
+		keys = append(keys, s.key)
+	}
+	for i, s := range tSecs {
+		if _, ok := find(oSecs, s.key); ok {
+			continue
+		}
+		if _, ok := find(bSecs, s.key); ok {
+			continue // deleted by ours; handled below
+		}
+		keys = insertAfterKey(keys, s.key, tSecs, i)
+	}
+	for _, s := range bSecs {
+		if _, ok := find(oSecs, s.key); !ok {
+			keys = append(keys, s.key)
+		}
+	}
+
+	conflicts := 0
+	var parts []string
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		b, _ := find(bSecs, key)
+		o, oOK := find(oSecs, key)
+		t, tOK := find(tSecs, key)
+		text, ok := mergeSection(b, o, t, oOK, tOK)
+		if !ok {
+			conflicts++
+		}
+		if strings.TrimSpace(text) != "" || key == "" {
+			parts = append(parts, strings.TrimRight(text, "\n"))
+		}
+	}
+	out := strings.Join(parts, "\n\n")
+	out = strings.TrimLeft(out, "\n")
+	if out != "" {
+		out += "\n"
+	}
+	return out, conflicts
+}
+
+// insertAfterKey places key after the key that precedes it in secs, or
+// at the end when that predecessor is not in keys.
+func insertAfterKey(keys []string, key string, secs []bodySection, idx int) []string {
+	if idx > 0 {
+		prev := secs[idx-1].key
+		for i, k := range keys {
+			if k == prev {
+				out := append([]string(nil), keys[:i+1]...)
+				out = append(out, key)
+				return append(out, keys[i+1:]...)
+			}
+		}
+	}
+	return append(keys, key)
+}
+
+func mergeSection(base, ours, theirs string, oursOK, theirsOK bool) (string, bool) {
+	norm := func(s string) string { return strings.TrimRight(s, "\n ") }
+	switch {
+	case norm(ours) == norm(theirs):
+		return ours, true
+	case norm(ours) == norm(base):
+		return theirs, true
+	case norm(theirs) == norm(base):
+		return ours, true
+	}
+	if oursOK && theirsOK {
+		switch {
+		case commentsTitleRe.MatchString(sectionHeading(ours)):
+			return mergeCommentsSection(base, ours, theirs), true
+		case tasksHeadingRe.MatchString(sectionHeading(ours)):
+			return mergeTasksSection(base, ours, theirs), true
+		}
+	}
+	return fmt.Sprintf("<<<<<<< %s\n%s\n=======\n%s\n>>>>>>> %s", mergeOursLabel, norm(ours), norm(theirs), mergeTheirsLabel), false
+}
+
+func sectionHeading(text string) string {
+	if i := strings.Index(text, "\n"); i >= 0 {
+		return strings.TrimSpace(text[:i])
+	}
+	return strings.TrimSpace(text)
+}
+
+// commentEntries splits a Comments section into its heading and one
+// string per comment (header line plus its text, trailing blanks trimmed).
+func commentEntries(text string) (string, []string) {
+	lines := strings.Split(text, "\n")
+	heading := ""
+	if len(lines) > 0 {
+		heading, lines = lines[0], lines[1:]
+	}
+	var entries []string
+	var cur []string
+	flush := func() {
+		if s := strings.TrimRight(strings.Join(cur, "\n"), "\n "); strings.TrimSpace(s) != "" {
+			entries = append(entries, s)
+		}
+		cur = nil
+	}
+	for _, line := range lines {
+		if commentStartRe.MatchString(line) {
+			flush()
+		}
+		cur = append(cur, line)
+	}
+	flush()
+	return heading, entries
+}
+
+func mergeCommentsSection(base, ours, theirs string) string {
+	_, b := commentEntries(base)
+	heading, o := commentEntries(ours)
+	_, t := commentEntries(theirs)
+	entries := mergeEntries(b, o, t)
+	// comments are a log: new ones from theirs go after ours, not next to
+	// their neighbour in theirs
+	inOurs := toSet(o)
+	sort.SliceStable(entries, func(i, j int) bool { return inOurs[entries[i]] && !inOurs[entries[j]] })
+	for i := range entries {
+		entries[i] = strings.TrimLeft(entries[i], "\n")
+	}
+	return heading + "\n\n" + strings.Join(entries, "\n\n") + "\n"
+}
+
+func mergeTasksSection(base, ours, theirs string) string {
+	type taskState struct {
+		done bool
+		line string
+	}
+	parse := func(text string) ([]string, map[string]taskState) {
+		var order []string
+		states := make(map[string]taskState)
+		for _, line := range strings.Split(text, "\n") {
+			m := taskLineRe.FindStringSubmatch(strings.TrimRight(line, " \t\r"))
+			if m == nil {
+				continue
+			}
+			key := strings.ToLower(strings.TrimSpace(m[2]))
+			if _, dup := states[key]; !dup {
+				order = append(order, key)
+			}
+			states[key] = taskState{done: m[1] != " ", line: line}
+		}
+		return order, states
+	}
+	bOrder, bStates := parse(base)
+	oOrder, oStates := parse(ours)
+	tOrder, tStates := parse(theirs)
+	var lines []string
+	for _, key := range mergeEntries(bOrder, oOrder, tOrder) {
+		o, oOK := oStates[key]
+		t, tOK := tStates[key]
+		b, bOK := bStates[key]
+		switch {
+		case oOK && tOK && bOK && o.done == b.done:
+			lines = append(lines, t.line)
+		case oOK:
+			lines = append(lines, o.line)
+		default:
+			lines = append(lines, t.line)
+		}
+	}
+	return sectionHeading(ours) + "\n\n" + strings.Join(lines, "\n") + "\n"
+}
+
+// mergeEntries merges three versions of an ordered list of unique
+// entries. Entries either side removed are dropped; entries only theirs
+// added are placed after their predecessor in theirs.
+func mergeEntries(base, ours, theirs []string) []string {
+	inBase := toSet(base)
+	inOurs := toSet(ours)
+	inTheirs := toSet(theirs)
+	var out []string
+	for _, e := range ours {
+		if inBase[e] && !inTheirs[e] {
+			continue
+		}
+		out = append(out, e)
+	}
+	for i, e := range theirs {
+		if inOurs[e] || inBase[e] {
+			continue
+		}
+		out = insertAfter(out, e, theirs[:i])
+	}
+	return out
+}
+
+// insertAfter inserts e after the last entry of before that is already
+// in list, or at the front when none is.
+func insertAfter(list []string, e string, before []string) []string {
+	for j := len(before) - 1; j >= 0; j-- {
+		for i, x := range list {
+			if x == before[j] {
+				out := append([]string(nil), list[:i+1]...)
+				out = append(out, e)
+				return append(out, list[i+1:]...)
+			}
+		}
+	}
+	return append([]string{e}, list...)
+}
+
+func toSet(xs []string) map[string]bool {
+	set := make(map[string]bool, len(xs))
+	for _, x := range xs {
+		set[x] = true
+	}
+	return set
+}
+
+// MergeOrderFile merges three versions of a _priority.md or _icebox.md.
+// If only theirs re-ranked the shared entries, their order wins;
+// otherwise ours does. Removals from either side are applied and
+// additions from theirs keep their place relative to their neighbours.
+// The header is taken from ours.
+func MergeOrderFile(base, ours, theirs string) string {
+	bEntries, oEntries, tEntries := ParseOrderEntries(base), ParseOrderEntries(ours), ParseOrderEntries(theirs)
+	titles := make(map[string]string)
+	paths := func(entries []OrderEntry) []string {
+		out := make([]string, 0, len(entries))
+		for _, e := range entries {
+			if _, ok := titles[e.Path]; !ok {
+				titles[e.Path] = e.Title
+			}
+			out = append(out, e.Path)
+		}
+		return out
+	}
+	o, t, b := paths(oEntries), paths(tEntries), paths(bEntries)
+
+	var merged []string
+	if sameRelativeOrder(b, o, t) && !sameRelativeOrder(b, t, o) {
+		merged = mergeEntries(b, t, o)
+	} else {
+		merged = mergeEntries(b, o, t)
+	}
+
+	var sb strings.Builder
+	for _, line := range strings.Split(ours, "\n") {
+		if orderLineRe.MatchString(line) {
+			break
+		}
+		sb.WriteString(line + "\n")
+	}
+	header := strings.TrimRight(sb.String(), "\n")
+	sb.Reset()
+	if header != "" {
+		sb.WriteString(header + "\n\n")
+	}
+	for _, p := range merged {
+		fmt.Fprintf(&sb, "- [%s](%s)\n", titles[p], p)
+	}
+	return sb.String()
+}
+
+// sameRelativeOrder reports whether the entries common to base, x and
+// other appear in the same order in base and x.
+func sameRelativeOrder(base, x, other []string) bool {
+	inX, inOther := toSet(x), toSet(other)
+	var fromBase, fromX []string
+	for _, p := range base {
+		if inX[p] && inOther[p] {
+			fromBase = append(fromBase, p)
+		}
+	}
+	inBase := toSet(base)
+	for _, p := range x {
+		if inBase[p] && inOther[p] {
+			fromX = append(fromX, p)
+		}
+	}
+	if len(fromBase) != len(fromX) {
+		return false
+	}
+	for i := range fromBase {
+		if fromBase[i] != fromX[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,112 @@
+package backlog
+
+import (
+	"strings"
+	"testing"
+)
+
+const mergeBase = `---
+title: Login
+status: unstarted
+estimate: "3"
+tags: [auth]
+modified: 2026-01-01T10:00:00Z
+---
+
+Users sign in with email.
+
+## Tasks
+
+- [ ] form
+- [ ] session
+
+## Comments
+
+@alice 2026-01-01
+first
+`
+
+func TestMergeItemClean(t *testing.T) {
+	ours := strings.NewReplacer(
+		"status: unstarted", "status: started",
+		"tags: [auth]", "tags: [auth, web]",
+		"modified: 2026-01-01T10:00:00Z", "modified: 2026-01-02T10:00:00Z",
+		"- [ ] form", "- [x] form",
+		"first\n", "first\n\n@bob 2026-01-02\nours comment\n",
+	).Replace(mergeBase)
+	theirs := strings.NewReplacer(
+		"estimate: \"3\"", "estimate: \"5\"",
+		"tags: [auth]", "tags: [auth, mobile]",
+		"modified: 2026-01-01T10:00:00Z", "modified: 2026-01-03T10:00:00Z",
+		"- [ ] session", "- [ ] session\n- [ ] logout",
+		"first\n", "first\n\n@carol 2026-01-03\ntheirs comment\n",
+	).Replace(mergeBase)
+
+	merged, conflicts, err := MergeItem(mergeBase, ours, theirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conflicts != 0 {
+		t.Fatalf("want a clean merge, got %d conflicts:\n%s", conflicts, merged)
+	}
+	item := NewBacklogItem("login", merged)
+	if item.Status() != "started" || item.Estimate() != "5" {
+		t.Errorf("status/estimate = %q/%q, want started/5", item.Status(), item.Estimate())
+	}
+	if got := strings.Join(item.Tags(), ","); got != "auth,web,mobile" {
+		t.Errorf("tags = %q, want auth,web,mobile", got)
+	}
+	if !strings.Contains(merged, "modified: 2026-01-03T10:00:00Z") {
+		t.Errorf("want the later modified stamp:\n%s", merged)
+	}
+	tasks := ParseTasks(item.Body())
+	if len(tasks) != 3 || !tasks[0].Done || tasks[2].Text != "logout" {
+		t.Errorf("tasks = %+v", tasks)
+	}
+	comments := item.Comments()
+	if len(comments) != 3 {
+		t.Fatalf("want 3 comments, got %d:\n%s", len(comments), merged)
+	}
+	if comments[1].Users[0] != "bob" || comments[2].Users[0] != "carol" {
+		t.Errorf("comment order = %v, %v", comments[1].Users, comments[2].Users)
+	}
+}
+
+func TestMergeItemConflict(t *testing.T) {
+	ours := strings.Replace(mergeBase, "status: unstarted", "status: started", 1)
+	theirs := strings.Replace(mergeBase, "status: unstarted", "status: finished", 1)
+	merged, conflicts, err := MergeItem(mergeBase, ours, theirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conflicts != 1 {
+		t.Fatalf("want 1 conflict, got %d", conflicts)
+	}
+	want := "<<<<<<< ours\nstatus: started\n=======\nstatus: finished\n>>>>>>> theirs\n"
+	if !strings.Contains(merged, want) {
+		t.Errorf("missing conflict block:\n%s", merged)
+	}
+	if !strings.Contains(merged, "Users sign in with email.") {
+		t.Errorf("body lost:\n%s", merged)
+	}
+}
+
+func TestMergeOrderFile(t *testing.T) {
+	base := "# Priority\n\n- [A](a.md)\n- [B](b.md)\n- [C](c.md)\n- [D](d.md)\n"
+	// ours: adds E after A, drops D
+	ours := "# Priority\n\n- [A](a.md)\n- [E](e.md)\n- [B](b.md)\n- [C](c.md)\n"
+	// theirs: moves C to the top, adds F at the bottom
+	theirs := "# Priority\n\n- [C](c.md)\n- [A](a.md)\n- [B](b.md)\n- [D](d.md)\n- [F](f.md)\n"
+	merged := MergeOrderFile(base, ours, theirs)
+	var got []string
+	for _, e := range ParseOrderEntries(merged) {
+		got = append(got, e.Path)
+	}
+	want := "c.md,a.md,e.md,b.md,f.md"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("order = %s, want %s\n%s", strings.Join(got, ","), want, merged)
+	}
+	if !strings.HasPrefix(merged, "# Priority\n\n- [C](c.md)\n") {
+		t.Errorf("header not preserved:\n%s", merged)
+	}
+}
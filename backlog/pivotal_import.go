@@ -0,0 +1,654 @@
+package backlog
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mreider/agilemarkdown/config"
+)
+
+// pivotalCommentRe splits a Tracker CSV comment cell of the form
+// "text (Person Name - Jan 2, 2006)" into text, author and date.
+var pivotalCommentRe = regexp.MustCompile(`(?s)^(.*?)\s*\(([^()]+?) - ([A-Z][a-z]{2} \d{1,2}, \d{4}[^()]*)\)\s*$`)
+
+// PivotalImporter reads a full Pivotal Tracker export (the CSV from
+// "Export stories" or the JSON project export) into a backlog directory.
+// Unlike CsvImporter it keeps owners, comments, tasks and blockers, and it
+// rebuilds _priority.md and _icebox.md from the export's ranks. Exports
+// written by `am export` also carry each item's backlog, file name and ID,
+// which are restored too.
+type PivotalImporter struct {
+	CsvImporter
+	exportPath string
+	cfg        *config.Config
+}
+
+type pivotalComment struct {
+	Author string
+	Date   string
+	Text   string
+}
+
+type pivotalTask struct {
+	Text string
+	Done bool
+}
+
+type pivotalBlocker struct {
+	Text     string
+	Resolved bool
+}
+
+type pivotalStory struct {
+	ID          int
+	Backlog     string
+	File        string
+	Rank        int
+	Title       string
+	Type        string
+	State       string
+	Estimate    string
+	RequestedBy string
+	Owners      []string
+	Labels      []string
+	Created     string
+	Started     string
+	Finished    string
+	Delivered   string
+	Accepted    string
+	Deadline    string
+	Description string
+	Epic        string
+	Icebox      bool
+	Archived    bool
+	Comments    []pivotalComment
+	Tasks       []pivotalTask
+	Blockers    []pivotalBlocker
+}
+
+func NewPivotalImporter(exportPath string, backlogDir string) *PivotalImporter {
+	return &PivotalImporter{CsvImporter: CsvImporter{backlogDir: backlogDir}, exportPath: exportPath}
+}
+
+func (imp *PivotalImporter) Import() error {
+	var stories []pivotalStory
+	var err error
+	if strings.EqualFold(filepath.Ext(imp.exportPath), ".json") {
+		stories, err = imp.readJSON()
+	} else {
+		stories, err = imp.readCSV()
+	}
+	if err != nil {
+		return err
+	}
+
+	root := NewBacklogsStructure(filepath.Join(imp.backlogDir, ".."))
+	userList := NewUserList(root.UsersDirectory())
+	if imp.cfg, err = config.LoadConfig(root.ConfigFile()); err != nil {
+		return err
+	}
+
+	dirs, err := imp.storyDirs(root, stories)
+	if err != nil {
+		return err
+	}
+	refs, err := LoadItemIndex(root)
+	if err != nil {
+		return err
+	}
+
+	orders := make(map[string]*importOrder)
+	var orderDirs []string
+	seen := make(map[string]bool)
+	for i, story := range stories {
+		if strings.TrimSpace(story.Title) == "" {
+			continue
+		}
+		dir := dirs[i]
+		itemFile, err := imp.createStoryIfNotExists(dir, story, userList, refs)
+		if err != nil {
+			return err
+		}
+		if seen[filepath.Join(dir, itemFile)] {
+			continue
+		}
+		seen[filepath.Join(dir, itemFile)] = true
+		if story.Archived {
+			continue
+		}
+		order := orders[dir]
+		if order == nil {
+			order = &importOrder{}
+			orders[dir] = order
+			orderDirs = append(orderDirs, dir)
+		}
+		entry := rankedEntry{OrderEntry: OrderEntry{Title: story.Title, Path: itemFile}, rank: story.Rank}
+		if story.Icebox || strings.EqualFold(strings.TrimSpace(story.State), "unscheduled") {
+			order.icebox = append(order.icebox, entry)
+		} else {
+			order.priority = append(order.priority, entry)
+		}
+	}
+	if len(orderDirs) == 0 {
+		orderDirs = append(orderDirs, imp.backlogDir)
+		orders[imp.backlogDir] = &importOrder{}
+	}
+	for _, dir := range orderDirs {
+		if err := rebuildOrder(dir, byRank(orders[dir].priority), byRank(orders[dir].icebox)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storyDirs picks the backlog folder for each story. An export that names
+// more than one backlog (`am export` of the whole tree) is spread back
+// across them, and each must already exist; anything else lands in the
+// backlog the import was run in.
+func (imp *PivotalImporter) storyDirs(root *BacklogsStructure, stories []pivotalStory) ([]string, error) {
+	dirs := make([]string, len(stories))
+	names := make(map[string]bool)
+	for i, story := range stories {
+		dirs[i] = imp.backlogDir
+		if story.Backlog != "" {
+			names[story.Backlog] = true
+		}
+	}
+	if len(names) < 2 {
+		return dirs, nil
+	}
+	existing, err := root.BacklogDirs()
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]string)
+	for _, dir := range existing {
+		known[filepath.Base(dir)] = dir
+	}
+	for i, story := range stories {
+		if story.Backlog == "" {
+			continue
+		}
+		dir, ok := known[story.Backlog]
+		if !ok {
+			return nil, fmt.Errorf("the export has stories for backlog '%s', which does not exist; create it with `am create-backlog %s` first", story.Backlog, story.Backlog)
+		}
+		dirs[i] = dir
+	}
+	return dirs, nil
+}
+
+// importOrder collects the stories headed for one backlog's priority and
+// icebox lists, in export row order.
+type importOrder struct {
+	priority []rankedEntry
+	icebox   []rankedEntry
+}
+
+type rankedEntry struct {
+	OrderEntry
+	rank int
+}
+
+// byRank orders entries by the export's Rank column. Unranked entries keep
+// their row order below the ranked ones.
+func byRank(entries []rankedEntry) []OrderEntry {
+	sort.SliceStable(entries, func(i, j int) bool {
+		ri, rj := entries[i].rank, entries[j].rank
+		if ri > 0 && rj > 0 {
+			return ri < rj
+		}
+		return ri > 0 && rj <= 0
+	})
+	out := make([]OrderEntry, len(entries))
+	for i, e := range entries {
+		out[i] = e.OrderEntry
+	}
+	return out
+}
+
+func (imp *PivotalImporter) readCSV() ([]pivotalStory, error) {
+	csvFile, err := os.Open(imp.exportPath)
+	if err != nil {
+		return nil, err
+	}
+	defer csvFile.Close()
+	reader := csv.NewReader(bufio.NewReader(csvFile))
+	reader.FieldsPerRecord = -1
+
+	var stories []pivotalStory
+	for {
+		line, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if imp.headers == nil {
+			imp.parseHeaders(line)
+			continue
+		}
+		stories = append(stories, imp.storyFromCSV(line))
+	}
+	return stories, nil
+}
+
+func (imp *PivotalImporter) storyFromCSV(line []string) pivotalStory {
+	story := pivotalStory{
+		Backlog:     imp.cellValue(line, "backlog"),
+		File:        imp.cellValue(line, "file"),
+		Title:       imp.cellValue(line, "title"),
+		Type:        imp.cellValue(line, "type"),
+		State:       imp.cellValue(line, "current state"),
+		Estimate:    imp.cellValue(line, "estimate"),
+		RequestedBy: imp.cellValue(line, "requested by"),
+		Owners:      imp.cellValues(line, "owned by"),
+		Created:     imp.cellValue(line, "created at"),
+		Started:     imp.cellValue(line, "started at"),
+		Finished:    imp.cellValue(line, "finished at"),
+		Delivered:   imp.cellValue(line, "delivered at"),
+		Accepted:    imp.cellValue(line, "accepted at"),
+		Deadline:    imp.cellValue(line, "deadline"),
+		Description: imp.cellValue(line, "description"),
+		Epic:        imp.cellValue(line, "epic"),
+		Icebox:      strings.EqualFold(imp.cellValue(line, "icebox"), "true"),
+		Archived:    strings.EqualFold(imp.cellValue(line, "archived"), "true"),
+	}
+	if story.Type == "" {
+		story.Type = imp.cellValue(line, "story type")
+	}
+	story.ID, _ = strconv.Atoi(imp.cellValue(line, "id"))
+	story.Rank, _ = strconv.Atoi(imp.cellValue(line, "rank"))
+	for _, label := range delimiterRe.Split(imp.cellValue(line, "labels"), -1) {
+		story.Labels = append(story.Labels, label)
+	}
+	for _, cell := range imp.cellValues(line, "comment") {
+		story.Comments = append(story.Comments, parsePivotalComment(cell))
+	}
+	tasks, taskStates := imp.pairedValues(line, "task", "task status")
+	for i, text := range tasks {
+		story.Tasks = append(story.Tasks, pivotalTask{Text: text, Done: strings.EqualFold(taskStates[i], "completed")})
+	}
+	blockers, blockerStates := imp.pairedValues(line, "blocker", "blocker status")
+	for i, text := range blockers {
+		story.Blockers = append(story.Blockers, pivotalBlocker{Text: text, Resolved: strings.EqualFold(blockerStates[i], "resolved")})
+	}
+	return story
+}
+
+// pairedValues returns the non-empty cells of a repeated column together
+// with the value of the matching repeated status column (Tracker writes
+// "Task, Task Status, Task, Task Status, ...").
+func (imp *PivotalImporter) pairedValues(line []string, header, statusHeader string) ([]string, []string) {
+	statusIndexes := imp.headers[statusHeader]
+	var values, states []string
+	for n, idx := range imp.headers[header] {
+		if idx >= len(line) || strings.TrimSpace(line[idx]) == "" {
+			continue
+		}
+		state := ""
+		if n < len(statusIndexes) && statusIndexes[n] < len(line) {
+			state = strings.TrimSpace(line[statusIndexes[n]])
+		}
+		values = append(values, strings.TrimSpace(line[idx]))
+		states = append(states, state)
+	}
+	return values, states
+}
+
+func parsePivotalComment(cell string) pivotalComment {
+	m := pivotalCommentRe.FindStringSubmatch(cell)
+	if m == nil {
+		return pivotalComment{Text: strings.TrimSpace(cell)}
+	}
+	return pivotalComment{Text: strings.TrimSpace(m[1]), Author: strings.TrimSpace(m[2]), Date: strings.TrimSpace(m[3])}
+}
+
+// pivotalName decodes a Tracker person or label, which the API renders
+// either as a bare string or as an object with a "name" field.
+type pivotalName string
+
+func (n *pivotalName) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*n = pivotalName(s)
+		return nil
+	}
+	var obj struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	*n = pivotalName(obj.Name)
+	return nil
+}
+
+type pivotalJSONComment struct {
+	Text      string      `json:"text"`
+	Person    pivotalName `json:"person,omitempty"`
+	CreatedAt string      `json:"created_at,omitempty"`
+}
+
+type pivotalJSONTask struct {
+	Description string `json:"description"`
+	Complete    bool   `json:"complete"`
+}
+
+type pivotalJSONBlocker struct {
+	Description string `json:"description"`
+	Resolved    bool   `json:"resolved"`
+}
+
+// pivotalJSONStory follows the Tracker API story shape. The fields after
+// Blockers are agilemarkdown extensions written by `am export`; Tracker
+// exports simply leave them empty.
+type pivotalJSONStory struct {
+	ID           int                  `json:"id,omitempty"`
+	Name         string               `json:"name"`
+	StoryType    string               `json:"story_type"`
+	CurrentState string               `json:"current_state"`
+	Estimate     *float64             `json:"estimate,omitempty"`
+	Description  string               `json:"description,omitempty"`
+	CreatedAt    string               `json:"created_at,omitempty"`
+	AcceptedAt   string               `json:"accepted_at,omitempty"`
+	Deadline     string               `json:"deadline,omitempty"`
+	RequestedBy  pivotalName          `json:"requested_by,omitempty"`
+	Owners       []pivotalName        `json:"owners,omitempty"`
+	Labels       []pivotalName        `json:"labels,omitempty"`
+	Comments     []pivotalJSONComment `json:"comments,omitempty"`
+	Tasks        []pivotalJSONTask    `json:"tasks,omitempty"`
+	Blockers     []pivotalJSONBlocker `json:"blockers,omitempty"`
+
+	Backlog     string   `json:"backlog,omitempty"`
+	File        string   `json:"file,omitempty"`
+	Rank        int      `json:"rank,omitempty"`
+	Icebox      bool     `json:"icebox,omitempty"`
+	Archived    bool     `json:"archived,omitempty"`
+	Epic        string   `json:"epic,omitempty"`
+	StartedAt   string   `json:"started_at,omitempty"`
+	FinishedAt  string   `json:"finished_at,omitempty"`
+	DeliveredAt string   `json:"delivered_at,omitempty"`
+	Acceptance  []string `json:"acceptance,omitempty"`
+}
+
+func (imp *PivotalImporter) readJSON() ([]pivotalStory, error) {
+	data, err := os.ReadFile(imp.exportPath)
+	if err != nil {
+		return nil, err
+	}
+	// Either a bare array of stories or a project export wrapping them.
+	var raw []pivotalJSONStory
+	if err := json.Unmarshal(data, &raw); err != nil {
+		var project struct {
+			Stories []pivotalJSONStory `json:"stories"`
+		}
+		if err := json.Unmarshal(data, &project); err != nil {
+			return nil, err
+		}
+		raw = project.Stories
+	}
+
+	stories := make([]pivotalStory, 0, len(raw))
+	for _, r := range raw {
+		story := pivotalStory{
+			ID:          r.ID,
+			Backlog:     r.Backlog,
+			File:        r.File,
+			Rank:        r.Rank,
+			Title:       r.Name,
+			Type:        r.StoryType,
+			State:       r.CurrentState,
+			RequestedBy: string(r.RequestedBy),
+			Created:     r.CreatedAt,
+			Started:     r.StartedAt,
+			Finished:    r.FinishedAt,
+			Delivered:   r.DeliveredAt,
+			Accepted:    r.AcceptedAt,
+			Deadline:    r.Deadline,
+			Description: r.Description,
+			Epic:        r.Epic,
+			Icebox:      r.Icebox,
+			Archived:    r.Archived,
+		}
+		if r.Estimate != nil {
+			story.Estimate = fmt.Sprintf("%g", *r.Estimate)
+		}
+		for _, o := range r.Owners {
+			story.Owners = append(story.Owners, string(o))
+		}
+		for _, l := range r.Labels {
+			story.Labels = append(story.Labels, string(l))
+		}
+		for _, c := range r.Comments {
+			story.Comments = append(story.Comments, pivotalComment{Text: c.Text, Author: string(c.Person), Date: c.CreatedAt})
+		}
+		for _, t := range r.Tasks {
+			story.Tasks = append(story.Tasks, pivotalTask{Text: t.Description, Done: t.Complete})
+		}
+		for _, b := range r.Blockers {
+			story.Blockers = append(story.Blockers, pivotalBlocker{Text: b.Description, Resolved: b.Resolved})
+		}
+		stories = append(stories, story)
+	}
+	return stories, nil
+}
+
+// createStoryIfNotExists writes one story as an item file in dir and
+// returns its basename. Existing items are left untouched but still
+// returned so the order rebuild can place them.
+func (imp *PivotalImporter) createStoryIfNotExists(dir string, story pivotalStory, userList *UserList, refs *ItemIndex) (string, error) {
+	itemFile := imp.storyFile(story)
+	itemPath := filepath.Join(dir, itemFile)
+	if _, err := os.Stat(filepath.Join(dir, archiveDirectoryName, itemFile)); err == nil {
+		fmt.Printf("The item '%s' already exists. Skipping.\n", strings.TrimSuffix(itemFile, ".md"))
+		return itemFile, nil
+	}
+	_, err := os.Stat(itemPath)
+	if err == nil {
+		fmt.Printf("The item '%s' already exists. Skipping.\n", strings.TrimSuffix(itemFile, ".md"))
+		return itemFile, nil
+	}
+	if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	item, err := LoadBacklogItem(itemPath)
+	if err != nil {
+		return "", err
+	}
+	if err := resolveImportUsers(story.Owners, userList); err != nil {
+		return "", err
+	}
+
+	tagSet := make(map[string]bool)
+	var tags []string
+	for _, label := range story.Labels {
+		label = spacesRe.ReplaceAllString(strings.TrimSpace(label), "-")
+		if label != "" && !tagSet[strings.ToLower(label)] {
+			tagSet[strings.ToLower(label)] = true
+			tags = append(tags, label)
+		}
+	}
+
+	status := imp.stateToStatus(story.State)
+	storyType := imp.storyType(story.Type)
+	created := parseImportDate(story.Created)
+	acceptedAt := parseImportDate(story.Accepted)
+	startedAt := parseImportDate(story.Started)
+	finishedAt := parseImportDate(story.Finished)
+	deliveredAt := parseImportDate(story.Delivered)
+
+	item.SetTitle(story.Title)
+	// Only am exports (the ones with a File column) carry item IDs; a
+	// Tracker story ID means nothing here.
+	if story.File != "" && story.ID > 0 && refs.Item(story.ID) == nil {
+		item.SetID(story.ID)
+	}
+	if created != "" {
+		item.SetCreated(created)
+		item.SetModified(created)
+	}
+	item.SetAuthor(story.RequestedBy)
+	item.SetType(storyType)
+	item.SetStatus(status)
+	item.SetAssignees(story.Owners)
+	if imp.estimable(storyType) {
+		item.SetEstimate(story.Estimate)
+	}
+	item.SetTags(tags)
+	item.SetEpic(story.Epic)
+	if startedAt != "" {
+		item.SetStarted(startedAt)
+	}
+	if finishedAt != "" {
+		item.SetFinished(finishedAt)
+	}
+	if deliveredAt != "" {
+		item.SetDelivered(deliveredAt)
+	}
+	if storyType == "release" {
+		if deadline := parseImportDate(story.Deadline); deadline != "" {
+			item.SetReleaseDate(deadline[:len(itemDateLayout)])
+		}
+	}
+	if acceptedAt != "" {
+		item.SetAccepted(acceptedAt)
+	} else if status == AcceptedStatus && created != "" {
+		item.SetAccepted(created)
+	}
+	var open []string
+	for _, b := range story.Blockers {
+		if !b.Resolved {
+			open = append(open, b.Text)
+		}
+	}
+	if len(open) > 0 {
+		item.SetBlocked(true, strings.Join(open, "; "))
+	}
+	item.SetDescription(pivotalBody(story, userList))
+	if story.Archived {
+		item.SetArchived(true)
+	}
+	if err := item.Save(); err != nil {
+		return "", err
+	}
+	if story.Archived {
+		return itemFile, item.MoveToBacklogArchiveDirectory()
+	}
+	return itemFile, nil
+}
+
+// storyFile is the item file name a story imports to: the File column when
+// it holds a plain item file name, otherwise one derived from the title.
+func (imp *PivotalImporter) storyFile(story pivotalStory) string {
+	name := story.File
+	if name != "" && name == filepath.Base(name) && strings.HasSuffix(name, ".md") &&
+		!strings.HasPrefix(name, "_") && !strings.HasPrefix(name, ".") {
+		return name
+	}
+	return fmt.Sprintf("%s.md", imp.getItemName(story.Title))
+}
+
+// estimable reports whether stories of storyType keep their points:
+// features always, bugs and chores only where the project points them.
+func (imp *PivotalImporter) estimable(storyType string) bool {
+	switch storyType {
+	case "bug":
+		return imp.cfg.StoryTypes.BugEstimable
+	case "chore":
+		return imp.cfg.StoryTypes.ChoreEstimable
+	}
+	return true
+}
+
+// pivotalBody lays out the description followed by the Tasks, Blockers and
+// Comments sections in the shapes ParseTasks and parseBodyComments read.
+func pivotalBody(story pivotalStory, userList *UserList) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(story.Description))
+	b.WriteString("\n")
+	if len(story.Tasks) > 0 {
+		b.WriteString("\n## Tasks\n\n")
+		for _, t := range story.Tasks {
+			mark := " "
+			if t.Done {
+				mark = "x"
+			}
+			fmt.Fprintf(&b, "- [%s] %s\n", mark, t.Text)
+		}
+	}
+	if len(story.Blockers) > 0 {
+		b.WriteString("\n## Blockers\n\n")
+		for _, bl := range story.Blockers {
+			mark := " "
+			if bl.Resolved {
+				mark = "x"
+			}
+			fmt.Fprintf(&b, "- [%s] %s\n", mark, bl.Text)
+		}
+	}
+	b.WriteString("\n## Possible solution\n\n## Comments\n")
+	for _, c := range story.Comments {
+		var authors []string
+		for _, author := range strings.Split(c.Author, ",") {
+			author = strings.TrimSpace(author)
+			if user := userList.User(author); user != nil {
+				author = user.Nickname()
+			} else {
+				author = strings.Replace(author, " ", ".", -1)
+			}
+			if author != "" {
+				authors = append(authors, "@"+author)
+			}
+		}
+		if len(authors) == 0 {
+			authors = append(authors, "@pivotal")
+		}
+		header := strings.Join(authors, " ")
+		if date := parseImportDate(c.Date); date != "" {
+			header += " " + date[:len(itemDateLayout)]
+		}
+		fmt.Fprintf(&b, "\n%s\n%s\n", header, strings.TrimSpace(c.Text))
+	}
+	b.WriteString("\n## Attachments\n")
+	return b.String()
+}
+
+// rebuildOrder rewrites dir's _priority.md and _icebox.md so imported
+// stories follow the export's order. Entries that were already there and
+// are not part of the export keep their relative order below the imported
+// ones.
+func rebuildOrder(dir string, priority, icebox []OrderEntry) error {
+	pri, err := LoadPriority(dir)
+	if err != nil {
+		return err
+	}
+	ice, err := LoadIcebox(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range append(append([]OrderEntry(nil), priority...), icebox...) {
+		pri.Remove(e.Path)
+		ice.Remove(e.Path)
+	}
+	for i, e := range priority {
+		pri.InsertAt(i, e)
+	}
+	for i, e := range icebox {
+		ice.InsertAt(i, e)
+	}
+	if err := pri.Save(); err != nil {
+		return err
+	}
+	return ice.Save()
+}
@@ -0,0 +1,179 @@
+package backlog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const pivotalCSV = `Id,Title,Labels,Iteration,Type,Estimate,Current State,Created at,Accepted at,Requested By,Description,Owned By,Owned By,Blocker,Blocker Status,Comment,Comment,Task,Task Status,Task,Task Status
+1,Login form,"auth, web ui",1,feature,3,accepted,"Mar 2, 2026","Mar 5, 2026",Ann Lee,Users sign in.,Bob Ray,Cy Doe,,,"Looks good (Ann Lee - Mar 5, 2026)",,Build form,completed,Wire API,completed
+2,Password reset,auth,2,feature,2,started,"Mar 3, 2026",,Ann Lee,,Bob Ray,,Waiting on mail vendor,unresolved,,,Send email,not completed,,
+3,Dark mode,,,feature,,unscheduled,"Mar 4, 2026",,Ann Lee,,,,,,,,,,,
+`
+
+func TestPivotalImportCSV(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "product")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	export := filepath.Join(root, "export.csv")
+	if err := os.WriteFile(export, []byte(pivotalCSV), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewPivotalImporter(export, dir).Import(); err != nil {
+		t.Fatal(err)
+	}
+
+	login, err := LoadBacklogItem(filepath.Join(dir, "login-form.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := login.Assignees(); len(got) != 2 || got[0] != "Bob Ray" || got[1] != "Cy Doe" {
+		t.Errorf("owners not imported: %v", got)
+	}
+	if got := login.Tags(); len(got) != 2 || got[1] != "web-ui" {
+		t.Errorf("labels not imported as tags: %v", got)
+	}
+	if login.Accepted().Format("2006-01-02") != "2026-03-05" {
+		t.Errorf("accepted_at not imported: %v", login.Accepted())
+	}
+	if tasks := ParseTasks(login.Body()); len(tasks) != 2 || !tasks[0].Done {
+		t.Errorf("tasks not imported: %+v", tasks)
+	}
+	comments := login.Comments()
+	if len(comments) != 1 || comments[0].Users[0] != "Ann.Lee" || !strings.Contains(strings.Join(comments[0].Text, " "), "Looks good") {
+		t.Errorf("comment not imported: %+v", comments)
+	}
+
+	reset, err := LoadBacklogItem(filepath.Join(dir, "password-reset.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reset.Blocked() || reset.BlockedReason() != "Waiting on mail vendor" {
+		t.Errorf("unresolved blocker not imported: blocked=%v reason=%q", reset.Blocked(), reset.BlockedReason())
+	}
+
+	pri, _ := LoadPriority(dir)
+	ice, _ := LoadIcebox(dir)
+	if e := pri.Entries(); len(e) != 2 || e[0].Path != "login-form.md" || e[1].Path != "password-reset.md" {
+		t.Errorf("priority not rebuilt from export order: %+v", e)
+	}
+	if e := ice.Entries(); len(e) != 1 || e[0].Path != "dark-mode.md" {
+		t.Errorf("icebox not rebuilt from unscheduled stories: %+v", e)
+	}
+}
+
+func TestPivotalImportJSON(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "product")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	export := filepath.Join(root, "export.json")
+	data := `{"stories": [{"name": "Search box", "story_type": "feature", "current_state": "accepted", "estimate": 5,
+		"created_at": "2026-03-01T10:00:00Z", "accepted_at": "2026-03-04T10:00:00Z",
+		"requested_by": {"name": "Ann Lee"}, "owners": [{"name": "Bob Ray"}], "labels": [{"name": "search"}],
+		"comments": [{"text": "Ship it", "person": {"name": "Ann Lee"}, "created_at": "2026-03-04T09:00:00Z"}],
+		"tasks": [{"description": "Index titles", "complete": true}]}]}`
+	if err := os.WriteFile(export, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewPivotalImporter(export, dir).Import(); err != nil {
+		t.Fatal(err)
+	}
+	it, err := LoadBacklogItem(filepath.Join(dir, "search-box.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it.Estimate() != "5" || it.Status() != AcceptedStatus.Name || it.Author() != "Ann Lee" {
+		t.Errorf("story fields not imported: estimate=%q status=%q author=%q", it.Estimate(), it.Status(), it.Author())
+	}
+	if tasks := ParseTasks(it.Body()); len(tasks) != 1 || tasks[0].Text != "Index titles" {
+		t.Errorf("tasks not imported: %+v", tasks)
+	}
+	if c := it.Comments(); len(c) != 1 {
+		t.Errorf("comments not imported: %+v", c)
+	}
+}
+
+func TestPivotalImportBugEstimates(t *testing.T) {
+	const data = `Id,Title,Type,Estimate,Current State
+1,Crash on save,bug,2,started
+2,Bump deps,chore,1,started
+`
+	for _, pointBugs := range []bool{false, true} {
+		root := t.TempDir()
+		dir := filepath.Join(root, "product")
+		if err := os.MkdirAll(filepath.Join(root, ".am"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if pointBugs {
+			cfg := "story_types:\n  bug_estimable: true\n"
+			if err := os.WriteFile(filepath.Join(root, ".am", "config.yaml"), []byte(cfg), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		export := filepath.Join(root, "export.csv")
+		if err := os.WriteFile(export, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := NewPivotalImporter(export, dir).Import(); err != nil {
+			t.Fatal(err)
+		}
+		bug, err := LoadBacklogItem(filepath.Join(dir, "crash-on-save.md"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		chore, err := LoadBacklogItem(filepath.Join(dir, "bump-deps.md"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := ""
+		if pointBugs {
+			want = "2"
+		}
+		if bug.Estimate() != want {
+			t.Errorf("bug_estimable=%v: bug estimate %q, want %q", pointBugs, bug.Estimate(), want)
+		}
+		if chore.Estimate() != "" {
+			t.Errorf("bug_estimable=%v: chore kept estimate %q", pointBugs, chore.Estimate())
+		}
+	}
+}
+
+func TestPivotalImportRankAndFile(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "product")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	export := filepath.Join(root, "export.csv")
+	data := `Id,Title,Type,Current State,Backlog,File,Rank
+4,Second,feature,unstarted,product,second.md,2
+9,First,feature,unstarted,product,Sign-in.md,1
+,Unranked,feature,unstarted,product,../escape.md,
+`
+	if err := os.WriteFile(export, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewPivotalImporter(export, dir).Import(); err != nil {
+		t.Fatal(err)
+	}
+	first, err := LoadBacklogItem(filepath.Join(dir, "Sign-in.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Title() != "First" || first.ID() != 9 {
+		t.Errorf("file or id not restored: title=%q id=%d", first.Title(), first.ID())
+	}
+	pri, _ := LoadPriority(dir)
+	if e := pri.Entries(); len(e) != 3 || e[0].Path != "Sign-in.md" || e[1].Path != "second.md" || e[2].Path != "unranked.md" {
+		t.Errorf("priority not rebuilt from ranks: %+v", e)
+	}
+}
@@ -48,14 +48,22 @@ func LoadOrderFile(path, header string) (*OrderFile, error) {
 		}
 		return nil, err
 	}
-	sc := bufio.NewScanner(strings.NewReader(string(data)))
+	f.entries = ParseOrderEntries(string(data))
+	return f, nil
+}
+
+// ParseOrderEntries reads the entries of an order file from its text, for
+// callers comparing versions that are not on disk (e.g. HEAD).
+func ParseOrderEntries(src string) []OrderEntry {
+	var entries []OrderEntry
+	sc := bufio.NewScanner(strings.NewReader(src))
 	for sc.Scan() {
 		m := orderLineRe.FindStringSubmatch(sc.Text())
 		if m != nil {
-			f.entries = append(f.entries, OrderEntry{Title: m[1], Path: strings.TrimSpace(m[2])})
+			entries = append(entries, OrderEntry{Title: m[1], Path: strings.TrimSpace(m[2])})
 		}
 	}
-	return f, nil
+	return entries
 }
 
 func (f *OrderFile) Path() string             { return f.path }
@@ -83,6 +91,20 @@ func (f *OrderFile) Remove(itemFile string) bool {
 	return true
 }
 
+// Rename points the entry for itemFile at newFile (and a new title, when
+// given) without changing its rank. Returns false if absent.
+func (f *OrderFile) Rename(itemFile, newFile, title string) bool {
+	i := f.IndexOf(itemFile)
+	if i < 0 {
+		return false
+	}
+	f.entries[i].Path = newFile
+	if title != "" {
+		f.entries[i].Title = title
+	}
+	return true
+}
+
 // InsertTop inserts e at index 0.
 func (f *OrderFile) InsertTop(e OrderEntry) { f.InsertAt(0, e) }
 
@@ -171,8 +193,8 @@ func (f *OrderFile) entryFor(p string) (OrderEntry, bool) {
 	return OrderEntry{}, false
 }
 
-// Save writes the order file back to disk with a fresh header.
-func (f *OrderFile) Save() error {
+// Bytes renders the order file with a fresh header, as Save writes it.
+func (f *OrderFile) Bytes() []byte {
 	var b strings.Builder
 	if f.header != "" {
 		fmt.Fprintf(&b, "# %s\n\nStack-rank order. Top is the highest priority. Edit by hand or via `am rank` / `am ice` / `am unice`. Regenerated by `am sync`.\n\n", f.header)
@@ -180,7 +202,12 @@ func (f *OrderFile) Save() error {
 	for _, e := range f.entries {
 		fmt.Fprintf(&b, "- [%s](%s)\n", e.Title, e.Path)
 	}
-	return os.WriteFile(f.path, []byte(b.String()), 0644)
+	return []byte(b.String())
+}
+
+// Save writes the order file back to disk with a fresh header.
+func (f *OrderFile) Save() error {
+	return os.WriteFile(f.path, f.Bytes(), 0644)
 }
 
 // titleFor is a fallback used when MoveAfter rebuilds an entry without a
@@ -0,0 +1,194 @@
+package backlog
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mreider/agilemarkdown/config"
+)
+
+// Release forecast verdicts.
+const (
+	ReleaseOnTrack = "on-track"
+	ReleaseAtRisk  = "at-risk"
+	ReleaseLate    = "late"
+	ReleaseDone    = "done"
+)
+
+// ReleaseForecast projects when the work ranked above a `type: release`
+// marker in _priority.md will be accepted. The expected date divides the
+// remaining points by rolling velocity; the confidence band re-runs the
+// same projection at velocity ± volatility. A release is late when the
+// expected date misses release_date, at risk when only the pessimistic
+// date does.
+type ReleaseForecast struct {
+	Backlog          string
+	Path             string
+	Title            string
+	ReleaseDate      time.Time
+	PointsAbove      float64
+	PointsRemaining  float64
+	Velocity         float64
+	Volatility       float64
+	Iterations       int
+	Iteration        int
+	ExpectedDate     time.Time
+	OptimisticDate   time.Time
+	PessimisticDate  time.Time
+	Status           string
+	UnestimatedAbove int
+}
+
+// ForecastReleases returns a forecast for every release marker in the
+// backlog's _priority.md, top first.
+func ForecastReleases(bck *Backlog, backlogDir string, cfg *config.Config, now time.Time) ([]ReleaseForecast, error) {
+	pri, err := LoadPriority(backlogDir)
+	if err != nil {
+		return nil, err
+	}
+	items := bck.ActiveItems()
+	byPath := make(map[string]*BacklogItem, len(items))
+	var accepted []*BacklogItem
+	for _, it := range items {
+		byPath[filepath.Base(it.Path())] = it
+		if CountsForVelocity(it, cfg) {
+			accepted = append(accepted, it)
+		}
+	}
+	overrides, _ := LoadIterationOverrides(filepath.Dir(backlogDir))
+	velocity, _, _ := ComputeVelocity(now, accepted, cfg, overrides)
+	volatility := VolatilityPercent(now, accepted, cfg, overrides)
+
+	var out []ReleaseForecast
+	entries := pri.Entries()
+	for i, e := range entries {
+		item := byPath[e.Path]
+		if item == nil || item.Type() != "release" {
+			continue
+		}
+		out = append(out, forecastRelease(filepath.Base(backlogDir), entries[:i], byPath, item, velocity, volatility, cfg, now))
+	}
+	return out, nil
+}
+
+// FindReleaseForecasts forecasts release markers across the project. An
+// empty backlogName covers every backlog; a non-empty slug (marker file
+// name, with or without .md) keeps only that marker.
+func FindReleaseForecasts(root *BacklogsStructure, cfg *config.Config, now time.Time, backlogName, slug string) ([]ReleaseForecast, error) {
+	dirs, err := root.BacklogDirs()
+	if err != nil {
+		return nil, err
+	}
+	want := ""
+	if slug != "" {
+		want = strings.TrimSuffix(filepath.Base(slug), ".md")
+	}
+	var out []ReleaseForecast
+	for _, dir := range dirs {
+		if backlogName != "" && filepath.Base(dir) != backlogName {
+			continue
+		}
+		bck, err := LoadBacklog(dir)
+		if err != nil {
+			return nil, err
+		}
+		forecasts, err := ForecastReleases(bck, dir, cfg, now)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range forecasts {
+			if want == "" || strings.TrimSuffix(filepath.Base(f.Path), ".md") == want {
+				out = append(out, f)
+			}
+		}
+	}
+	if want != "" && len(out) == 0 {
+		return nil, fmt.Errorf("release %q not found in any _priority.md", slug)
+	}
+	return out, nil
+}
+
+func forecastRelease(backlogName string, above []OrderEntry, byPath map[string]*BacklogItem, marker *BacklogItem, velocity, volatility float64, cfg *config.Config, now time.Time) ReleaseForecast {
+	f := ReleaseForecast{
+		Backlog:     backlogName,
+		Path:        filepath.Join(backlogName, filepath.Base(marker.Path())),
+		Title:       marker.Title(),
+		ReleaseDate: releaseDateFor(marker),
+		Velocity:    velocity,
+		Volatility:  volatility,
+	}
+	for _, e := range above {
+		it := byPath[e.Path]
+		if it == nil || it.Type() == "release" {
+			continue
+		}
+		pts := it.estimateAsFloat()
+		f.PointsAbove += pts
+		if strings.EqualFold(it.Status(), AcceptedStatus.Name) {
+			continue
+		}
+		if pts == 0 && (it.Type() == "" || it.Type() == "feature") && strings.TrimSpace(it.Estimate()) == "" {
+			f.UnestimatedAbove++
+		}
+		f.PointsRemaining += pts
+	}
+
+	iterStart := IterationStartFor(now, cfg)
+	weeks := cfg.Iteration.LengthWeeks
+	if weeks < 1 {
+		weeks = 1
+	}
+	finish := func(v float64) (int, time.Time) {
+		n := 1
+		if f.PointsRemaining > 0 {
+			if v <= 0 {
+				v = 1
+			}
+			n = int(math.Ceil(f.PointsRemaining / v))
+		}
+		// last day of the n-th iteration, counting the current one
+		return n, iterStart.AddDate(0, 0, 7*weeks*n-1)
+	}
+	f.Iterations, f.ExpectedDate = finish(velocity)
+	f.Iteration = iterationNumberFor(iterStart, cfg) + f.Iterations - 1
+	spread := velocity * volatility / 100
+	_, f.OptimisticDate = finish(velocity + spread)
+	_, f.PessimisticDate = finish(math.Max(velocity-spread, velocity*0.1))
+
+	switch {
+	case f.PointsRemaining == 0 && f.PointsAbove > 0:
+		f.Status = ReleaseDone
+	case f.ReleaseDate.IsZero():
+		f.Status = ReleaseOnTrack
+	case f.ExpectedDate.After(f.ReleaseDate):
+		f.Status = ReleaseLate
+	case f.PessimisticDate.After(f.ReleaseDate):
+		f.Status = ReleaseAtRisk
+	default:
+		f.Status = ReleaseOnTrack
+	}
+	return f
+}
+
+// ReleaseForecastASCII renders one forecast as a short report.
+func ReleaseForecastASCII(f ReleaseForecast) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Release %s  (%s)\n\n", f.Title, f.Path)
+	if !f.ReleaseDate.IsZero() {
+		fmt.Fprintf(&b, "  target:       %s\n", f.ReleaseDate.Format("2006-01-02"))
+	} else {
+		b.WriteString("  target:       (no release_date)\n")
+	}
+	fmt.Fprintf(&b, "  above marker: %.0f pts, %.0f remaining\n", f.PointsAbove, f.PointsRemaining)
+	if f.UnestimatedAbove > 0 {
+		fmt.Fprintf(&b, "                %d unestimated feature(s) not counted\n", f.UnestimatedAbove)
+	}
+	fmt.Fprintf(&b, "  velocity:     %.0f / iteration, volatility %.0f%%\n", f.Velocity, f.Volatility)
+	fmt.Fprintf(&b, "  projected:    iteration %d, done by %s\n", f.Iteration, f.ExpectedDate.Format("2006-01-02"))
+	fmt.Fprintf(&b, "  range:        %s .. %s\n", f.OptimisticDate.Format("2006-01-02"), f.PessimisticDate.Format("2006-01-02"))
+	fmt.Fprintf(&b, "  verdict:      %s\n", strings.ToUpper(f.Status))
+	return b.String()
+}
@@ -0,0 +1,49 @@
+package backlog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mreider/agilemarkdown/config"
+)
+
+func TestForecastReleaseVerdicts(t *testing.T) {
+	c := config.Defaults() // monday, 1 week, UTC
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	story := func(status, estimate string) *BacklogItem {
+		return NewBacklogItem("s", "---\ntitle: S\nstatus: "+status+"\ntype: feature\nestimate: \""+estimate+"\"\n---\n")
+	}
+	byPath := map[string]*BacklogItem{
+		"a.md": story("accepted", "3"),
+		"b.md": story("started", "5"),
+		"c.md": story("unstarted", "5"),
+		"d.md": story("unstarted", ""),
+	}
+	above := []OrderEntry{{Path: "a.md"}, {Path: "b.md"}, {Path: "c.md"}, {Path: "d.md"}}
+
+	// 10 points remain; at velocity 5 that is two iterations, ending
+	// Sun Mar 15. Volatility 40% gives 7..3 pts, i.e. Mar 15..Mar 29.
+	for _, tc := range []struct {
+		date string
+		want string
+	}{
+		{"2026-03-10", ReleaseLate},
+		{"2026-03-20", ReleaseAtRisk},
+		{"2026-04-01", ReleaseOnTrack},
+	} {
+		marker := NewBacklogItem("v1", "---\ntitle: v1\ntype: release\nrelease_date: "+tc.date+"\n---\n")
+		f := forecastRelease("product", above, byPath, marker, 5, 40, c, now)
+		if f.Status != tc.want {
+			t.Errorf("release %s: status %q, want %q", tc.date, f.Status, tc.want)
+		}
+		if f.PointsAbove != 13 || f.PointsRemaining != 10 || f.UnestimatedAbove != 1 {
+			t.Errorf("points above %.0f remaining %.0f unestimated %d, want 13/10/1", f.PointsAbove, f.PointsRemaining, f.UnestimatedAbove)
+		}
+		if got := f.ExpectedDate.Format("2006-01-02"); got != "2026-03-15" {
+			t.Errorf("expected date %s, want 2026-03-15", got)
+		}
+		if got := f.PessimisticDate.Format("2006-01-02"); got != "2026-03-29" {
+			t.Errorf("pessimistic date %s, want 2026-03-29", got)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package backlog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/mreider/agilemarkdown/utils"
+)
+
+var mdFileLinkRe = regexp.MustCompile(`\]\(([^)\s]+\.md)\)`)
+
+// RenameItem retitles item and moves its file to match the new title,
+// the same name sync would pick, so the next sync leaves it alone.
+// Returns the new path.
+func RenameItem(root *BacklogsStructure, item *BacklogItem, title string) (string, error) {
+	title = strings.TrimSpace(title)
+	name := utils.GetValidFileName(title)
+	if name == "" {
+		return "", fmt.Errorf("title %q leaves no usable file name", title)
+	}
+	newPath := filepath.Join(filepath.Dir(item.Path()), name+".md")
+	if newPath != item.Path() {
+		// A case-only change names the same file on a case-insensitive
+		// filesystem, but a different one on Linux.
+		if existing, err := os.Stat(newPath); err == nil {
+			if current, err := os.Stat(item.Path()); err != nil || !os.SameFile(existing, current) {
+				return "", fmt.Errorf("%s already exists", filepath.Base(newPath))
+			}
+		}
+	}
+	// Move first, so a failed move leaves the item as it was.
+	item.SetTitle(title)
+	item.SetModified(utils.GetCurrentTimestamp())
+	if err := MoveItemFile(root, item, newPath); err != nil {
+		return "", err
+	}
+	if err := item.Save(); err != nil {
+		return "", err
+	}
+	return newPath, nil
+}
+
+// MoveItemFile renames item's file within its directory and rewrites
+// everything that points at it: the _priority.md or _icebox.md entry
+// (rank kept), the backlog overview, blocked_by/blocks entries, and
+// markdown links in other items and epic pages.
+func MoveItemFile(root *BacklogsStructure, item *BacklogItem, newPath string) error {
+	oldPath := item.Path()
+	if oldPath == newPath {
+		return nil
+	}
+	g, err := LoadDependencyGraph(root)
+	if err != nil {
+		return err
+	}
+	oldKey := g.Key(item)
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return err
+	}
+	item.file.SetPath(newPath)
+	item.name = strings.TrimSuffix(filepath.Base(newPath), filepath.Ext(newPath))
+	newKey := g.Key(item)
+
+	backlogDir := filepath.Join(root.Root(), strings.SplitN(oldKey, "/", 2)[0])
+	oldBase, newBase := filepath.Base(oldPath), filepath.Base(newPath)
+	for _, load := range []func(string) (*OrderFile, error){LoadPriority, LoadIcebox} {
+		f, err := load(backlogDir)
+		if err != nil {
+			return err
+		}
+		if f.Rename(oldBase, newBase, item.Title()) {
+			if err := f.Save(); err != nil {
+				return err
+			}
+		}
+	}
+	if overviewPath, ok := FindOverviewFileInRootDirectory(backlogDir); ok {
+		overview, err := LoadBacklogOverview(overviewPath)
+		if err != nil {
+			return err
+		}
+		if err := overview.UpdateItemLinkInOverviewFile(oldPath, newPath); err != nil {
+			return err
+		}
+	}
+
+	for key, other := range g.items {
+		if key == oldKey {
+			continue
+		}
+		if hasDependencyRef(key, other.BlockedBy(), oldKey) {
+			other.SetBlockedBy(append(withoutDependencyRef(key, other.BlockedBy(), oldKey), newKey))
+		}
+		if hasDependencyRef(key, other.Blocks(), oldKey) {
+			other.SetBlocks(append(withoutDependencyRef(key, other.Blocks(), oldKey), newKey))
+		}
+		body := other.Body()
+		if linked := relinkFile(body, filepath.Dir(other.Path()), oldPath, newPath); linked != body {
+			other.SetBody(linked)
+		}
+		if other.file.Dirty() {
+			if err := other.Save(); err != nil {
+				return err
+			}
+		}
+	}
+	epics, err := LoadEpics(root)
+	if err != nil {
+		return err
+	}
+	for _, e := range epics {
+		desc := e.Description()
+		if linked := relinkFile(desc, root.EpicsDirectory(), oldPath, newPath); linked != desc {
+			e.SetDescription(linked)
+			if err := e.Save(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// relinkFile rewrites markdown links in text (written in dir) that point
+// at oldPath so they point at newPath.
+func relinkFile(text, dir, oldPath, newPath string) string {
+	return mdFileLinkRe.ReplaceAllStringFunc(text, func(m string) string {
+		target := mdFileLinkRe.FindStringSubmatch(m)[1]
+		if filepath.IsAbs(target) || strings.Contains(target, "://") {
+			return m
+		}
+		if filepath.Clean(filepath.Join(dir, filepath.FromSlash(target))) != filepath.Clean(oldPath) {
+			return m
+		}
+		rel, err := filepath.Rel(dir, newPath)
+		if err != nil {
+			return m
+		}
+		return "](" + filepath.ToSlash(rel) + ")"
+	})
+}
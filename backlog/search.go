@@ -0,0 +1,793 @@
+package backlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Full-text search. The index lives in .am/cache/search.json: one entry
+// per item file (archives included) with its filterable fields and
+// stemmed term counts, plus the term -> documents postings. Updating it
+// re-reads only files whose size or mtime changed, so sync and every
+// search keep it current for the cost of a stat per file.
+//
+// Queries mix free text, "quoted phrases" and field filters:
+//
+//	status:started tag:auth owner:alice estimate:>3 "login flow"
+//
+// Commas give alternatives (status:finished,delivered), "none" matches a
+// missing owner, epic or estimate, and "me" in owner: or author: is
+// resolved by the caller (ResolveMe). A leading "-" negates a term or
+// filter. Results are ranked with BM25 over title (weighted 3), tags (2)
+// and body (1). Query words that are not in the index fall back to
+// indexed words one or two edits away.
+
+const (
+	searchIndexFileName = "search.json"
+	searchIndexVersion  = 2
+
+	searchTitleWeight = 3
+	searchTagWeight   = 2
+	searchBodyWeight  = 1
+
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	// searchTypoWeight scales the score of a word matched by edit distance
+	// instead of exactly, so exact hits still rank first.
+	searchTypoWeight = 0.5
+)
+
+// SearchFields lists the field names a query can filter on.
+var SearchFields = []string{"status", "type", "tag", "owner", "author", "epic", "backlog", "estimate", "id", "archived"}
+
+var searchStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"for": true, "from": true, "in": true, "is": true, "it": true, "of": true, "on": true, "or": true,
+	"that": true, "the": true, "this": true, "to": true, "with": true,
+}
+
+type searchDoc struct {
+	Path     string         `json:"path"`
+	ModTime  int64          `json:"mtime"`
+	Size     int64          `json:"size"`
+	ID       int            `json:"id,omitempty"`
+	Title    string         `json:"title"`
+	Status   string         `json:"status,omitempty"`
+	Type     string         `json:"type,omitempty"`
+	Owners   []string       `json:"owners,omitempty"`
+	Author   string         `json:"author,omitempty"`
+	Tags     []string       `json:"tags,omitempty"`
+	Epic     string         `json:"epic,omitempty"`
+	Estimate string         `json:"estimate,omitempty"`
+	Archived bool           `json:"archived,omitempty"`
+	Body     string         `json:"body,omitempty"`
+	Terms    map[string]int `json:"terms"`
+	Length   int            `json:"length"`
+}
+
+// SearchIndex is the persisted inverted index over every item.
+type SearchIndex struct {
+	Version  int              `json:"version"`
+	Docs     []*searchDoc     `json:"docs"`
+	Postings map[string][]int `json:"postings"`
+	avgLen   float64
+}
+
+func (s *BacklogsStructure) searchIndexFile() string {
+	return filepath.Join(s.CacheDirectory(), searchIndexFileName)
+}
+
+// UpdateSearchIndex brings the on-disk index up to date with the item
+// files and returns it. Only new or changed files are parsed; the index
+// is written back only when something changed.
+func UpdateSearchIndex(root *BacklogsStructure) (*SearchIndex, error) {
+	old := loadSearchIndex(root)
+	known := make(map[string]*searchDoc, len(old.Docs))
+	for _, d := range old.Docs {
+		known[d.Path] = d
+	}
+	files, err := searchItemFiles(root)
+	if err != nil {
+		return nil, err
+	}
+	idx := &SearchIndex{Version: searchIndexVersion}
+	changed := len(files) != len(old.Docs)
+	for _, path := range files {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		rel, _ := filepath.Rel(root.Root(), path)
+		rel = filepath.ToSlash(rel)
+		if d := known[rel]; d != nil && d.ModTime == info.ModTime().UnixNano() && d.Size == info.Size() {
+			idx.Docs = append(idx.Docs, d)
+			continue
+		}
+		item, err := LoadBacklogItem(path)
+		if err != nil {
+			return nil, err
+		}
+		idx.Docs = append(idx.Docs, newSearchDoc(rel, info, item))
+		changed = true
+	}
+	if !changed && old.Postings != nil {
+		idx.Postings = old.Postings
+	} else {
+		idx.buildPostings()
+		if err := idx.save(root); err != nil {
+			return nil, err
+		}
+	}
+	total := 0
+	for _, d := range idx.Docs {
+		total += d.Length
+	}
+	if len(idx.Docs) > 0 {
+		idx.avgLen = float64(total) / float64(len(idx.Docs))
+	}
+	return idx, nil
+}
+
+// searchItemFiles lists item files the way LoadBacklog finds them, without
+// parsing them.
+func searchItemFiles(root *BacklogsStructure) ([]string, error) {
+	dirs, err := root.BacklogDirs()
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, d := range dirs {
+		for _, dir := range []string{d, filepath.Join(d, archiveDirectoryName)} {
+			infos, err := os.ReadDir(dir)
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return nil, err
+			}
+			for _, info := range infos {
+				baseName := strings.TrimSuffix(info.Name(), filepath.Ext(info.Name()))
+				if !info.IsDir() && strings.HasSuffix(info.Name(), ".md") && !IsForbiddenItemName(baseName) {
+					files = append(files, filepath.Join(dir, info.Name()))
+				}
+			}
+		}
+	}
+	return files, nil
+}
+
+func newSearchDoc(rel string, info os.FileInfo, item *BacklogItem) *searchDoc {
+	d := itemSearchDoc(rel, item)
+	d.ModTime = info.ModTime().UnixNano()
+	d.Size = info.Size()
+	return d
+}
+
+// itemSearchDoc indexes one item in memory; newSearchDoc adds the file
+// stamps.
+func itemSearchDoc(rel string, item *BacklogItem) *searchDoc {
+	d := &searchDoc{
+		Path:     rel,
+		ID:       item.ID(),
+		Title:    item.Title(),
+		Status:   strings.ToLower(item.Status()),
+		Type:     item.Type(),
+		Author:   strings.ToLower(strings.TrimSpace(item.Author())),
+		Epic:     strings.ToLower(item.Epic()),
+		Estimate: strings.TrimSpace(item.Estimate()),
+		Archived: item.Archived() || filepath.Base(filepath.Dir(item.Path())) == archiveDirectoryName,
+		Body:     item.Body(),
+		Terms:    map[string]int{},
+	}
+	for _, o := range item.Assignees() {
+		d.Owners = append(d.Owners, strings.ToLower(o))
+	}
+	for _, t := range item.Tags() {
+		d.Tags = append(d.Tags, strings.ToLower(t))
+	}
+	add := func(text string, weight int) {
+		for _, w := range searchTerms(text) {
+			d.Terms[w] += weight
+			d.Length += weight
+		}
+	}
+	add(d.Title, searchTitleWeight)
+	add(strings.Join(d.Tags, " "), searchTagWeight)
+	add(d.Body, searchBodyWeight)
+	return d
+}
+
+func (idx *SearchIndex) buildPostings() {
+	idx.Postings = map[string][]int{}
+	for i, d := range idx.Docs {
+		for t := range d.Terms {
+			idx.Postings[t] = append(idx.Postings[t], i)
+		}
+	}
+}
+
+// loadSearchIndex reads the cached index. A missing, unreadable or
+// outdated cache yields an empty index, which the caller rebuilds.
+func loadSearchIndex(root *BacklogsStructure) *SearchIndex {
+	idx := &SearchIndex{}
+	data, err := os.ReadFile(root.searchIndexFile())
+	if err != nil || json.Unmarshal(data, idx) != nil || idx.Version != searchIndexVersion {
+		return &SearchIndex{Version: searchIndexVersion}
+	}
+	return idx
+}
+
+func (idx *SearchIndex) save(root *BacklogsStructure) error {
+	dir := root.CacheDirectory()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	// The cache is derived data; keep it out of sync's commits without
+	// relying on the project's own .gitignore.
+	ignore := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(ignore); os.IsNotExist(err) {
+		if err := os.WriteFile(ignore, []byte("*\n"), 0644); err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	tmp := root.searchIndexFile() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, root.searchIndexFile())
+}
+
+// searchTerms splits text into lowercase stemmed words, dropping stop
+// words and single letters.
+func searchTerms(text string) []string {
+	var out []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(w) < 2 && !unicode.IsDigit(rune(w[0])) || searchStopWords[w] {
+			continue
+		}
+		out = append(out, stemWord(w))
+	}
+	return out
+}
+
+// stemWord strips common English suffixes so "logging", "logged" and
+// "logs" all index as "log". It is deliberately light: the same word
+// always stems the same way, which is all ranking needs.
+func stemWord(w string) string {
+	if len(w) <= 3 {
+		return w
+	}
+	for _, s := range []struct{ suffix, repl string }{
+		{"ational", "ate"}, {"ization", "ize"}, {"fulness", "ful"}, {"iveness", "ive"},
+		{"ations", "ate"}, {"ation", "ate"}, {"ments", ""}, {"ment", ""}, {"ness", ""},
+		{"ingly", ""}, {"edly", ""}, {"ings", ""}, {"ing", ""}, {"sses", "ss"},
+		{"ies", "y"}, {"ied", "y"}, {"ed", ""}, {"ly", ""}, {"ss", "ss"}, {"s", ""},
+	} {
+		if !strings.HasSuffix(w, s.suffix) {
+			continue
+		}
+		stem := w[:len(w)-len(s.suffix)] + s.repl
+		if len(stem) >= 3 && strings.ContainsAny(stem, "aeiouy") {
+			w = stem
+		}
+		break
+	}
+	if n := len(w); n > 3 && w[n-1] == w[n-2] && !strings.ContainsRune("lsz", rune(w[n-1])) && !strings.ContainsRune("aeiou", rune(w[n-1])) {
+		w = w[:n-1]
+	}
+	if n := len(w); n > 3 && w[n-1] == 'e' {
+		w = w[:n-1]
+	}
+	return w
+}
+
+// SearchQuery is a parsed query string.
+type SearchQuery struct {
+	Words    []string
+	Phrases  []string
+	Excluded []string
+	Filters  []SearchFilter
+}
+
+// SearchFilter is one field:value clause. Values are alternatives
+// (status:finished,delivered). Op is "=", ">", ">=", "<" or "<=" (the
+// last four for estimate and id only).
+type SearchFilter struct {
+	Field  string
+	Op     string
+	Values []string
+	Negate bool
+}
+
+// ParseSearchQuery splits q into words, quoted phrases and field filters.
+// Unknown fields are an error so a typo does not silently match nothing.
+func ParseSearchQuery(q string) (*SearchQuery, error) {
+	sq := &SearchQuery{}
+	for _, tok := range splitSearchQuery(q) {
+		negate := strings.HasPrefix(tok, "-") && len(tok) > 1
+		if negate {
+			tok = tok[1:]
+		}
+		if strings.HasPrefix(tok, `"`) {
+			phrase := strings.ToLower(strings.Join(strings.Fields(strings.Trim(tok, `"`)), " "))
+			if phrase == "" {
+				continue
+			}
+			if negate {
+				sq.Excluded = append(sq.Excluded, phrase)
+			} else {
+				sq.Phrases = append(sq.Phrases, phrase)
+			}
+			continue
+		}
+		if field, value, ok := strings.Cut(tok, ":"); ok && isSearchFieldName(field) {
+			f, err := parseSearchFilter(strings.ToLower(field), value)
+			if err != nil {
+				return nil, err
+			}
+			f.Negate = negate
+			sq.Filters = append(sq.Filters, f)
+			continue
+		}
+		if negate {
+			sq.Excluded = append(sq.Excluded, strings.ToLower(tok))
+		} else {
+			sq.Words = append(sq.Words, tok)
+		}
+	}
+	return sq, nil
+}
+
+func isSearchFieldName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < 'a' || r > 'z' {
+			if r < 'A' || r > 'Z' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// splitSearchQuery splits on spaces, keeping "quoted phrases" (and
+// field:"quoted values") together.
+func splitSearchQuery(q string) []string {
+	var out []string
+	var cur strings.Builder
+	quoted := false
+	for _, r := range q {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			cur.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if cur.Len() > 0 {
+				out = append(out, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if cur.Len() > 0 {
+		out = append(out, cur.String())
+	}
+	return out
+}
+
+func parseSearchFilter(field, value string) (SearchFilter, error) {
+	switch field {
+	case "assigned", "assignee":
+		field = "owner"
+	case "requester":
+		field = "author"
+	case "tags":
+		field = "tag"
+	}
+	known := false
+	for _, f := range SearchFields {
+		known = known || f == field
+	}
+	if !known {
+		return SearchFilter{}, fmt.Errorf("unknown search field %q (use %s)", field, strings.Join(SearchFields, ", "))
+	}
+	f := SearchFilter{Field: field, Op: "="}
+	value = strings.ToLower(strings.Trim(value, `"`))
+	if field == "estimate" || field == "id" {
+		for _, op := range []string{">=", "<=", ">", "<", "="} {
+			if strings.HasPrefix(value, op) {
+				f.Op, value = op, strings.TrimPrefix(value, op)
+				break
+			}
+		}
+	}
+	for _, v := range strings.Split(value, ",") {
+		v = strings.TrimSpace(v)
+		if field == "id" {
+			v = strings.TrimPrefix(v, "#")
+		}
+		if v == "" {
+			continue
+		}
+		if (field == "estimate" || field == "id") && !(field == "estimate" && v == "none" && f.Op == "=") {
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				return SearchFilter{}, fmt.Errorf("%s:%s: want a number", field, value)
+			}
+		}
+		f.Values = append(f.Values, v)
+	}
+	if len(f.Values) == 0 {
+		return SearchFilter{}, fmt.Errorf("%s: needs a value", field)
+	}
+	return f, nil
+}
+
+// match reports whether d passes the filter: any one of its values
+// matching is enough.
+func (f SearchFilter) match(d *searchDoc) bool {
+	ok := false
+	for _, v := range f.Values {
+		if ok = f.matchValue(d, v); ok {
+			break
+		}
+	}
+	return ok != f.Negate
+}
+
+func (f SearchFilter) matchValue(d *searchDoc, v string) bool {
+	switch f.Field {
+	case "status":
+		return d.Status == v
+	case "type":
+		return d.Type == v
+	case "tag":
+		return containsString(d.Tags, v)
+	case "owner":
+		return containsString(d.Owners, v) || v == "none" && len(d.Owners) == 0
+	case "author":
+		return d.Author == v
+	case "epic":
+		return d.Epic == v || v == "none" && d.Epic == ""
+	case "backlog":
+		return strings.ToLower(strings.SplitN(d.Path, "/", 2)[0]) == v
+	case "archived":
+		return d.Archived == (v == "true" || v == "yes")
+	case "estimate", "id":
+		if v == "none" {
+			return d.Estimate == ""
+		}
+		var have float64
+		if f.Field == "id" {
+			if d.ID == 0 {
+				return false
+			}
+			have = float64(d.ID)
+		} else {
+			var err error
+			if have, err = strconv.ParseFloat(d.Estimate, 64); err != nil {
+				return false
+			}
+		}
+		want, _ := strconv.ParseFloat(v, 64)
+		switch f.Op {
+		case ">":
+			return have > want
+		case ">=":
+			return have >= want
+		case "<":
+			return have < want
+		case "<=":
+			return have <= want
+		default:
+			return have == want
+		}
+	}
+	return false
+}
+
+// UsesMe reports whether an owner: or author: filter names "me".
+func (q *SearchQuery) UsesMe() bool {
+	for _, f := range q.Filters {
+		if (f.Field == "owner" || f.Field == "author") && containsString(f.Values, "me") {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveMe replaces "me" in owner: and author: filters with the given
+// handles (lowercase nickname, name, emails). With no handles "me"
+// matches nobody.
+func (q *SearchQuery) ResolveMe(handles []string) {
+	for i, f := range q.Filters {
+		if f.Field != "owner" && f.Field != "author" {
+			continue
+		}
+		var values []string
+		for _, v := range f.Values {
+			if v != "me" {
+				values = append(values, v)
+				continue
+			}
+			if len(handles) == 0 {
+				values = append(values, "\x00")
+			}
+			values = append(values, handles...)
+		}
+		q.Filters[i].Values = values
+	}
+}
+
+func containsString(xs []string, s string) bool {
+	for _, x := range xs {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchMatch is one ranked result.
+type SearchMatch struct {
+	Path     string
+	ID       int
+	Title    string
+	Status   string
+	Type     string
+	Estimate string
+	Archived bool
+	Snippet  string
+	Score    float64
+}
+
+// Search ranks the documents matching q. Archived items are skipped
+// unless includeArchived is set or the query filters on archived:.
+func (idx *SearchIndex) Search(q *SearchQuery, includeArchived bool) []SearchMatch {
+	for _, f := range q.Filters {
+		includeArchived = includeArchived || f.Field == "archived"
+	}
+	type weighted struct {
+		term   string
+		weight float64
+	}
+	var terms []weighted
+	seen := map[string]bool{}
+	addTerms := func(text string) {
+		for _, t := range searchTerms(text) {
+			if seen[t] {
+				continue
+			}
+			seen[t] = true
+			if len(idx.Postings[t]) > 0 {
+				terms = append(terms, weighted{t, 1})
+				continue
+			}
+			for _, near := range idx.nearTerms(t) {
+				if !seen[near] {
+					seen[near] = true
+					terms = append(terms, weighted{near, searchTypoWeight})
+				}
+			}
+		}
+	}
+	for _, w := range q.Words {
+		addTerms(w)
+	}
+	for _, p := range q.Phrases {
+		addTerms(p)
+	}
+	text := len(q.Words)+len(q.Phrases) > 0
+
+	n := float64(len(idx.Docs))
+	var out []SearchMatch
+	for _, d := range idx.Docs {
+		if d.Archived && !includeArchived {
+			continue
+		}
+		if !idx.matchesFilters(d, q) {
+			continue
+		}
+		score := 0.0
+		for _, t := range terms {
+			tf := float64(d.Terms[t.term])
+			if tf == 0 {
+				continue
+			}
+			df := float64(len(idx.Postings[t.term]))
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			norm := 1 - bm25B + bm25B*float64(d.Length)/idx.avgLen
+			score += t.weight * idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+		if text && score == 0 {
+			continue
+		}
+		out = append(out, SearchMatch{
+			Path:     d.Path,
+			ID:       d.ID,
+			Title:    d.Title,
+			Status:   d.Status,
+			Type:     d.Type,
+			Estimate: d.Estimate,
+			Archived: d.Archived,
+			Snippet:  d.snippet(q),
+			Score:    math.Round(score*100) / 100,
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		return out[i].Path < out[j].Path
+	})
+	return out
+}
+
+// matchesFilters applies field filters, quoted phrases and exclusions.
+func (idx *SearchIndex) matchesFilters(d *searchDoc, q *SearchQuery) bool {
+	for _, f := range q.Filters {
+		if !f.match(d) {
+			return false
+		}
+	}
+	if len(q.Phrases) == 0 && len(q.Excluded) == 0 {
+		return true
+	}
+	text := strings.ToLower(strings.Join(strings.Fields(d.Title+" "+strings.Join(d.Tags, " ")+" "+d.Body), " "))
+	for _, p := range q.Phrases {
+		if !strings.Contains(text, p) {
+			return false
+		}
+	}
+	for _, x := range q.Excluded {
+		if strings.Contains(x, " ") {
+			if strings.Contains(text, x) {
+				return false
+			}
+			continue
+		}
+		for _, t := range searchTerms(x) {
+			if d.Terms[t] > 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// MatchItem reports whether item, at rel (slash-separated, relative to
+// the project root), satisfies q on its own: filters, phrases and
+// exclusions as in Search, and every free word present. The coach uses
+// it for custom rules.
+func (q *SearchQuery) MatchItem(item *BacklogItem, rel string) bool {
+	d := itemSearchDoc(rel, item)
+	if !(&SearchIndex{}).matchesFilters(d, q) {
+		return false
+	}
+	for _, w := range q.Words {
+		for _, t := range searchTerms(w) {
+			if d.Terms[t] == 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// nearTerms returns indexed terms within typo distance of t: one edit for
+// words of four or more letters, two from eight.
+func (idx *SearchIndex) nearTerms(t string) []string {
+	limit := 0
+	switch {
+	case len(t) >= 8:
+		limit = 2
+	case len(t) >= 4:
+		limit = 1
+	}
+	if limit == 0 {
+		return nil
+	}
+	var out []string
+	for term := range idx.Postings {
+		if d := len(term) - len(t); d > limit || -d > limit {
+			continue
+		}
+		if editDistance(t, term, limit) <= limit {
+			out = append(out, term)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// editDistance is the Damerau-Levenshtein (optimal string alignment)
+// distance between a and b, giving up early once it exceeds limit.
+func editDistance(a, b string, limit int) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		best := cur[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+			best = min(best, cur[j])
+		}
+		if best > limit {
+			return best
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+// snippet returns a short excerpt of the body around the first phrase or
+// query word it contains.
+func (d *searchDoc) snippet(q *SearchQuery) string {
+	lower := strings.ToLower(d.Body)
+	at, size := -1, 0
+	for _, needle := range append(append([]string{}, q.Phrases...), q.Words...) {
+		needle = strings.ToLower(needle)
+		if i := strings.Index(lower, needle); i >= 0 && (at < 0 || i < at) {
+			at, size = i, len(needle)
+		}
+	}
+	if at < 0 {
+		// Fall back to the first word whose stem occurs, e.g. "logging"
+		// for a query of "logs".
+		for _, w := range q.Words {
+			stem := stemWord(strings.ToLower(w))
+			if i := strings.Index(lower, stem); i >= 0 && (at < 0 || i < at) {
+				at, size = i, len(stem)
+			}
+		}
+	}
+	if at < 0 {
+		return ""
+	}
+	const radius = 60
+	start := max(at-radius, 0)
+	end := min(at+size+radius, len(d.Body))
+	for start > 0 && !isRuneStart(d.Body[start]) {
+		start--
+	}
+	for end < len(d.Body) && !isRuneStart(d.Body[end]) {
+		end++
+	}
+	out := strings.ReplaceAll(strings.TrimSpace(d.Body[start:end]), "\n", " ")
+	if start > 0 {
+		out = "…" + out
+	}
+	if end < len(d.Body) {
+		out += "…"
+	}
+	return out
+}
+
+func isRuneStart(b byte) bool { return b&0xC0 != 0x80 }
@@ -0,0 +1,104 @@
+package backlog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSearchIndex(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "product", "archive"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(path, front, body string) {
+		text := "---\n" + front + "---\n\n" + body + "\n"
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("product/login.md", "title: Login flow\nid: 1\nstatus: started\nassigned: Alice\ntags: [auth]\nestimate: 5\n", "Users sign in with email.")
+	write("product/signup.md", "title: Signup\nid: 2\nstatus: unstarted\ntags: [auth]\nestimate: 2\n", "After signing up, show the login flow.")
+	write("product/billing.md", "title: Billing page\nid: 3\nstatus: started\nestimate: 8\n", "Invoices and payments.")
+	write("product/archive/old-login.md", "title: Old login\nid: 4\nstatus: accepted\narchive: true\n", "Legacy logins.")
+
+	root := NewBacklogsStructure(dir)
+	search := func(query string, archived bool) []string {
+		t.Helper()
+		q, err := ParseSearchQuery(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		idx, err := UpdateSearchIndex(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, m := range idx.Search(q, archived) {
+			got = append(got, strings.TrimSuffix(filepath.Base(m.Path), ".md"))
+		}
+		return got
+	}
+	for _, tc := range []struct {
+		query    string
+		archived bool
+		want     string
+	}{
+		// Title hits outrank body hits; stemming matches "logins"/"login".
+		{"login", false, "login signup"},
+		{"login", true, "old-login login signup"},
+		{`"login flow"`, false, "login signup"},
+		{"status:started tag:auth owner:alice estimate:>3 \"login flow\"", false, "login"},
+		{"estimate:<=5 -tag:auth", false, ""},
+		{"status:started -invoices", false, "login"},
+		{"archived:true", false, "old-login"},
+		{"id:>=2 backlog:product", false, "billing signup"},
+		// One transposition away from "invoices".
+		{"inovices", false, "billing"},
+		{"signing", false, "login signup"},
+		{"invoice", false, "billing"},
+	} {
+		if got := strings.Join(search(tc.query, tc.archived), " "); got != tc.want {
+			t.Errorf("search(%q, %v) = %q, want %q", tc.query, tc.archived, got, tc.want)
+		}
+	}
+	if _, err := ParseSearchQuery("colour:red"); err == nil {
+		t.Error("unknown field accepted")
+	}
+
+	// Edits are picked up on the next search without a full rebuild.
+	write("product/billing.md", "title: Billing page\nid: 3\nstatus: finished\nestimate: 8\n", "Invoices, payments and refunds.")
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(dir, "product/billing.md"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(search("refund status:finished", false), " "); got != "billing" {
+		t.Errorf("after edit got %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(root.CacheDirectory(), ".gitignore")); err != nil {
+		t.Errorf("cache directory not ignored: %v", err)
+	}
+}
+
+func TestSearchQueryMatchItem(t *testing.T) {
+	item := NewBacklogItem("login", "---\ntitle: Login flow\nstatus: started\ntype: feature\nestimate: 5\ntags: [auth]\n---\n\nUsers sign in with email.\n")
+	for query, want := range map[string]bool{
+		"type:feature estimate:>3":   true,
+		"status:started owner:none":  true,
+		"tag:auth -\"sign in\"":      false,
+		"backlog:product email":      true,
+		"backlog:design":             false,
+		"estimate:none":              false,
+		"type:bug,feature passwords": false,
+	} {
+		q, err := ParseSearchQuery(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := q.MatchItem(item, "product/login.md"); got != want {
+			t.Errorf("%s: got %v want %v", query, got, want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package backlog
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
@@ -9,6 +10,9 @@ import (
 
 const (
 	configFileName        = ".am/config.yaml"
+	lastIDFileName        = ".am/last-id"
+	cacheDirectoryName    = ".am/cache"
+	journalDirectoryName  = ".am/journal"
 	indexFileName         = "index.md"
 	velocityFileName      = "velocity.md"
 	velocityDirectoryName = "velocity"
@@ -19,11 +23,20 @@ const (
 	usersFileName         = "users.md"
 	timelineFileName      = "timeline.md"
 	timelineDirectoryName = "timeline"
+	epicsDirectoryName    = "epics"
+	filtersDirectoryName  = "filters"
+	filtersFileName       = ".am/filters.yaml"
+	coachFileName         = ".am/coach.yaml"
+	coachLogFileName      = ".am/coach-log.jsonl"
 )
 
 var (
-	ForbiddenBacklogNames = []string{velocityDirectoryName, archiveDirectoryName, TagsDirectoryName, usersDirectoryName, timelineDirectoryName}
+	ForbiddenBacklogNames = []string{velocityDirectoryName, archiveDirectoryName, TagsDirectoryName, usersDirectoryName, timelineDirectoryName, epicsDirectoryName, filtersDirectoryName}
 	ForbiddenItemNames    = []string{archiveDirectoryName, "_priority", "_icebox"}
+
+	// lateReservedNames joined ForbiddenBacklogNames after projects could
+	// already have backlogs by those names.
+	lateReservedNames = []string{epicsDirectoryName, filtersDirectoryName}
 )
 
 type BacklogsStructure struct {
@@ -42,6 +55,23 @@ func (s *BacklogsStructure) ConfigFile() string {
 	return filepath.Join(s.root, configFileName)
 }
 
+// LastIDFile holds the highest item ID ever handed out.
+func (s *BacklogsStructure) LastIDFile() string {
+	return filepath.Join(s.root, lastIDFileName)
+}
+
+// CacheDirectory holds derived data (the search index) that is rebuilt
+// on demand and never committed.
+func (s *BacklogsStructure) CacheDirectory() string {
+	return filepath.Join(s.root, cacheDirectoryName)
+}
+
+// JournalDirectory holds the undo journal: one entry per mutating
+// command, local to this checkout and never committed.
+func (s *BacklogsStructure) JournalDirectory() string {
+	return filepath.Join(s.root, journalDirectoryName)
+}
+
 func (s *BacklogsStructure) IndexFile() string {
 	return filepath.Join(s.root, indexFileName)
 }
@@ -74,6 +104,33 @@ func (s *BacklogsStructure) TimelineDirectory() string {
 	return filepath.Join(s.root, timelineDirectoryName)
 }
 
+func (s *BacklogsStructure) EpicsDirectory() string {
+	return filepath.Join(s.root, epicsDirectoryName)
+}
+
+// FiltersFile holds the project's saved searches.
+func (s *BacklogsStructure) FiltersFile() string {
+	return filepath.Join(s.root, filtersFileName)
+}
+
+// CoachFile holds the project's coach rule settings and custom rules.
+func (s *BacklogsStructure) CoachFile() string {
+	return filepath.Join(s.root, coachFileName)
+}
+
+// CoachLogFile is the append-only record of coach overrides.
+func (s *BacklogsStructure) CoachLogFile() string {
+	return filepath.Join(s.root, coachLogFileName)
+}
+
+// FiltersDirectory holds the pages sync renders for saved searches.
+func (s *BacklogsStructure) FiltersDirectory() string {
+	return filepath.Join(s.root, filtersDirectoryName)
+}
+
+// BacklogDirs lists the candidate backlog folders. It fails when a
+// backlog is named after a folder am has since reserved, rather than
+// leaving that backlog out of everything without a word.
 func (s *BacklogsStructure) BacklogDirs() ([]string, error) {
 	infos, err := os.ReadDir(s.root)
 	if err != nil {
@@ -81,7 +138,13 @@ func (s *BacklogsStructure) BacklogDirs() ([]string, error) {
 	}
 	result := make([]string, 0, len(infos))
 	for _, info := range infos {
-		if !info.IsDir() || strings.HasPrefix(info.Name(), ".") || IsForbiddenBacklogName(info.Name()) {
+		if !info.IsDir() || strings.HasPrefix(info.Name(), ".") {
+			continue
+		}
+		if IsForbiddenBacklogName(info.Name()) {
+			if err := s.checkLateReservedName(info.Name()); err != nil {
+				return nil, err
+			}
 			continue
 		}
 		result = append(result, filepath.Join(s.root, info.Name()))
@@ -89,3 +152,20 @@ func (s *BacklogsStructure) BacklogDirs() ([]string, error) {
 	sort.Strings(result)
 	return result, nil
 }
+
+// checkLateReservedName reports a backlog called name (a folder with a
+// `<name>.md` overview beside it) when am now writes generated pages to
+// that folder.
+func (s *BacklogsStructure) checkLateReservedName(name string) error {
+	reserved := false
+	for _, r := range lateReservedNames {
+		reserved = reserved || strings.EqualFold(r, name)
+	}
+	if !reserved {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(s.root, name+".md")); err != nil {
+		return nil
+	}
+	return fmt.Errorf("the backlog '%[1]s' has a name am now uses for its generated %[2]s pages; rename it, e.g. `git mv %[1]s %[1]s-backlog && git mv %[1]s.md %[1]s-backlog.md`, then run am sync", name, strings.ToLower(name))
+}
@@ -90,6 +90,16 @@ func (u *User) HasEmail(email string) bool {
 	return false
 }
 
+// Handles returns the lowercase names an item may use for this user in
+// `assigned:` or `author:`: nickname, name and emails.
+func (u *User) Handles() []string {
+	handles := []string{strings.ToLower(u.Nickname()), strings.ToLower(u.Name())}
+	for _, e := range u.Emails() {
+		handles = append(handles, strings.ToLower(e))
+	}
+	return handles
+}
+
 func (u *User) HasName(name string) bool {
 	return strings.EqualFold(u.Name(), utils.CollapseWhiteSpaces(name))
 }
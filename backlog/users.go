@@ -198,6 +198,26 @@ func (ul *UserList) fixObsoleteUserFiles() error {
 	return nil
 }
 
+// CurrentUserHandles returns the handles of the current git user, for
+// resolving "me" in search queries. A user missing from the list falls
+// back to the git name, email and email local part.
+func (ul *UserList) CurrentUserHandles() []string {
+	name, email, _ := git.CurrentUser()
+	if u := ul.User(email); u != nil {
+		return u.Handles()
+	}
+	if u := ul.User(name); u != nil {
+		return u.Handles()
+	}
+	var handles []string
+	for _, h := range []string{name, email, strings.SplitN(email, "@", 2)[0]} {
+		if h = strings.ToLower(utils.CollapseWhiteSpaces(h)); h != "" {
+			handles = append(handles, h)
+		}
+	}
+	return handles
+}
+
 func (ul *UserList) ResolveGitUsers(unknownUsers []string) (unresolvedUsers []string, err error) {
 	names, emails, _ := git.KnownUsers()
 	currentUserName, currentUserEmail, _ := git.CurrentUser()
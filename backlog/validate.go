@@ -2,6 +2,7 @@ package backlog
 
 import (
 	"fmt"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -49,6 +50,10 @@ func ValidateItem(item *BacklogItem) []ItemValidationError {
 		}
 	}
 
+	if raw := strings.TrimSpace(item.file.GetString(itemKeyID)); raw != "" && item.ID() <= 0 {
+		errs = append(errs, ItemValidationError{Path: item.Path(), Key: itemKeyID, Message: fmt.Sprintf("must be a positive integer, got %q", raw)})
+	}
+
 	if est := strings.TrimSpace(item.Estimate()); est != "" {
 		if _, err := strconv.ParseFloat(est, 64); err != nil {
 			errs = append(errs, ItemValidationError{Path: item.Path(), Key: "estimate", Message: fmt.Sprintf("must be numeric, got %q", est)})
@@ -64,6 +69,27 @@ func ValidateItem(item *BacklogItem) []ItemValidationError {
 		}
 	}
 
+	errs = append(errs, validateDependencyRefs(item, itemKeyBlockedBy, item.BlockedBy())...)
+	errs = append(errs, validateDependencyRefs(item, itemKeyBlocks, item.Blocks())...)
+
+	return errs
+}
+
+// validateDependencyRefs checks the shape of blocked_by/blocks entries.
+// Whether they name real stories, and whether they loop, needs the whole
+// project; see ValidateDependencies.
+func validateDependencyRefs(item *BacklogItem, key string, refs []string) []ItemValidationError {
+	var errs []ItemValidationError
+	self := filepath.Base(item.Path())
+	for _, ref := range refs {
+		ref = strings.TrimSpace(filepath.ToSlash(ref))
+		switch {
+		case !strings.HasSuffix(ref, ".md"):
+			errs = append(errs, ItemValidationError{Path: item.Path(), Key: key, Message: fmt.Sprintf("must list item paths ending in .md, got %q", ref)})
+		case ref == self || strings.HasSuffix("/"+ref, "/"+filepath.Base(filepath.Dir(item.Path()))+"/"+self):
+			errs = append(errs, ItemValidationError{Path: item.Path(), Key: key, Message: "an item cannot depend on itself"})
+		}
+	}
 	return errs
 }
 
@@ -44,6 +44,7 @@ func PriorityASCII(bck *Backlog, backlogDir string, cfg *config.Config, now time
 	if velocity <= 0 {
 		velocity = 1
 	}
+	volatility := VolatilityPercent(now, accepted, cfg, overrides)
 
 	iterStart := IterationStartFor(now, cfg)
 	weeks := cfg.Iteration.LengthWeeks
@@ -73,7 +74,8 @@ func PriorityASCII(bck *Backlog, backlogDir string, cfg *config.Config, now time
 		_ = bandHeaderIdx
 	}
 
-	for i, e := range pri.Entries() {
+	entries := pri.Entries()
+	for i, e := range entries {
 		item := byPath[e.Path]
 		if hideAccepted && item != nil && strings.EqualFold(item.Status(), AcceptedStatus.Name) {
 			continue
@@ -98,13 +100,18 @@ func PriorityASCII(bck *Backlog, backlogDir string, cfg *config.Config, now time
 		writeOrderRow(&b, e, item, i)
 		if item != nil && item.Type() == "release" {
 			if rd := releaseDateFor(item); !rd.IsZero() {
-				// Late if the iteration containing the marker starts on
-				// or after the release date.
-				if !bandStart.Before(rd) {
-					fmt.Fprintf(&b, "       LATE: target %s, this iteration starts %s\n",
-						rd.Format("2006-01-02"), bandStart.Format("2006-01-02"))
-				} else {
-					fmt.Fprintf(&b, "       on track: target %s\n", rd.Format("2006-01-02"))
+				f := forecastRelease(filepath.Base(backlogDir), entries[:i], byPath, item, velocity, volatility, cfg, now)
+				switch f.Status {
+				case ReleaseLate:
+					fmt.Fprintf(&b, "       LATE: target %s, projected %s (range %s .. %s)\n",
+						rd.Format("2006-01-02"), f.ExpectedDate.Format("2006-01-02"),
+						f.OptimisticDate.Format("2006-01-02"), f.PessimisticDate.Format("2006-01-02"))
+				case ReleaseAtRisk:
+					fmt.Fprintf(&b, "       at risk: target %s, projected %s, could slip to %s\n",
+						rd.Format("2006-01-02"), f.ExpectedDate.Format("2006-01-02"), f.PessimisticDate.Format("2006-01-02"))
+				default:
+					fmt.Fprintf(&b, "       on track: target %s, projected %s\n",
+						rd.Format("2006-01-02"), f.ExpectedDate.Format("2006-01-02"))
 				}
 			}
 		}
@@ -237,6 +244,11 @@ func EpicASCII(rootDir, slug string) (string, error) {
 	}
 
 	var b strings.Builder
+	if rs.HasEpics() {
+		if epic, err := FindEpic(rs, slug); err == nil {
+			writeEpicHeader(&b, epic)
+		}
+	}
 	if len(rows) == 0 {
 		fmt.Fprintf(&b, "Epic %q: no stories carry this epic slug.\n", slug)
 		return b.String(), nil
@@ -305,6 +317,28 @@ func EpicASCII(rootDir, slug string) (string, error) {
 	return b.String(), nil
 }
 
+// writeEpicHeader prints the epic file's metadata above the rollup.
+func writeEpicHeader(b *strings.Builder, epic *Epic) {
+	fmt.Fprintf(b, "%s  [%s]\n", epic.Title(), epic.Status())
+	var meta []string
+	if o := epic.Owner(); o != "" {
+		meta = append(meta, "owner "+o)
+	}
+	if r := epic.Release(); r != "" {
+		meta = append(meta, "release "+r)
+	}
+	if l := epic.Labels(); len(l) > 0 {
+		meta = append(meta, "labels "+strings.Join(l, ", "))
+	}
+	if len(meta) > 0 {
+		fmt.Fprintf(b, "%s\n", strings.Join(meta, "  ·  "))
+	}
+	if d := epic.Description(); d != "" {
+		fmt.Fprintf(b, "\n%s\n", d)
+	}
+	b.WriteString("\n")
+}
+
 func writeOrderRow(b *strings.Builder, e OrderEntry, item *BacklogItem, idx int) {
 	mark := "●"
 	status := "?"
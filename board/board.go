@@ -0,0 +1,133 @@
+// Package board serves the local web board behind `am serve`: one
+// backlog laid out as Current, Backlog, Icebox and Done columns, with
+// ranking, state changes and the acceptance ceremony going through the
+// same code paths as the CLI and MCP tools.
+package board
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/config"
+	"github.com/mreider/agilemarkdown/mcpserver"
+)
+
+// Board is one backlog's columns. Current is the top of _priority.md up
+// to rolling velocity, the same first band `am show priority` draws.
+// BelowLine, the Backlog column, is the rest of priority. Done holds
+// stories accepted before the current iteration began, newest first;
+// stories accepted during it stay in Current and count toward
+// AcceptedPoints.
+type Board struct {
+	Backlog        string               `json:"backlog"`
+	Backlogs       []string             `json:"backlogs"`
+	Iteration      int                  `json:"iteration"`
+	Start          string               `json:"start"`
+	End            string               `json:"end"`
+	Velocity       float64              `json:"velocity"`
+	CurrentPoints  float64              `json:"current_points"`
+	AcceptedPoints float64              `json:"accepted_points"`
+	Current        []mcpserver.OrderRow `json:"current"`
+	BelowLine      []mcpserver.OrderRow `json:"below_line"`
+	Icebox         []mcpserver.OrderRow `json:"icebox"`
+	Done           []mcpserver.OrderRow `json:"done"`
+}
+
+// Load lays out the named backlog as of now. An empty name picks the
+// first backlog.
+func Load(ctx context.Context, rootDir, name string, now time.Time) (*Board, error) {
+	list, err := mcpserver.ListBacklogs(ctx, rootDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Backlogs) == 0 {
+		return nil, fmt.Errorf("no backlogs in %s; create one with `am create-backlog`", rootDir)
+	}
+	if name == "" {
+		name = list.Backlogs[0]
+	}
+	if !hasBacklog(list.Backlogs, name) {
+		return nil, fmt.Errorf("unknown backlog %q", name)
+	}
+	cfg, err := config.LoadConfig(filepath.Join(rootDir, ".am", "config.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	bck, err := backlog.LoadBacklog(filepath.Join(rootDir, name))
+	if err != nil {
+		return nil, err
+	}
+	accepted := map[string]time.Time{}
+	for _, it := range bck.ActiveItems() {
+		accepted[filepath.Base(it.Path())] = it.Accepted()
+	}
+	pri, err := mcpserver.PriorityList(ctx, rootDir, mcpserver.PriorityListArgs{Backlog: name})
+	if err != nil {
+		return nil, err
+	}
+	ice, err := mcpserver.IceboxList(ctx, rootDir, mcpserver.IceboxListArgs{Backlog: name})
+	if err != nil {
+		return nil, err
+	}
+
+	start := backlog.IterationStartFor(now, cfg)
+	b := &Board{
+		Backlog:   name,
+		Backlogs:  list.Backlogs,
+		Iteration: backlog.IterationNumberFor(start, cfg),
+		Start:     start.Format("2006-01-02"),
+		End:       backlog.IterationEndFor(now, cfg).AddDate(0, 0, -1).Format("2006-01-02"),
+		Velocity:  pri.Velocity,
+		Current:   []mcpserver.OrderRow{},
+		BelowLine: []mcpserver.OrderRow{},
+		Icebox:    ice.Items,
+		Done:      []mcpserver.OrderRow{},
+	}
+	// With no velocity yet there is no line to draw; everything ranked
+	// is current.
+	full := false
+	for _, row := range pri.Items {
+		done := strings.EqualFold(row.Status, backlog.AcceptedStatus.Name)
+		if at := accepted[row.Path]; done && !at.IsZero() && at.Before(start) {
+			b.Done = append(b.Done, row)
+			continue
+		}
+		pts := points(row.Estimate)
+		if !full && b.Velocity > 0 && b.CurrentPoints > 0 && b.CurrentPoints+pts > b.Velocity {
+			full = true
+		}
+		if full {
+			b.BelowLine = append(b.BelowLine, row)
+			continue
+		}
+		b.Current = append(b.Current, row)
+		b.CurrentPoints += pts
+		if done {
+			b.AcceptedPoints += pts
+		}
+	}
+	sort.SliceStable(b.Done, func(i, j int) bool {
+		return accepted[b.Done[i].Path].After(accepted[b.Done[j].Path])
+	})
+	return b, nil
+}
+
+func hasBacklog(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func points(estimate string) float64 {
+	v, _ := strconv.ParseFloat(strings.TrimSpace(estimate), 64)
+	return v
+}
@@ -0,0 +1,243 @@
+package board
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/mcpserver"
+)
+
+func TestBoardColumnsAndWrites(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	story := func(name, fm, body string) {
+		write("product/"+name+".md", "---\ntitle: "+name+"\n"+fm+"---\n\n"+body)
+	}
+	write(".am/config.yaml", "iteration:\n  length_weeks: 1\nvelocity:\n  strategy: manual\n  initial_velocity: 3\n")
+	write("product.md", "# product\n")
+	lastMonth := time.Now().AddDate(0, -1, 0).Format("2006-01-02")
+	story("shipped", "type: chore\nstatus: accepted\naccepted: "+lastMonth+"\n", "old news\n")
+	story("login", "type: feature\nestimate: 2\nstatus: delivered\n", "## Acceptance\n\n- [ ] form posts\n- [ ] errors show\n")
+	story("signup", "type: feature\nestimate: 1\nstatus: unstarted\n", "no criteria\n")
+	story("reports", "type: feature\nestimate: 2\nstatus: unstarted\n", "later\n")
+	story("someday", "type: feature\nstatus: unstarted\n", "maybe\n")
+	write("product/_priority.md", "- [shipped](shipped.md)\n- [login](login.md)\n- [signup](signup.md)\n- [reports](reports.md)\n")
+	write("product/_icebox.md", "- [someday](someday.md)\n")
+
+	s, err := NewServer(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	b := getBoard(t, srv.URL)
+	if got := paths(b.Current); got != "login.md signup.md" {
+		t.Fatalf("current = %s", got)
+	}
+	if got := paths(b.BelowLine); got != "reports.md" {
+		t.Fatalf("backlog = %s", got)
+	}
+	if got := paths(b.Icebox); got != "someday.md" {
+		t.Fatalf("icebox = %s", got)
+	}
+	if got := paths(b.Done); got != "shipped.md" {
+		t.Fatalf("done = %s", got)
+	}
+
+	// The coach: accepting is refused outside the ceremony, and starting a
+	// feature without criteria is a nudge that needs confirming.
+	code, body := post(t, srv.URL+"/api/status", `{"backlog":"product","item":"login.md","status":"accepted"}`)
+	if code != http.StatusConflict || !strings.Contains(body, "coach-refuses-pm-accepts") {
+		t.Fatalf("accept via button: %d %s", code, body)
+	}
+	code, body = post(t, srv.URL+"/api/status", `{"backlog":"product","item":"signup.md","status":"started"}`)
+	if code != http.StatusConflict || !strings.Contains(body, `"nudge":true`) {
+		t.Fatalf("unconfirmed nudge: %d %s", code, body)
+	}
+	if code, body = post(t, srv.URL+"/api/status", `{"backlog":"product","item":"signup.md","status":"started","confirm":true}`); code != http.StatusOK {
+		t.Fatalf("confirmed start: %d %s", code, body)
+	}
+	if st := loadStatus(t, dir, "signup"); st != "started" {
+		t.Fatalf("signup status = %s", st)
+	}
+
+	if code, body = post(t, srv.URL+"/api/rank", `{"backlog":"product","item":"someday.md","before":"signup.md"}`); code != http.StatusOK {
+		t.Fatalf("rank from icebox: %d %s", code, body)
+	}
+	if got := paths(getBoard(t, srv.URL).Current); got != "login.md someday.md signup.md" {
+		t.Fatalf("current after rank = %s", got)
+	}
+	if code, body = post(t, srv.URL+"/api/icebox", `{"backlog":"product","item":"reports.md"}`); code != http.StatusOK {
+		t.Fatalf("ice: %d %s", code, body)
+	}
+
+	// The ceremony: every bullet has to be verified.
+	if code, body = post(t, srv.URL+"/api/accept", `{"backlog":"product","item":"login.md","verified":[1]}`); code != http.StatusConflict {
+		t.Fatalf("partial accept: %d %s", code, body)
+	}
+	if code, body = post(t, srv.URL+"/api/accept", `{"backlog":"product","item":"login.md","verified":[1,2]}`); code != http.StatusOK {
+		t.Fatalf("accept: %d %s", code, body)
+	}
+	item, err := backlog.LoadBacklogItem(filepath.Join(dir, "product", "login.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Status() != "accepted" || strings.Count(item.Body(), "[x]") != 2 {
+		t.Fatalf("accepted login: status %s body %q", item.Status(), item.Body())
+	}
+
+	if code, _ = post(t, srv.URL+"/api/status", `{"backlog":"../etc","item":"passwd.md","status":"started"}`); code != http.StatusBadRequest {
+		t.Fatalf("unknown backlog: %d", code)
+	}
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/api/status", strings.NewReader(`{"backlog":"product","item":"signup.md","status":"finished"}`))
+	req.Header.Set("Content-Type", "text/plain")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Fatalf("text/plain post: %d", resp.StatusCode)
+	}
+
+	entries, err := backlog.LoadJournal(backlog.NewBacklogsStructure(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 || entries[0].Source != "web" || entries[3].Command != "accept product/login.md" {
+		t.Fatalf("journal = %+v", entries)
+	}
+}
+
+func getBoard(t *testing.T, url string) *Board {
+	t.Helper()
+	resp, err := http.Get(url + "/api/board?backlog=product")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b := &Board{}
+	if err := json.NewDecoder(resp.Body).Decode(b); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func post(t *testing.T, url, body string) (int, string) {
+	t.Helper()
+	resp, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(data)
+}
+
+func paths(rows []mcpserver.OrderRow) string {
+	var out []string
+	for _, r := range rows {
+		out = append(out, r.Path)
+	}
+	return strings.Join(out, " ")
+}
+
+func loadStatus(t *testing.T, dir, name string) string {
+	t.Helper()
+	item, err := backlog.LoadBacklogItem(filepath.Join(dir, "product", name+".md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return item.Status()
+}
+
+func TestBoardAcceptRunsCoach(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	coach := func(level string) {
+		write(".am/coach.yaml", "custom:\n  - slug: qa-signs-off\n    actions: [set_status]\n    when: status:accepted tag:needs-qa\n    rule: QA signs off first\n    level: "+level+"\n")
+	}
+	write("product.md", "# product\n")
+	write("product/login.md", "---\ntitle: login\ntype: feature\nestimate: 2\nstatus: delivered\ntags: [needs-qa]\n---\n\n## Acceptance\n\n- [ ] form posts\n")
+	write("product/_priority.md", "- [login](login.md)\n")
+
+	s, err := NewServer(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	coach("refuse")
+	code, body := post(t, srv.URL+"/api/accept", `{"backlog":"product","item":"login.md","verified":[1]}`)
+	if code != http.StatusConflict || !strings.Contains(body, "qa-signs-off") {
+		t.Fatalf("refused accept: %d %s", code, body)
+	}
+	coach("nudge")
+	code, body = post(t, srv.URL+"/api/accept", `{"backlog":"product","item":"login.md","verified":[1]}`)
+	if code != http.StatusConflict || !strings.Contains(body, `"nudge":true`) {
+		t.Fatalf("unconfirmed nudge: %d %s", code, body)
+	}
+	if code, body = post(t, srv.URL+"/api/accept", `{"backlog":"product","item":"login.md","verified":[1],"confirm":true}`); code != http.StatusOK {
+		t.Fatalf("confirmed accept: %d %s", code, body)
+	}
+	if st := loadStatus(t, dir, "login"); st != "accepted" {
+		t.Fatalf("login status = %s", st)
+	}
+}
+
+func TestBoardChecksHost(t *testing.T) {
+	s, err := NewServer(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+	s.allowHosts(srv.Listener.Addr().String())
+
+	for host, want := range map[string]int{
+		srv.Listener.Addr().String(): http.StatusOK,
+		"evil.example:80":            http.StatusForbidden,
+	} {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"/", nil)
+		req.Host = host
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("Host %s: %d, want %d", host, resp.StatusCode, want)
+		}
+	}
+}
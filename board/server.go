@@ -0,0 +1,387 @@
+package board
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/mcpserver"
+)
+
+//go:embed static
+var static embed.FS
+
+// Server answers the board page and its JSON API for one project root.
+// Writes take mu and land in the undo journal with source "web", so
+// `am undo` rolls back a drag or a button press like any other change.
+type Server struct {
+	root  string
+	hosts map[string]bool // allowed Host headers; nil allows any
+	mu    sync.Mutex
+}
+
+// NewServer returns a board for the project at rootDir.
+func NewServer(rootDir string) (*Server, error) {
+	abs, err := filepath.Abs(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{root: abs}, nil
+}
+
+// Serve runs the board on addr until ctx is done.
+func Serve(ctx context.Context, rootDir, addr string) error {
+	s, err := NewServer(rootDir)
+	if err != nil {
+		return err
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s.allowHosts(ln.Addr().String())
+	backlog.EnableItemCache()
+	httpSrv := &http.Server{Handler: s.Handler()}
+	go func() {
+		<-ctx.Done()
+		shutdown, done := context.WithTimeout(context.Background(), 5*time.Second)
+		defer done()
+		_ = httpSrv.Shutdown(shutdown)
+	}()
+	fmt.Fprintf(os.Stderr, "am serve: board for %s on http://%s\n", s.root, ln.Addr())
+	if err := httpSrv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Handler routes the page, its assets and the API.
+func (s *Server) Handler() http.Handler {
+	assets, _ := fs.Sub(static, "static")
+	mux := http.NewServeMux()
+	mux.Handle("GET /", http.FileServer(http.FS(assets)))
+	mux.HandleFunc("GET /api/board", s.board)
+	mux.HandleFunc("GET /api/acceptance", s.acceptance)
+	mux.HandleFunc("POST /api/status", s.status)
+	mux.HandleFunc("POST /api/rank", s.rank)
+	mux.HandleFunc("POST /api/icebox", s.icebox)
+	mux.HandleFunc("POST /api/accept", s.accept)
+	mux.HandleFunc("POST /api/reject", s.reject)
+	return s.checkHost(mux)
+}
+
+// allowHosts pins the Host header to the loopback address the board
+// listens on. Another site can point its own name at 127.0.0.1 (DNS
+// rebinding) and so pass as same-origin, but its requests still name
+// that site in Host. A board bound to a non-loopback address is shared
+// on purpose and answers any Host.
+func (s *Server) allowHosts(addr string) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return
+	}
+	s.hosts = map[string]bool{
+		net.JoinHostPort(host, port):        true,
+		net.JoinHostPort("localhost", port): true,
+	}
+}
+
+func (s *Server) checkHost(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.hosts != nil && !s.hosts[strings.ToLower(r.Host)] {
+			writeError(w, http.StatusForbidden, fmt.Errorf("unexpected Host %q", r.Host))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// refusal is a coach verdict the board shows instead of writing. A nudge
+// can be overridden by resending with confirm set; a refusal cannot.
+type refusal struct {
+	Error string                 `json:"error"`
+	Coach mcpserver.CoachVerdict `json:"coach"`
+}
+
+type statusRequest struct {
+	Backlog string `json:"backlog"`
+	Item    string `json:"item"`
+	Status  string `json:"status"`
+	Confirm bool   `json:"confirm,omitempty"`
+}
+
+type rankRequest struct {
+	Backlog string `json:"backlog"`
+	Item    string `json:"item"`
+	Before  string `json:"before,omitempty"` // empty ranks it at the bottom of priority
+}
+
+type acceptRequest struct {
+	Backlog  string `json:"backlog"`
+	Item     string `json:"item"`
+	Verified []int  `json:"verified"`
+	Confirm  bool   `json:"confirm,omitempty"`
+}
+
+type rejectRequest struct {
+	Backlog       string `json:"backlog"`
+	Item          string `json:"item"`
+	Reason        string `json:"reason"`
+	FailingBullet int    `json:"failing_bullet,omitempty"`
+}
+
+func (s *Server) board(w http.ResponseWriter, r *http.Request) {
+	b, err := Load(r.Context(), s.root, r.URL.Query().Get("backlog"), time.Now())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, b)
+}
+
+func (s *Server) acceptance(w http.ResponseWriter, r *http.Request) {
+	ref, err := s.itemRef(r.Context(), r.URL.Query().Get("backlog"), r.URL.Query().Get("item"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	res, err := mcpserver.AcceptancePrompt(r.Context(), s.root, mcpserver.AcceptancePromptArgs{Path: ref})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// status is a state button. The coach gets the first word: accepting
+// always refuses here (the ceremony form is the way in), and a nudge
+// such as starting a feature without acceptance criteria needs confirm.
+func (s *Server) status(w http.ResponseWriter, r *http.Request) {
+	var req statusRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	ref, err := s.itemRef(r.Context(), req.Backlog, req.Item)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	verdict, err := mcpserver.CoachCheck(r.Context(), s.root, mcpserver.CoachCheckArgs{Action: "set_status", Path: ref, Status: req.Status})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if !verdict.Allowed || (verdict.Nudge && !req.Confirm) {
+		if verdict.Source == "coach-refuses-pm-accepts" {
+			verdict.Next = "open the story's acceptance ceremony on the board"
+		}
+		writeJSON(w, http.StatusConflict, refusal{Error: verdict.Rule, Coach: verdict})
+		return
+	}
+	s.write(w, fmt.Sprintf("%s %s", req.Status, ref), func() error {
+		_, err := mcpserver.SetStatus(r.Context(), s.root, mcpserver.SetStatusArgs{Path: ref, Status: req.Status})
+		return err
+	})
+}
+
+// rank places an item in _priority.md before another, pulling it out of
+// the icebox first when that is where it was dragged from.
+func (s *Server) rank(w http.ResponseWriter, r *http.Request) {
+	var req rankRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if _, err := s.itemRef(r.Context(), req.Backlog, req.Item); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	args := mcpserver.RankItemArgs{Backlog: req.Backlog, ItemPath: filepath.Base(req.Item), Position: "bottom"}
+	command := fmt.Sprintf("rank %s/%s", req.Backlog, args.ItemPath)
+	if req.Before != "" && filepath.Base(req.Before) != args.ItemPath {
+		args.Position, args.Before = "", filepath.Base(req.Before)
+		command += " before " + args.Before
+	}
+	s.write(w, command, func() error {
+		_, err := mcpserver.RankItem(r.Context(), s.root, args)
+		return err
+	})
+}
+
+func (s *Server) icebox(w http.ResponseWriter, r *http.Request) {
+	var req rankRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	ref, err := s.itemRef(r.Context(), req.Backlog, req.Item)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.write(w, "ice "+ref, func() error {
+		_, err := mcpserver.MoveToIcebox(r.Context(), s.root, mcpserver.MoveToIceboxArgs{Backlog: req.Backlog, ItemPath: filepath.Base(req.Item)})
+		return err
+	})
+}
+
+// accept is the PM side of the ceremony: the story must be delivered and
+// every acceptance bullet ticked as verified on the form. The coach runs
+// as for any status change, less the rule that keeps the dev pair from
+// accepting, since this is the PM. The bullets are marked verified and
+// the story accepted in one journal entry.
+func (s *Server) accept(w http.ResponseWriter, r *http.Request) {
+	var req acceptRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	ref, err := s.itemRef(r.Context(), req.Backlog, req.Item)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	verdict, err := mcpserver.CoachCheckPM(r.Context(), s.root, mcpserver.CoachCheckArgs{Action: "set_status", Path: ref, Status: backlog.AcceptedStatus.Name})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if !verdict.Allowed || (verdict.Nudge && !req.Confirm) {
+		writeJSON(w, http.StatusConflict, refusal{Error: verdict.Rule, Coach: verdict})
+		return
+	}
+	s.write(w, "accept "+ref, func() error {
+		prompt, err := mcpserver.AcceptancePrompt(r.Context(), s.root, mcpserver.AcceptancePromptArgs{Path: ref})
+		if err != nil {
+			return err
+		}
+		if prompt.Status != backlog.DeliveredStatus.Name {
+			return fmt.Errorf("%s is %s; only delivered stories go through acceptance", prompt.Title, prompt.Status)
+		}
+		verified := map[int]bool{}
+		for _, i := range req.Verified {
+			verified[i] = true
+		}
+		var missing []string
+		for _, b := range prompt.Bullets {
+			if !verified[b.Index] {
+				missing = append(missing, fmt.Sprintf("%d", b.Index))
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("verify every acceptance bullet before accepting (unchecked: %s), or reject with the one that failed", strings.Join(missing, ", "))
+		}
+		for _, b := range prompt.Bullets {
+			if b.State == "verified" {
+				continue
+			}
+			if _, err := mcpserver.SetAcceptanceState(r.Context(), s.root, mcpserver.SetAcceptanceStateArgs{Path: ref, Index: b.Index, State: "verified"}); err != nil {
+				return err
+			}
+		}
+		_, err = mcpserver.SetStatus(r.Context(), s.root, mcpserver.SetStatusArgs{Path: ref, Status: backlog.AcceptedStatus.Name})
+		return err
+	})
+}
+
+// reject records why the PM said no, so the pair has something to act
+// on; a bare rejection is refused.
+func (s *Server) reject(w http.ResponseWriter, r *http.Request) {
+	var req rejectRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	ref, err := s.itemRef(r.Context(), req.Backlog, req.Item)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("give a reason for the rejection"))
+		return
+	}
+	s.write(w, "reject "+ref, func() error {
+		prompt, err := mcpserver.AcceptancePrompt(r.Context(), s.root, mcpserver.AcceptancePromptArgs{Path: ref})
+		if err != nil {
+			return err
+		}
+		if prompt.Status != backlog.DeliveredStatus.Name {
+			return fmt.Errorf("%s is %s; only delivered stories can be rejected", prompt.Title, prompt.Status)
+		}
+		_, err = mcpserver.RejectItem(r.Context(), s.root, mcpserver.RejectItemArgs{Path: ref, Reason: req.Reason, FailingBullet: req.FailingBullet})
+		return err
+	})
+}
+
+// write runs fn under the lock and journals the files it changed.
+func (s *Server) write(w http.ResponseWriter, command string, fn func() error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	root := backlog.NewBacklogsStructure(s.root)
+	snap, snapErr := backlog.TakeSnapshot(root)
+	err := fn()
+	if snapErr == nil {
+		if _, jerr := backlog.RecordJournal(root, snap, backlog.JournalEntry{Source: "web", Command: command}); jerr != nil {
+			fmt.Fprintf(os.Stderr, "am serve: undo journal: %v\n", jerr)
+		}
+	}
+	if err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mcpserver.OkResult{OK: true})
+}
+
+// itemRef checks that backlog is one of the project's backlogs and
+// returns the item's path relative to the root. Only the item's file
+// name is used, so a request can't reach outside the backlog folder.
+func (s *Server) itemRef(ctx context.Context, name, item string) (string, error) {
+	list, err := mcpserver.ListBacklogs(ctx, s.root)
+	if err != nil {
+		return "", err
+	}
+	if !hasBacklog(list.Backlogs, name) {
+		return "", fmt.Errorf("unknown backlog %q", name)
+	}
+	base := filepath.Base(filepath.FromSlash(item))
+	if base == "." || base == ".." || !strings.HasSuffix(base, ".md") || strings.HasPrefix(base, "_") {
+		return "", fmt.Errorf("not a story: %q", item)
+	}
+	return name + "/" + base, nil
+}
+
+// readJSON insists on a JSON content type: a page on another site can't
+// send one without a CORS preflight, which the board never answers, so
+// it can't drive the board through the user's browser.
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		writeError(w, http.StatusUnsupportedMediaType, fmt.Errorf("send application/json"))
+		return false
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("bad request body: %w", err))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,289 @@
+// The board page. Everything it shows comes from /api/board, and every
+// change goes back through the API, so the files on disk stay the only
+// state: the page re-reads them after each change and every few seconds.
+'use strict';
+
+const $ = sel => document.querySelector(sel);
+let board = null;
+let dragged = null;
+
+// The next state for each status, as Pivotal's buttons offer it.
+const buttons = {
+  unstarted: [['Start', 'started']],
+  started: [['Finish', 'finished']],
+  finished: [['Deliver', 'delivered']],
+  rejected: [['Restart', 'started']],
+};
+
+async function api(method, path, body) {
+  const opts = { method, headers: {} };
+  if (body) {
+    opts.headers['Content-Type'] = 'application/json';
+    opts.body = JSON.stringify(body);
+  }
+  const res = await fetch(path, opts);
+  const data = await res.json();
+  if (!res.ok) {
+    const err = new Error(data.error || res.statusText);
+    err.coach = data.coach;
+    throw err;
+  }
+  return data;
+}
+
+function notice(text) {
+  $('#notice').textContent = text || '';
+}
+
+async function load() {
+  const name = $('#backlog').value || new URLSearchParams(location.search).get('backlog') || '';
+  try {
+    board = await api('GET', '/api/board?backlog=' + encodeURIComponent(name));
+  } catch (err) {
+    notice(err.message);
+    return;
+  }
+  render();
+}
+
+function render() {
+  const select = $('#backlog');
+  if (select.options.length !== board.backlogs.length) {
+    select.replaceChildren(...board.backlogs.map(b => new Option(b, b)));
+  }
+  select.value = board.backlog;
+  $('#iteration').textContent =
+    `Iteration ${board.iteration} · ${board.start} – ${board.end} · velocity ${board.velocity}`;
+  $('#current-meta').textContent = `${board.accepted_points}/${board.current_points} pts`;
+  $('#backlog-meta').textContent = count(board.below_line);
+  $('#icebox-meta').textContent = count(board.icebox);
+  $('#done-meta').textContent = count(board.done);
+  fill('current', board.current, true);
+  fill('backlog', board.below_line, true);
+  fill('icebox', board.icebox, true);
+  fill('done', board.done, false);
+}
+
+function count(rows) {
+  return rows.length === 1 ? '1 story' : `${rows.length} stories`;
+}
+
+function fill(column, rows, draggable) {
+  const cards = document.querySelector(`[data-column="${column}"] .cards`);
+  cards.replaceChildren(...rows.map(row => card(row, column, draggable)));
+}
+
+function card(row, column, draggable) {
+  const el = document.createElement('div');
+  el.className = 'card ' + (row.type || 'feature');
+  el.dataset.path = row.path;
+  el.draggable = draggable;
+
+  const title = document.createElement('div');
+  title.className = 'title';
+  title.textContent = row.title;
+  el.append(title);
+
+  const details = document.createElement('div');
+  details.className = 'details';
+  const bits = [row.type || 'feature', row.status || 'unstarted'];
+  if (row.estimate) bits.push(row.estimate + ' pts');
+  if (row.assignees) bits.push(row.assignees.join(', '));
+  if (row.epic) bits.push('epic:' + row.epic);
+  for (const t of row.tags || []) bits.push('#' + t);
+  for (const b of bits) {
+    const span = document.createElement('span');
+    span.textContent = b;
+    details.append(span);
+  }
+  if (row.blocked) {
+    const span = document.createElement('span');
+    span.className = 'blocked';
+    span.textContent = 'blocked';
+    details.append(span);
+  }
+  el.append(details);
+
+  if (column !== 'done') {
+    const actions = document.createElement('div');
+    actions.className = 'actions';
+    for (const [label, status] of buttons[row.status || 'unstarted'] || []) {
+      actions.append(button(label, () => setStatus(row, status)));
+    }
+    if (row.status === 'delivered') {
+      actions.append(button('Accept / reject…', () => openCeremony(row)));
+    }
+    el.append(actions);
+  }
+
+  if (draggable) {
+    el.addEventListener('dragstart', e => {
+      dragged = { path: row.path, from: column };
+      e.dataTransfer.setData('text/plain', row.path);
+    });
+    el.addEventListener('dragover', e => {
+      if (!dragged || column === 'done') return;
+      e.preventDefault();
+      el.classList.add('drop-before');
+    });
+    el.addEventListener('dragleave', () => el.classList.remove('drop-before'));
+    el.addEventListener('drop', e => {
+      e.preventDefault();
+      e.stopPropagation();
+      el.classList.remove('drop-before');
+      drop(column, row.path);
+    });
+  }
+  return el;
+}
+
+function button(label, onClick) {
+  const b = document.createElement('button');
+  b.type = 'button';
+  b.textContent = label;
+  b.addEventListener('click', onClick);
+  return b;
+}
+
+// drop moves the dragged story into column, before the card at beforePath
+// or, when dropped on the column itself, at the column's end. Current and
+// Backlog are both _priority.md, so the end of Current is just above the
+// first Backlog story.
+async function drop(column, beforePath) {
+  if (!dragged || dragged.path === beforePath) return;
+  const item = dragged.path;
+  dragged = null;
+  try {
+    if (column === 'icebox') {
+      if (board.icebox.some(r => r.path === item)) return;
+      await api('POST', '/api/icebox', { backlog: board.backlog, item });
+    } else if (column === 'current' || column === 'backlog') {
+      let before = beforePath || '';
+      if (!before && column === 'current' && board.below_line.length > 0) {
+        before = board.below_line[0].path;
+      }
+      await api('POST', '/api/rank', { backlog: board.backlog, item, before });
+    } else {
+      return;
+    }
+    notice('');
+  } catch (err) {
+    notice(err.message);
+  }
+  load();
+}
+
+// setStatus asks the server, which consults the coach first. A nudge
+// comes back as a question; a refusal as the rule and the next move.
+async function setStatus(row, status, confirm) {
+  try {
+    await api('POST', '/api/status', { backlog: board.backlog, item: row.path, status, confirm: !!confirm });
+    notice('');
+  } catch (err) {
+    const c = err.coach;
+    if (c && c.allowed && c.nudge) {
+      if (window.confirm(`Coach: ${c.rule}.\n${c.next || ''}\n\nGo ahead anyway?`)) {
+        return setStatus(row, status, true);
+      }
+      return;
+    }
+    notice(c ? `Coach: ${c.rule}. ${c.next || ''}` : err.message);
+  }
+  load();
+}
+
+let ceremonyRow = null;
+
+async function openCeremony(row) {
+  let prompt;
+  try {
+    prompt = await api('GET', `/api/acceptance?backlog=${encodeURIComponent(board.backlog)}&item=${encodeURIComponent(row.path)}`);
+  } catch (err) {
+    notice(err.message);
+    return;
+  }
+  ceremonyRow = row;
+  $('#ceremony-title').textContent = prompt.title;
+  $('#ceremony-meta').textContent = [prompt.type, prompt.estimate && prompt.estimate + ' pts', prompt.path].filter(Boolean).join(' · ');
+  const bullets = prompt.bullets || [];
+  $('#ceremony-empty').hidden = bullets.length > 0;
+  $('#ceremony-bullets').replaceChildren(...bullets.map(b => {
+    const li = document.createElement('li');
+    const label = document.createElement('label');
+    const box = document.createElement('input');
+    box.type = 'checkbox';
+    box.value = b.index;
+    box.checked = b.state === 'verified';
+    label.append(box, ' ', b.text);
+    if (b.claim_note) {
+      const note = document.createElement('div');
+      note.className = 'meta';
+      note.textContent = 'claim: ' + b.claim_note;
+      label.append(note);
+    }
+    li.append(label);
+    return li;
+  }));
+  $('#ceremony-failing').replaceChildren(new Option('none', '0'), ...bullets.map(b => new Option(`${b.index}. ${b.text}`, b.index)));
+  $('#ceremony-reason').value = '';
+  $('#ceremony-error').textContent = '';
+  $('#ceremony').showModal();
+}
+
+async function decide(path, body) {
+  try {
+    await api('POST', path, Object.assign({ backlog: board.backlog, item: ceremonyRow.path }, body));
+  } catch (err) {
+    const c = err.coach;
+    if (c && c.allowed && c.nudge && !body.confirm) {
+      if (window.confirm(`Coach: ${c.rule}.\n${c.next || ''}\n\nGo ahead anyway?`)) {
+        return decide(path, Object.assign({}, body, { confirm: true }));
+      }
+      return;
+    }
+    $('#ceremony-error').textContent = c ? `Coach: ${c.rule}. ${c.next || ''}` : err.message;
+    return;
+  }
+  $('#ceremony').close();
+  load();
+}
+
+$('#ceremony-accept').addEventListener('click', () => {
+  const verified = [...document.querySelectorAll('#ceremony-bullets input:checked')].map(b => Number(b.value));
+  decide('/api/accept', { verified });
+});
+
+$('#ceremony-reject').addEventListener('click', () => {
+  decide('/api/reject', {
+    reason: $('#ceremony-reason').value,
+    failing_bullet: Number($('#ceremony-failing').value),
+  });
+});
+
+for (const col of document.querySelectorAll('.col')) {
+  const column = col.dataset.column;
+  if (column === 'done') continue;
+  col.addEventListener('dragover', e => {
+    if (!dragged) return;
+    e.preventDefault();
+    col.classList.add('drop-target');
+  });
+  col.addEventListener('dragleave', () => col.classList.remove('drop-target'));
+  col.addEventListener('drop', e => {
+    e.preventDefault();
+    col.classList.remove('drop-target');
+    drop(column, '');
+  });
+}
+
+$('#backlog').addEventListener('change', () => {
+  history.replaceState(null, '', '?backlog=' + encodeURIComponent($('#backlog').value));
+  load();
+});
+
+// Pick up edits made elsewhere (an editor, an agent, a git pull).
+setInterval(() => {
+  if (!$('#ceremony').open && !dragged) load();
+}, 5000);
+
+load();
@@ -6,20 +6,27 @@ package coach
 
 import (
 	"embed"
+	"encoding/json"
+	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/mreider/agilemarkdown/git"
+	"github.com/mreider/agilemarkdown/markdown"
 )
 
-//go:embed CLAUDE.md AGENTS.md copilot-instructions.md cursor-coach.mdc agilemarkdown-coach.md skills hooks settings.json
+//go:embed CLAUDE.md AGENTS.md copilot-instructions.md cursor-coach.mdc agilemarkdown-coach.md skills settings.json
 var templatesFS embed.FS
 
 type installEntry struct {
 	source string
 	target string
 	mode   os.FileMode
+	// merge, when set, folds the template into a target that already
+	// exists and reports whether it changed anything.
+	merge func(dst string, data []byte) (bool, error)
 }
 
 // install maps each embedded template to its target path inside the
@@ -28,44 +35,62 @@ type installEntry struct {
 var install = []installEntry{
 	// Canonical coach body. Claude Code's CLAUDE.md @-imports this; the
 	// other agent conventions ship the same body inline.
-	{"agilemarkdown-coach.md", ".claude/agilemarkdown-coach.md", 0o644},
+	{"agilemarkdown-coach.md", ".claude/agilemarkdown-coach.md", 0o644, nil},
 	// CLAUDE.md is a thin @-import so a user's existing CLAUDE.md, if
 	// present, is not overwritten. When missing, the projection installs
 	// a one-line file that pulls in the canonical body.
-	{"CLAUDE.md", "CLAUDE.md", 0o644},
-	{"AGENTS.md", "AGENTS.md", 0o644},
-	{"copilot-instructions.md", ".github/copilot-instructions.md", 0o644},
-	{"cursor-coach.mdc", ".cursor/rules/coach.mdc", 0o644},
-	{"skills/am-accept/SKILL.md", ".claude/skills/am-accept/SKILL.md", 0o644},
-	{"skills/am-align/SKILL.md", ".claude/skills/am-align/SKILL.md", 0o644},
-	{"skills/am-decompose/SKILL.md", ".claude/skills/am-decompose/SKILL.md", 0o644},
-	{"skills/am-inception/SKILL.md", ".claude/skills/am-inception/SKILL.md", 0o644},
-	{"skills/am-plan/SKILL.md", ".claude/skills/am-plan/SKILL.md", 0o644},
-	{"skills/am-retro/SKILL.md", ".claude/skills/am-retro/SKILL.md", 0o644},
-	{"hooks/coach-gate.sh", ".claude/hooks/coach-gate.sh", 0o755},
-	{"settings.json", ".claude/settings.json", 0o644},
+	{"CLAUDE.md", "CLAUDE.md", 0o644, nil},
+	{"AGENTS.md", "AGENTS.md", 0o644, nil},
+	{"copilot-instructions.md", ".github/copilot-instructions.md", 0o644, nil},
+	{"cursor-coach.mdc", ".cursor/rules/coach.mdc", 0o644, nil},
+	{"skills/am-accept/SKILL.md", ".claude/skills/am-accept/SKILL.md", 0o644, nil},
+	{"skills/am-align/SKILL.md", ".claude/skills/am-align/SKILL.md", 0o644, nil},
+	{"skills/am-decompose/SKILL.md", ".claude/skills/am-decompose/SKILL.md", 0o644, nil},
+	{"skills/am-inception/SKILL.md", ".claude/skills/am-inception/SKILL.md", 0o644, nil},
+	{"skills/am-plan/SKILL.md", ".claude/skills/am-plan/SKILL.md", 0o644, nil},
+	{"skills/am-retro/SKILL.md", ".claude/skills/am-retro/SKILL.md", 0o644, nil},
+	// The coach gate: `am hook pre-tool-use` on every agilemarkdown tool.
+	{"settings.json", ".claude/settings.json", 0o644, mergeSettings},
 }
 
 // InstallTemplates writes the four coach-mode templates into rootDir
 // when missing. Returns the list of paths written (relative to
-// rootDir). Idempotent: existing files are never overwritten.
+// rootDir). Idempotent: existing files are never overwritten, except
+// that the coach gate is added to an existing .claude/settings.json.
+// Each template written is recorded in LockFile; UpgradeTemplates
+// brings existing ones up to date.
 //
 // Newly written files are staged via `git add` if a repo is present.
 // The caller commits.
 func InstallTemplates(rootDir string) ([]string, error) {
+	lk, err := loadLock(rootDir)
+	if err != nil {
+		return nil, err
+	}
 	var written []string
 	for _, t := range install {
 		dst := filepath.Join(rootDir, t.target)
+		data, err := fs.ReadFile(templatesFS, t.source)
+		if err != nil {
+			return written, err
+		}
 		if _, err := os.Stat(dst); err == nil {
+			if t.merge == nil {
+				continue
+			}
+			changed, err := t.merge(dst, data)
+			if err != nil {
+				return written, fmt.Errorf("%s: %w", t.target, err)
+			}
+			if changed {
+				_ = git.Add(dst)
+				written = append(written, t.target)
+			}
 			continue
 		}
 		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
 			return written, err
 		}
-		data, err := fs.ReadFile(templatesFS, t.source)
-		if err != nil {
-			return written, err
-		}
 		mode := t.mode
 		if mode == 0 {
 			mode = 0o644
@@ -75,6 +100,135 @@ func InstallTemplates(rootDir string) ([]string, error) {
 		}
 		_ = git.Add(dst)
 		written = append(written, t.target)
+		if t.merge == nil {
+			if err := lk.record(rootDir, t, data); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, lk.save(rootDir)
+}
+
+// gateCommand is the PreToolUse hook command the settings template
+// wires up. legacyGate is the script it replaced.
+const (
+	gateCommand = "am hook pre-tool-use"
+	legacyGate  = "coach-gate.sh"
+)
+
+// mergeSettings adds the template's PreToolUse entry to an existing
+// Claude Code settings file unless some entry already runs the gate,
+// and drops entries that ran the old bash gate. Everything else in the
+// file is kept.
+func mergeSettings(dst string, tmpl []byte) (bool, error) {
+	raw, err := os.ReadFile(dst)
+	if err != nil {
+		return false, err
+	}
+	settings := map[string]any{}
+	if strings.TrimSpace(string(raw)) != "" {
+		if err := json.Unmarshal(raw, &settings); err != nil {
+			return false, err
+		}
+	}
+	var want map[string]any
+	if err := json.Unmarshal(tmpl, &want); err != nil {
+		return false, err
+	}
+	hooks, _ := settings["hooks"].(map[string]any)
+	if hooks == nil {
+		hooks = map[string]any{}
+	}
+	entries, _ := hooks["PreToolUse"].([]any)
+	changed, wired := false, false
+	kept := entries[:0:0]
+	for _, e := range entries {
+		group, _ := e.(map[string]any)
+		cmds, _ := group["hooks"].([]any)
+		var keep []any
+		for _, h := range cmds {
+			hook, _ := h.(map[string]any)
+			cmd, _ := hook["command"].(string)
+			switch {
+			case strings.Contains(cmd, legacyGate):
+				changed = true
+				continue
+			case strings.TrimSpace(cmd) == gateCommand:
+				wired = true
+			}
+			keep = append(keep, h)
+		}
+		if group == nil || len(cmds) == 0 {
+			kept = append(kept, e)
+			continue
+		}
+		if len(keep) == 0 {
+			continue
+		}
+		group["hooks"] = keep
+		kept = append(kept, group)
+	}
+	if !wired {
+		wantHooks, _ := want["hooks"].(map[string]any)
+		wantEntries, _ := wantHooks["PreToolUse"].([]any)
+		kept = append(kept, wantEntries...)
+		changed = true
+	}
+	if !changed {
+		return false, nil
+	}
+	hooks["PreToolUse"] = kept
+	settings["hooks"] = hooks
+	out, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(dst, append(out, '\n'), 0o644)
+}
+
+// Skill is one of the coach's ceremonies (inception, plan, align,
+// decompose, accept, retro), read from its embedded SKILL.md, or one
+// the team added under TeamSkillsDir.
+type Skill struct {
+	Name        string // short name, without the am- prefix
+	Description string
+	Body        string // the SKILL.md text after the frontmatter
+	// Team skills only: the SKILL.md path relative to the project
+	// root, and the arguments its frontmatter declares.
+	Source    string
+	Arguments []SkillArgument
+}
+
+// SkillArgument is an optional input a team skill takes.
+type SkillArgument struct {
+	Name        string
+	Description string
+}
+
+// Skills returns the embedded coach skills sorted by name.
+func Skills() ([]Skill, error) {
+	entries, err := fs.ReadDir(templatesFS, "skills")
+	if err != nil {
+		return nil, err
+	}
+	var skills []Skill
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		data, err := fs.ReadFile(templatesFS, "skills/"+e.Name()+"/SKILL.md")
+		if err != nil {
+			return nil, err
+		}
+		f, err := markdown.ParseFrontmatter(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("skill %s: %w", e.Name(), err)
+		}
+		skills = append(skills, Skill{
+			Name:        strings.TrimPrefix(e.Name(), "am-"),
+			Description: strings.TrimSpace(f.GetString("description")),
+			Body:        strings.TrimLeft(f.Body(), "\n"),
+		})
 	}
-	return written, nil
+	return skills, nil
 }
@@ -0,0 +1,271 @@
+package coach
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mreider/agilemarkdown/git"
+)
+
+func gitRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Skipf("git init: %v %s", err, out)
+	}
+	return dir
+}
+
+// TestUpgradeTemplates walks a template through each state: installed,
+// behind upstream, edited and behind, and edited where upstream changed
+// the same line.
+func TestUpgradeTemplates(t *testing.T) {
+	dir := gitRepo(t)
+	if _, err := InstallTemplates(dir); err != nil {
+		t.Fatal(err)
+	}
+	lk, err := loadLock(dir)
+	if err != nil || len(lk.Templates) == 0 {
+		t.Fatalf("lock after install = %+v, %v", lk, err)
+	}
+	statuses, err := CheckTemplates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range statuses {
+		if s.State != TemplateCurrent {
+			t.Errorf("fresh install: %s is %s", s.Target, s.State)
+		}
+	}
+
+	// Pretend AGENTS.md and the copilot projection were installed from
+	// an older upstream whose intro line differed.
+	upstream, _ := os.ReadFile(filepath.Join(dir, "AGENTS.md"))
+	intro := strings.Split(string(upstream), "\n")[2]
+	old := strings.Replace(string(upstream), intro, "An older intro.", 1)
+	write := func(target, content string) {
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(target)), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, target := range []string{"AGENTS.md", ".github/copilot-instructions.md", ".cursor/rules/coach.mdc"} {
+		e := lk.Templates[target]
+		e.Blob = git.BlobID([]byte(old))
+		lk.Templates[target] = e
+		write(BaseDir+"/"+target, old)
+	}
+	lk.changed = true
+	if err := lk.save(dir); err != nil {
+		t.Fatal(err)
+	}
+	write("AGENTS.md", old)
+	write(".github/copilot-instructions.md", old+"\n## Team notes\n\nWe pair on Fridays.\n")
+	write(".cursor/rules/coach.mdc", strings.Replace(old, "An older intro.", "Our own intro.", 1))
+
+	// The base travels with the repository: a fresh clone sees the same.
+	clone := filepath.Join(t.TempDir(), "clone")
+	for _, args := range [][]string{
+		{"-C", dir, "add", "-A"},
+		{"-C", dir, "-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-qm", "install"},
+		{"clone", "-q", dir, clone},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v %s", args, err, out)
+		}
+	}
+
+	want := map[string]TemplateState{
+		"AGENTS.md":                       TemplateOutOfDate,
+		".github/copilot-instructions.md": TemplateDiverged,
+		".cursor/rules/coach.mdc":         TemplateDiverged,
+	}
+	for _, repo := range []string{dir, clone} {
+		statuses, err = CheckTemplates(repo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range statuses {
+			if w, ok := want[s.Target]; ok && s.State != w {
+				t.Errorf("check %s: %s is %s, want %s", filepath.Base(repo), s.Target, s.State, w)
+			}
+		}
+	}
+	if stale, _ := StaleProjections(dir); len(stale) != 3 {
+		t.Errorf("stale projections = %v", stale)
+	}
+
+	want = map[string]TemplateState{
+		"AGENTS.md":                       TemplateUpgraded,
+		".github/copilot-instructions.md": TemplateMerged,
+		".cursor/rules/coach.mdc":         TemplateConflict,
+	}
+	statuses, err = UpgradeTemplates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range statuses {
+		if w, ok := want[s.Target]; ok && s.State != w {
+			t.Errorf("upgrade: %s is %s, want %s", s.Target, s.State, w)
+		}
+	}
+	read := func(target string) string {
+		data, _ := os.ReadFile(filepath.Join(dir, filepath.FromSlash(target)))
+		return string(data)
+	}
+	if read("AGENTS.md") != string(upstream) {
+		t.Errorf("AGENTS.md was not replaced with upstream")
+	}
+	if read(BaseDir+"/AGENTS.md") != string(upstream) {
+		t.Errorf("base of AGENTS.md not moved to upstream")
+	}
+	if got := read(".github/copilot-instructions.md"); !strings.Contains(got, intro) || !strings.Contains(got, "We pair on Fridays.") {
+		t.Errorf("merge lost a side:\n%s", got)
+	}
+	if got := read(".cursor/rules/coach.mdc"); !strings.Contains(got, "<<<<<<<") {
+		t.Errorf("conflict left no markers:\n%s", got)
+	}
+
+	// Upstream is now the base everywhere: a second upgrade has nothing
+	// to do.
+	statuses, err = UpgradeTemplates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range statuses {
+		if s.State != TemplateCurrent && s.State != TemplateEdited {
+			t.Errorf("second upgrade: %s is %s", s.Target, s.State)
+		}
+	}
+}
+
+// TestMergeSettings checks that an existing settings file gains the
+// gate, loses the old bash gate and keeps everything else.
+func TestMergeSettings(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "settings.json")
+	existing := `{"permissions":{"allow":["Bash(ls)"]},"hooks":{"PreToolUse":[
+		{"matcher":"mcp__agilemarkdown__set_status","hooks":[{"type":"command","command":"bash .claude/hooks/coach-gate.sh"}]},
+		{"matcher":"Bash","hooks":[{"type":"command","command":"echo hi"}]}]}}`
+	if err := os.WriteFile(path, []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := templatesFS.ReadFile("settings.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, err := mergeSettings(path, tmpl)
+	if err != nil || !changed {
+		t.Fatalf("mergeSettings = %v, %v", changed, err)
+	}
+	data, _ := os.ReadFile(path)
+	var got struct {
+		Permissions map[string]any `json:"permissions"`
+		Hooks       struct {
+			PreToolUse []struct {
+				Matcher string `json:"matcher"`
+				Hooks   []struct {
+					Command string `json:"command"`
+				} `json:"hooks"`
+			} `json:"PreToolUse"`
+		} `json:"hooks"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	var commands []string
+	for _, e := range got.Hooks.PreToolUse {
+		for _, h := range e.Hooks {
+			commands = append(commands, e.Matcher+" "+h.Command)
+		}
+	}
+	if strings.Join(commands, "; ") != "Bash echo hi; mcp__agilemarkdown__.* am hook pre-tool-use" || got.Permissions == nil {
+		t.Errorf("merged settings:\n%s", data)
+	}
+	if changed, err := mergeSettings(path, tmpl); err != nil || changed {
+		t.Errorf("second merge = %v, %v; want no change", changed, err)
+	}
+	// A hand-written hook that is not an object is kept, not a panic.
+	odd := `{"hooks":{"PreToolUse":[{"matcher":"Bash","hooks":["echo hi"]}]}}`
+	if err := os.WriteFile(path, []byte(odd), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := mergeSettings(path, tmpl); err != nil || !changed {
+		t.Fatalf("merge over a string hook = %v, %v", changed, err)
+	}
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), `"echo hi"`) {
+		t.Errorf("string hook dropped:\n%s", data)
+	}
+}
+
+// TestProjectTeamSkills projects a team skill, leaves a file am did not
+// write alone, and cleans up once the skill is deleted.
+func TestProjectTeamSkills(t *testing.T) {
+	dir := gitRepo(t)
+	if _, err := InstallTemplates(dir); err != nil {
+		t.Fatal(err)
+	}
+	write := func(target, content string) {
+		path := filepath.Join(dir, filepath.FromSlash(target))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".am/skills/bug-triage/SKILL.md", "---\ndescription: Weekly bug triage.\narguments:\n  backlog: backlog to triage\n---\n\n# Triage\n")
+	write(".am/skills/design-review/SKILL.md", "---\ndescription: Design review.\n---\n\n# Review\n")
+	write(".cursor/rules/design-review.mdc", "our own rule\n")
+
+	written, removed, skipped, err := ProjectTeamSkills(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(written, " "); got != ".claude/skills/bug-triage/SKILL.md .claude/skills/design-review/SKILL.md .cursor/rules/bug-triage.mdc AGENTS.md" {
+		t.Errorf("written = %s", got)
+	}
+	if len(removed) != 0 || strings.Join(skipped, " ") != ".cursor/rules/design-review.mdc" {
+		t.Errorf("removed = %v, skipped = %v", removed, skipped)
+	}
+	skill, _ := os.ReadFile(filepath.Join(dir, ".claude", "skills", "bug-triage", "SKILL.md"))
+	if !strings.HasPrefix(string(skill), "---\nname: bug-triage\ndescription: Weekly bug triage.\n---\n") || !strings.Contains(string(skill), "am ceremony bug-triage") {
+		t.Errorf("projected skill:\n%s", skill)
+	}
+	agents, _ := os.ReadFile(filepath.Join(dir, "AGENTS.md"))
+	if !strings.Contains(string(agents), "- `bug-triage`: Weekly bug triage.") {
+		t.Errorf("AGENTS.md has no Team ceremonies section:\n%s", agents)
+	}
+	if stale, _ := StaleProjections(dir); len(stale) != 0 {
+		t.Errorf("team section counted as drift: %v", stale)
+	}
+	if written, _, _, _ := ProjectTeamSkills(dir); len(written) != 0 {
+		t.Errorf("second run wrote %v", written)
+	}
+
+	if err := os.RemoveAll(filepath.Join(dir, ".am", "skills")); err != nil {
+		t.Fatal(err)
+	}
+	written, removed, _, err = ProjectTeamSkills(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(written, " ") != "AGENTS.md" || len(removed) != 3 {
+		t.Errorf("after delete: written = %v, removed = %v", written, removed)
+	}
+	upstream, _ := templatesFS.ReadFile("AGENTS.md")
+	if agents, _ := os.ReadFile(filepath.Join(dir, "AGENTS.md")); string(agents) != string(upstream) {
+		t.Errorf("AGENTS.md not restored:\n%s", agents)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".cursor", "rules", "design-review.mdc")); err != nil {
+		t.Errorf("team's own rule removed: %v", err)
+	}
+
+	write(".am/skills/am-plan/SKILL.md", "---\ndescription: Shadow.\n---\n")
+	if _, err := TeamSkills(dir); err == nil {
+		t.Error("am- prefixed team skill accepted")
+	}
+}
@@ -0,0 +1,115 @@
+package coach
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"github.com/mreider/agilemarkdown/git"
+	"gopkg.in/yaml.v3"
+)
+
+// LockFile records, for each template am installed, the git blob id of
+// the upstream text the file came from; BaseDir keeps that text, at the
+// template's own path. `am init --upgrade` uses it as the base of a
+// three-way merge, so a team's edits survive a new coach body. Both are
+// committed with the backlog, so every clone can merge.
+const (
+	LockFile = ".am/coach.lock"
+	BaseDir  = ".am/coach-base"
+)
+
+const lockHeader = "# Written by am init. Each template's upstream version as a git blob id;\n# the text is in " + BaseDir + ". am init --upgrade merges new templates\n# against it. Do not edit.\n"
+
+type lock struct {
+	Templates map[string]lockEntry `yaml:"templates"`
+
+	changed bool
+}
+
+type lockEntry struct {
+	Source string `yaml:"source"`
+	Blob   string `yaml:"blob"`
+}
+
+// loadLock reads rootDir's lock. A missing lock is empty.
+func loadLock(rootDir string) (*lock, error) {
+	l := &lock{Templates: map[string]lockEntry{}}
+	data, err := os.ReadFile(filepath.Join(rootDir, LockFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return l, nil
+		}
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, l); err != nil {
+		return nil, err
+	}
+	if l.Templates == nil {
+		l.Templates = map[string]lockEntry{}
+	}
+	return l, nil
+}
+
+// record notes that t's target now derives from upstream and keeps the
+// text under BaseDir, staged, as the base of a later upgrade.
+func (l *lock) record(rootDir string, t installEntry, upstream []byte) error {
+	path := filepath.Join(rootDir, BaseDir, filepath.FromSlash(t.target))
+	if old, err := os.ReadFile(path); err != nil || !bytes.Equal(old, upstream) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, upstream, 0644); err != nil {
+			return err
+		}
+		_ = git.Add(path)
+	}
+	l.set(t, git.BlobID(upstream))
+	return nil
+}
+
+// base reads back the text record kept for t. A lock written before
+// BaseDir existed only has the blob id, which git may still hold.
+func (l *lock) base(rootDir string, blobs *git.BlobReader, t installEntry) []byte {
+	e, ok := l.Templates[t.target]
+	if !ok {
+		return nil
+	}
+	text, err := os.ReadFile(filepath.Join(rootDir, BaseDir, filepath.FromSlash(t.target)))
+	if err == nil && git.BlobID(text) == e.Blob {
+		return text
+	}
+	if old, err := blobs.Read(e.Blob); err == nil {
+		return []byte(old)
+	}
+	return nil
+}
+
+func (l *lock) set(t installEntry, blob string) {
+	if e, ok := l.Templates[t.target]; ok && e.Blob == blob && e.Source == t.source {
+		return
+	}
+	l.Templates[t.target] = lockEntry{Source: t.source, Blob: blob}
+	l.changed = true
+}
+
+// save writes the lock when something changed and stages it.
+func (l *lock) save(rootDir string) error {
+	if !l.changed {
+		return nil
+	}
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(rootDir, LockFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append([]byte(lockHeader), data...), 0644); err != nil {
+		return err
+	}
+	_ = git.Add(path)
+	l.changed = false
+	return nil
+}
@@ -0,0 +1,322 @@
+package coach
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/mreider/agilemarkdown/git"
+	"github.com/mreider/agilemarkdown/markdown"
+	"gopkg.in/yaml.v3"
+)
+
+// TeamSkillsDir holds the team's own ceremonies, one
+// <name>/SKILL.md each, next to the six built into am. A team skill's
+// frontmatter has a description and, optionally, the arguments it
+// takes:
+//
+//	---
+//	description: Weekly bug triage. Use when the team says "triage".
+//	arguments:
+//	  backlog: backlog whose bugs to triage
+//	---
+//
+// The body is a Go text/template; am ceremony and the MCP prompt fill
+// in live backlog data (see mcpserver's ceremony funcs).
+const TeamSkillsDir = ".am/skills"
+
+// generatedMarker opens every file am init projects from a team skill.
+// It is how a later run tells its own output from a file the team
+// wrote, and so which ones it may rewrite or remove.
+const generatedMarker = "<!-- Generated by am init from " + TeamSkillsDir + "/"
+
+// Team-skill names follow Claude Code's skill naming. am- is reserved
+// for the built-ins, and coach for the Cursor rule carrying the body.
+var teamSkillName = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// TeamSkills reads the skills under TeamSkillsDir, sorted by name. A
+// missing directory means none. A skill without a description, with a
+// name am cannot project, or shadowing a built-in is an error.
+func TeamSkills(rootDir string) ([]Skill, error) {
+	entries, err := os.ReadDir(filepath.Join(rootDir, TeamSkillsDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	builtins, err := Skills()
+	if err != nil {
+		return nil, err
+	}
+	var skills []Skill
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		name := e.Name()
+		source := TeamSkillsDir + "/" + name + "/SKILL.md"
+		data, err := os.ReadFile(filepath.Join(rootDir, filepath.FromSlash(source)))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		switch {
+		case !teamSkillName.MatchString(name):
+			return nil, fmt.Errorf("%s: name must be lowercase letters, digits and hyphens", source)
+		case strings.HasPrefix(name, "am-") || name == "coach":
+			return nil, fmt.Errorf("%s: %q is reserved for am's own skills", source, name)
+		case slices.ContainsFunc(builtins, func(b Skill) bool { return b.Name == name }):
+			return nil, fmt.Errorf("%s: %q is a built-in skill", source, name)
+		}
+		f, err := markdown.ParseFrontmatter(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+		if n := f.GetString("name"); n != "" && n != name {
+			return nil, fmt.Errorf("%s: name %q does not match its directory", source, n)
+		}
+		s := Skill{
+			Name:        name,
+			Description: strings.TrimSpace(f.GetString("description")),
+			Body:        strings.TrimLeft(f.Body(), "\n"),
+			Source:      source,
+		}
+		if s.Description == "" {
+			return nil, fmt.Errorf("%s: description is required", source)
+		}
+		for _, kv := range f.GetMap("arguments") {
+			s.Arguments = append(s.Arguments, SkillArgument{Name: kv.Key, Description: kv.Value})
+		}
+		skills = append(skills, s)
+	}
+	return skills, nil
+}
+
+// ProjectTeamSkills writes each team skill where the agents look for
+// the built-ins: .claude/skills/<name>/SKILL.md, .cursor/rules/<name>.mdc
+// and a Team ceremonies section at the end of AGENTS.md. The projections
+// point at the MCP prompt and am ceremony rather than copying the body,
+// so the agent always gets the script with today's data. Projections of
+// skills since deleted are removed. A file am did not generate is never
+// touched; its path comes back in skipped. Changes are staged.
+func ProjectTeamSkills(rootDir string) (written, removed, skipped []string, err error) {
+	skills, err := TeamSkills(rootDir)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	keep := map[string]bool{}
+	for _, s := range skills {
+		pointer := teamSkillPointer(s)
+		claude, err := withFrontmatter(claudeSkillFrontmatter{Name: s.Name, Description: s.Description}, pointer)
+		if err != nil {
+			return written, removed, skipped, err
+		}
+		cursor, err := withFrontmatter(cursorRuleFrontmatter{Description: s.Description}, pointer)
+		if err != nil {
+			return written, removed, skipped, err
+		}
+		for target, data := range map[string][]byte{
+			".claude/skills/" + s.Name + "/SKILL.md": claude,
+			".cursor/rules/" + s.Name + ".mdc":       cursor,
+		} {
+			keep[target] = true
+			changed, err := writeGenerated(rootDir, target, data)
+			switch {
+			case errors.Is(err, errNotGenerated):
+				skipped = append(skipped, target)
+			case err != nil:
+				return written, removed, skipped, err
+			case changed:
+				written = append(written, target)
+			}
+		}
+	}
+	removed, err = removeStaleGenerated(rootDir, keep)
+	if err != nil {
+		return written, removed, skipped, err
+	}
+	changed, err := writeAgentsSection(rootDir, skills)
+	if err != nil {
+		return written, removed, skipped, err
+	}
+	if changed {
+		written = append(written, "AGENTS.md")
+	}
+	slices.Sort(written)
+	return written, removed, skipped, nil
+}
+
+func teamSkillPointer(s Skill) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s%s/SKILL.md; edit that file and rerun am init. -->\n\n", generatedMarker, s.Name)
+	fmt.Fprintf(&b, "# %s\n\n%s\n\n", s.Name, s.Description)
+	fmt.Fprintf(&b, "This is one of the team's own ceremonies. Get its script, with the\n")
+	fmt.Fprintf(&b, "backlog's current data filled in, from the `%s` MCP prompt, or run\n", s.Name)
+	fmt.Fprintf(&b, "`am ceremony %s`%s. Then follow it.\n", s.Name, argumentUsage(s))
+	for _, a := range s.Arguments {
+		fmt.Fprintf(&b, "\n- `%s`: %s", a.Name, a.Description)
+	}
+	if len(s.Arguments) > 0 {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func argumentUsage(s Skill) string {
+	if len(s.Arguments) == 0 {
+		return ""
+	}
+	var pairs []string
+	for _, a := range s.Arguments {
+		pairs = append(pairs, a.Name+"=...")
+	}
+	return " (arguments as `" + strings.Join(pairs, " ") + "`)"
+}
+
+// Frontmatter for the Claude Code skill and the Cursor rule. Cursor
+// attaches a rule with a description and alwaysApply off when the agent
+// judges it relevant, as Claude Code does a skill.
+type claudeSkillFrontmatter struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+}
+
+type cursorRuleFrontmatter struct {
+	Description string `yaml:"description"`
+	AlwaysApply bool   `yaml:"alwaysApply"`
+}
+
+func withFrontmatter(fm any, body string) ([]byte, error) {
+	data, err := yaml.Marshal(fm)
+	if err != nil {
+		return nil, err
+	}
+	return []byte("---\n" + string(data) + "---\n" + body), nil
+}
+
+var errNotGenerated = errors.New("not generated by am init")
+
+// writeGenerated writes data to target unless a file am did not
+// generate is already there.
+func writeGenerated(rootDir, target string, data []byte) (bool, error) {
+	dst := filepath.Join(rootDir, filepath.FromSlash(target))
+	old, err := os.ReadFile(dst)
+	switch {
+	case err == nil && bytes.Equal(old, data):
+		return false, nil
+	case err == nil && !bytes.Contains(old, []byte(generatedMarker)):
+		return false, errNotGenerated
+	case err != nil && !os.IsNotExist(err):
+		return false, err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(dst, data, 0o644); err != nil {
+		return false, err
+	}
+	_ = git.Add(dst)
+	return true, nil
+}
+
+// removeStaleGenerated deletes generated projections not in keep.
+func removeStaleGenerated(rootDir string, keep map[string]bool) ([]string, error) {
+	var candidates []string
+	if dirs, err := os.ReadDir(filepath.Join(rootDir, ".claude", "skills")); err == nil {
+		for _, d := range dirs {
+			candidates = append(candidates, ".claude/skills/"+d.Name()+"/SKILL.md")
+		}
+	}
+	if rules, err := filepath.Glob(filepath.Join(rootDir, ".cursor", "rules", "*.mdc")); err == nil {
+		for _, r := range rules {
+			candidates = append(candidates, ".cursor/rules/"+filepath.Base(r))
+		}
+	}
+	var removed []string
+	for _, target := range candidates {
+		if keep[target] {
+			continue
+		}
+		dst := filepath.Join(rootDir, filepath.FromSlash(target))
+		data, err := os.ReadFile(dst)
+		if err != nil || !bytes.Contains(data, []byte(generatedMarker)) {
+			continue
+		}
+		if err := os.Remove(dst); err != nil {
+			return removed, err
+		}
+		if strings.HasSuffix(target, "/SKILL.md") {
+			_ = os.Remove(filepath.Dir(dst))
+		}
+		_ = git.Add(dst)
+		removed = append(removed, target)
+	}
+	return removed, nil
+}
+
+// The Team ceremonies section of AGENTS.md sits between these markers.
+// projectionBody strips it, so it does not count as drift from the
+// canonical coach body.
+const (
+	teamSectionStart = "<!-- am:team-skills -->"
+	teamSectionEnd   = "<!-- /am:team-skills -->"
+)
+
+// writeAgentsSection replaces the Team ceremonies section of AGENTS.md,
+// appending it the first time and dropping it when the team has no
+// skills. A missing AGENTS.md is left missing.
+func writeAgentsSection(rootDir string, skills []Skill) (bool, error) {
+	dst := filepath.Join(rootDir, "AGENTS.md")
+	old, err := os.ReadFile(dst)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	text := strings.TrimRight(stripTeamSection(string(old)), "\n") + "\n"
+	if len(skills) > 0 {
+		var b strings.Builder
+		b.WriteString("\n" + teamSectionStart + "\n")
+		b.WriteString("## Team ceremonies\n\n")
+		b.WriteString("This team's own skills, from " + TeamSkillsDir + ". For a ceremony's\n")
+		b.WriteString("script with live backlog data, use the MCP prompt of the same name or\n")
+		b.WriteString("run `am ceremony <name>`.\n\n")
+		for _, s := range skills {
+			fmt.Fprintf(&b, "- `%s`: %s\n", s.Name, strings.Join(strings.Fields(s.Description), " "))
+		}
+		b.WriteString(teamSectionEnd + "\n")
+		text += b.String()
+	}
+	if text == string(old) {
+		return false, nil
+	}
+	if err := os.WriteFile(dst, []byte(text), 0o644); err != nil {
+		return false, err
+	}
+	_ = git.Add(dst)
+	return true, nil
+}
+
+// stripTeamSection removes the Team ceremonies section and the blank
+// line before it.
+func stripTeamSection(s string) string {
+	start := strings.Index(s, teamSectionStart)
+	if start < 0 {
+		return s
+	}
+	end := strings.Index(s[start:], teamSectionEnd)
+	if end < 0 {
+		return s
+	}
+	end += start + len(teamSectionEnd)
+	return strings.TrimRight(s[:start], "\n") + "\n" + strings.TrimLeft(s[end:], "\n")
+}
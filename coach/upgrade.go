@@ -0,0 +1,220 @@
+package coach
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mreider/agilemarkdown/git"
+)
+
+// TemplateState is where an installed template stands against the one
+// embedded in this binary.
+type TemplateState string
+
+const (
+	TemplateCurrent   TemplateState = "current"
+	TemplateEdited    TemplateState = "edited"              // local edits, upstream unchanged
+	TemplateOutOfDate TemplateState = "out of date"         // upstream changed, no local edits
+	TemplateDiverged  TemplateState = "edited, out of date" // both changed
+	TemplateUntracked TemplateState = "untracked"           // differs, and am cannot tell how
+	TemplateUpgraded  TemplateState = "upgraded"
+	TemplateMerged    TemplateState = "merged"
+	TemplateConflict  TemplateState = "conflict"
+)
+
+// TemplateStatus is one installed template. Conflicts counts the
+// conflict markers a merge left behind.
+type TemplateStatus struct {
+	Target    string
+	State     TemplateState
+	Conflicts int
+}
+
+// CheckTemplates reports every installed template against upstream
+// without changing any of them. It fills in LockFile for templates it
+// can place.
+func CheckTemplates(rootDir string) ([]TemplateStatus, error) {
+	return reconcile(rootDir, false)
+}
+
+// UpgradeTemplates brings installed templates up to date. A template
+// nobody edited is replaced; an edited one gets a three-way merge of
+// the upstream change into the local file, base from LockFile. A merge
+// that conflicts leaves git's markers in the file. Templates am cannot
+// place (no lock entry, and not in git as am installed them) are left
+// alone.
+func UpgradeTemplates(rootDir string) ([]TemplateStatus, error) {
+	return reconcile(rootDir, true)
+}
+
+func reconcile(rootDir string, apply bool) ([]TemplateStatus, error) {
+	lk, err := loadLock(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	blobs, err := git.NewBlobReader(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	defer blobs.Close()
+	var out []TemplateStatus
+	for _, t := range install {
+		if t.merge != nil {
+			continue
+		}
+		dst := filepath.Join(rootDir, t.target)
+		local, err := os.ReadFile(dst)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return out, err
+		}
+		upstream, err := fs.ReadFile(templatesFS, t.source)
+		if err != nil {
+			return out, err
+		}
+		st := TemplateStatus{Target: t.target}
+		upstreamID := git.BlobID(upstream)
+		base := baseVersion(rootDir, blobs, lk, t)
+		keep := base
+		switch {
+		case bytes.Equal(local, upstream):
+			st.State = TemplateCurrent
+			keep = upstream
+		case base == nil:
+			st.State = TemplateUntracked
+		case git.BlobID(base) == upstreamID:
+			st.State = TemplateEdited
+		case bytes.Equal(local, base):
+			st.State = TemplateOutOfDate
+			if apply {
+				if err := os.WriteFile(dst, upstream, t.mode); err != nil {
+					return out, err
+				}
+				st.State = TemplateUpgraded
+			}
+		default:
+			st.State = TemplateDiverged
+			if apply {
+				merged, conflicts, err := mergeTemplate(local, base, upstream)
+				if err != nil {
+					return out, fmt.Errorf("%s: %w", t.target, err)
+				}
+				if err := os.WriteFile(dst, merged, t.mode); err != nil {
+					return out, err
+				}
+				st.State, st.Conflicts = TemplateMerged, conflicts
+				if conflicts > 0 {
+					st.State = TemplateConflict
+				}
+			}
+		}
+		if st.State == TemplateUpgraded || st.State == TemplateMerged || st.State == TemplateConflict {
+			_ = git.Add(dst)
+			keep = upstream
+		}
+		if keep != nil {
+			if err := lk.record(rootDir, t, keep); err != nil {
+				return out, err
+			}
+		}
+		out = append(out, st)
+	}
+	return out, lk.save(rootDir)
+}
+
+// baseVersion is the upstream text t's target was installed or last
+// upgraded from: the copy the lock points at, or, for a template
+// installed before the lock existed, the version git saw added,
+// provided it is recognisably an agilemarkdown template. nil when
+// neither is there.
+func baseVersion(rootDir string, blobs *git.BlobReader, lk *lock, t installEntry) []byte {
+	if _, ok := lk.Templates[t.target]; ok {
+		return lk.base(rootDir, blobs, t)
+	}
+	id, err := git.FirstVersion(rootDir, t.target)
+	if err != nil || id == "" {
+		return nil
+	}
+	text, err := blobs.Read(id)
+	if err != nil || !strings.Contains(text, "agilemarkdown") {
+		return nil
+	}
+	return []byte(text)
+}
+
+// mergeTemplate runs git's three-way file merge: the team's file as
+// ours, upstream as theirs.
+func mergeTemplate(local, base, upstream []byte) ([]byte, int, error) {
+	dir, err := os.MkdirTemp("", "am-coach-merge")
+	if err != nil {
+		return nil, 0, err
+	}
+	defer os.RemoveAll(dir)
+	paths := map[string][]byte{"ours": local, "base": base, "theirs": upstream}
+	for name, data := range paths {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			return nil, 0, err
+		}
+	}
+	ours := filepath.Join(dir, "ours")
+	conflicts, err := git.MergeFile(ours, filepath.Join(dir, "base"), filepath.Join(dir, "theirs"))
+	if err != nil {
+		return nil, 0, err
+	}
+	merged, err := os.ReadFile(ours)
+	return merged, conflicts, err
+}
+
+// projections are the files that carry the coach body inline.
+var projections = []string{"AGENTS.md", ".github/copilot-instructions.md", ".cursor/rules/coach.mdc"}
+
+// StaleProjections lists the projections that no longer match the
+// canonical body at .claude/agilemarkdown-coach.md, and CLAUDE.md when
+// it does not import it. An edit to the canonical body has to be copied
+// to the others by hand; this is how am init spots one that was not.
+func StaleProjections(rootDir string) ([]string, error) {
+	canon, err := os.ReadFile(filepath.Join(rootDir, ".claude", "agilemarkdown-coach.md"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	want := projectionBody(string(canon))
+	var stale []string
+	if data, err := os.ReadFile(filepath.Join(rootDir, "CLAUDE.md")); err == nil && !strings.Contains(string(data), "@.claude/agilemarkdown-coach.md") {
+		stale = append(stale, "CLAUDE.md")
+	}
+	for _, p := range projections {
+		data, err := os.ReadFile(filepath.Join(rootDir, filepath.FromSlash(p)))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return stale, err
+		}
+		if projectionBody(string(data)) != want {
+			stale = append(stale, p)
+		}
+	}
+	return stale, nil
+}
+
+// projectionBody drops a Cursor frontmatter block and leading blank
+// lines, as tests/check-coach-projections.sh does, and the Team
+// ceremonies section am init keeps in AGENTS.md.
+func projectionBody(s string) string {
+	s = stripTeamSection(s)
+	if rest, ok := strings.CutPrefix(s, "---\n"); ok {
+		if i := strings.Index(rest, "\n---\n"); i >= 0 {
+			s = rest[i+len("\n---\n"):]
+		}
+	}
+	return strings.TrimLeft(s, "\n")
+}
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mreider/agilemarkdown/mcpserver"
+	"github.com/urfave/cli/v3"
+)
+
+// ActivityCommand prints the activity feed rebuilt from git history.
+// Equivalent to the `activity_feed` MCP tool.
+var ActivityCommand = &cli.Command{
+	Name:  "activity",
+	Usage: "Activity feed from git history: status changes, estimates, owners, comments, re-ranks (newest first)",
+	Flags: []cli.Flag{
+		&cli.StringFlag{Name: "since", Usage: "only activity on or after this date (YYYY-MM-DD)"},
+		&cli.StringFlag{Name: "user", Usage: "only changes committed by this user (name, email or nickname)"},
+		&cli.StringFlag{Name: "backlog", Usage: "only items in this backlog"},
+		&cli.IntFlag{Name: "limit", Value: 100, Usage: "max entries"},
+		&cli.BoolFlag{Name: "json", Usage: "emit the feed as JSON (machine-readable)"},
+	},
+	Action: func(ctx context.Context, c *cli.Command) error {
+		root, err := findRootDirectory()
+		if err != nil {
+			return err
+		}
+		res, err := mcpserver.ActivityFeed(ctx, root, mcpserver.ActivityFeedArgs{
+			Since:   c.String("since"),
+			User:    c.String("user"),
+			Backlog: c.String("backlog"),
+			Limit:   c.Int("limit"),
+		})
+		if err != nil {
+			return err
+		}
+		if c.Bool("json") {
+			return emitJSON(res)
+		}
+		if res.Count == 0 {
+			fmt.Println("(no activity)")
+			return nil
+		}
+		day := ""
+		for _, e := range res.Entries {
+			local := e.When.Local()
+			if d := local.Format("Mon Jan 2, 2006"); d != day {
+				if day != "" {
+					fmt.Println()
+				}
+				fmt.Println(d)
+				day = d
+			}
+			fmt.Printf("  %s  %s\n", local.Format("15:04"), e.Text)
+		}
+		return nil
+	},
+}
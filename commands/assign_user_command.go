@@ -29,13 +29,10 @@ var AssignUserCommand = &cli.Command{
 	},
 	Action: func(ctx context.Context, c *cli.Command) error {
 		if c.NArg() >= 2 {
-			path, err := filepath.Abs(c.Args().Get(0))
+			path, err := itemPathFromArg(c.Args().Get(0))
 			if err != nil {
 				return err
 			}
-			if !strings.HasSuffix(path, ".md") {
-				path += ".md"
-			}
 			users := append([]string(nil), c.Args().Slice()[1:]...)
 			if len(users) > 3 {
 				return fmt.Errorf("at most 3 assignees allowed; got %d", len(users))
@@ -3,8 +3,8 @@ package commands
 import (
 	"context"
 	"fmt"
+	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/mreider/agilemarkdown/backlog"
 	"github.com/mreider/agilemarkdown/utils"
@@ -13,27 +13,28 @@ import (
 
 var BlockCommand = &cli.Command{
 	Name:      "block",
-	Usage:     "Mark a story as blocked, optionally with a reason",
+	Usage:     "Mark a story as blocked, optionally with a reason, or record the stories it waits on with --by",
 	ArgsUsage: "ITEM_PATH",
 	Flags: []cli.Flag{
 		&cli.StringFlag{Name: "reason", Usage: "blocker reason; stored in `blocked_reason:` frontmatter"},
+		&cli.StringSliceFlag{Name: "by", Usage: "path of a story this one waits on (repeatable); stored in `blocked_by:` and cleared when that story is accepted"},
 	},
 	Action: func(ctx context.Context, c *cli.Command) error {
 		if c.NArg() != 1 {
 			fmt.Println("path to an item file is required")
 			return nil
 		}
-		path, err := filepath.Abs(c.Args().Get(0))
+		path, err := itemPathFromArg(c.Args().Get(0))
 		if err != nil {
 			return err
 		}
-		if !strings.HasSuffix(path, ".md") {
-			path += ".md"
-		}
 		item, err := backlog.LoadBacklogItem(path)
 		if err != nil {
 			return err
 		}
+		if by := c.StringSlice("by"); len(by) > 0 {
+			return changeBlockers(item, by, backlog.AddBlocker, "blocked by")
+		}
 		item.SetBlocked(true, c.String("reason"))
 		item.SetModified(utils.GetCurrentTimestamp())
 		if err := item.Save(); err != nil {
@@ -46,24 +47,27 @@ var BlockCommand = &cli.Command{
 
 var UnblockCommand = &cli.Command{
 	Name:      "unblock",
-	Usage:     "Clear a story's blocked flag",
+	Usage:     "Clear a story's blocked flag, or with --by drop the listed dependencies",
 	ArgsUsage: "ITEM_PATH",
+	Flags: []cli.Flag{
+		&cli.StringSliceFlag{Name: "by", Usage: "path of a story to remove from `blocked_by:` (repeatable)"},
+	},
 	Action: func(ctx context.Context, c *cli.Command) error {
 		if c.NArg() != 1 {
 			fmt.Println("path to an item file is required")
 			return nil
 		}
-		path, err := filepath.Abs(c.Args().Get(0))
+		path, err := itemPathFromArg(c.Args().Get(0))
 		if err != nil {
 			return err
 		}
-		if !strings.HasSuffix(path, ".md") {
-			path += ".md"
-		}
 		item, err := backlog.LoadBacklogItem(path)
 		if err != nil {
 			return err
 		}
+		if by := c.StringSlice("by"); len(by) > 0 {
+			return changeBlockers(item, by, backlog.RemoveBlocker, "no longer blocked by")
+		}
 		item.SetBlocked(false, "")
 		item.SetModified(utils.GetCurrentTimestamp())
 		if err := item.Save(); err != nil {
@@ -73,3 +77,27 @@ var UnblockCommand = &cli.Command{
 		return nil
 	},
 }
+
+// changeBlockers applies fn (AddBlocker or RemoveBlocker) for each --by
+// path. Paths may be relative to the current directory or to the root.
+func changeBlockers(item *backlog.BacklogItem, refs []string, fn func(*backlog.BacklogsStructure, *backlog.BacklogItem, string) error, verb string) error {
+	root, err := findRootDirectory()
+	if err != nil {
+		return err
+	}
+	structure := backlog.NewBacklogsStructure(root)
+	for _, ref := range refs {
+		if abs, err := itemPathFromArg(ref); err == nil {
+			if _, err := os.Stat(abs); err == nil {
+				if rel, err := filepath.Rel(root, abs); err == nil {
+					ref = filepath.ToSlash(rel)
+				}
+			}
+		}
+		if err := fn(structure, item, ref); err != nil {
+			return err
+		}
+		fmt.Printf("%s -> %s %s\n", filepath.Base(item.Path()), verb, ref)
+	}
+	return nil
+}
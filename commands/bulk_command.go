@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mreider/agilemarkdown/mcpserver"
+	"github.com/urfave/cli/v3"
+)
+
+// BulkCommand applies one set of edits to every item a search query
+// matches, all-or-nothing.
+var BulkCommand = &cli.Command{
+	Name:  "bulk",
+	Usage: "Edit every story matching a search query at once (all-or-nothing, coach-checked)",
+	Flags: []cli.Flag{
+		&cli.StringFlag{Name: "where", Usage: "search query selecting the stories, e.g. 'tag:auth status:unstarted'", Required: true},
+		&cli.StringSliceFlag{Name: "set", Usage: "FIELD=VALUE for estimate, status, type, owner or epic (repeatable); VALUE none clears"},
+		&cli.StringSliceFlag{Name: "add-tag", Usage: "tag to add (repeatable)"},
+		&cli.StringSliceFlag{Name: "remove-tag", Usage: "tag to remove (repeatable)"},
+		&cli.StringFlag{Name: "set-epic", Usage: "epic slug to attach (same as --set epic=SLUG)"},
+		&cli.StringFlag{Name: "move", Usage: "icebox or priority; moved stories go to the bottom"},
+		&cli.BoolFlag{Name: "archived", Usage: "include archived stories in the match"},
+		&cli.BoolFlag{Name: "dry-run", Usage: "show the changes without writing"},
+		&cli.BoolFlag{Name: "json", Usage: "emit the result as JSON (machine-readable)"},
+	},
+	Action: func(ctx context.Context, c *cli.Command) error {
+		root, err := findRootDirectory()
+		if err != nil {
+			return err
+		}
+		set := map[string]string{}
+		for _, kv := range c.StringSlice("set") {
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok {
+				return fmt.Errorf("--set wants FIELD=VALUE, got %q", kv)
+			}
+			set[k] = v
+		}
+		if c.IsSet("set-epic") {
+			set["epic"] = c.String("set-epic")
+		}
+		res, err := mcpserver.BulkUpdate(ctx, root, mcpserver.BulkUpdateArgs{
+			Where:      c.String("where"),
+			Set:        set,
+			AddTags:    c.StringSlice("add-tag"),
+			RemoveTags: c.StringSlice("remove-tag"),
+			Move:       c.String("move"),
+			Archived:   c.Bool("archived"),
+			DryRun:     c.Bool("dry-run"),
+		})
+		if err != nil {
+			return err
+		}
+		if c.Bool("json") {
+			return emitJSON(res)
+		}
+		for _, it := range res.Items {
+			id := ""
+			if it.ID > 0 {
+				id = fmt.Sprintf(" #%d", it.ID)
+			}
+			fmt.Printf("%s%s %s\n", it.Path, id, it.Title)
+			for _, ch := range it.Changes {
+				fmt.Printf("  %s: %s -> %s\n", ch.Field, orNone(ch.From), orNone(ch.To))
+			}
+			for _, v := range it.Coach {
+				label := "refused"
+				if v.Allowed {
+					label = "nudge"
+				}
+				fmt.Printf("  %s: %s (%s)\n", label, v.Rule, v.Next)
+			}
+		}
+		fmt.Printf("%d of %d matching stories change.", res.Changed, res.Matched)
+		switch {
+		case res.Refused > 0:
+			fmt.Println()
+			return errors.New("coach refused some changes; nothing written")
+		case res.Applied:
+			fmt.Println(" Written.")
+		case res.DryRun && res.Changed > 0:
+			fmt.Println(" Dry run; nothing written.")
+		default:
+			fmt.Println()
+		}
+		return nil
+	},
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
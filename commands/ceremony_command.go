@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mreider/agilemarkdown/coach"
+	"github.com/mreider/agilemarkdown/mcpserver"
+	"github.com/urfave/cli/v3"
+)
+
+// CeremonyCommand prints a ceremony's script: one of the six built-in
+// coach skills, or one the team wrote in .am/skills/<name>/SKILL.md,
+// with its live backlog data filled in. It is the same text the MCP
+// prompt of that name returns. With no name it lists them.
+var CeremonyCommand = &cli.Command{
+	Name:      "ceremony",
+	Usage:     "Print a coach or team ceremony's script with live backlog data filled in",
+	ArgsUsage: "[NAME [ARG=VALUE ...]]",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{Name: "json", Usage: "emit the result as JSON (machine-readable)"},
+	},
+	Action: func(ctx context.Context, c *cli.Command) error {
+		root, err := findRootDirectory()
+		if err != nil {
+			return err
+		}
+		if c.NArg() == 0 {
+			return listCeremonies(root)
+		}
+		args := map[string]string{}
+		for _, pair := range c.Args().Slice()[1:] {
+			k, v, ok := strings.Cut(pair, "=")
+			if !ok || k == "" {
+				return fmt.Errorf("usage: am ceremony NAME [ARG=VALUE ...]; got %q", pair)
+			}
+			args[k] = v
+		}
+		res, err := mcpserver.Ceremony(ctx, root, mcpserver.CeremonyArgs{Name: c.Args().First(), Arguments: args})
+		if err != nil {
+			return err
+		}
+		if c.Bool("json") {
+			return emitJSON(res)
+		}
+		fmt.Print(res.Text)
+		return nil
+	},
+}
+
+func listCeremonies(root string) error {
+	builtins, err := coach.Skills()
+	if err != nil {
+		return err
+	}
+	team, err := coach.TeamSkills(root)
+	for _, group := range []struct {
+		heading string
+		skills  []coach.Skill
+	}{{"coach", builtins}, {"team (" + coach.TeamSkillsDir + ")", team}} {
+		if len(group.skills) == 0 {
+			continue
+		}
+		fmt.Printf("%s:\n", group.heading)
+		for _, s := range group.skills {
+			var names []string
+			for _, a := range s.Arguments {
+				names = append(names, a.Name+"=")
+			}
+			summary, _, _ := strings.Cut(strings.Join(strings.Fields(s.Description), " "), ". ")
+			fmt.Printf("  %-24s %s\n", strings.TrimSpace(s.Name+" "+strings.Join(names, " ")), summary)
+		}
+	}
+	return err
+}
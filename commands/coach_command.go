@@ -10,6 +10,7 @@ import (
 
 	"github.com/mreider/agilemarkdown/backlog"
 	"github.com/mreider/agilemarkdown/config"
+	"github.com/mreider/agilemarkdown/mcpserver"
 	"github.com/urfave/cli/v3"
 )
 
@@ -28,13 +29,10 @@ var AcceptancePromptCommand = &cli.Command{
 		if err != nil {
 			return err
 		}
-		path, err := filepath.Abs(c.Args().Get(0))
+		path, err := itemPathFromArg(c.Args().Get(0))
 		if err != nil {
 			return err
 		}
-		if !strings.HasSuffix(path, ".md") {
-			path += ".md"
-		}
 		rel, _ := filepath.Rel(root, path)
 		item, err := backlog.LoadBacklogItem(path)
 		if err != nil {
@@ -74,9 +72,13 @@ var AcceptancePromptCommand = &cli.Command{
 // or via the `--action` flag (`am coach-check --action set_status`).
 // Both forms are equivalent; the flag form matches the natural reading
 // of the coach doc, the positional form matches typical CLI shape.
+//
+// With --override --reason it mirrors coach_override instead: the
+// refusal or nudge is recorded in `.am/coach-log.jsonl` and the same
+// check passes for the next hour.
 var CoachCheckCommand = &cli.Command{
 	Name:      "coach-check",
-	Usage:     "Preflight an action against Pivotal canon (set_status / set_estimate / create_item)",
+	Usage:     "Preflight an action against Pivotal canon and .am/coach.yaml (set_status / set_estimate / create_item / pull)",
 	ArgsUsage: "ACTION",
 	Flags: []cli.Flag{
 		&cli.StringFlag{Name: "action", Usage: "action to preflight (synonym for the positional ACTION argument)"},
@@ -84,6 +86,8 @@ var CoachCheckCommand = &cli.Command{
 		&cli.StringFlag{Name: "status", Usage: "target status (with set_status)"},
 		&cli.StringFlag{Name: "estimate", Usage: "target estimate (with set_estimate or create_item)"},
 		&cli.StringFlag{Name: "type", Usage: "story type (with create_item)"},
+		&cli.BoolFlag{Name: "override", Usage: "go ahead despite a refusal or nudge; records who, which rule and why in .am/coach-log.jsonl"},
+		&cli.StringFlag{Name: "reason", Usage: "why the team is overriding the coach (required with --override)"},
 	},
 	Action: func(ctx context.Context, c *cli.Command) error {
 		action := strings.TrimSpace(c.String("action"))
@@ -91,9 +95,21 @@ var CoachCheckCommand = &cli.Command{
 			action = c.Args().Get(0)
 		}
 		if action == "" {
-			return fmt.Errorf("usage: am coach-check ACTION [--path P] [--status S] [--estimate N] [--type T]\n   or: am coach-check --action ACTION [--path P] ...")
+			return fmt.Errorf("usage: am coach-check ACTION [--path P] [--status S] [--estimate N] [--type T] [--override --reason R]\n   or: am coach-check --action ACTION [--path P] ...")
 		}
-		verdict, err := runCoachCheck(action, c.String("path"), c.String("status"), c.String("estimate"), c.String("type"))
+		if c.Bool("override") {
+			res, err := runCoachOverride(ctx, action, c.String("path"), c.String("status"), c.String("estimate"), c.String("type"), c.String("reason"))
+			if err != nil {
+				return err
+			}
+			b, _ := json.MarshalIndent(res, "", "  ")
+			fmt.Println(string(b))
+			return nil
+		}
+		if c.String("reason") != "" {
+			return fmt.Errorf("--reason goes with --override")
+		}
+		verdict, err := runCoachCheck(ctx, action, c.String("path"), c.String("status"), c.String("estimate"), c.String("type"))
 		if err != nil {
 			return err
 		}
@@ -152,91 +168,52 @@ var IterationFitCommand = &cli.Command{
 	},
 }
 
-type coachVerdict struct {
-	Allowed bool   `json:"allowed"`
-	Rule    string `json:"rule,omitempty"`
-	Source  string `json:"source,omitempty"`
-	Next    string `json:"next,omitempty"`
-	Nudge   bool   `json:"nudge,omitempty"`
-	Detail  string `json:"detail,omitempty"`
+// runCoachCheck runs the same rules as the coach_check MCP tool, so
+// `.am/coach.yaml` tunes both.
+func runCoachCheck(ctx context.Context, action, path, status, estimate, typ string) (mcpserver.CoachVerdict, error) {
+	root, path, err := coachRootAndPath(path)
+	if err != nil {
+		return mcpserver.CoachVerdict{}, err
+	}
+	return mcpserver.CoachCheck(ctx, root, mcpserver.CoachCheckArgs{
+		Action:   action,
+		Path:     path,
+		Status:   status,
+		Estimate: estimate,
+		Type:     typ,
+	})
 }
 
-func runCoachCheck(action, path, status, estimate, typ string) (coachVerdict, error) {
-	// Light duplication of the server-side logic so the CLI doesn't
-	// require an MCP session. Keep behavior in lock-step with
-	// mcpserver/coach.go.
-	switch strings.ToLower(strings.TrimSpace(action)) {
-	case "set_status":
-		if path == "" {
-			return coachVerdict{}, fmt.Errorf("path is required")
-		}
-		if strings.EqualFold(status, "accepted") {
-			return coachVerdict{
-				Allowed: false,
-				Rule:    "the dev pair does not accept its own work",
-				Source:  "coach-refuses-pm-accepts",
-				Next:    fmt.Sprintf("render PM ceremony with `am accept-prompt %s`", path),
-			}, nil
-		}
-	case "set_estimate":
-		// Light client-side preflight; full logic is server-side. Just
-		// catch the 8-pt cap and report. CLI users wanting full canon
-		// should call the MCP tool.
-		if estimate == "" {
-			return coachVerdict{Allowed: true}, nil
-		}
-		var pts float64
-		fmt.Sscanf(estimate, "%f", &pts)
-		if pts > 8 {
-			return coachVerdict{
-				Allowed: false,
-				Rule:    "features over 8 points are epics",
-				Source:  "8-point-hard-cap",
-				Next:    "split the story",
-			}, nil
-		}
-	case "create_item":
-		if estimate != "" {
-			var pts float64
-			fmt.Sscanf(estimate, "%f", &pts)
-			if pts > 8 && (typ == "" || typ == "feature") {
-				return coachVerdict{
-					Allowed: false,
-					Rule:    "features over 8 points are epics",
-					Source:  "8-point-hard-cap",
-					Next:    "split the story before creating it",
-				}, nil
-			}
-		}
-	case "pull":
-		if path == "" {
-			return coachVerdict{}, fmt.Errorf("path is required")
-		}
-		abs, err := filepath.Abs(path)
-		if err != nil {
-			return coachVerdict{}, err
-		}
-		if !strings.HasSuffix(abs, ".md") {
-			abs += ".md"
-		}
-		item, err := backlog.LoadBacklogItem(abs)
-		if err != nil {
-			return coachVerdict{}, err
-		}
-		itemType := item.Type()
-		if itemType == "" {
-			itemType = "feature"
-		}
-		if itemType == "feature" && backlog.AcceptanceBulletTexts(item.Body()) == nil {
-			return coachVerdict{
-				Allowed: false,
-				Rule:    "feature has no acceptance criteria",
-				Source:  "acceptance-before-pull",
-				Next:    fmt.Sprintf("draft a `## Acceptance` section in %s, or run `am align %s` before pulling", filepath.Base(path), filepath.Base(path)),
-			}, nil
-		}
+// runCoachOverride mirrors the coach_override MCP tool.
+func runCoachOverride(ctx context.Context, action, path, status, estimate, typ, reason string) (mcpserver.CoachOverrideResult, error) {
+	root, path, err := coachRootAndPath(path)
+	if err != nil {
+		return mcpserver.CoachOverrideResult{}, err
+	}
+	return mcpserver.CoachOverride(ctx, root, mcpserver.CoachOverrideArgs{
+		Action:   action,
+		Path:     path,
+		Status:   status,
+		Estimate: estimate,
+		Type:     typ,
+		Reason:   reason,
+	})
+}
+
+// coachRootAndPath takes an item path relative to the working directory,
+// like every other command, and returns it relative to the project root
+// for the coach.
+func coachRootAndPath(path string) (string, string, error) {
+	root, err := findRootDirectory()
+	if err != nil || path == "" {
+		return root, path, err
+	}
+	abs, err := itemPathFromArg(path)
+	if err != nil {
+		return "", "", err
 	}
-	return coachVerdict{Allowed: true}, nil
+	rel, err := filepath.Rel(root, abs)
+	return root, rel, err
 }
 
 func iterationFitNumbers(rootDir, backlogDir, candidate string) (velocity, planned float64, counted int, err error) {
@@ -23,13 +23,10 @@ var CommentCommand = &cli.Command{
 			fmt.Println("usage: am comment ITEM_PATH TEXT")
 			return nil
 		}
-		path, err := filepath.Abs(c.Args().Get(0))
+		path, err := itemPathFromArg(c.Args().Get(0))
 		if err != nil {
 			return err
 		}
-		if !strings.HasSuffix(path, ".md") {
-			path += ".md"
-		}
 		text := strings.TrimSpace(strings.Join(c.Args().Tail(), " "))
 		if text == "" {
 			fmt.Println("comment text is required")
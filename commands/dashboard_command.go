@@ -82,6 +82,12 @@ var DashboardCommand = &cli.Command{
 		fmt.Printf("  cycle time:      %s\n", formatHoursDur(median))
 		fmt.Printf("  rejection rate:  %.0f%% (latest)\n", latest)
 		fmt.Printf("  accepted total:  %d stories\n", acceptedCount)
+		if num, outcome, ok, err := iterationPlanOutcome(root, dirs, cfg, now); err != nil {
+			return err
+		} else if ok {
+			fmt.Printf("  iteration %d:    planned %.0f, accepted %.0f, remaining %.0f, scope added %.0f pts\n",
+				num, outcome.Planned, outcome.Accepted, outcome.CarryOver, outcome.ScopeAdded)
+		}
 		return nil
 	},
 }
@@ -101,6 +107,10 @@ var NextCommand = &cli.Command{
 		if err != nil {
 			return err
 		}
+		deps, err := backlog.LoadDependencyGraph(structure)
+		if err != nil {
+			return err
+		}
 		for _, d := range dirs {
 			pri, err := backlog.LoadPriority(d)
 			if err != nil {
@@ -119,7 +129,7 @@ var NextCommand = &cli.Command{
 				if !ok {
 					continue
 				}
-				if it.Blocked() {
+				if it.Blocked() || len(deps.OpenBlockers(it)) > 0 {
 					continue
 				}
 				if !strings.EqualFold(it.Status(), backlog.UnstartedStatus.Name) {
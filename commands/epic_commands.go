@@ -0,0 +1,148 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/mcpserver"
+	"github.com/urfave/cli/v3"
+)
+
+// Subcommands of `am epic`. The bare `am epic ITEM_PATH SLUG` form that
+// tags an item is still handled by EpicCommand's own action.
+var epicSubcommands = []*cli.Command{
+	epicCreateCmd,
+	epicListCmd,
+	epicShowCmd,
+	epicCloseCmd,
+}
+
+var epicCreateCmd = &cli.Command{
+	Name:      "create",
+	Usage:     "Create epics/SLUG.md with a title, description, owner, target release and labels",
+	ArgsUsage: "SLUG [TITLE]",
+	Flags: []cli.Flag{
+		&cli.StringFlag{Name: "description", Usage: "epic description (markdown)"},
+		&cli.StringFlag{Name: "owner", Usage: "owner of the epic"},
+		&cli.StringFlag{Name: "release", Usage: "slug of the target release marker"},
+		&cli.StringSliceFlag{Name: "label", Usage: "label (repeatable)"},
+		&cli.BoolFlag{Name: "json", Usage: "emit the created epic as JSON (machine-readable)"},
+	},
+	Action: func(ctx context.Context, c *cli.Command) error {
+		if c.NArg() < 1 {
+			return fmt.Errorf("usage: am epic create SLUG [TITLE]")
+		}
+		root, err := findRootDirectory()
+		if err != nil {
+			return err
+		}
+		row, err := mcpserver.CreateEpic(ctx, root, mcpserver.CreateEpicArgs{
+			Slug:        c.Args().Get(0),
+			Title:       strings.Join(c.Args().Slice()[1:], " "),
+			Description: c.String("description"),
+			Owner:       c.String("owner"),
+			Release:     c.String("release"),
+			Labels:      c.StringSlice("label"),
+		})
+		if err != nil {
+			return err
+		}
+		if c.Bool("json") {
+			return emitJSON(row)
+		}
+		fmt.Printf("created %s\n", row.Path)
+		return nil
+	},
+}
+
+var epicListCmd = &cli.Command{
+	Name:  "list",
+	Usage: "List epics with status and remaining points",
+	Flags: []cli.Flag{
+		&cli.StringFlag{Name: "status", Usage: "only open or closed epics"},
+		&cli.BoolFlag{Name: "json", Usage: "emit the epics as JSON (machine-readable)"},
+	},
+	Action: func(ctx context.Context, c *cli.Command) error {
+		root, err := findRootDirectory()
+		if err != nil {
+			return err
+		}
+		res, err := mcpserver.ListEpics(ctx, root, mcpserver.ListEpicsArgs{Status: c.String("status")})
+		if err != nil {
+			return err
+		}
+		if c.Bool("json") {
+			return emitJSON(res)
+		}
+		if res.Count == 0 {
+			fmt.Println("No epics. Create one with `am epic create SLUG TITLE`.")
+			return nil
+		}
+		for _, e := range res.Epics {
+			fmt.Printf("  %-20s  %-6s  %3d/%-3d stories  %4.0f pts left  %s\n",
+				e.Slug, e.Status, e.AcceptedStories, e.Stories, e.RemainingPoints, e.Title)
+		}
+		return nil
+	},
+}
+
+var epicShowCmd = &cli.Command{
+	Name:      "show",
+	Usage:     "Show an epic: metadata, progress and member stories",
+	ArgsUsage: "SLUG",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{Name: "json", Usage: "emit the epic as JSON (machine-readable)"},
+	},
+	Action: func(ctx context.Context, c *cli.Command) error {
+		if c.NArg() != 1 {
+			return fmt.Errorf("usage: am epic show SLUG")
+		}
+		root, err := findRootDirectory()
+		if err != nil {
+			return err
+		}
+		slug := c.Args().Get(0)
+		if _, err := backlog.FindEpic(backlog.NewBacklogsStructure(root), slug); err != nil {
+			return err
+		}
+		if c.Bool("json") {
+			res, err := mcpserver.EpicProgress(ctx, root, mcpserver.EpicProgressArgs{Slug: slug})
+			if err != nil {
+				return err
+			}
+			return emitJSON(res)
+		}
+		text, err := backlog.EpicASCII(root, slug)
+		if err != nil {
+			return err
+		}
+		fmt.Print(text)
+		return nil
+	},
+}
+
+var epicCloseCmd = &cli.Command{
+	Name:      "close",
+	Usage:     "Mark an epic closed once its stories are accepted",
+	ArgsUsage: "SLUG",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{Name: "force", Usage: "close even though some stories are not accepted"},
+	},
+	Action: func(ctx context.Context, c *cli.Command) error {
+		if c.NArg() != 1 {
+			return fmt.Errorf("usage: am epic close SLUG")
+		}
+		root, err := findRootDirectory()
+		if err != nil {
+			return err
+		}
+		row, err := mcpserver.CloseEpic(ctx, root, mcpserver.CloseEpicArgs{Slug: c.Args().Get(0), Force: c.Bool("force")})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s -> %s\n", row.Slug, row.Status)
+		return nil
+	},
+}
@@ -0,0 +1,30 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/mreider/agilemarkdown/actions"
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/urfave/cli/v3"
+)
+
+// ExportCommand dumps every backlog (or the ones named with --backlog) in
+// rank order. csv and json carry everything `am import --format pivotal`
+// needs to rebuild the backlog; pivotal-csv is the plain Tracker layout.
+var ExportCommand = &cli.Command{
+	Name:  "export",
+	Usage: "Export backlogs as csv, json or pivotal-csv (re-importable with `am import --format pivotal`)",
+	Flags: []cli.Flag{
+		&cli.StringFlag{Name: "format", Value: backlog.ExportFormatCsv, Usage: "csv, json or pivotal-csv"},
+		&cli.StringSliceFlag{Name: "backlog", Usage: "limit the export to this backlog (repeatable)"},
+		&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Usage: "write to this file instead of stdout"},
+	},
+	Action: func(ctx context.Context, c *cli.Command) error {
+		root, err := findRootDirectory()
+		if err != nil {
+			return err
+		}
+		action := actions.NewExportAction(root, c.String("format"), c.StringSlice("backlog"), c.String("output"))
+		return action.Execute()
+	},
+}
@@ -12,16 +12,13 @@ import (
 	"github.com/urfave/cli/v3"
 )
 
-// loadItemFromArg resolves an item path argument (relative or absolute,
-// .md optional) into a loaded BacklogItem.
+// loadItemFromArg resolves an item argument (a path, .md optional, or
+// "#1234") into a loaded BacklogItem.
 func loadItemFromArg(arg string) (*backlog.BacklogItem, error) {
 	if arg == "" {
 		return nil, fmt.Errorf("item path required")
 	}
-	if !strings.HasSuffix(arg, ".md") {
-		arg += ".md"
-	}
-	abs, err := filepath.Abs(arg)
+	abs, err := itemPathFromArg(arg)
 	if err != nil {
 		return nil, err
 	}
@@ -140,16 +137,18 @@ var TagCommand = &cli.Command{
 }
 
 // EpicCommand sets the epic slug on an item, or clears it with --unset.
+// Its subcommands (create, list, show, close) manage epics/<slug>.md.
 var EpicCommand = &cli.Command{
 	Name:      "epic",
-	Usage:     "Set the epic slug on an item, or --unset to clear",
+	Usage:     "Set the epic slug on an item (--unset to clear), or create|list|show|close epics",
 	ArgsUsage: "ITEM_PATH [SLUG]",
 	Flags: []cli.Flag{
 		&cli.BoolFlag{Name: "unset", Usage: "clear the epic field"},
 	},
+	Commands: epicSubcommands,
 	Action: func(ctx context.Context, c *cli.Command) error {
 		if c.NArg() < 1 {
-			return fmt.Errorf("usage: am epic ITEM_PATH SLUG  OR  am epic ITEM_PATH --unset")
+			return fmt.Errorf("usage: am epic ITEM_PATH SLUG  OR  am epic ITEM_PATH --unset  OR  am epic create|list|show|close")
 		}
 		item, err := loadItemFromArg(c.Args().Get(0))
 		if err != nil {
@@ -161,6 +160,13 @@ var EpicCommand = &cli.Command{
 			if c.NArg() != 2 {
 				return fmt.Errorf("usage: am epic ITEM_PATH SLUG")
 			}
+			root, err := findRootDirectory()
+			if err != nil {
+				return err
+			}
+			if err := backlog.CheckEpicRef(backlog.NewBacklogsStructure(root), c.Args().Get(1)); err != nil {
+				return err
+			}
 			item.SetEpic(c.Args().Get(1))
 		}
 		item.SetModified(utils.GetCurrentTimestamp())
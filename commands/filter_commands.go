@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mreider/agilemarkdown/mcpserver"
+	"github.com/urfave/cli/v3"
+)
+
+// FilterCommand runs the saved searches in .am/filters.yaml. Sync
+// renders the same filters as filters/<name>.md pages.
+var FilterCommand = &cli.Command{
+	Name:  "filter",
+	Usage: "List or run saved searches from .am/filters.yaml",
+	Commands: []*cli.Command{
+		filterListCmd,
+		filterRunCmd,
+	},
+}
+
+var filterListCmd = &cli.Command{
+	Name:  "list",
+	Usage: "List saved filters and their queries",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{Name: "json", Usage: "emit the filters as JSON (machine-readable)"},
+	},
+	Action: func(ctx context.Context, c *cli.Command) error {
+		root, err := findRootDirectory()
+		if err != nil {
+			return err
+		}
+		res, err := mcpserver.ListFilters(ctx, root, mcpserver.ListFiltersArgs{})
+		if err != nil {
+			return err
+		}
+		if c.Bool("json") {
+			return emitJSON(res)
+		}
+		for _, f := range res.Filters {
+			fmt.Printf("%-24s %s\n", f.Name, f.Query)
+		}
+		return nil
+	},
+}
+
+var filterRunCmd = &cli.Command{
+	Name:      "run",
+	Usage:     "Run a saved filter; owner:me and author:me mean the current git user",
+	ArgsUsage: "NAME",
+	Flags: []cli.Flag{
+		&cli.IntFlag{Name: "limit", Usage: "max hits to return (default all)"},
+		&cli.BoolFlag{Name: "json", Usage: "emit the hits as JSON (machine-readable)"},
+	},
+	Action: func(ctx context.Context, c *cli.Command) error {
+		if c.NArg() < 1 {
+			return fmt.Errorf("usage: am filter run NAME")
+		}
+		root, err := findRootDirectory()
+		if err != nil {
+			return err
+		}
+		res, err := mcpserver.RunFilter(ctx, root, mcpserver.RunFilterArgs{
+			Name:  strings.Join(c.Args().Slice(), " "),
+			Limit: c.Int("limit"),
+		})
+		if err != nil {
+			return err
+		}
+		if c.Bool("json") {
+			return emitJSON(res)
+		}
+		fmt.Printf("%s  (%s)\n", res.Name, res.Query)
+		if res.Count == 0 {
+			fmt.Println("  nothing matches")
+		}
+		for _, h := range res.Hits {
+			id := ""
+			if h.ID > 0 {
+				id = fmt.Sprintf("#%d ", h.ID)
+			}
+			fmt.Printf("  %s%s [%s]  %s\n", id, h.Title, h.Status, h.Path)
+		}
+		return nil
+	},
+}
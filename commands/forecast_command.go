@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/config"
+	"github.com/mreider/agilemarkdown/mcpserver"
+	"github.com/urfave/cli/v3"
+)
+
+// ForecastCommand runs a Monte Carlo delivery forecast for an epic or
+// for everything ranked down to an item. Equivalent to the `forecast`
+// MCP tool.
+var ForecastCommand = &cli.Command{
+	Name:  "forecast",
+	Usage: "Monte Carlo delivery forecast: 50/85/95% completion dates for an epic or a priority cut-off",
+	Flags: []cli.Flag{
+		&cli.StringFlag{Name: "epic", Usage: "forecast every open story with this epic slug"},
+		&cli.StringFlag{Name: "until", Usage: "forecast everything ranked at or above this item in _priority.md"},
+		&cli.IntFlag{Name: "trials", Value: backlog.DefaultForecastTrials, Usage: "number of simulated futures"},
+		&cli.Uint64Flag{Name: "seed", Value: backlog.DefaultForecastSeed, Usage: "random seed (same seed, same answer)"},
+		&cli.BoolFlag{Name: "json", Usage: "emit the forecast as JSON (machine-readable)"},
+	},
+	Action: func(ctx context.Context, c *cli.Command) error {
+		epic, until := c.String("epic"), c.String("until")
+		if (epic == "") == (until == "") {
+			return fmt.Errorf("usage: am forecast --epic SLUG | --until PATH")
+		}
+		root, err := findRootDirectory()
+		if err != nil {
+			return err
+		}
+		if until != "" {
+			// Accept "#1234" or a path relative to the current directory
+			// (e.g. from inside a backlog) as well as one relative to the root.
+			if abs, err := itemPathFromArg(until); err == nil {
+				if _, err := os.Stat(abs); err == nil {
+					if rel, err := filepath.Rel(root, abs); err == nil {
+						until = rel
+					}
+				}
+			}
+		}
+		if c.Bool("json") {
+			res, err := mcpserver.Forecast(ctx, root, mcpserver.ForecastArgs{
+				Epic:   epic,
+				Until:  until,
+				Trials: c.Int("trials"),
+				Seed:   c.Uint64("seed"),
+			})
+			if err != nil {
+				return err
+			}
+			return emitJSON(res)
+		}
+		cfg, err := config.LoadConfig(filepath.Join(root, ".am", "config.yaml"))
+		if err != nil {
+			return err
+		}
+		now := time.Now().In(cfg.IterationLocation())
+		opts := backlog.DeliveryForecastOptions{Trials: c.Int("trials"), Seed: c.Uint64("seed")}
+		structure := backlog.NewBacklogsStructure(root)
+		var f *backlog.DeliveryForecast
+		if epic != "" {
+			f, err = backlog.ForecastEpic(structure, cfg, now, epic, opts)
+		} else {
+			f, err = backlog.ForecastUntil(structure, cfg, now, until, opts)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Print(backlog.DeliveryForecastASCII(f))
+		return nil
+	},
+}
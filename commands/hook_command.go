@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mreider/agilemarkdown/mcpserver"
+	"github.com/urfave/cli/v3"
+)
+
+// HookCommand groups the agent-host hooks `am init` wires up. Today that
+// is the Claude Code PreToolUse gate in `.claude/settings.json`:
+//
+//	{"matcher": "mcp__agilemarkdown__.*",
+//	 "hooks": [{"type": "command", "command": "am hook pre-tool-use"}]}
+var HookCommand = &cli.Command{
+	Name:   "hook",
+	Usage:  "Agent-host hooks (installed by am init)",
+	Hidden: true,
+	Commands: []*cli.Command{
+		{
+			Name:   "pre-tool-use",
+			Usage:  "Claude Code PreToolUse gate: reads the hook envelope on stdin, answers with the coach's decision",
+			Action: hookPreToolUseAction,
+		},
+	},
+}
+
+// hookEnvelope is the part of Claude Code's hook input the gate reads.
+type hookEnvelope struct {
+	HookEventName string          `json:"hook_event_name"`
+	Cwd           string          `json:"cwd"`
+	ToolName      string          `json:"tool_name"`
+	ToolInput     json.RawMessage `json:"tool_input"`
+}
+
+type hookOutput struct {
+	HookSpecificOutput hookDecision `json:"hookSpecificOutput"`
+}
+
+type hookDecision struct {
+	HookEventName            string `json:"hookEventName"`
+	PermissionDecision       string `json:"permissionDecision"`
+	PermissionDecisionReason string `json:"permissionDecisionReason"`
+}
+
+// hookPreToolUseAction prints a deny or ask decision as the structured
+// hook response and exits 0. An allowed call prints nothing, so the
+// host's own permission rules still apply. When the gate itself fails
+// (a broken coach.yaml, an item that does not exist) it exits 1: Claude
+// Code shows the error to the user and lets the tool call run, which
+// reports its own error if there is one.
+func hookPreToolUseAction(ctx context.Context, c *cli.Command) error {
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+	var env hookEnvelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return fmt.Errorf("am hook pre-tool-use: %w", err)
+	}
+	if env.HookEventName != "" && env.HookEventName != "PreToolUse" {
+		return nil
+	}
+	if env.Cwd != "" {
+		if err := os.Chdir(env.Cwd); err != nil {
+			return err
+		}
+	}
+	root, err := findRootDirectory()
+	if err != nil {
+		return nil
+	}
+	decision, err := mcpserver.GateToolUse(ctx, root, env.ToolName, env.ToolInput)
+	if err != nil {
+		return fmt.Errorf("am hook pre-tool-use: %s: %w", env.ToolName, err)
+	}
+	if decision.Decision == mcpserver.GateAllow {
+		return nil
+	}
+	b, err := json.Marshal(hookOutput{HookSpecificOutput: hookDecision{
+		HookEventName:            "PreToolUse",
+		PermissionDecision:       decision.Decision,
+		PermissionDecisionReason: decision.Reason,
+	}})
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+	return nil
+}
@@ -9,8 +9,11 @@ import (
 
 var ImportCommand = &cli.Command{
 	Name:      "import",
-	Usage:     "Import an existing Pivotal Tracker story",
-	ArgsUsage: "CSV_FILE",
+	Usage:     "Import existing Pivotal Tracker stories",
+	ArgsUsage: "FILE...",
+	Flags: []cli.Flag{
+		&cli.StringFlag{Name: "format", Value: actions.ImportFormatCsv, Usage: "csv (basic columns) or pivotal (full Tracker CSV/JSON export: owners, comments, tasks, blockers, priority/icebox order; files from am export also restore backlogs, file names and IDs)"},
+	},
 	Action: func(ctx context.Context, c *cli.Command) error {
 		if err := checkIsBacklogDirectory(); err != nil {
 			fmt.Println(err)
@@ -21,7 +24,7 @@ var ImportCommand = &cli.Command{
 			return nil
 		}
 
-		action := actions.NewImportAction(".", c.Args().Slice())
+		action := actions.NewImportAction(".", c.Args().Slice(), c.String("format"))
 		return action.Execute()
 	},
 }
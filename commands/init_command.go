@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/mreider/agilemarkdown/actions"
 	"github.com/mreider/agilemarkdown/backlog"
 	"github.com/mreider/agilemarkdown/coach"
 	"github.com/mreider/agilemarkdown/config"
@@ -15,14 +17,31 @@ import (
 
 // InitCommand seeds an existing agilemarkdown repo with the coach-mode
 // projections (CLAUDE.md, AGENTS.md, .github/copilot-instructions.md,
-// .cursor/rules/coach.mdc, .claude/skills/*, .claude/hooks/coach-gate.sh,
-// .claude/settings.json). Idempotent: existing files are not touched.
+// .cursor/rules/coach.mdc, .claude/skills/*, and .claude/settings.json
+// with the `am hook pre-tool-use` gate) and registers the
+// `am merge-driver` git merge driver in .gitattributes. Idempotent:
+// existing files are not touched, except that the gate is added to an
+// existing .claude/settings.json.
+//
+// Team skills in .am/skills/<name>/SKILL.md are projected next to the
+// built-ins, as .claude/skills/<name>/SKILL.md, .cursor/rules/<name>.mdc
+// and a Team ceremonies section in AGENTS.md; these are regenerated on
+// every run.
+//
+// It then reports templates that are behind the ones in this binary and
+// projections that have drifted from .claude/agilemarkdown-coach.md.
+// With --upgrade it brings the templates up to date, merging upstream
+// changes into files the team edited (base: .am/coach.lock and
+// .am/coach-base).
 //
 // Use this for repos that ran `am create-backlog` before v4.4 (when the
 // projections did not exist) and want to inherit the coach stance.
 var InitCommand = &cli.Command{
 	Name:  "init",
 	Usage: "Install or refresh the coach-mode projections (idempotent)",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{Name: "upgrade", Usage: "bring installed coach templates up to date, merging upstream changes into local edits"},
+	},
 	Action: func(ctx context.Context, c *cli.Command) error {
 		root, err := findRootDirectory()
 		if err != nil {
@@ -47,13 +66,91 @@ var InitCommand = &cli.Command{
 		if err != nil {
 			return err
 		}
-		if len(written) == 0 {
-			fmt.Println("coach mode already installed; nothing to do")
-			return nil
+		projected, removed, skipped, err := coach.ProjectTeamSkills(root)
+		if err != nil {
+			return err
+		}
+		written = append(written, projected...)
+		attrsWritten, err := actions.InstallMergeDriver(root)
+		if err != nil {
+			return err
+		}
+		if attrsWritten {
+			_ = git.Add(filepath.Join(root, ".gitattributes"))
+			written = append(written, ".gitattributes")
 		}
 		for _, p := range written {
 			fmt.Printf("wrote %s\n", p)
 		}
+		for _, p := range removed {
+			fmt.Printf("removed %s\n", p)
+		}
+		for _, p := range skipped {
+			fmt.Printf("left %s alone: it exists and am init did not write it; rename the team skill or remove the file\n", p)
+		}
+		check := coach.CheckTemplates
+		if c.Bool("upgrade") {
+			check = coach.UpgradeTemplates
+		}
+		statuses, err := check(root)
+		if err != nil {
+			return err
+		}
+		stale, err := coach.StaleProjections(root)
+		if err != nil {
+			return err
+		}
+		reported := printTemplateStatuses(statuses)
+		if len(stale) > 0 {
+			fmt.Println("out of date with .claude/agilemarkdown-coach.md (copy the body across):")
+			for _, p := range stale {
+				fmt.Printf("  %s\n", p)
+			}
+		}
+		if len(written)+len(removed)+len(skipped) == 0 && !reported && len(stale) == 0 {
+			fmt.Println("coach mode already installed; nothing to do")
+		}
+		var conflicted []string
+		for _, s := range statuses {
+			if s.State == coach.TemplateConflict {
+				conflicted = append(conflicted, s.Target)
+			}
+		}
+		if len(conflicted) > 0 {
+			return cli.Exit(fmt.Sprintf("resolve the conflict markers in %s, then commit", strings.Join(conflicted, ", ")), 1)
+		}
 		return nil
 	},
 }
+
+// printTemplateStatuses lists the templates that are not current and
+// says what to do about each. Reports whether it printed anything.
+func printTemplateStatuses(statuses []coach.TemplateStatus) bool {
+	var lines []string
+	for _, s := range statuses {
+		var note string
+		switch s.State {
+		case coach.TemplateCurrent, coach.TemplateEdited:
+			continue
+		case coach.TemplateOutOfDate, coach.TemplateDiverged:
+			note = "run am init --upgrade"
+		case coach.TemplateUntracked:
+			note = "not in .am/coach.lock; am can't tell your edits from an older template, so it leaves this file alone. Delete it and run am init to take the current one"
+		case coach.TemplateUpgraded:
+			note = "no local edits"
+		case coach.TemplateMerged:
+			note = "upstream changes merged into your edits"
+		case coach.TemplateConflict:
+			note = fmt.Sprintf("%d conflict(s) between your edits and upstream", s.Conflicts)
+		}
+		lines = append(lines, fmt.Sprintf("  %-40s %s: %s", s.Target, s.State, note))
+	}
+	if len(lines) == 0 {
+		return false
+	}
+	fmt.Println("coach templates:")
+	for _, l := range lines {
+		fmt.Println(l)
+	}
+	return true
+}
@@ -128,10 +128,11 @@ var TypeMixCommand = &cli.Command{
 
 var SearchCommand = &cli.Command{
 	Name:      "search",
-	Usage:     "Substring search across all stories. Emits scored hits as JSON.",
+	Usage:     "Full-text search across stories (words, \"phrases\", field:value filters). Emits ranked hits as JSON.",
 	ArgsUsage: "QUERY",
 	Flags: []cli.Flag{
 		&cli.IntFlag{Name: "limit", Value: 20, Usage: "max hits to return"},
+		&cli.BoolFlag{Name: "archived", Usage: "include archived stories"},
 	},
 	Action: func(ctx context.Context, c *cli.Command) error {
 		if c.NArg() < 1 {
@@ -141,8 +142,16 @@ var SearchCommand = &cli.Command{
 		if err != nil {
 			return err
 		}
-		query := strings.Join(c.Args().Slice(), " ")
-		res, err := mcpserver.Search(ctx, root, mcpserver.SearchArgs{Query: query, Limit: c.Int("limit")})
+		// The shell has already eaten the quotes around a phrase; put them
+		// back on any argument that still holds a space.
+		parts := c.Args().Slice()
+		for i, p := range parts {
+			if strings.ContainsAny(p, " \t") && !strings.Contains(p, `"`) {
+				parts[i] = `"` + p + `"`
+			}
+		}
+		query := strings.Join(parts, " ")
+		res, err := mcpserver.Search(ctx, root, mcpserver.SearchArgs{Query: query, Limit: c.Int("limit"), Archived: c.Bool("archived")})
 		if err != nil {
 			return err
 		}
@@ -221,11 +230,7 @@ var SetDescriptionCommand = &cli.Command{
 // `.md`) into a path relative to the project root, the shape the MCP
 // tool args expect.
 func relToRoot(root, arg string) (string, error) {
-	p := arg
-	if !strings.HasSuffix(p, ".md") {
-		p += ".md"
-	}
-	abs, err := filepath.Abs(p)
+	abs, err := itemPathFromArg(arg)
 	if err != nil {
 		return "", err
 	}
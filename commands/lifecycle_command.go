@@ -32,6 +32,10 @@ var PullCommand = &cli.Command{
 		if err != nil {
 			return err
 		}
+		deps, err := backlog.LoadDependencyGraph(structure)
+		if err != nil {
+			return err
+		}
 		for _, d := range dirs {
 			pri, err := backlog.LoadPriority(d)
 			if err != nil {
@@ -47,7 +51,7 @@ var PullCommand = &cli.Command{
 			}
 			for _, e := range pri.Entries() {
 				it, ok := byBase[e.Path]
-				if !ok || it.Blocked() {
+				if !ok || it.Blocked() || len(deps.OpenBlockers(it)) > 0 {
 					continue
 				}
 				if !strings.EqualFold(it.Status(), backlog.UnstartedStatus.Name) {
@@ -142,6 +146,8 @@ var sprintPlanCmd = &cli.Command{
 	Usage: "Render the iteration plan for a backlog (top of priority up to rolling velocity, with warnings)",
 	Flags: []cli.Flag{
 		&cli.BoolFlag{Name: "json", Usage: "emit the iteration plan as JSON (machine-readable)"},
+		&cli.BoolFlag{Name: "commit", Usage: "freeze the committed slice into .am/iterations/<n>.yaml for the current iteration"},
+		&cli.BoolFlag{Name: "force", Usage: "with --commit, replace an existing snapshot for this iteration"},
 	},
 	Action: func(ctx context.Context, c *cli.Command) error {
 		if err := checkIsBacklogDirectory(); err != nil {
@@ -155,6 +161,18 @@ var sprintPlanCmd = &cli.Command{
 		if err != nil {
 			return err
 		}
+		if c.Bool("commit") {
+			res, err := mcpserver.SprintCommit(ctx, root, mcpserver.SprintCommitArgs{Backlog: filepath.Base(dir), Force: c.Bool("force")})
+			if err != nil {
+				return err
+			}
+			if c.Bool("json") {
+				return emitJSON(res)
+			}
+			fmt.Printf("Committed iteration %d (%s) for %s: %.0f pts across %d stories -> %s\n",
+				res.Iteration, res.Start, res.Backlog, res.Points, len(res.Items), res.Path)
+			return nil
+		}
 		if c.Bool("json") {
 			res, err := mcpserver.SprintPlan(ctx, root, mcpserver.SprintPlanArgs{Backlog: filepath.Base(dir)})
 			if err != nil {
@@ -250,11 +268,44 @@ var sprintPlanCmd = &cli.Command{
 	},
 }
 
+// iterationPlanOutcome sums the committed snapshots of the iteration
+// containing now across dirs. ok is false when no backlog committed a
+// plan for that iteration.
+func iterationPlanOutcome(root string, dirs []string, cfg *config.Config, now time.Time) (int, backlog.IterationPlanOutcome, bool, error) {
+	var total backlog.IterationPlanOutcome
+	start := backlog.IterationStartFor(now, cfg)
+	num := backlog.IterationNumberFor(start, cfg)
+	plan, err := backlog.LoadIterationPlan(root, num)
+	if err != nil || plan == nil {
+		return num, total, false, err
+	}
+	ok := false
+	for _, d := range dirs {
+		b := plan.Backlog(filepath.Base(d))
+		if b == nil {
+			continue
+		}
+		bck, err := backlog.LoadBacklog(d)
+		if err != nil {
+			return num, total, false, err
+		}
+		o := backlog.EvaluateIterationPlan(b, bck.AllItems(), start, backlog.IterationEndFor(now, cfg))
+		total.Planned += o.Planned
+		total.Accepted += o.Accepted
+		total.CarryOver += o.CarryOver
+		total.ScopeAdded += o.ScopeAdded
+		total.CarryOverItems = append(total.CarryOverItems, o.CarryOverItems...)
+		total.AddedItems = append(total.AddedItems, o.AddedItems...)
+		ok = true
+	}
+	return num, total, ok, nil
+}
+
 // RetroCommand renders an end-of-iteration summary the human reads
 // before answering the three retro questions.
 var RetroCommand = &cli.Command{
 	Name:  "retro",
-	Usage: "Print an end-of-iteration retro summary (velocity, mix, accepted, rejected, recent learnings)",
+	Usage: "Print an end-of-iteration retro summary (velocity, mix, accepted, rejected, coach overrides, recent learnings)",
 	Action: func(ctx context.Context, c *cli.Command) error {
 		root, err := findRootDirectory()
 		if err != nil {
@@ -307,6 +358,29 @@ var RetroCommand = &cli.Command{
 		fmt.Printf("  rejection rate:  %.0f%% (latest, target band 5-15%%)\n", latest)
 		fmt.Printf("  accepted total:  %d\n", acceptedCount)
 		fmt.Printf("  rejected total:  %d\n", rejectedCount)
+		num, outcome, planned, err := iterationPlanOutcome(root, dirs, cfg, time.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Println()
+		if planned {
+			fmt.Printf("Iteration %d, planned vs. actual:\n", num)
+			fmt.Printf("  planned:         %.0f pts\n", outcome.Planned)
+			fmt.Printf("  accepted:        %.0f pts\n", outcome.Accepted)
+			fmt.Printf("  carry-over:      %.0f pts (%d stories)\n", outcome.CarryOver, len(outcome.CarryOverItems))
+			for _, p := range outcome.CarryOverItems {
+				fmt.Printf("    - %s\n", p)
+			}
+			fmt.Printf("  scope added:     %.0f pts (%d stories)\n", outcome.ScopeAdded, len(outcome.AddedItems))
+			for _, p := range outcome.AddedItems {
+				fmt.Printf("    - %s\n", p)
+			}
+		} else {
+			fmt.Printf("Iteration %d has no committed plan (run `am sprint plan --commit` at iteration start).\n", num)
+		}
+		if err := printCoachOverrides(structure, backlog.IterationStartFor(time.Now(), cfg)); err != nil {
+			return err
+		}
 		fmt.Println()
 		fmt.Println("Three questions:")
 		fmt.Println("  1. What worked?")
@@ -337,6 +411,30 @@ var RetroCommand = &cli.Command{
 	},
 }
 
+// printCoachOverrides lists overrides per rule, most overridden this
+// iteration first. A rule the team keeps overriding is an agreement to
+// revisit.
+func printCoachOverrides(root *backlog.BacklogsStructure, iterationStart time.Time) error {
+	overrides, err := backlog.LoadCoachOverrides(root)
+	if err != nil || len(overrides) == 0 {
+		return err
+	}
+	fmt.Println()
+	fmt.Println("Coach overrides (this iteration / all time):")
+	for _, s := range backlog.SummarizeCoachOverrides(overrides, iterationStart) {
+		last := fmt.Sprintf("%q", truncate(s.Last.Reason, 60))
+		if s.Last.Author != "" {
+			last = s.Last.Author + ": " + last
+		}
+		if s.Last.Item != "" {
+			last += " on " + s.Last.Item
+		}
+		fmt.Printf("  %-26s %2d / %-3d last: %s\n", s.Rule, s.Recent, s.Total, last)
+	}
+	fmt.Println("  Keep the rule, change the agreement, or tune it in .am/coach.yaml.")
+	return nil
+}
+
 func acceptanceBulletCount(body string) int {
 	return len(backlog.AcceptanceBulletTexts(body))
 }
@@ -11,14 +11,41 @@ import (
 func NewMCPCommand(version string) *cli.Command {
 	return &cli.Command{
 		Name:      "mcp",
-		Usage:     "Run an MCP (Model Context Protocol) stdio server exposing this backlog",
+		Usage:     "Run an MCP (Model Context Protocol) server exposing this backlog, over stdio or --http",
 		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "http",
+				Usage: "serve the streamable HTTP transport on this address (e.g. 127.0.0.1:7331, or :7331 with --token) instead of stdio",
+			},
+			&cli.StringFlag{
+				Name:    "token",
+				Usage:   "with --http, require \"Authorization: Bearer <token>\" on every request",
+				Sources: cli.EnvVars("AM_MCP_TOKEN"),
+			},
+			&cli.BoolFlag{
+				Name:  "insecure-no-auth",
+				Usage: "with --http on a non-loopback address, serve without --token",
+			},
+			&cli.BoolFlag{
+				Name:  "read-only",
+				Usage: "register only the tools that don't write",
+			},
+		},
 		Action: func(ctx context.Context, c *cli.Command) error {
 			rootDir, err := findRootDirectory()
 			if err != nil {
 				rootDir = "."
 			}
-			return mcpserver.Run(ctx, rootDir, version)
+			if addr := c.String("http"); addr != "" {
+				return mcpserver.RunHTTP(ctx, rootDir, version, mcpserver.HTTPOptions{
+					Addr:           addr,
+					Token:          c.String("token"),
+					ReadOnly:       c.Bool("read-only"),
+					InsecureNoAuth: c.Bool("insecure-no-auth"),
+				})
+			}
+			return mcpserver.Run(ctx, rootDir, version, c.Bool("read-only"))
 		},
 	}
 }
@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mreider/agilemarkdown/actions"
+	"github.com/urfave/cli/v3"
+)
+
+// MergeDriverCommand is the git merge driver registered by `am init`:
+//
+//	[merge "agilemarkdown"]
+//		driver = am merge-driver %O %A %B %P
+//
+// It writes the merge into the ours file and exits non-zero when
+// conflicts remain, which is how git tells a clean merge from a
+// conflicted one.
+var MergeDriverCommand = &cli.Command{
+	Name:      "merge-driver",
+	Usage:     "Git merge driver: three-way merge of item files and _priority.md/_icebox.md",
+	ArgsUsage: "BASE OURS THEIRS [PATH]",
+	Hidden:    true,
+	Action: func(ctx context.Context, c *cli.Command) error {
+		if c.NArg() < 3 {
+			return fmt.Errorf("usage: am merge-driver BASE OURS THEIRS [PATH]")
+		}
+		args := c.Args().Slice()
+		path := args[1]
+		if len(args) > 3 {
+			path = args[3]
+		}
+		conflicts, err := actions.NewMergeDriverAction(args[0], args[1], args[2], path).Execute()
+		if err != nil {
+			return err
+		}
+		if conflicts > 0 {
+			return cli.Exit(fmt.Sprintf("%s: %d conflict(s)", path, conflicts), 1)
+		}
+		return nil
+	},
+}
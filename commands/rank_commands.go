@@ -18,10 +18,7 @@ func itemAndBacklogDir(itemArg string) (string, string, error) {
 	if itemArg == "" {
 		return "", "", fmt.Errorf("item path required")
 	}
-	if !strings.HasSuffix(itemArg, ".md") {
-		itemArg += ".md"
-	}
-	abs, err := filepath.Abs(itemArg)
+	abs, err := itemPathFromArg(itemArg)
 	if err != nil {
 		return "", "", err
 	}
@@ -30,12 +27,17 @@ func itemAndBacklogDir(itemArg string) (string, string, error) {
 }
 
 // resolveAnchor maps an --after/--before flag value to the basename of an
-// item file inside the same backlog directory. Accepts a path or a
-// title-ish string with .md optional.
+// item file inside the same backlog directory. Accepts a path, "#1234",
+// or a title-ish string with .md optional.
 func resolveAnchor(backlogDir, anchor string) string {
 	if anchor == "" {
 		return ""
 	}
+	if backlog.ParseItemRef(anchor) > 0 {
+		if p, err := itemPathFromArg(anchor); err == nil {
+			return filepath.Base(p)
+		}
+	}
 	if !strings.HasSuffix(anchor, ".md") {
 		anchor += ".md"
 	}
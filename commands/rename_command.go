@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mreider/agilemarkdown/mcpserver"
+	"github.com/urfave/cli/v3"
+)
+
+// RenameCommand retitles an item and renames its file, rewriting the
+// order files, overview, dependency lists and links that point at it.
+var RenameCommand = &cli.Command{
+	Name:      "rename",
+	Usage:     "Retitle an item and rename its file, updating every order file and reference",
+	ArgsUsage: "ITEM_PATH NEW_TITLE",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{Name: "json", Usage: "emit the new id and path as JSON"},
+	},
+	Action: func(ctx context.Context, c *cli.Command) error {
+		if c.NArg() < 2 {
+			return fmt.Errorf("usage: am rename ITEM_PATH \"New title\"")
+		}
+		root, err := findRootDirectory()
+		if err != nil {
+			return err
+		}
+		rel, err := relToRoot(root, c.Args().Get(0))
+		if err != nil {
+			return err
+		}
+		title := strings.TrimSpace(strings.Join(c.Args().Tail(), " "))
+		res, err := mcpserver.RenameItem(ctx, root, mcpserver.RenameItemArgs{Path: rel, Title: title})
+		if err != nil {
+			return err
+		}
+		if c.Bool("json") {
+			return emitJSON(res)
+		}
+		fmt.Printf("%s -> %s\n", rel, res.Path)
+		return nil
+	},
+}
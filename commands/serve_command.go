@@ -0,0 +1,30 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/mreider/agilemarkdown/board"
+	"github.com/urfave/cli/v3"
+)
+
+// ServeCommand runs the local web board for people who don't live in
+// VS Code. It binds to localhost unless told otherwise.
+var ServeCommand = &cli.Command{
+	Name:      "serve",
+	Usage:     "Run the web board (Current, Backlog, Icebox, Done) on a local HTTP server",
+	ArgsUsage: " ",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "addr",
+			Value: "127.0.0.1:7330",
+			Usage: "address to listen on",
+		},
+	},
+	Action: func(ctx context.Context, c *cli.Command) error {
+		root, err := findRootDirectory()
+		if err != nil {
+			return err
+		}
+		return board.Serve(ctx, root, c.String("addr"))
+	},
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/mreider/agilemarkdown/backlog"
@@ -24,12 +26,31 @@ func emitJSON(v any) error {
 	return nil
 }
 
+// highlightLate paints lines that report a late release red when stdout
+// is a terminal and NO_COLOR is unset. MCP callers get the plain text.
+func highlightLate(text string) string {
+	if os.Getenv("NO_COLOR") != "" {
+		return text
+	}
+	if fi, err := os.Stdout.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		t := strings.TrimSpace(l)
+		if strings.HasPrefix(t, "LATE:") || strings.HasSuffix(t, " LATE") {
+			lines[i] = "\x1b[31m" + l + "\x1b[0m"
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 // ShowCommand wraps view-only renderers (priority, icebox, epic,
 // iteration). All output is ASCII so it works inline in a chat or a
 // terminal.
 var ShowCommand = &cli.Command{
 	Name:      "show",
-	Usage:     "Render a view: priority, icebox, epic, or iteration",
+	Usage:     "Render a view: priority, icebox, epic, iteration, release, or deps",
 	ArgsUsage: "VIEW [ARGS]",
 	Commands: []*cli.Command{
 		showPriorityCmd,
@@ -38,6 +59,97 @@ var ShowCommand = &cli.Command{
 		showIterationCmd,
 		showBurnupCmd,
 		showCFDCmd,
+		showReleaseCmd,
+		showDepsCmd,
+	},
+}
+
+var showDepsCmd = &cli.Command{
+	Name:  "deps",
+	Usage: "Render the blocked_by dependency graph for the project, a backlog or an epic",
+	Flags: []cli.Flag{
+		&cli.StringFlag{Name: "backlog", Usage: "only dependencies touching this backlog"},
+		&cli.StringFlag{Name: "epic", Usage: "only dependencies touching stories in this epic"},
+		&cli.BoolFlag{Name: "dot", Usage: "emit a Graphviz digraph"},
+		&cli.BoolFlag{Name: "mermaid", Usage: "emit a Mermaid flowchart"},
+		&cli.BoolFlag{Name: "json", Usage: "emit nodes, edges and cycles as JSON (machine-readable)"},
+	},
+	Action: func(ctx context.Context, c *cli.Command) error {
+		root, err := findRootDirectory()
+		if err != nil {
+			return err
+		}
+		args := mcpserver.DependencyGraphArgs{Backlog: c.String("backlog"), Epic: c.String("epic"), Format: "ascii"}
+		switch {
+		case c.Bool("dot") && c.Bool("mermaid"):
+			return fmt.Errorf("usage: am show deps [--dot|--mermaid]")
+		case c.Bool("dot"):
+			args.Format = "dot"
+		case c.Bool("mermaid"):
+			args.Format = "mermaid"
+		case c.Bool("json"):
+			args.Format = ""
+		}
+		res, err := mcpserver.DependencyGraph(ctx, root, args)
+		if err != nil {
+			return err
+		}
+		if c.Bool("json") {
+			return emitJSON(res)
+		}
+		fmt.Print(res.Text)
+		if args.Format == "ascii" {
+			for _, cycle := range res.Cycles {
+				fmt.Printf("\ncycle: %s\n", strings.Join(cycle, " -> "))
+			}
+		}
+		return nil
+	},
+}
+
+var showReleaseCmd = &cli.Command{
+	Name:      "release",
+	Usage:     "Forecast a release marker: points above it, projected iteration, on-track / at-risk / late",
+	ArgsUsage: "[RELEASE_SLUG]",
+	Flags: []cli.Flag{
+		&cli.StringFlag{Name: "backlog", Usage: "only markers in this backlog"},
+		&cli.BoolFlag{Name: "json", Usage: "emit the forecast as JSON (machine-readable)"},
+	},
+	Action: func(ctx context.Context, c *cli.Command) error {
+		if c.NArg() > 1 {
+			return fmt.Errorf("usage: am show release [RELEASE_SLUG]")
+		}
+		root, err := findRootDirectory()
+		if err != nil {
+			return err
+		}
+		if c.Bool("json") {
+			res, err := mcpserver.ReleaseForecast(ctx, root, mcpserver.ReleaseForecastArgs{Release: c.Args().Get(0), Backlog: c.String("backlog")})
+			if err != nil {
+				return err
+			}
+			return emitJSON(res)
+		}
+		cfg, err := config.LoadConfig(filepath.Join(root, ".am", "config.yaml"))
+		if err != nil {
+			return err
+		}
+		now := time.Now().In(cfg.IterationLocation())
+		forecasts, err := backlog.FindReleaseForecasts(backlog.NewBacklogsStructure(root), cfg, now, c.String("backlog"), c.Args().Get(0))
+		if err != nil {
+			return err
+		}
+		if len(forecasts) == 0 {
+			fmt.Println("No release markers in _priority.md.")
+			return nil
+		}
+		for i, f := range forecasts {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Print(highlightLate(backlog.ReleaseForecastASCII(f)))
+		}
+		return nil
 	},
 }
 
@@ -47,6 +159,7 @@ var showCFDCmd = &cli.Command{
 	Flags: []cli.Flag{
 		&cli.IntFlag{Name: "days", Value: 30, Usage: "lookback window in days"},
 		&cli.BoolFlag{Name: "json", Usage: "emit structured rows instead of ASCII"},
+		&cli.BoolFlag{Name: "from-git", Usage: "replay each item's committed revisions (shows rejections, restarts, icebox time)"},
 	},
 	Action: func(ctx context.Context, c *cli.Command) error {
 		root, err := findRootDirectory()
@@ -54,28 +167,36 @@ var showCFDCmd = &cli.Command{
 			return err
 		}
 		if c.Bool("json") {
-			res, err := mcpserver.CumulativeFlow(ctx, root, mcpserver.CFDArgs{Days: c.Int("days")})
+			res, err := mcpserver.CumulativeFlow(ctx, root, mcpserver.CFDArgs{Days: c.Int("days"), FromGit: c.Bool("from-git")})
 			if err != nil {
 				return err
 			}
 			return emitJSON(res)
 		}
 		structure := backlog.NewBacklogsStructure(root)
-		dirs, err := structure.BacklogDirs()
-		if err != nil {
-			return err
-		}
-		var all []*backlog.BacklogItem
-		for _, d := range dirs {
-			bck, err := backlog.LoadBacklog(d)
+		end := time.Now().UTC()
+		start := end.AddDate(0, 0, -c.Int("days"))
+		var rows []backlog.CFDRow
+		if c.Bool("from-git") {
+			rows, err = backlog.CFDRowsFromGit(structure, start, end)
+			if err != nil {
+				return err
+			}
+		} else {
+			dirs, err := structure.BacklogDirs()
 			if err != nil {
 				return err
 			}
-			all = append(all, bck.AllItems()...)
+			var all []*backlog.BacklogItem
+			for _, d := range dirs {
+				bck, err := backlog.LoadBacklog(d)
+				if err != nil {
+					return err
+				}
+				all = append(all, bck.AllItems()...)
+			}
+			rows = backlog.CFDRows(all, start, end)
 		}
-		end := time.Now().UTC()
-		start := end.AddDate(0, 0, -c.Int("days"))
-		rows := backlog.CFDRows(all, start, end)
 		fmt.Print(backlog.CFDASCII(rows))
 		return nil
 	},
@@ -167,7 +288,7 @@ var showPriorityCmd = &cli.Command{
 		if err != nil {
 			return err
 		}
-		fmt.Print(text)
+		fmt.Print(highlightLate(text))
 		return nil
 	},
 }
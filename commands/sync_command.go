@@ -20,6 +20,10 @@ func NewSyncCommand() *cli.Command {
 				Name:   "author",
 				Hidden: true,
 			},
+			&cli.BoolFlag{
+				Name:  "per-author",
+				Usage: "commit item changes separately for each assignee (also config sync.commit_per_author)",
+			},
 		},
 		Action: func(ctx context.Context, c *cli.Command) error {
 			rootDir, err := findRootDirectory()
@@ -27,7 +31,7 @@ func NewSyncCommand() *cli.Command {
 				return err
 			}
 
-			action := actions.NewSyncAction(rootDir, c.String("author"), c.Bool("test"))
+			action := actions.NewSyncAction(rootDir, c.String("author"), c.Bool("per-author"), c.Bool("test"))
 			return action.Execute()
 		},
 	}
@@ -140,9 +140,9 @@ func taskTickAction(ctx context.Context, c *cli.Command) error {
 }
 
 func mustItemPath(arg string) string {
-	p, _ := filepath.Abs(arg)
-	if !strings.HasSuffix(p, ".md") {
-		p += ".md"
+	p, err := itemPathFromArg(arg)
+	if err != nil {
+		p, _ = filepath.Abs(arg + ".md")
 	}
 	return p
 }
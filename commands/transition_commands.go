@@ -24,13 +24,10 @@ func transitionCmd(name, usage string, target *backlog.BacklogItemStatus) *cli.C
 				fmt.Println("path to an item file is required")
 				return nil
 			}
-			path, err := filepath.Abs(c.Args().Get(0))
+			path, err := itemPathFromArg(c.Args().Get(0))
 			if err != nil {
 				return err
 			}
-			if !strings.HasSuffix(path, ".md") {
-				path += ".md"
-			}
 			item, err := backlog.LoadBacklogItem(path)
 			if err != nil {
 				return err
@@ -40,7 +37,7 @@ func transitionCmd(name, usage string, target *backlog.BacklogItemStatus) *cli.C
 				return err
 			}
 			fmt.Printf("%s -> %s\n", filepath.Base(path), target.Name)
-			return nil
+			return releaseDependents(item)
 		},
 	}
 }
@@ -52,6 +49,25 @@ var (
 	AcceptCommand  = transitionCmd("accept", "Accept an item (counts toward velocity)", backlog.AcceptedStatus)
 )
 
+// releaseDependents drops an accepted item from the blocked_by lists of
+// the stories waiting on it and reports each one. Outside a project it
+// does nothing.
+func releaseDependents(item *backlog.BacklogItem) error {
+	root, err := findRootDirectory()
+	if err != nil {
+		return nil
+	}
+	released, err := backlog.ReleaseDependents(backlog.NewBacklogsStructure(root), item)
+	if err != nil {
+		return err
+	}
+	for _, it := range released {
+		rel, _ := filepath.Rel(root, it.Path())
+		fmt.Printf("  unblocks %s\n", rel)
+	}
+	return nil
+}
+
 // deliverCmd transitions an item to `delivered` and, with --prompt,
 // immediately renders the PM acceptance ceremony so the dev pair does
 // not have to remember the next move. The default behavior matches the
@@ -69,13 +85,10 @@ func deliverCmd() *cli.Command {
 				fmt.Println("path to an item file is required")
 				return nil
 			}
-			path, err := filepath.Abs(c.Args().Get(0))
+			path, err := itemPathFromArg(c.Args().Get(0))
 			if err != nil {
 				return err
 			}
-			if !strings.HasSuffix(path, ".md") {
-				path += ".md"
-			}
 			item, err := backlog.LoadBacklogItem(path)
 			if err != nil {
 				return err
@@ -152,13 +165,10 @@ var RejectCommand = &cli.Command{
 			fmt.Println("path to an item file is required")
 			return nil
 		}
-		path, err := filepath.Abs(c.Args().Get(0))
+		path, err := itemPathFromArg(c.Args().Get(0))
 		if err != nil {
 			return err
 		}
-		if !strings.HasSuffix(path, ".md") {
-			path += ".md"
-		}
 		item, err := backlog.LoadBacklogItem(path)
 		if err != nil {
 			return err
@@ -0,0 +1,190 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/mcpserver"
+	"github.com/urfave/cli/v3"
+)
+
+// notJournaled are the commands that stay out of the undo journal: sync
+// and init commit to git, which is their undo; merge-driver works on
+// git's temporary files; mcp and serve journal each change themselves;
+// undo and redo replay the journal. The rest only read (export writes
+// outside the backlog, if anywhere), so there is nothing to snapshot.
+var notJournaled = map[string]bool{
+	"sync": true, "init": true, "merge-driver": true, "mcp": true, "serve": true, "undo": true, "redo": true,
+	"hook": true, "activity": true, "align": true, "cycle-time": true, "rejection-rate": true, "ceremony": true,
+	"accept-prompt": true, "coach-check": true, "iteration-fit": true, "coach": true, "dashboard": true,
+	"next": true, "export": true, "filter": true, "forecast": true, "list-backlogs": true, "list-items": true,
+	"get-item": true, "get-comments": true, "type-mix": true, "search": true, "history": true, "whoami": true,
+	"retro": true, "show": true, "velocity": true,
+}
+
+// JournalCommands wraps the action of every command (and subcommand)
+// that may write so the files it changes are recorded in the undo
+// journal. Commands that change nothing record nothing.
+func JournalCommands(cmds []*cli.Command) {
+	for _, cmd := range cmds {
+		if notJournaled[cmd.Name] {
+			continue
+		}
+		JournalCommands(cmd.Commands)
+		if cmd.Action != nil {
+			cmd.Action = journaled(cmd.Action)
+		}
+	}
+}
+
+func journaled(action cli.ActionFunc) cli.ActionFunc {
+	return func(ctx context.Context, c *cli.Command) error {
+		rootDir, err := findRootDirectory()
+		if err != nil {
+			return action(ctx, c)
+		}
+		root := backlog.NewBacklogsStructure(rootDir)
+		snap, err := backlog.TakeSnapshot(root)
+		if err != nil {
+			return action(ctx, c)
+		}
+		actionErr := action(ctx, c)
+		if _, err := backlog.RecordJournal(root, snap, backlog.JournalEntry{Source: "cli", Command: commandLine()}); err != nil {
+			fmt.Fprintf(os.Stderr, "undo journal: %v\n", err)
+		}
+		return actionErr
+	}
+}
+
+func commandLine() string {
+	parts := []string{"am"}
+	for _, arg := range os.Args[1:] {
+		if arg == "" || strings.ContainsAny(arg, " \t\"'#") {
+			arg = strconv.Quote(arg)
+		}
+		parts = append(parts, arg)
+	}
+	return strings.Join(parts, " ")
+}
+
+var journalFlags = []cli.Flag{
+	&cli.BoolFlag{Name: "force", Usage: "overwrite files edited since the change"},
+	&cli.BoolFlag{Name: "json", Usage: "emit the affected journal entries as JSON"},
+}
+
+// UndoCommand rolls back the last N journaled changes.
+var UndoCommand = &cli.Command{
+	Name:      "undo",
+	Usage:     "Roll back the last N changes made by am commands or MCP tools (default 1)",
+	ArgsUsage: "[N]",
+	Flags: append([]cli.Flag{
+		&cli.BoolFlag{Name: "list", Usage: "show the journal instead of undoing"},
+	}, journalFlags...),
+	Action: func(ctx context.Context, c *cli.Command) error {
+		root, err := journalRoot()
+		if err != nil {
+			return err
+		}
+		if c.Bool("list") {
+			entries, err := backlog.LoadJournal(root)
+			if err != nil {
+				return err
+			}
+			if c.Bool("json") {
+				return emitJSON(mcpserver.SummarizeJournal(entries))
+			}
+			if len(entries) == 0 {
+				fmt.Println("The journal is empty.")
+			}
+			for _, e := range entries {
+				printJournalEntry("", e)
+			}
+			return nil
+		}
+		n, err := journalCount(c)
+		if err != nil {
+			return err
+		}
+		entries, err := backlog.UndoJournal(root, n, c.Bool("force"))
+		if err != nil {
+			return err
+		}
+		if c.Bool("json") {
+			return emitJSON(mcpserver.SummarizeJournal(entries))
+		}
+		for _, e := range entries {
+			printJournalEntry("Undid ", e)
+		}
+		return nil
+	},
+}
+
+// RedoCommand reapplies the most recently undone changes.
+var RedoCommand = &cli.Command{
+	Name:      "redo",
+	Usage:     "Reapply the last N undone changes (default 1)",
+	ArgsUsage: "[N]",
+	Flags:     journalFlags,
+	Action: func(ctx context.Context, c *cli.Command) error {
+		root, err := journalRoot()
+		if err != nil {
+			return err
+		}
+		n, err := journalCount(c)
+		if err != nil {
+			return err
+		}
+		entries, err := backlog.RedoJournal(root, n, c.Bool("force"))
+		if err != nil {
+			return err
+		}
+		if c.Bool("json") {
+			return emitJSON(mcpserver.SummarizeJournal(entries))
+		}
+		for _, e := range entries {
+			printJournalEntry("Redid ", e)
+		}
+		return nil
+	},
+}
+
+func journalRoot() (*backlog.BacklogsStructure, error) {
+	rootDir, err := findRootDirectory()
+	if err != nil {
+		return nil, err
+	}
+	return backlog.NewBacklogsStructure(rootDir), nil
+}
+
+func journalCount(c *cli.Command) (int, error) {
+	if c.NArg() == 0 {
+		return 1, nil
+	}
+	n, err := strconv.Atoi(c.Args().First())
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("N must be a positive number, got %q", c.Args().First())
+	}
+	return n, nil
+}
+
+func printJournalEntry(prefix string, e *backlog.JournalEntry) {
+	command := e.Command
+	if e.Source == "mcp" {
+		command = "mcp " + command
+		if e.Args != "" {
+			command += " " + e.Args
+		}
+	}
+	state := ""
+	if prefix == "" && e.Undone {
+		state = " (undone)"
+	}
+	fmt.Printf("%s#%d %s %s%s\n", prefix, e.Seq, e.Time, command, state)
+	for _, p := range e.Paths() {
+		fmt.Printf("  %s\n", p)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/mreider/agilemarkdown/backlog"
 	"github.com/mreider/agilemarkdown/config"
@@ -46,6 +47,26 @@ func findRootDirectory() (string, error) {
 	return dir, nil
 }
 
+// itemPathFromArg turns an ITEM argument into an absolute path. "#1234"
+// (or a bare 1234 with no such file) is looked up by ID; anything else is
+// a file path with ".md" optional.
+func itemPathFromArg(arg string) (string, error) {
+	p := arg
+	if !strings.HasSuffix(p, ".md") {
+		p += ".md"
+	}
+	if backlog.ParseItemRef(arg) > 0 {
+		if _, err := os.Stat(p); err != nil {
+			root, err := findRootDirectory()
+			if err != nil {
+				return "", err
+			}
+			return backlog.ResolveItemPath(backlog.NewBacklogsStructure(root), arg)
+		}
+	}
+	return filepath.Abs(p)
+}
+
 // AddConfigAndGitIgnore creates `.am/config.yaml` with sensible defaults
 // when missing, and writes a baseline `.gitignore`. Both are committed
 // in one shot so a fresh repo starts clean.
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CoachConfig is the team's tuning of the coach (`.am/coach.yaml`). The
+// built-in rules all run at their default level until a team says
+// otherwise; custom rules add the team's own checks in the `am search`
+// query syntax, matched against the item as it would be after the
+// action:
+//
+//	rules:
+//	  8-point-hard-cap:
+//	    max: 5
+//	  acceptance-criteria:
+//	    level: refuse
+//	  toil-is-not-progress:
+//	    enabled: false
+//	custom:
+//	  - slug: owner-before-start
+//	    actions: [set_status]
+//	    when: status:started owner:none
+//	    rule: nobody owns this story
+//	    next: set an owner before starting it
+//	    level: nudge
+type CoachConfig struct {
+	Rules  map[string]CoachRule `yaml:"rules,omitempty"`
+	Custom []CustomCoachRule    `yaml:"custom,omitempty"`
+}
+
+// CoachRule overrides one rule, built-in or custom, by slug.
+type CoachRule struct {
+	// Enabled: false switches the rule off. Default on.
+	Enabled *bool `yaml:"enabled,omitempty"`
+
+	// Level: refuse or nudge. Empty keeps the rule's own level.
+	Level string `yaml:"level,omitempty"`
+
+	// Max is the point cap for 8-point-hard-cap. Default: the largest
+	// value on the estimation scale.
+	Max float64 `yaml:"max,omitempty"`
+
+	// Types are the story types a type-scoped rule (the point cap and
+	// the two acceptance rules) applies to. Default: feature.
+	Types []string `yaml:"types,omitempty"`
+}
+
+// CustomCoachRule is a team rule: when the item matches When, the coach
+// answers with Rule and Next under Slug.
+type CustomCoachRule struct {
+	Slug string `yaml:"slug"`
+
+	// Actions limits the rule to some of set_status, set_estimate,
+	// create_item and pull. Default: all of them.
+	Actions []string `yaml:"actions,omitempty"`
+
+	// When is a search query (`type:feature estimate:none`).
+	When string `yaml:"when"`
+
+	Rule string `yaml:"rule"`
+	Next string `yaml:"next,omitempty"`
+
+	// Level: refuse (default) or nudge.
+	Level string `yaml:"level,omitempty"`
+}
+
+// CoachActions are the actions coach_check knows.
+var CoachActions = []string{"set_status", "set_estimate", "create_item", "pull"}
+
+// LoadCoachConfig reads `path`. A missing file is an empty config. Rule
+// slugs and When queries are checked by the coach itself, which knows
+// both.
+func LoadCoachConfig(path string) (*CoachConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &CoachConfig{}, nil
+		}
+		return nil, err
+	}
+	c := &CoachConfig{}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("coach.yaml: %w", err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("coach.yaml: %w", err)
+	}
+	return c, nil
+}
+
+// Validate checks levels, actions and that custom rules are complete.
+func (c *CoachConfig) Validate() error {
+	for slug, r := range c.Rules {
+		if err := validCoachLevel(r.Level); err != nil {
+			return fmt.Errorf("rules.%s: %w", slug, err)
+		}
+		if r.Max < 0 {
+			return fmt.Errorf("rules.%s: max must not be negative", slug)
+		}
+	}
+	seen := map[string]bool{}
+	for i, r := range c.Custom {
+		if strings.TrimSpace(r.Slug) == "" {
+			return fmt.Errorf("custom[%d]: slug is required", i)
+		}
+		if seen[r.Slug] {
+			return fmt.Errorf("custom: duplicate slug %q", r.Slug)
+		}
+		seen[r.Slug] = true
+		if strings.TrimSpace(r.When) == "" || strings.TrimSpace(r.Rule) == "" {
+			return fmt.Errorf("custom %s: when and rule are required", r.Slug)
+		}
+		if err := validCoachLevel(r.Level); err != nil {
+			return fmt.Errorf("custom %s: %w", r.Slug, err)
+		}
+		for _, a := range r.Actions {
+			if !isCoachAction(a) {
+				return fmt.Errorf("custom %s: unknown action %q (use %s)", r.Slug, a, strings.Join(CoachActions, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// Rule returns the overrides for slug; the zero value when there are none.
+func (c *CoachConfig) Rule(slug string) CoachRule {
+	return c.Rules[slug]
+}
+
+// On reports whether the rule runs.
+func (r CoachRule) On() bool {
+	return r.Enabled == nil || *r.Enabled
+}
+
+func validCoachLevel(level string) error {
+	switch level {
+	case "", "refuse", "nudge":
+		return nil
+	}
+	return fmt.Errorf("level must be refuse or nudge, got %q", level)
+}
+
+func isCoachAction(a string) bool {
+	for _, x := range CoachActions {
+		if x == a {
+			return true
+		}
+	}
+	return false
+}
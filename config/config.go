@@ -18,6 +18,7 @@ type Config struct {
 	Iteration  Iteration  `yaml:"iteration"`
 	Velocity   Velocity   `yaml:"velocity"`
 	StoryTypes StoryTypes `yaml:"story_types"`
+	Sync       Sync       `yaml:"sync,omitempty"`
 }
 
 type Estimation struct {
@@ -71,6 +72,13 @@ type StoryTypes struct {
 	ChoreEstimable bool `yaml:"chore_estimable"`
 }
 
+type Sync struct {
+	// CommitPerAuthor: when true, `am sync` splits item changes into one
+	// commit per attributed author (the item's assignee, or its author for
+	// new items) instead of a single sync commit.
+	CommitPerAuthor bool `yaml:"commit_per_author,omitempty"`
+}
+
 // Defaults returns the standard agilemarkdown configuration: Pivotal-style
 // fibonacci 0-8, 1-week iterations starting Monday UTC, rolling-3 velocity,
 // bugs and chores not estimable.
@@ -251,3 +259,18 @@ func scaleValues(scale string) []float64 {
 	}
 	return nil
 }
+
+// MaxEstimate is the largest value on the estimation scale, 8 when the
+// scale has none.
+func (c *Config) MaxEstimate() float64 {
+	if len(c.Estimation.Values) == 0 {
+		return 8
+	}
+	max := c.Estimation.Values[0]
+	for _, v := range c.Estimation.Values[1:] {
+		if v > max {
+			max = v
+		}
+	}
+	return max
+}
@@ -130,6 +130,34 @@ velocity:
 	}
 }
 
+func TestCoachConfig(t *testing.T) {
+	dir := t.TempDir()
+	c, err := LoadCoachConfig(filepath.Join(dir, "coach.yaml"))
+	if err != nil || !c.Rule("8-point-hard-cap").On() {
+		t.Fatalf("missing coach.yaml: %+v, %v", c, err)
+	}
+	if got := (&Config{Estimation: Estimation{Values: scaleValues("linear")}}).MaxEstimate(); got != 3 {
+		t.Errorf("linear max = %v want 3", got)
+	}
+	bad := map[string]string{
+		"level":  "rules:\n  bugs-are-tax:\n    level: shout\n",
+		"action": "custom:\n  - slug: x\n    when: type:bug\n    rule: r\n    actions: [delete]\n",
+		"when":   "custom:\n  - slug: x\n    rule: r\n",
+		"slug":   "custom:\n  - when: type:bug\n    rule: r\n",
+		"dup":    "custom:\n  - {slug: x, when: type:bug, rule: r}\n  - {slug: x, when: type:bug, rule: r}\n",
+		"max":    "rules:\n  8-point-hard-cap:\n    max: -1\n",
+	}
+	for name, yaml := range bad {
+		path := filepath.Join(dir, name+".yaml")
+		if err := os.WriteFile(path, []byte(yaml), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadCoachConfig(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
 func contains(s, sub string) bool {
 	for i := 0; i+len(sub) <= len(s); i++ {
 		if s[i:i+len(sub)] == sub {
@@ -1,12 +1,17 @@
 package git
 
 import (
+	"bufio"
 	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -185,6 +190,244 @@ func ModifiedFiles(dir string) ([]string, error) {
 	return strings.Split(out, "\n"), nil
 }
 
+// FileChange is one staged path as reported by `git diff --name-status`.
+// Status is the one-letter code (A, M, D, R, ...). OldPath is set for
+// renames only. Paths are relative to the repository root.
+type FileChange struct {
+	Status  string
+	Path    string
+	OldPath string
+}
+
+// emptyTreeHash is git's well-known empty tree, used as the diff base
+// before the first commit.
+const emptyTreeHash = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// StagedChanges lists the index changes against HEAD (or against the
+// empty tree in a fresh repository), with rename detection.
+func StagedChanges(repoDir string) ([]FileChange, error) {
+	base := "HEAD"
+	if _, err := runGitCommandInDirectory(repoDir, []string{"rev-parse", "--verify", "--quiet", "HEAD"}); err != nil {
+		base = emptyTreeHash
+	}
+	cmd := exec.Command("git", "diff", "--cached", "--name-status", "-M", "-z", base)
+	cmd.Dir = repoDir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+	var changes []FileChange
+	for i := 0; i < len(fields); i++ {
+		status := fields[i]
+		if status == "" {
+			continue
+		}
+		change := FileChange{Status: status[:1]}
+		if (change.Status == "R" || change.Status == "C") && i+2 < len(fields) {
+			change.OldPath, change.Path = fields[i+1], fields[i+2]
+			i += 2
+		} else if i+1 < len(fields) {
+			change.Path = fields[i+1]
+			i++
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// CommitPaths commits only the given paths, leaving the rest of the index
+// staged for a later commit.
+func CommitPaths(repoDir, msg, author string, paths []string) error {
+	args := []string{"commit", "-m", msg}
+	if author != "" {
+		args = append(args, "--author", author)
+	}
+	args = append(args, "--")
+	args = append(args, paths...)
+	out, err := runGitCommandInDirectory(repoDir, args)
+	if err != nil && strings.Contains(out, "nothing to commit, working tree clean") {
+		err = nil
+	}
+	return err
+}
+
+// LogEntry is one commit from LogChanges with the files it touched.
+type LogEntry struct {
+	Hash    string
+	Author  string
+	Email   string
+	When    time.Time
+	Subject string
+	Files   []BlobChange
+}
+
+// BlobChange is one file of a LogEntry. OldBlob and NewBlob are the blob
+// ids before and after the commit; the null id stands for "absent".
+type BlobChange struct {
+	Status  string
+	Path    string
+	OldPath string
+	OldBlob string
+	NewBlob string
+}
+
+// NullBlob is the id git reports for the missing side of an add/delete.
+const NullBlob = "0000000000000000000000000000000000000000"
+
+// LogChanges walks `git log --raw` (newest first, merges skipped) and
+// returns each commit with the blob ids of the files it changed. since
+// is passed to --since when non-empty.
+func LogChanges(repoDir, since string) ([]LogEntry, error) {
+	args := []string{"-c", "core.quotePath=false", "log", "--raw", "--no-abbrev", "-M", "--no-merges", "--no-decorate",
+		"--pretty=format:%x1e%H%x1f%an%x1f%ae%x1f%aI%x1f%s"}
+	if since != "" {
+		args = append(args, "--since="+since)
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoDir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	var entries []LogEntry
+	for _, record := range strings.Split(string(out), "\x1e") {
+		lines := strings.Split(strings.TrimSpace(record), "\n")
+		parts := strings.SplitN(lines[0], "\x1f", 5)
+		if len(parts) < 5 {
+			continue
+		}
+		when, _ := time.Parse(time.RFC3339, parts[3])
+		entry := LogEntry{Hash: parts[0], Author: parts[1], Email: parts[2], When: when, Subject: parts[4]}
+		for _, line := range lines[1:] {
+			// :100644 100644 <old> <new> M\tpath  |  ... R100\told\tnew
+			if !strings.HasPrefix(line, ":") {
+				continue
+			}
+			tab := strings.Split(line, "\t")
+			meta := strings.Fields(tab[0])
+			if len(meta) < 5 || len(tab) < 2 {
+				continue
+			}
+			change := BlobChange{Status: meta[4][:1], OldBlob: meta[2], NewBlob: meta[3], Path: tab[1]}
+			if len(tab) > 2 {
+				change.OldPath, change.Path = tab[1], tab[2]
+			}
+			entry.Files = append(entry.Files, change)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// BlobReader reads blob contents through one long-running
+// `git cat-file --batch`, so walking history does not fork per file.
+type BlobReader struct {
+	cmd *exec.Cmd
+	in  io.WriteCloser
+	out *bufio.Reader
+}
+
+func NewBlobReader(repoDir string) (*BlobReader, error) {
+	cmd := exec.Command("git", "cat-file", "--batch")
+	cmd.Dir = repoDir
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &BlobReader{cmd: cmd, in: in, out: bufio.NewReader(out)}, nil
+}
+
+// Read returns the content of blob id. The null id reads as "".
+func (r *BlobReader) Read(id string) (string, error) {
+	if id == "" || id == NullBlob {
+		return "", nil
+	}
+	if _, err := fmt.Fprintln(r.in, id); err != nil {
+		return "", err
+	}
+	header, err := r.out.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(header)
+	if len(fields) < 3 {
+		return "", fmt.Errorf("cat-file: %s", strings.TrimSpace(header))
+	}
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return "", fmt.Errorf("cat-file: %s", strings.TrimSpace(header))
+	}
+	buf := make([]byte, size+1) // content plus trailing newline
+	if _, err := io.ReadFull(r.out, buf); err != nil {
+		return "", err
+	}
+	return string(buf[:size]), nil
+}
+
+func (r *BlobReader) Close() error {
+	_ = r.in.Close()
+	return r.cmd.Wait()
+}
+
+// BlobID is the id git gives content as a blob, without touching the
+// repository.
+func BlobID(content []byte) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(content))
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// FirstVersion returns the blob id path had in the commit that added
+// it, or "" when path was never committed.
+func FirstVersion(repoDir, path string) (string, error) {
+	out, err := runGitCommandInDirectory(repoDir, []string{"log", "--diff-filter=A", "--no-renames", "--format=", "--raw", "--no-abbrev", "--", path})
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(out, "\n")
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 4 {
+		return "", nil
+	}
+	return fields[3], nil
+}
+
+// MergeDriverName is the merge driver `am init` registers in
+// .gitattributes and .git/config.
+const MergeDriverName = "agilemarkdown"
+
+// ConfigureMergeDriver registers `am merge-driver` in the repository's
+// local config. Git does not version .git/config, so every clone needs
+// this once; it is safe to call repeatedly.
+func ConfigureMergeDriver(repoDir string) error {
+	section := "merge." + MergeDriverName
+	if _, err := runGitCommandInDirectory(repoDir, []string{"config", section + ".name", "agilemarkdown semantic merge"}); err != nil {
+		return err
+	}
+	_, err := runGitCommandInDirectory(repoDir, []string{"config", section + ".driver", "am merge-driver %O %A %B %P"})
+	return err
+}
+
+// MergeFile runs `git merge-file` on three files, writing the result into
+// ours. Returns the number of conflicts left in ours.
+func MergeFile(ours, base, theirs string) (int, error) {
+	cmd := exec.Command("git", "merge-file", "-L", "ours", "-L", "base", "-L", "theirs", ours, base, theirs)
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() > 0 && exitErr.ExitCode() < 128 {
+		return exitErr.ExitCode(), nil
+	}
+	return 0, err
+}
+
 func GetRootGitDirectory(dir string) string {
 	dir, _ = filepath.Abs(dir)
 	for {
@@ -56,6 +56,8 @@ func main() {
 			commands.VelocityCommand,
 			commands.AliasCommand,
 			commands.ImportCommand,
+			commands.ExportCommand,
+			commands.MergeDriverCommand,
 			commands.ArchiveCommand,
 			commands.TimelineCommand,
 			commands.DeleteTagCommand,
@@ -96,6 +98,7 @@ func main() {
 			commands.InceptionCommand,
 			commands.SprintCommand,
 			commands.RetroCommand,
+			commands.CeremonyCommand,
 			commands.PullCommand,
 			commands.ListBacklogsCommand,
 			commands.ListItemsCommand,
@@ -104,12 +107,23 @@ func main() {
 			commands.TypeMixCommand,
 			commands.WhoamiCommand,
 			commands.HistoryCommand,
+			commands.ActivityCommand,
+			commands.ForecastCommand,
 			commands.SearchCommand,
 			commands.SetDescriptionCommand,
+			commands.RenameCommand,
+			commands.FilterCommand,
+			commands.BulkCommand,
+			commands.UndoCommand,
+			commands.RedoCommand,
+			commands.ServeCommand,
+			commands.HookCommand,
 			commands.NewMCPCommand(version),
 		},
 	}
 
+	commands.JournalCommands(app.Commands)
+
 	if err := app.Run(context.Background(), os.Args); err != nil {
 		log.Fatal(err)
 	}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -177,6 +178,33 @@ func (f *FrontmatterFile) Save() error {
 // MarkDirty forces the next Save to write even when no setter was called.
 func (f *FrontmatterFile) MarkDirty() { f.dirty = true }
 
+// Clone returns an independent copy: setters on one never show up in
+// the other. Used to hand out cached parses.
+func (f *FrontmatterFile) Clone() *FrontmatterFile {
+	c := *f
+	c.root = cloneNode(f.root, map[*yaml.Node]*yaml.Node{})
+	return &c
+}
+
+func cloneNode(n *yaml.Node, seen map[*yaml.Node]*yaml.Node) *yaml.Node {
+	if n == nil {
+		return nil
+	}
+	if c, ok := seen[n]; ok {
+		return c
+	}
+	c := *n
+	seen[n] = &c
+	c.Alias = cloneNode(n.Alias, seen)
+	if n.Content != nil {
+		c.Content = make([]*yaml.Node, len(n.Content))
+		for i, child := range n.Content {
+			c.Content[i] = cloneNode(child, seen)
+		}
+	}
+	return &c
+}
+
 // findKey returns the mapping pair (key, value) yaml.Nodes for `key`,
 // or (nil,nil) if missing.
 func (f *FrontmatterFile) findKey(key string) (*yaml.Node, *yaml.Node) {
@@ -308,6 +336,15 @@ func (f *FrontmatterFile) SetBool(key string, value bool) {
 	f.setNode(key, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"})
 }
 
+// SetInt writes an integer at `key`. Zero removes the key.
+func (f *FrontmatterFile) SetInt(key string, value int) {
+	if value == 0 {
+		f.removeKey(key)
+		return
+	}
+	f.setNode(key, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(value)})
+}
+
 // GetMap returns a string->string view of the mapping value at `key`.
 // Returns nil when the key is missing or not a mapping. Order preserved.
 func (f *FrontmatterFile) GetMap(key string) []KV {
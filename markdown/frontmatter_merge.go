@@ -0,0 +1,237 @@
+package markdown
+
+import (
+	"bytes"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MergeOptions tunes MergeFrontmatter for the keys of one file kind.
+type MergeOptions struct {
+	// SetKeys are merged as sets even when one side writes a scalar:
+	// additions from both sides are kept, removals from either side win.
+	// YAML sequences are always merged this way.
+	SetKeys []string
+	// LatestKeys resolve concurrent edits to the lexically greater value.
+	// Meant for ISO timestamps such as `modified`.
+	LatestKeys []string
+}
+
+// FrontmatterConflict is a key both sides changed to different values.
+// A nil side means that side deleted the key.
+type FrontmatterConflict struct {
+	Key    string
+	Ours   *yaml.Node
+	Theirs *yaml.Node
+}
+
+// MergeFrontmatter merges three versions of a frontmatter block key by
+// key. Keys follow ours' order, with keys only theirs added appended at
+// the end. The merged body is ours'; callers merge bodies themselves.
+// Conflicting keys keep ours' value in the returned file and are listed
+// in the conflicts; render them with BytesWithConflicts.
+func MergeFrontmatter(base, ours, theirs *FrontmatterFile, opts MergeOptions) (*FrontmatterFile, []FrontmatterConflict) {
+	merged := &FrontmatterFile{path: ours.path, root: emptyMapping(), body: ours.body, hasFM: true, dirty: true}
+	var conflicts []FrontmatterConflict
+
+	keys := ours.Keys()
+	seen := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		seen[k] = true
+	}
+	for _, k := range theirs.Keys() {
+		if !seen[k] && !base.HasKey(k) {
+			keys = append(keys, k)
+			seen[k] = true
+		}
+	}
+	for _, k := range base.Keys() {
+		if !seen[k] {
+			// deleted by ours; theirs may have changed it
+			keys = append(keys, k)
+			seen[k] = true
+		}
+	}
+
+	for _, k := range keys {
+		_, b := base.findKey(k)
+		_, o := ours.findKey(k)
+		_, t := theirs.findKey(k)
+		value, ok := mergeValue(k, b, o, t, opts)
+		if !ok {
+			conflicts = append(conflicts, FrontmatterConflict{Key: k, Ours: o, Theirs: t})
+			value = o
+			if value == nil {
+				value = t
+			}
+		}
+		if value != nil {
+			merged.root.Content = append(merged.root.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k}, value)
+		}
+	}
+	return merged, conflicts
+}
+
+func mergeValue(key string, b, o, t *yaml.Node, opts MergeOptions) (*yaml.Node, bool) {
+	bStr, oStr, tStr := nodeString(b), nodeString(o), nodeString(t)
+	switch {
+	case oStr == tStr:
+		return o, true
+	case oStr == bStr:
+		return t, true
+	case tStr == bStr:
+		return o, true
+	}
+	if containsKey(opts.SetKeys, key) || isSequence(o) || isSequence(t) {
+		return mergeSet(nodeItems(b), nodeItems(o), nodeItems(t), o, t), true
+	}
+	if containsKey(opts.LatestKeys, key) && o != nil && t != nil {
+		if tStr > oStr {
+			return t, true
+		}
+		return o, true
+	}
+	return nil, false
+}
+
+func mergeSet(b, o, t []string, oNode, tNode *yaml.Node) *yaml.Node {
+	inBase := make(map[string]bool, len(b))
+	for _, s := range b {
+		inBase[s] = true
+	}
+	inOurs := make(map[string]bool, len(o))
+	for _, s := range o {
+		inOurs[s] = true
+	}
+	inTheirs := make(map[string]bool, len(t))
+	for _, s := range t {
+		inTheirs[s] = true
+	}
+	var out []string
+	for _, s := range o {
+		if inBase[s] && !inTheirs[s] {
+			continue
+		}
+		out = append(out, s)
+	}
+	for _, s := range t {
+		if !inOurs[s] && !inBase[s] {
+			out = append(out, s)
+		}
+	}
+	switch {
+	case len(out) == 0:
+		return nil
+	case len(out) == 1 && !isSequence(oNode) && !isSequence(tNode):
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: out[0]}
+	}
+	seq := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle, Tag: "!!seq"}
+	for _, s := range out {
+		seq.Content = append(seq.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s})
+	}
+	return seq
+}
+
+func nodeItems(n *yaml.Node) []string {
+	if n == nil {
+		return nil
+	}
+	if n.Kind == yaml.ScalarNode {
+		if s := strings.TrimSpace(n.Value); s != "" {
+			return []string{s}
+		}
+		return nil
+	}
+	var out []string
+	for _, c := range n.Content {
+		if c.Kind == yaml.ScalarNode {
+			out = append(out, c.Value)
+		}
+	}
+	return out
+}
+
+func isSequence(n *yaml.Node) bool { return n != nil && n.Kind == yaml.SequenceNode }
+
+func containsKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeString is the comparison form of a value: its YAML encoding, or ""
+// for a missing key.
+func nodeString(n *yaml.Node) string {
+	if n == nil {
+		return ""
+	}
+	data, err := yaml.Marshal(n)
+	if err != nil {
+		return n.Value
+	}
+	return string(data)
+}
+
+// BytesWithConflicts serializes the file like Bytes, but renders each
+// conflicting key as a git-style conflict block inside the frontmatter,
+// so the file stays unparseable until someone picks a side.
+func (f *FrontmatterFile) BytesWithConflicts(conflicts []FrontmatterConflict, oursLabel, theirsLabel string) []byte {
+	if len(conflicts) == 0 {
+		return f.Bytes()
+	}
+	byKey := make(map[string]FrontmatterConflict, len(conflicts))
+	for _, c := range conflicts {
+		byKey[c.Key] = c
+	}
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	for i := 0; i+1 < len(f.root.Content); i += 2 {
+		key := f.root.Content[i].Value
+		c, ok := byKey[key]
+		if !ok {
+			buf.Write(encodePair(key, f.root.Content[i+1]))
+			continue
+		}
+		writeConflict(&buf, c, oursLabel, theirsLabel)
+	}
+	buf.WriteString("---\n")
+	if f.body != "" {
+		if !strings.HasPrefix(f.body, "\n") {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(f.body)
+		if !strings.HasSuffix(f.body, "\n") {
+			buf.WriteString("\n")
+		}
+	}
+	return buf.Bytes()
+}
+
+func writeConflict(buf *bytes.Buffer, c FrontmatterConflict, oursLabel, theirsLabel string) {
+	buf.WriteString("<<<<<<< " + oursLabel + "\n")
+	if c.Ours != nil {
+		buf.Write(encodePair(c.Key, c.Ours))
+	}
+	buf.WriteString("=======\n")
+	if c.Theirs != nil {
+		buf.Write(encodePair(c.Key, c.Theirs))
+	}
+	buf.WriteString(">>>>>>> " + theirsLabel + "\n")
+}
+
+func encodePair(key string, value *yaml.Node) []byte {
+	m := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value,
+	}}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	_ = enc.Encode(m)
+	_ = enc.Close()
+	return buf.Bytes()
+}
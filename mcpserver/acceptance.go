@@ -32,7 +32,10 @@ type ListAcceptanceResult struct {
 
 func listAcceptanceTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, ListAcceptanceArgs) (*mcp.CallToolResult, ListAcceptanceResult, error) {
 	return func(ctx context.Context, req *mcp.CallToolRequest, args ListAcceptanceArgs) (*mcp.CallToolResult, ListAcceptanceResult, error) {
-		path := filepath.Join(root.Root(), args.Path)
+		path, err := backlog.ResolveItemPath(root, args.Path)
+		if err != nil {
+			return nil, ListAcceptanceResult{}, err
+		}
 		item, err := backlog.LoadBacklogItem(path)
 		if err != nil {
 			return nil, ListAcceptanceResult{}, err
@@ -47,7 +50,8 @@ func listAcceptanceTool(root *backlog.BacklogsStructure) func(context.Context, *
 				ClaimNote: b.ClaimNote,
 			})
 		}
-		return nil, ListAcceptanceResult{Path: args.Path, Bullets: out, Count: len(out)}, nil
+		rel, _ := filepath.Rel(root.Root(), path)
+		return nil, ListAcceptanceResult{Path: rel, Bullets: out, Count: len(out)}, nil
 	}
 }
 
@@ -64,7 +68,10 @@ func setAcceptanceStateTool(root *backlog.BacklogsStructure) func(context.Contex
 		if err != nil {
 			return nil, OkResult{}, err
 		}
-		path := filepath.Join(root.Root(), args.Path)
+		path, err := backlog.ResolveItemPath(root, args.Path)
+		if err != nil {
+			return nil, OkResult{}, err
+		}
 		item, err := backlog.LoadBacklogItem(path)
 		if err != nil {
 			return nil, OkResult{}, err
@@ -92,7 +99,10 @@ func appendAcceptanceBulletTool(root *backlog.BacklogsStructure) func(context.Co
 		if args.Text == "" {
 			return nil, OkResult{}, fmt.Errorf("text is required")
 		}
-		path := filepath.Join(root.Root(), args.Path)
+		path, err := backlog.ResolveItemPath(root, args.Path)
+		if err != nil {
+			return nil, OkResult{}, err
+		}
 		item, err := backlog.LoadBacklogItem(path)
 		if err != nil {
 			return nil, OkResult{}, err
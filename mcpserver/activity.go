@@ -0,0 +1,57 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mreider/agilemarkdown/backlog"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type ActivityFeedArgs struct {
+	Since   string `json:"since,omitempty" jsonschema:"only activity on or after this date (YYYY-MM-DD or RFC3339)"`
+	User    string `json:"user,omitempty" jsonschema:"only commits by this user (name, email or nickname)"`
+	Backlog string `json:"backlog,omitempty" jsonschema:"only items in this backlog"`
+	Limit   int    `json:"limit,omitempty" jsonschema:"max entries; defaults to 100"`
+}
+
+type ActivityFeedResult struct {
+	Entries []backlog.ActivityEntry `json:"entries"`
+	Count   int                     `json:"count"`
+}
+
+func activityFeedTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, ActivityFeedArgs) (*mcp.CallToolResult, ActivityFeedResult, error) {
+	return func(ctx context.Context, req *mcp.CallToolRequest, args ActivityFeedArgs) (*mcp.CallToolResult, ActivityFeedResult, error) {
+		since, err := parseActivitySince(args.Since)
+		if err != nil {
+			return nil, ActivityFeedResult{}, err
+		}
+		limit := args.Limit
+		if limit <= 0 {
+			limit = 100
+		}
+		entries, err := backlog.Activity(root, backlog.ActivityFilter{Since: since, User: args.User, Backlog: args.Backlog, Limit: limit})
+		if err != nil {
+			return nil, ActivityFeedResult{}, err
+		}
+		if entries == nil {
+			entries = []backlog.ActivityEntry{}
+		}
+		return nil, ActivityFeedResult{Entries: entries, Count: len(entries)}, nil
+	}
+}
+
+func parseActivitySince(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("since: want YYYY-MM-DD or RFC3339, got %q", s)
+}
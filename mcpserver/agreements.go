@@ -156,7 +156,10 @@ type SetHypothesisArgs struct {
 
 func setHypothesisTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, SetHypothesisArgs) (*mcp.CallToolResult, OkResult, error) {
 	return func(ctx context.Context, req *mcp.CallToolRequest, args SetHypothesisArgs) (*mcp.CallToolResult, OkResult, error) {
-		path := filepath.Join(root.Root(), args.Path)
+		path, err := backlog.ResolveItemPath(root, args.Path)
+		if err != nil {
+			return nil, OkResult{}, err
+		}
 		item, err := backlog.LoadBacklogItem(path)
 		if err != nil {
 			return nil, OkResult{}, err
@@ -8,6 +8,7 @@ package mcpserver
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/mreider/agilemarkdown/backlog"
 )
@@ -26,6 +27,21 @@ func IceboxList(ctx context.Context, root string, args IceboxListArgs) (IceboxLi
 	return r, err
 }
 
+func CreateEpic(ctx context.Context, root string, args CreateEpicArgs) (EpicRow, error) {
+	_, r, err := createEpicTool(wrapRoot(root))(ctx, nil, args)
+	return r, err
+}
+
+func ListEpics(ctx context.Context, root string, args ListEpicsArgs) (ListEpicsResult, error) {
+	_, r, err := listEpicsTool(wrapRoot(root))(ctx, nil, args)
+	return r, err
+}
+
+func CloseEpic(ctx context.Context, root string, args CloseEpicArgs) (EpicRow, error) {
+	_, r, err := closeEpicTool(wrapRoot(root))(ctx, nil, args)
+	return r, err
+}
+
 func EpicProgress(ctx context.Context, root string, args EpicProgressArgs) (EpicProgressResult, error) {
 	_, r, err := epicProgressTool(wrapRoot(root))(ctx, nil, args)
 	return r, err
@@ -56,6 +72,16 @@ func CumulativeFlow(ctx context.Context, root string, args CFDArgs) (CFDResult,
 	return r, err
 }
 
+func Forecast(ctx context.Context, root string, args ForecastArgs) (ForecastResult, error) {
+	_, r, err := forecastTool(wrapRoot(root))(ctx, nil, args)
+	return r, err
+}
+
+func ReleaseForecast(ctx context.Context, root string, args ReleaseForecastArgs) (ReleaseForecastResult, error) {
+	_, r, err := releaseForecastTool(wrapRoot(root))(ctx, nil, args)
+	return r, err
+}
+
 func Search(ctx context.Context, root string, args SearchArgs) (SearchResult, error) {
 	_, r, err := searchTool(wrapRoot(root))(ctx, nil, args)
 	return r, err
@@ -91,7 +117,105 @@ func SprintPlan(ctx context.Context, root string, args SprintPlanArgs) (SprintPl
 	return r, err
 }
 
+func SprintCommit(ctx context.Context, root string, args SprintCommitArgs) (SprintCommitResult, error) {
+	_, r, err := sprintCommitTool(wrapRoot(root))(ctx, nil, args)
+	return r, err
+}
+
+func ActivityFeed(ctx context.Context, root string, args ActivityFeedArgs) (ActivityFeedResult, error) {
+	_, r, err := activityFeedTool(wrapRoot(root))(ctx, nil, args)
+	return r, err
+}
+
 func SetDescription(ctx context.Context, root string, args SetDescriptionArgs) (OkResult, error) {
 	_, r, err := setDescriptionTool(wrapRoot(root))(ctx, nil, args)
 	return r, err
 }
+
+func DependencyGraph(ctx context.Context, root string, args DependencyGraphArgs) (DependencyGraphResult, error) {
+	_, r, err := dependencyGraphTool(wrapRoot(root))(ctx, nil, args)
+	return r, err
+}
+
+func RenameItem(ctx context.Context, root string, args RenameItemArgs) (RenameItemResult, error) {
+	_, r, err := renameItemTool(wrapRoot(root))(ctx, nil, args)
+	return r, err
+}
+
+func ListFilters(ctx context.Context, root string, args ListFiltersArgs) (ListFiltersResult, error) {
+	_, r, err := listFiltersTool(wrapRoot(root))(ctx, nil, args)
+	return r, err
+}
+
+func RunFilter(ctx context.Context, root string, args RunFilterArgs) (RunFilterResult, error) {
+	_, r, err := runFilterTool(wrapRoot(root))(ctx, nil, args)
+	return r, err
+}
+
+func BulkUpdate(ctx context.Context, root string, args BulkUpdateArgs) (BulkUpdateResult, error) {
+	_, r, err := bulkUpdateTool(wrapRoot(root))(ctx, nil, args)
+	return r, err
+}
+
+func UndoLast(ctx context.Context, root string, args UndoLastArgs) (UndoLastResult, error) {
+	_, r, err := undoLastTool(wrapRoot(root))(ctx, nil, args)
+	return r, err
+}
+
+func SetStatus(ctx context.Context, root string, args SetStatusArgs) (OkResult, error) {
+	_, r, err := setStatus(wrapRoot(root))(ctx, nil, args)
+	return r, err
+}
+
+func RankItem(ctx context.Context, root string, args RankItemArgs) (OkResult, error) {
+	_, r, err := rankItemTool(wrapRoot(root))(ctx, nil, args)
+	return r, err
+}
+
+func MoveToIcebox(ctx context.Context, root string, args MoveToIceboxArgs) (OkResult, error) {
+	_, r, err := moveToIceboxTool(wrapRoot(root))(ctx, nil, args)
+	return r, err
+}
+
+func MoveToPriority(ctx context.Context, root string, args MoveToPriorityArgs) (OkResult, error) {
+	_, r, err := moveToPriorityTool(wrapRoot(root))(ctx, nil, args)
+	return r, err
+}
+
+func RejectItem(ctx context.Context, root string, args RejectItemArgs) (OkResult, error) {
+	_, r, err := rejectItemTool(wrapRoot(root))(ctx, nil, args)
+	return r, err
+}
+
+func CoachCheck(ctx context.Context, root string, args CoachCheckArgs) (CoachVerdict, error) {
+	_, r, err := coachCheckTool(wrapRoot(root))(ctx, nil, args)
+	return r, err
+}
+
+// CoachCheckPM is CoachCheck for a change the PM makes themselves.
+func CoachCheckPM(ctx context.Context, root string, args CoachCheckArgs) (CoachVerdict, error) {
+	return pmCoachCheck(wrapRoot(root), args)
+}
+
+func CoachOverride(ctx context.Context, root string, args CoachOverrideArgs) (CoachOverrideResult, error) {
+	_, r, err := coachOverrideTool(wrapRoot(root), "cli")(ctx, nil, args)
+	return r, err
+}
+
+func AcceptancePrompt(ctx context.Context, root string, args AcceptancePromptArgs) (AcceptancePromptResult, error) {
+	_, r, err := acceptancePromptTool(wrapRoot(root))(ctx, nil, args)
+	return r, err
+}
+
+func SetAcceptanceState(ctx context.Context, root string, args SetAcceptanceStateArgs) (OkResult, error) {
+	_, r, err := setAcceptanceStateTool(wrapRoot(root))(ctx, nil, args)
+	return r, err
+}
+
+func GateToolUse(ctx context.Context, root string, tool string, input json.RawMessage) (GateDecision, error) {
+	return gateTool(wrapRoot(root), tool, input)
+}
+
+func Ceremony(ctx context.Context, root string, args CeremonyArgs) (CeremonyResult, error) {
+	return runCeremony(wrapRoot(root), args)
+}
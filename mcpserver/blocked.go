@@ -2,7 +2,6 @@ package mcpserver
 
 import (
 	"context"
-	"path/filepath"
 
 	"github.com/mreider/agilemarkdown/backlog"
 	"github.com/mreider/agilemarkdown/utils"
@@ -11,17 +10,29 @@ import (
 )
 
 type BlockItemArgs struct {
-	Path   string `json:"path"`
-	Reason string `json:"reason,omitempty"`
+	Path      string   `json:"path"`
+	Reason    string   `json:"reason,omitempty"`
+	BlockedBy []string `json:"blocked_by,omitempty" jsonschema:"paths (relative to the project root) of stories this one waits on; recorded as a dependency instead of the blocked flag"`
 }
 
 func blockItemTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, BlockItemArgs) (*mcp.CallToolResult, OkResult, error) {
 	return func(ctx context.Context, req *mcp.CallToolRequest, args BlockItemArgs) (*mcp.CallToolResult, OkResult, error) {
-		path := filepath.Join(root.Root(), args.Path)
+		path, err := backlog.ResolveItemPath(root, args.Path)
+		if err != nil {
+			return nil, OkResult{}, err
+		}
 		item, err := backlog.LoadBacklogItem(path)
 		if err != nil {
 			return nil, OkResult{}, err
 		}
+		if len(args.BlockedBy) > 0 {
+			for _, ref := range args.BlockedBy {
+				if err := backlog.AddBlocker(root, item, ref); err != nil {
+					return nil, OkResult{}, err
+				}
+			}
+			return nil, OkResult{OK: true}, nil
+		}
 		item.SetBlocked(true, args.Reason)
 		item.SetModified(utils.GetCurrentTimestamp())
 		if err := item.Save(); err != nil {
@@ -32,16 +43,28 @@ func blockItemTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.C
 }
 
 type UnblockItemArgs struct {
-	Path string `json:"path"`
+	Path      string   `json:"path"`
+	BlockedBy []string `json:"blocked_by,omitempty" jsonschema:"drop only these dependencies and leave the blocked flag alone"`
 }
 
 func unblockItemTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, UnblockItemArgs) (*mcp.CallToolResult, OkResult, error) {
 	return func(ctx context.Context, req *mcp.CallToolRequest, args UnblockItemArgs) (*mcp.CallToolResult, OkResult, error) {
-		path := filepath.Join(root.Root(), args.Path)
+		path, err := backlog.ResolveItemPath(root, args.Path)
+		if err != nil {
+			return nil, OkResult{}, err
+		}
 		item, err := backlog.LoadBacklogItem(path)
 		if err != nil {
 			return nil, OkResult{}, err
 		}
+		if len(args.BlockedBy) > 0 {
+			for _, ref := range args.BlockedBy {
+				if err := backlog.RemoveBlocker(root, item, ref); err != nil {
+					return nil, OkResult{}, err
+				}
+			}
+			return nil, OkResult{OK: true}, nil
+		}
 		item.SetBlocked(false, "")
 		item.SetModified(utils.GetCurrentTimestamp())
 		if err := item.Save(); err != nil {
@@ -58,7 +81,10 @@ type SetDescriptionArgs struct {
 
 func setDescriptionTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, SetDescriptionArgs) (*mcp.CallToolResult, OkResult, error) {
 	return func(ctx context.Context, req *mcp.CallToolRequest, args SetDescriptionArgs) (*mcp.CallToolResult, OkResult, error) {
-		path := filepath.Join(root.Root(), args.Path)
+		path, err := backlog.ResolveItemPath(root, args.Path)
+		if err != nil {
+			return nil, OkResult{}, err
+		}
 		item, err := backlog.LoadBacklogItem(path)
 		if err != nil {
 			return nil, OkResult{}, err
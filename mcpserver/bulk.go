@@ -0,0 +1,319 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mreider/agilemarkdown/actions"
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/utils"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type BulkUpdateArgs struct {
+	Where      string            `json:"where" jsonschema:"search query selecting the items, e.g. tag:auth status:unstarted (same syntax as search)"`
+	Set        map[string]string `json:"set,omitempty" jsonschema:"field -> value for estimate, status, type, owner (comma-separated, at most 3) or epic; an empty value or none clears"`
+	AddTags    []string          `json:"add_tags,omitempty"`
+	RemoveTags []string          `json:"remove_tags,omitempty"`
+	Move       string            `json:"move,omitempty" jsonschema:"icebox | priority; moved items go to the bottom of the list"`
+	Archived   bool              `json:"archived,omitempty" jsonschema:"include archived items in the match"`
+	DryRun     bool              `json:"dry_run,omitempty" jsonschema:"report the changes without writing"`
+}
+
+type BulkChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+type BulkItem struct {
+	Path    string         `json:"path"`
+	ID      int            `json:"id,omitempty"`
+	Title   string         `json:"title"`
+	Changes []BulkChange   `json:"changes"`
+	Coach   []CoachVerdict `json:"coach,omitempty" jsonschema:"refusals and nudges from the coach checks on this item's changes"`
+}
+
+type BulkUpdateResult struct {
+	Where   string     `json:"where"`
+	Matched int        `json:"matched"`
+	Changed int        `json:"changed"`
+	Refused int        `json:"refused" jsonschema:"items with a coach refusal; any refusal means nothing is written"`
+	DryRun  bool       `json:"dry_run,omitempty"`
+	Applied bool       `json:"applied"`
+	Items   []BulkItem `json:"items"`
+}
+
+var bulkSetFields = []string{"estimate", "status", "type", "owner", "epic"}
+
+// bulkUpdateTool edits every item matching a query in one go. Each edit
+// goes through the usual BacklogItem setters and the same coach checks
+// as set_estimate / set_status. A single refusal aborts the whole batch;
+// otherwise all item and order files are written together or not at all.
+// Stories an accepted item blocked are released after that write.
+func bulkUpdateTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, BulkUpdateArgs) (*mcp.CallToolResult, BulkUpdateResult, error) {
+	return func(ctx context.Context, req *mcp.CallToolRequest, args BulkUpdateArgs) (*mcp.CallToolResult, BulkUpdateResult, error) {
+		res := BulkUpdateResult{Where: args.Where, DryRun: args.DryRun, Items: []BulkItem{}}
+		set, err := normalizeBulkSet(root, args.Set)
+		if err != nil {
+			return nil, res, err
+		}
+		move := strings.ToLower(strings.TrimSpace(args.Move))
+		if move != "" && move != "icebox" && move != "priority" {
+			return nil, res, fmt.Errorf("move must be icebox or priority, not %q", args.Move)
+		}
+		if len(set) == 0 && len(args.AddTags) == 0 && len(args.RemoveTags) == 0 && move == "" {
+			return nil, res, fmt.Errorf("nothing to change: give set, add_tags, remove_tags or move")
+		}
+		q, err := backlog.ParseSearchQuery(args.Where)
+		if err != nil {
+			return nil, res, err
+		}
+		if len(q.Words)+len(q.Phrases)+len(q.Excluded)+len(q.Filters) == 0 {
+			return nil, res, fmt.Errorf("where is required; bulk edits never default to every item")
+		}
+		if q.UsesMe() {
+			q.ResolveMe(backlog.NewUserList(root.UsersDirectory()).CurrentUserHandles())
+		}
+		idx, err := backlog.UpdateSearchIndex(root)
+		if err != nil {
+			return nil, res, err
+		}
+		matches := idx.Search(q, args.Archived)
+		res.Matched = len(matches)
+
+		rules, err := loadCoachRules(root)
+		if err != nil {
+			return nil, res, err
+		}
+		now := utils.GetCurrentTimestamp()
+		files := map[string][]byte{}
+		var accepted []string
+		orders := map[string][2]*backlog.OrderFile{}
+		for _, m := range matches {
+			path := filepath.Join(root.Root(), filepath.FromSlash(m.Path))
+			item, err := backlog.LoadBacklogItem(path)
+			if err != nil {
+				return nil, res, err
+			}
+			bi := BulkItem{Path: m.Path, ID: item.ID(), Title: item.Title(), Changes: []BulkChange{}}
+			change := func(field, from, to string) {
+				if from != to {
+					bi.Changes = append(bi.Changes, BulkChange{Field: field, From: from, To: to})
+				}
+			}
+			typ := item.Type()
+			if v, ok := set["type"]; ok {
+				change("type", typ, v)
+				item.SetType(v)
+			}
+			if v, ok := set["estimate"]; ok {
+				change("estimate", item.Estimate(), v)
+				item.SetEstimate(v)
+			}
+			if v, ok := set["owner"]; ok {
+				var owners []string
+				if v != "" {
+					owners = strings.Split(v, ",")
+				}
+				before := item.Assigned()
+				item.SetAssignees(owners)
+				change("owner", before, item.Assigned())
+			}
+			if v, ok := set["epic"]; ok {
+				change("epic", item.Epic(), v)
+				item.SetEpic(v)
+			}
+			if len(args.AddTags) > 0 || len(args.RemoveTags) > 0 {
+				before := item.Tags()
+				item.SetTags(editTags(before, args.AddTags, args.RemoveTags))
+				change("tags", strings.Join(before, ", "), strings.Join(item.Tags(), ", "))
+			}
+			if v, ok := set["status"]; ok && v != item.Status() {
+				verdict := rules.verdict(&coachCase{action: "set_status", path: m.Path, item: item, typ: item.Type(), status: v})
+				if !verdict.Allowed || verdict.Nudge {
+					bi.Coach = append(bi.Coach, verdict)
+				}
+				change("status", item.Status(), v)
+				actions.ApplyStatusTransition(item, backlog.StatusByName(v))
+				if v == backlog.AcceptedStatus.Name {
+					accepted = append(accepted, path)
+				}
+			}
+			if hasBulkChange(bi.Changes, "estimate", "type") && item.Estimate() != "" {
+				if pts, err := strconv.ParseFloat(item.Estimate(), 64); err == nil {
+					verdict := rules.verdict(&coachCase{action: "set_estimate", path: m.Path, item: item, typ: item.Type(), points: pts, estimated: true})
+					if !verdict.Allowed || verdict.Nudge {
+						bi.Coach = append(bi.Coach, verdict)
+					}
+				}
+			}
+			if move != "" && !m.Archived {
+				from, err := bulkMove(orders, item, move)
+				if err != nil {
+					return nil, res, err
+				}
+				change("list", from, move)
+			}
+			if len(bi.Changes) == 0 {
+				continue
+			}
+			if item.File().Dirty() {
+				item.SetModified(now)
+				files[path] = item.Content()
+			}
+			for _, v := range bi.Coach {
+				if !v.Allowed {
+					res.Refused++
+					break
+				}
+			}
+			res.Items = append(res.Items, bi)
+		}
+		res.Changed = len(res.Items)
+		if res.Refused > 0 || args.DryRun || res.Changed == 0 {
+			return nil, res, nil
+		}
+		for _, pair := range orders {
+			for _, f := range pair {
+				files[f.Path()] = f.Bytes()
+			}
+		}
+		if err := backlog.WriteFilesAtomically(files); err != nil {
+			return nil, res, err
+		}
+		res.Applied = true
+		// Accepted blockers let their dependents go, as set_status does.
+		for _, path := range accepted {
+			item, err := backlog.LoadBacklogItem(path)
+			if err != nil {
+				return nil, res, err
+			}
+			if _, err := backlog.ReleaseDependents(root, item); err != nil {
+				return nil, res, err
+			}
+		}
+		return nil, res, nil
+	}
+}
+
+// normalizeBulkSet validates the set map and canonicalizes its values:
+// "none" becomes empty (clear), statuses and types are checked, and an
+// epic must exist.
+func normalizeBulkSet(root *backlog.BacklogsStructure, in map[string]string) (map[string]string, error) {
+	out := map[string]string{}
+	for k, v := range in {
+		field := strings.ToLower(strings.TrimSpace(k))
+		if field == "assigned" || field == "assignee" {
+			field = "owner"
+		}
+		v = strings.TrimSpace(v)
+		if strings.EqualFold(v, "none") {
+			v = ""
+		}
+		switch field {
+		case "estimate":
+			if v != "" {
+				if _, err := strconv.ParseFloat(v, 64); err != nil {
+					return nil, fmt.Errorf("estimate must be numeric, not %q", v)
+				}
+			}
+		case "status":
+			st := backlog.StatusByName(v)
+			if st == nil {
+				return nil, fmt.Errorf("invalid status %q (valid: %s)", v, backlog.AllStatusesList())
+			}
+			v = st.Name
+		case "type":
+			v = strings.ToLower(v)
+			switch v {
+			case "feature", "bug", "chore", "release":
+			default:
+				return nil, fmt.Errorf("type must be feature, bug, chore or release, not %q", v)
+			}
+		case "owner":
+			if n := len(strings.Split(v, ",")); v != "" && n > 3 {
+				return nil, fmt.Errorf("at most 3 assignees allowed; got %d", n)
+			}
+		case "epic":
+			if err := backlog.CheckEpicRef(root, v); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("can't set %q (settable: %s)", k, strings.Join(bulkSetFields, ", "))
+		}
+		out[field] = v
+	}
+	return out, nil
+}
+
+func editTags(tags, add, remove []string) []string {
+	drop := map[string]bool{}
+	for _, t := range remove {
+		drop[strings.ToLower(strings.TrimSpace(t))] = true
+	}
+	var out []string
+	seen := map[string]bool{}
+	for _, t := range append(append([]string{}, tags...), add...) {
+		key := strings.ToLower(strings.TrimSpace(t))
+		if key == "" || drop[key] || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, strings.TrimSpace(t))
+	}
+	return out
+}
+
+func hasBulkChange(changes []BulkChange, fields ...string) bool {
+	for _, c := range changes {
+		for _, f := range fields {
+			if c.Field == f {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// bulkMove moves item's entry to the bottom of the icebox or priority
+// list of its backlog, loading each backlog's pair of order files once.
+// Returns the list it was on before ("none" when on neither).
+func bulkMove(orders map[string][2]*backlog.OrderFile, item *backlog.BacklogItem, to string) (string, error) {
+	dir := filepath.Dir(item.Path())
+	pair, ok := orders[dir]
+	if !ok {
+		pri, err := backlog.LoadPriority(dir)
+		if err != nil {
+			return "", err
+		}
+		ice, err := backlog.LoadIcebox(dir)
+		if err != nil {
+			return "", err
+		}
+		pair = [2]*backlog.OrderFile{pri, ice}
+		orders[dir] = pair
+	}
+	pri, ice := pair[0], pair[1]
+	base := filepath.Base(item.Path())
+	from, target, other := "none", ice, pri
+	if to == "priority" {
+		target, other = pri, ice
+	}
+	switch {
+	case pri.IndexOf(base) >= 0:
+		from = "priority"
+	case ice.IndexOf(base) >= 0:
+		from = "icebox"
+	}
+	if from == to {
+		return from, nil
+	}
+	other.Remove(base)
+	target.InsertBottom(backlog.OrderEntry{Title: item.Title(), Path: base})
+	return from, nil
+}
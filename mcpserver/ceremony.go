@@ -0,0 +1,205 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/coach"
+)
+
+type CeremonyArgs struct {
+	Name      string            `json:"name"`
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+type CeremonyResult struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Team        bool   `json:"team,omitempty"`
+	Text        string `json:"text"`
+}
+
+// findCeremony looks name up among the built-in coach skills, then the
+// team's own in .am/skills, so a broken team skill cannot take the
+// built-ins down with it.
+func findCeremony(root *backlog.BacklogsStructure, name string) (coach.Skill, error) {
+	skills, err := coach.Skills()
+	if err != nil {
+		return coach.Skill{}, err
+	}
+	if i := slices.IndexFunc(skills, func(s coach.Skill) bool { return s.Name == name }); i >= 0 {
+		return skills[i], nil
+	}
+	team, err := coach.TeamSkills(root.Root())
+	if err != nil {
+		return coach.Skill{}, err
+	}
+	if i := slices.IndexFunc(team, func(s coach.Skill) bool { return s.Name == name }); i >= 0 {
+		return team[i], nil
+	}
+	var names []string
+	for _, s := range append(skills, team...) {
+		names = append(names, s.Name)
+	}
+	return coach.Skill{}, fmt.Errorf("no ceremony %q (have: %s)", name, strings.Join(names, ", "))
+}
+
+// runCeremony renders a ceremony's script. A team skill's body is a
+// text/template executed against the backlog as it is now (the funcs
+// in ceremonyFuncs, arguments as {{.name}}); a built-in's is used as
+// written. Either way the arguments given are listed at the end.
+func runCeremony(root *backlog.BacklogsStructure, args CeremonyArgs) (CeremonyResult, error) {
+	s, err := findCeremony(root, args.Name)
+	if err != nil {
+		return CeremonyResult{}, err
+	}
+	declared := promptArguments(s)
+	for k := range args.Arguments {
+		if !slices.ContainsFunc(declared, func(a *mcp.PromptArgument) bool { return a.Name == k }) {
+			return CeremonyResult{}, fmt.Errorf("%s takes no argument %q", s.Name, k)
+		}
+	}
+	text := s.Body
+	if s.Source != "" {
+		tmpl, err := template.New(s.Name).Funcs(ceremonyFuncs(root)).Option("missingkey=zero").Parse(s.Body)
+		if err != nil {
+			return CeremonyResult{}, fmt.Errorf("%s: %w", s.Source, err)
+		}
+		data := map[string]string{}
+		for _, a := range declared {
+			data[a.Name] = strings.TrimSpace(args.Arguments[a.Name])
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, data); err != nil {
+			return CeremonyResult{}, fmt.Errorf("%s: %w", s.Source, err)
+		}
+		text = b.String()
+	}
+	var given []string
+	for _, a := range declared {
+		if v := strings.TrimSpace(args.Arguments[a.Name]); v != "" {
+			given = append(given, fmt.Sprintf("- %s: %s", a.Name, v))
+		}
+	}
+	if len(given) > 0 {
+		text = strings.TrimRight(text, "\n") + "\n\n## This run\n\n" + strings.Join(given, "\n") + "\n"
+	}
+	return CeremonyResult{Name: s.Name, Description: s.Description, Team: s.Source != "", Text: text}, nil
+}
+
+// ceremonyFuncs is the live data a team skill can pull into its script:
+//
+//	{{today}}                       the date, YYYY-MM-DD
+//	{{iteration}}                   the current iteration number
+//	{{velocity "product"}}          a backlog's velocity ("" for all)
+//	{{count "type:bug -status:accepted"}}
+//	{{search "type:bug status:unstarted"}}  matching stories, one per line
+//	{{priority "product"}}          a backlog's priority order
+//	{{icebox "product"}}            a backlog's icebox
+//
+// Queries are am search syntax. Lists come back as markdown bullets.
+func ceremonyFuncs(root *backlog.BacklogsStructure) template.FuncMap {
+	ctx := context.Background()
+	return template.FuncMap{
+		"today": func() string { return time.Now().Format("2006-01-02") },
+		"iteration": func() (int, error) {
+			_, r, err := dashboardTool(root)(ctx, nil, DashboardArgs{})
+			return r.Iteration, err
+		},
+		"velocity": func(name string) (float64, error) {
+			_, r, err := dashboardTool(root)(ctx, nil, DashboardArgs{Backlog: name})
+			return r.Velocity, err
+		},
+		"count": func(query string) (int, error) {
+			matches, err := ceremonySearch(root, query)
+			return len(matches), err
+		},
+		"search": func(query string) (string, error) {
+			matches, err := ceremonySearch(root, query)
+			if err != nil {
+				return "", err
+			}
+			var lines []string
+			for _, m := range matches {
+				lines = append(lines, ceremonyLine(m.ID, m.Title, m.Path, m.Type, m.Status, m.Estimate))
+			}
+			return bullets(lines), nil
+		},
+		"priority": func(name string) (string, error) {
+			_, r, err := priorityListTool(root)(ctx, nil, PriorityListArgs{Backlog: name})
+			if err != nil {
+				return "", err
+			}
+			return orderBullets(name, r.Items), nil
+		},
+		"icebox": func(name string) (string, error) {
+			_, r, err := iceboxListTool(root)(ctx, nil, IceboxListArgs{Backlog: name})
+			if err != nil {
+				return "", err
+			}
+			return orderBullets(name, r.Items), nil
+		},
+	}
+}
+
+// ceremonySearch is searchTool without the hit limit: a ceremony wants
+// every bug, not the top twenty.
+func ceremonySearch(root *backlog.BacklogsStructure, query string) ([]backlog.SearchMatch, error) {
+	q, err := backlog.ParseSearchQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(q.Words)+len(q.Phrases)+len(q.Excluded)+len(q.Filters) == 0 {
+		return nil, nil
+	}
+	if q.UsesMe() {
+		q.ResolveMe(backlog.NewUserList(root.UsersDirectory()).CurrentUserHandles())
+	}
+	idx, err := backlog.UpdateSearchIndex(root)
+	if err != nil {
+		return nil, err
+	}
+	return idx.Search(q, false), nil
+}
+
+func orderBullets(name string, rows []OrderRow) string {
+	var lines []string
+	for _, r := range rows {
+		lines = append(lines, ceremonyLine(0, r.Title, name+"/"+r.Path, r.Type, r.Status, r.Estimate))
+	}
+	return bullets(lines)
+}
+
+func ceremonyLine(id int, title, path, typ, status, estimate string) string {
+	line := title
+	if id > 0 {
+		line = fmt.Sprintf("#%d %s", id, title)
+	}
+	line += " (" + path + ")"
+	var facts []string
+	for _, f := range []string{typ, status} {
+		if f != "" {
+			facts = append(facts, f)
+		}
+	}
+	if estimate != "" {
+		facts = append(facts, estimate+" pts")
+	}
+	if len(facts) > 0 {
+		line += ": " + strings.Join(facts, ", ")
+	}
+	return line
+}
+
+func bullets(lines []string) string {
+	if len(lines) == 0 {
+		return "- (none)"
+	}
+	return "- " + strings.Join(lines, "\n- ")
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/mreider/agilemarkdown/backlog"
 	"github.com/mreider/agilemarkdown/config"
+	"github.com/mreider/agilemarkdown/git"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -37,176 +38,156 @@ type CoachVerdict struct {
 	Detail  string `json:"detail,omitempty"`
 }
 
-// coachCheckTool runs the canon checks. Hard refusals (allowed=false) on:
-//   - 8-pt cap on features
+// coachCheckTool runs the canon checks (coachRegistry in
+// coach_rules.go) as `.am/coach.yaml` tunes them, then the team's custom
+// rules. Hard refusals (allowed=false) by default on:
+//   - 8-pt cap on features (the top of the estimation scale)
 //   - bugs/chores with an estimate
 //   - dev-as-PM accepting their own story (always render PM ceremony)
 //   - releases moving through state machine
+//   - pulling a feature with no `## Acceptance` section
 //
-// Nudges (allowed=true, nudge=true) on:
+// Nudges (allowed=true, nudge=true) by default on:
 //   - feature start with no `## Acceptance` section in the body
-//   - iteration overcommit beyond rolling velocity
 func coachCheckTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, CoachCheckArgs) (*mcp.CallToolResult, CoachVerdict, error) {
 	return func(ctx context.Context, req *mcp.CallToolRequest, args CoachCheckArgs) (*mcp.CallToolResult, CoachVerdict, error) {
-		switch strings.ToLower(strings.TrimSpace(args.Action)) {
-		case "set_status":
-			return checkSetStatus(root, args)
-		case "set_estimate":
-			return checkSetEstimate(root, args)
-		case "create_item":
-			return checkCreateItem(args)
-		case "pull":
-			return checkPull(root, args)
+		rules, c, early, err := prepareCoachCheck(root, args)
+		if err != nil || early != nil {
+			return nil, derefVerdict(early), err
 		}
-		return nil, CoachVerdict{Allowed: true, Detail: "no rule registered for this action"}, nil
+		return nil, rules.verdict(c), nil
 	}
 }
 
-// checkPull is the pre-pull alignment gate. It fires when the agent is
-// about to start coding on a story (am pull, am-align skill, or any
-// caller that wants the same hard check). When a feature has no
-// `## Acceptance` section in its body, the check refuses: agents that
-// confidently build the wrong thing are the central failure mode of
-// AI-paired delivery, and a story without acceptance criteria gives the
-// agent nothing to build toward.
-func checkPull(root *backlog.BacklogsStructure, args CoachCheckArgs) (*mcp.CallToolResult, CoachVerdict, error) {
-	if args.Path == "" {
-		return nil, CoachVerdict{}, fmt.Errorf("path is required for pull check")
+// pmCoachCheck judges a change the PM makes by hand, such as accepting
+// through the board's ceremony form. Every rule applies except
+// coach-refuses-pm-accepts, which is there to stop the dev pair.
+func pmCoachCheck(root *backlog.BacklogsStructure, args CoachCheckArgs) (CoachVerdict, error) {
+	rules, c, early, err := prepareCoachCheck(root, args)
+	if err != nil || early != nil {
+		return derefVerdict(early), err
 	}
-	path := filepath.Join(root.Root(), args.Path)
-	item, err := backlog.LoadBacklogItem(path)
-	if err != nil {
-		return nil, CoachVerdict{}, err
-	}
-	typ := item.Type()
-	if typ == "" {
-		typ = "feature"
-	}
-	// Only features need acceptance criteria. Bugs and chores have their
-	// own conventions for "done" that do not flow through the bullet
-	// ceremony.
-	if typ != "feature" {
-		return nil, CoachVerdict{Allowed: true}, nil
-	}
-	if backlog.AcceptanceBulletTexts(item.Body()) == nil {
-		return nil, CoachVerdict{
-			Allowed: false,
-			Rule:    "feature has no acceptance criteria",
-			Source:  "acceptance-before-pull",
-			Next:    fmt.Sprintf("draft a `## Acceptance` section in %s, or run /am-align before pulling", args.Path),
-		}, nil
-	}
-	return nil, CoachVerdict{Allowed: true}, nil
+	rules.skip = "coach-refuses-pm-accepts"
+	return rules.verdict(c), nil
 }
 
-func checkSetStatus(root *backlog.BacklogsStructure, args CoachCheckArgs) (*mcp.CallToolResult, CoachVerdict, error) {
-	if args.Path == "" {
-		return nil, CoachVerdict{}, fmt.Errorf("path is required for set_status check")
+// prepareCoachCheck loads the rules and the item under review. early is
+// set when the answer does not need the rules at all.
+func prepareCoachCheck(root *backlog.BacklogsStructure, args CoachCheckArgs) (*coachRules, *coachCase, *CoachVerdict, error) {
+	action := strings.ToLower(strings.TrimSpace(args.Action))
+	switch action {
+	case "set_status", "set_estimate", "create_item", "pull":
+	default:
+		return nil, nil, &CoachVerdict{Allowed: true, Detail: "no rule registered for this action"}, nil
 	}
-	target := strings.ToLower(strings.TrimSpace(args.Status))
-	path := filepath.Join(root.Root(), args.Path)
-	item, err := backlog.LoadBacklogItem(path)
+	rules, err := loadCoachRules(root)
 	if err != nil {
-		return nil, CoachVerdict{}, err
-	}
-	if target == backlog.AcceptedStatus.Name {
-		return nil, CoachVerdict{
-			Allowed: false,
-			Rule:    "the dev pair does not accept its own work",
-			Source:  "coach-refuses-pm-accepts",
-			Next:    fmt.Sprintf("render PM ceremony with acceptance_prompt(path=%q) and wait for the human", args.Path),
-		}, nil
+		return nil, nil, nil, err
 	}
-	if item.Type() == "release" {
-		return nil, CoachVerdict{
-			Allowed: false,
-			Rule:    "releases are date markers, not state-machine items",
-			Source:  "dates-slide-scope-doesnt",
-			Next:    "leave status alone; update release_date instead",
-		}, nil
+	c := &coachCase{
+		action: action,
+		path:   args.Path,
+		typ:    strings.ToLower(strings.TrimSpace(args.Type)),
+		status: strings.ToLower(strings.TrimSpace(args.Status)),
 	}
-	if target == backlog.StartedStatus.Name && (item.Type() == "" || item.Type() == "feature") {
-		if backlog.AcceptanceBulletTexts(item.Body()) == nil {
-			return nil, CoachVerdict{
-				Allowed: true,
-				Nudge:   true,
-				Rule:    "feature has no acceptance criteria",
-				Source:  "acceptance-criteria",
-				Next:    "add a `## Acceptance` section with bullets to the body before starting, or skip with intent",
-			}, nil
+	if action != "create_item" {
+		if args.Path == "" {
+			return nil, nil, nil, fmt.Errorf("path is required for %s check", action)
 		}
-	}
-	return nil, CoachVerdict{Allowed: true}, nil
-}
-
-func checkSetEstimate(root *backlog.BacklogsStructure, args CoachCheckArgs) (*mcp.CallToolResult, CoachVerdict, error) {
-	if args.Path == "" {
-		return nil, CoachVerdict{}, fmt.Errorf("path is required for set_estimate check")
-	}
-	pts, err := strconv.ParseFloat(strings.TrimSpace(args.Estimate), 64)
-	if err != nil {
-		return nil, CoachVerdict{Allowed: false, Rule: "estimate must be numeric", Source: "8-point-hard-cap"}, nil
-	}
-	path := filepath.Join(root.Root(), args.Path)
-	item, err := backlog.LoadBacklogItem(path)
-	if err != nil {
-		return nil, CoachVerdict{}, err
-	}
-	cfg, _ := config.LoadConfig(filepath.Join(root.Root(), ".am", "config.yaml"))
-	typ := item.Type()
-	if typ == "" {
-		typ = "feature"
-	}
-	if pts > 0 {
-		if typ == "bug" && (cfg == nil || !cfg.StoryTypes.BugEstimable) {
-			return nil, CoachVerdict{
-				Allowed: false,
-				Rule:    "bugs are not pointed by default",
-				Source:  "bugs-are-tax",
-				Next:    "strip the estimate, or convert to a feature, or set story_types.bug_estimable in .am/config.yaml",
-			}, nil
+		path, err := backlog.ResolveItemPath(root, args.Path)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if c.item, err = backlog.LoadBacklogItem(path); err != nil {
+			return nil, nil, nil, err
 		}
-		if typ == "chore" && (cfg == nil || !cfg.StoryTypes.ChoreEstimable) {
-			return nil, CoachVerdict{
-				Allowed: false,
-				Rule:    "chores are not pointed by default",
-				Source:  "toil-is-not-progress",
-				Next:    "strip the estimate, or set story_types.chore_estimable in .am/config.yaml",
-			}, nil
+		if c.typ == "" {
+			c.typ = c.item.Type()
 		}
 	}
-	if typ == "feature" && pts > 8 {
-		return nil, CoachVerdict{
-			Allowed: false,
-			Rule:    "features over 8 points are epics",
-			Source:  "8-point-hard-cap",
-			Next:    "split the story; keep each piece at or below 8 points",
-		}, nil
+	if est := strings.TrimSpace(args.Estimate); action == "set_estimate" || action == "create_item" && est != "" {
+		if c.points, err = strconv.ParseFloat(est, 64); err != nil {
+			return nil, nil, &CoachVerdict{Allowed: false, Rule: "estimate must be numeric", Source: "8-point-hard-cap"}, nil
+		}
+		c.estimated = true
 	}
-	return nil, CoachVerdict{Allowed: true}, nil
+	return rules, c, nil, nil
 }
 
-func checkCreateItem(args CoachCheckArgs) (*mcp.CallToolResult, CoachVerdict, error) {
-	if args.Estimate == "" {
-		return nil, CoachVerdict{Allowed: true}, nil
-	}
-	pts, err := strconv.ParseFloat(strings.TrimSpace(args.Estimate), 64)
-	if err != nil {
-		return nil, CoachVerdict{Allowed: false, Rule: "estimate must be numeric"}, nil
+func derefVerdict(v *CoachVerdict) CoachVerdict {
+	if v == nil {
+		return CoachVerdict{}
 	}
-	typ := strings.ToLower(strings.TrimSpace(args.Type))
-	if typ == "" {
-		typ = "feature"
-	}
-	if typ == "feature" && pts > 8 {
-		return nil, CoachVerdict{
-			Allowed: false,
-			Rule:    "features over 8 points are epics",
-			Source:  "8-point-hard-cap",
-			Next:    "split the story before creating it",
-		}, nil
+	return *v
+}
+
+// CoachOverrideArgs is a coach_check plus the human's reason for going
+// ahead anyway.
+type CoachOverrideArgs struct {
+	Action   string `json:"action" jsonschema:"set_status | set_estimate | create_item | pull"`
+	Path     string `json:"path,omitempty" jsonschema:"path of the item, when relevant"`
+	Status   string `json:"status,omitempty" jsonschema:"target status when action=set_status"`
+	Estimate string `json:"estimate,omitempty" jsonschema:"target estimate when action=set_estimate or create_item"`
+	Type     string `json:"type,omitempty" jsonschema:"story type when action=create_item or coercing a check"`
+	Reason   string `json:"reason" jsonschema:"why the team is going ahead anyway, in the human's words"`
+}
+
+type CoachOverrideResult struct {
+	Verdict  CoachVerdict          `json:"verdict" jsonschema:"the refusal or nudge being overridden"`
+	Override backlog.CoachOverride `json:"override"`
+}
+
+// coachOverrideTool records that a human is going ahead despite the
+// coach. For the next hour the same rule on the same item and action
+// comes back allowed, with the override in Detail, so the write (and
+// the hook gating it) goes through; after that the rule applies again.
+// source is "mcp" or "cli".
+func coachOverrideTool(root *backlog.BacklogsStructure, source string) func(context.Context, *mcp.CallToolRequest, CoachOverrideArgs) (*mcp.CallToolResult, CoachOverrideResult, error) {
+	return func(ctx context.Context, req *mcp.CallToolRequest, args CoachOverrideArgs) (*mcp.CallToolResult, CoachOverrideResult, error) {
+		if strings.TrimSpace(args.Reason) == "" {
+			return nil, CoachOverrideResult{}, fmt.Errorf("reason is required: say why the team is going ahead anyway")
+		}
+		rules, c, early, err := prepareCoachCheck(root, CoachCheckArgs{
+			Action:   args.Action,
+			Path:     args.Path,
+			Status:   args.Status,
+			Estimate: args.Estimate,
+			Type:     args.Type,
+		})
+		if err != nil {
+			return nil, CoachOverrideResult{}, err
+		}
+		// Judge the action afresh: an earlier override must not make
+		// this one look like there is nothing to override.
+		var v CoachVerdict
+		if early == nil {
+			rules.overrides = nil
+			v = rules.verdict(c)
+		}
+		if early != nil || v.Allowed && !v.Nudge {
+			return nil, CoachOverrideResult{}, fmt.Errorf("the coach has nothing to override here")
+		}
+		author := requestGitAuthor(req)
+		if author == "" {
+			name, email, _ := git.CurrentUser()
+			author = name
+			if email != "" {
+				author = fmt.Sprintf("%s <%s>", name, email)
+			}
+		}
+		o, err := backlog.AppendCoachOverride(root, backlog.CoachOverride{
+			Author: author,
+			Source: source,
+			Rule:   v.Source,
+			Action: c.action,
+			Item:   rules.itemKey(c),
+			Reason: strings.TrimSpace(args.Reason),
+		})
+		if err != nil {
+			return nil, CoachOverrideResult{}, err
+		}
+		return nil, CoachOverrideResult{Verdict: v, Override: o}, nil
 	}
-	return nil, CoachVerdict{Allowed: true}, nil
 }
 
 // AcceptancePromptArgs renders the PM ceremony for one delivered story.
@@ -230,7 +211,10 @@ type AcceptancePromptResult struct {
 // set_status or reject_item.
 func acceptancePromptTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, AcceptancePromptArgs) (*mcp.CallToolResult, AcceptancePromptResult, error) {
 	return func(ctx context.Context, req *mcp.CallToolRequest, args AcceptancePromptArgs) (*mcp.CallToolResult, AcceptancePromptResult, error) {
-		path := filepath.Join(root.Root(), args.Path)
+		path, err := backlog.ResolveItemPath(root, args.Path)
+		if err != nil {
+			return nil, AcceptancePromptResult{}, err
+		}
 		item, err := backlog.LoadBacklogItem(path)
 		if err != nil {
 			return nil, AcceptancePromptResult{}, err
@@ -245,8 +229,9 @@ func acceptancePromptTool(root *backlog.BacklogsStructure) func(context.Context,
 		if typ == "" {
 			typ = "feature"
 		}
+		rel, _ := filepath.Rel(root.Root(), path)
 		var b strings.Builder
-		fmt.Fprintf(&b, "Story: %s (%s)\n", item.Title(), args.Path)
+		fmt.Fprintf(&b, "Story: %s (%s)\n", item.Title(), rel)
 		fmt.Fprintf(&b, "Type: %s\n", typ)
 		fmt.Fprintf(&b, "Status staging: %s -> accepted\n", item.Status())
 		if est := item.Estimate(); est != "" {
@@ -269,7 +254,7 @@ func acceptancePromptTool(root *backlog.BacklogsStructure) func(context.Context,
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: b.String()}},
 		}, AcceptancePromptResult{
-			Path:       args.Path,
+			Path:       rel,
 			Title:      item.Title(),
 			Type:       typ,
 			Status:     item.Status(),
@@ -323,33 +308,13 @@ func iterationFitTool(root *backlog.BacklogsStructure) func(context.Context, *mc
 		if err != nil {
 			return nil, IterationFitResult{}, err
 		}
-		byPath := indexItems(bck)
-		var planned float64
-		var counted int
-		cap := velocity
-		for _, e := range pri.Entries() {
-			it, ok := byPath[e.Path]
-			if !ok {
-				continue
-			}
-			if strings.EqualFold(it.Status(), backlog.AcceptedStatus.Name) {
-				continue
-			}
-			pts := parsePoints(it.Estimate())
-			if pts <= 0 {
-				continue
-			}
-			if cap > 0 && planned+pts > cap {
-				break
-			}
-			planned += pts
-			counted++
-		}
+		band, planned := iterationBand(pri.Entries(), indexItems(bck), velocity)
+		counted := len(band)
 		if args.CandidatePath != "" {
-			candPath := filepath.Join(root.Root(), args.CandidatePath)
-			cand, err := backlog.LoadBacklogItem(candPath)
-			if err == nil {
-				planned += parsePoints(cand.Estimate())
+			if candPath, err := backlog.ResolveItemPath(root, args.CandidatePath); err == nil {
+				if cand, err := backlog.LoadBacklogItem(candPath); err == nil {
+					planned += parsePoints(cand.Estimate())
+				}
 			}
 		}
 		_ = now
@@ -365,3 +330,31 @@ func iterationFitTool(root *backlog.BacklogsStructure) func(context.Context, *mc
 		}, nil
 	}
 }
+
+// iterationBand walks the priority list the way yesterday's weather
+// fills an iteration: unfinished, pointed items in order until the next
+// one would go over velocity (no cap when velocity is 0). It returns
+// the basenames counted and their points.
+func iterationBand(entries []backlog.OrderEntry, byPath map[string]*backlog.BacklogItem, velocity float64) ([]string, float64) {
+	var band []string
+	var planned float64
+	for _, e := range entries {
+		it, ok := byPath[e.Path]
+		if !ok {
+			continue
+		}
+		if strings.EqualFold(it.Status(), backlog.AcceptedStatus.Name) {
+			continue
+		}
+		pts := parsePoints(it.Estimate())
+		if pts <= 0 {
+			continue
+		}
+		if velocity > 0 && planned+pts > velocity {
+			break
+		}
+		planned += pts
+		band = append(band, e.Path)
+	}
+	return band, planned
+}
@@ -0,0 +1,371 @@
+package mcpserver
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/config"
+	"github.com/mreider/agilemarkdown/utils"
+)
+
+// coachRule is one built-in canon check. check returns the refusal (Rule
+// and Next) or nil when the case passes; the engine fills in Source and
+// the level. nudge is the default level, which `.am/coach.yaml` can
+// change either way.
+type coachRule struct {
+	slug    string
+	actions []string
+	nudge   bool
+	check   func(c *coachCase, p coachParams) *CoachVerdict
+}
+
+// coachParams are a rule's settings after `.am/coach.yaml` is applied.
+type coachParams struct {
+	max   float64
+	types []string
+}
+
+// coachCase is the action under review. item is the story as it is on
+// disk (nil for create_item); typ is its type after the action, never
+// empty.
+type coachCase struct {
+	action    string
+	path      string
+	item      *backlog.BacklogItem
+	typ       string
+	status    string
+	points    float64
+	estimated bool
+	cfg       *config.Config
+}
+
+func (c *coachCase) hasAcceptance() bool {
+	return c.item != nil && backlog.AcceptanceBulletTexts(c.item.Body()) != nil
+}
+
+// coachRegistry is the canon in the order it is checked. The first
+// refusal wins; failing that, the first nudge.
+var coachRegistry = []coachRule{
+	{
+		slug:    "coach-refuses-pm-accepts",
+		actions: []string{"set_status"},
+		check: func(c *coachCase, p coachParams) *CoachVerdict {
+			if c.status != backlog.AcceptedStatus.Name {
+				return nil
+			}
+			return &CoachVerdict{
+				Rule: "the dev pair does not accept its own work",
+				Next: fmt.Sprintf("render PM ceremony with acceptance_prompt(path=%q) and wait for the human", c.path),
+			}
+		},
+	},
+	{
+		slug:    "dates-slide-scope-doesnt",
+		actions: []string{"set_status"},
+		check: func(c *coachCase, p coachParams) *CoachVerdict {
+			if c.typ != "release" {
+				return nil
+			}
+			return &CoachVerdict{
+				Rule: "releases are date markers, not state-machine items",
+				Next: "leave status alone; update release_date instead",
+			}
+		},
+	},
+	{
+		slug:    "acceptance-criteria",
+		actions: []string{"set_status"},
+		nudge:   true,
+		check: func(c *coachCase, p coachParams) *CoachVerdict {
+			if c.status != backlog.StartedStatus.Name || !containsFold(p.types, c.typ) || c.hasAcceptance() {
+				return nil
+			}
+			return &CoachVerdict{
+				Rule: fmt.Sprintf("%s has no acceptance criteria", c.typ),
+				Next: "add a `## Acceptance` section with bullets to the body before starting, or skip with intent",
+			}
+		},
+	},
+	{
+		slug:    "bugs-are-tax",
+		actions: []string{"set_estimate", "create_item"},
+		check: func(c *coachCase, p coachParams) *CoachVerdict {
+			if c.typ != "bug" || c.points <= 0 || c.cfg.StoryTypes.BugEstimable {
+				return nil
+			}
+			return &CoachVerdict{
+				Rule: "bugs are not pointed by default",
+				Next: "strip the estimate, or convert to a feature, or set story_types.bug_estimable in .am/config.yaml",
+			}
+		},
+	},
+	{
+		slug:    "toil-is-not-progress",
+		actions: []string{"set_estimate", "create_item"},
+		check: func(c *coachCase, p coachParams) *CoachVerdict {
+			if c.typ != "chore" || c.points <= 0 || c.cfg.StoryTypes.ChoreEstimable {
+				return nil
+			}
+			return &CoachVerdict{
+				Rule: "chores are not pointed by default",
+				Next: "strip the estimate, or set story_types.chore_estimable in .am/config.yaml",
+			}
+		},
+	},
+	{
+		slug:    "8-point-hard-cap",
+		actions: []string{"set_estimate", "create_item"},
+		check: func(c *coachCase, p coachParams) *CoachVerdict {
+			if !containsFold(p.types, c.typ) || c.points <= p.max {
+				return nil
+			}
+			next := fmt.Sprintf("split the story; keep each piece at or below %g points", p.max)
+			if c.action == "create_item" {
+				next = "split the story before creating it"
+			}
+			return &CoachVerdict{
+				Rule: fmt.Sprintf("%ss over %g points are epics", c.typ, p.max),
+				Next: next,
+			}
+		},
+	},
+	{
+		// Agents that confidently build the wrong thing are the central
+		// failure mode of AI-paired delivery; a story without acceptance
+		// criteria gives the agent nothing to build toward. Bugs and
+		// chores have their own conventions for "done".
+		slug:    "acceptance-before-pull",
+		actions: []string{"pull"},
+		check: func(c *coachCase, p coachParams) *CoachVerdict {
+			if !containsFold(p.types, c.typ) || c.hasAcceptance() {
+				return nil
+			}
+			return &CoachVerdict{
+				Rule: fmt.Sprintf("%s has no acceptance criteria", c.typ),
+				Next: fmt.Sprintf("draft a `## Acceptance` section in %s, or run /am-align before pulling", c.path),
+			}
+		},
+	},
+}
+
+// coachRuleSlugs lists the built-in slugs, for errors and docs.
+func coachRuleSlugs() []string {
+	out := make([]string, 0, len(coachRegistry))
+	for _, r := range coachRegistry {
+		out = append(out, r.slug)
+	}
+	return out
+}
+
+// coachCustomRule is a CustomCoachRule with its query parsed.
+type coachCustomRule struct {
+	config.CustomCoachRule
+	when *backlog.SearchQuery
+}
+
+// coachOverrideWindow is how long an override lets the overridden rule
+// through for the same item and action.
+const coachOverrideWindow = time.Hour
+
+// coachRules is the canon as this project has tuned it, with the
+// overrides still in their window.
+type coachRules struct {
+	root      *backlog.BacklogsStructure
+	cfg       *config.Config
+	coach     *config.CoachConfig
+	custom    []coachCustomRule
+	overrides []backlog.CoachOverride
+	skip      string // a built-in rule left out of this check
+}
+
+// loadCoachRules reads `.am/config.yaml` and `.am/coach.yaml`. A rule
+// slug nobody knows or a custom query that does not parse is an error,
+// so a typo does not quietly switch a check off.
+func loadCoachRules(root *backlog.BacklogsStructure) (*coachRules, error) {
+	cfg, err := config.LoadConfig(root.ConfigFile())
+	if err != nil {
+		return nil, err
+	}
+	coach, err := config.LoadCoachConfig(root.CoachFile())
+	if err != nil {
+		return nil, err
+	}
+	r := &coachRules{root: root, cfg: cfg, coach: coach}
+	known := map[string]bool{}
+	for _, slug := range coachRuleSlugs() {
+		known[slug] = true
+	}
+	for _, c := range coach.Custom {
+		if known[c.Slug] {
+			return nil, fmt.Errorf("coach.yaml: custom rule %q shadows a built-in rule", c.Slug)
+		}
+		q, err := backlog.ParseSearchQuery(c.When)
+		if err != nil {
+			return nil, fmt.Errorf("coach.yaml: custom %s: %w", c.Slug, err)
+		}
+		known[c.Slug] = true
+		r.custom = append(r.custom, coachCustomRule{CustomCoachRule: c, when: q})
+	}
+	for slug := range coach.Rules {
+		if !known[slug] {
+			return nil, fmt.Errorf("coach.yaml: unknown rule %q (built-in: %s)", slug, strings.Join(coachRuleSlugs(), ", "))
+		}
+	}
+	overrides, err := backlog.LoadCoachOverrides(root)
+	if err != nil {
+		return nil, err
+	}
+	since := time.Now().Add(-coachOverrideWindow)
+	for _, o := range overrides {
+		if at, err := utils.ParseTimestamp(o.Time); err == nil && at.After(since) {
+			r.overrides = append(r.overrides, o)
+		}
+	}
+	return r, nil
+}
+
+// itemKey names c's item in the override log: its path relative to the
+// root, empty for create_item.
+func (r *coachRules) itemKey(c *coachCase) string {
+	if c.item == nil {
+		return ""
+	}
+	rel, err := filepath.Rel(r.root.Root(), c.item.Path())
+	if err != nil {
+		return filepath.ToSlash(c.path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// override returns the newest override in the window for slug on c.
+func (r *coachRules) override(slug string, c *coachCase) *backlog.CoachOverride {
+	key := r.itemKey(c)
+	for i := len(r.overrides) - 1; i >= 0; i-- {
+		o := &r.overrides[i]
+		if o.Rule == slug && o.Action == c.action && o.Item == key {
+			return o
+		}
+	}
+	return nil
+}
+
+// verdict runs every enabled rule that covers c.action. A refusal
+// overridden in the last hour lets the action through, saying so in
+// Detail, unless another rule refuses or nudges.
+func (r *coachRules) verdict(c *coachCase) CoachVerdict {
+	c.cfg = r.cfg
+	if c.typ == "" {
+		c.typ = "feature"
+	}
+	var nudge, overridden *CoachVerdict
+	decide := func(slug string, defaultNudge bool, v *CoachVerdict) bool {
+		if v == nil {
+			return false
+		}
+		v.Source = slug
+		v.Allowed = levelIsNudge(r.coach.Rule(slug).Level, defaultNudge)
+		v.Nudge = v.Allowed
+		if o := r.override(slug, c); o != nil {
+			if overridden == nil {
+				v.Allowed, v.Nudge = true, false
+				v.Detail = fmt.Sprintf("overridden by %s at %s: %s", o.Author, o.Time, o.Reason)
+				overridden = v
+			}
+			return false
+		}
+		if v.Nudge {
+			if nudge == nil {
+				nudge = v
+			}
+			return false
+		}
+		return true
+	}
+	for _, rule := range coachRegistry {
+		over := r.coach.Rule(rule.slug)
+		if !over.On() || rule.slug == r.skip || !containsFold(rule.actions, c.action) {
+			continue
+		}
+		p := coachParams{max: over.Max, types: over.Types}
+		if p.max == 0 {
+			p.max = r.cfg.MaxEstimate()
+		}
+		if len(p.types) == 0 {
+			p.types = []string{"feature"}
+		}
+		if v := rule.check(c, p); decide(rule.slug, rule.nudge, v) {
+			return *v
+		}
+	}
+	if len(r.custom) > 0 {
+		prospect, rel := r.prospect(c)
+		for _, rule := range r.custom {
+			if !r.coach.Rule(rule.Slug).On() || len(rule.Actions) > 0 && !containsFold(rule.Actions, c.action) {
+				continue
+			}
+			if !rule.when.MatchItem(prospect, rel) {
+				continue
+			}
+			v := &CoachVerdict{Rule: rule.Rule, Next: rule.Next}
+			if decide(rule.Slug, rule.Level == "nudge", v) {
+				return *v
+			}
+		}
+	}
+	if nudge != nil {
+		return *nudge
+	}
+	if overridden != nil {
+		return *overridden
+	}
+	return CoachVerdict{Allowed: true}
+}
+
+// prospect is the item as it would be after the action, in memory, for
+// custom rules to match against, with its path relative to the root.
+func (r *coachRules) prospect(c *coachCase) (*backlog.BacklogItem, string) {
+	var item *backlog.BacklogItem
+	rel := filepath.ToSlash(c.path)
+	if c.item != nil {
+		item = backlog.NewBacklogItem(c.item.Name(), string(c.item.Content()))
+		if p, err := filepath.Rel(r.root.Root(), c.item.Path()); err == nil && c.item.Path() != "" {
+			rel = filepath.ToSlash(p)
+		}
+	} else {
+		item = backlog.NewBacklogItem("", "---\n---\n")
+		item.SetStatus(backlog.UnstartedStatus)
+	}
+	item.SetType(c.typ)
+	if c.status != "" {
+		if s := backlog.StatusByName(c.status); s != nil {
+			item.SetStatus(s)
+		}
+	}
+	if c.estimated {
+		item.SetEstimate(fmt.Sprintf("%g", c.points))
+	}
+	return item, rel
+}
+
+// levelIsNudge applies a `.am/coach.yaml` level to a rule's default.
+func levelIsNudge(level string, defaultNudge bool) bool {
+	switch level {
+	case "nudge":
+		return true
+	case "refuse":
+		return false
+	}
+	return defaultNudge
+}
+
+func containsFold(xs []string, s string) bool {
+	for _, x := range xs {
+		if strings.EqualFold(strings.TrimSpace(x), s) {
+			return true
+		}
+	}
+	return false
+}
@@ -3,7 +3,6 @@ package mcpserver
 import (
 	"context"
 	"fmt"
-	"path/filepath"
 	"strings"
 
 	"github.com/mreider/agilemarkdown/backlog"
@@ -30,7 +29,10 @@ type GetCommentsResult struct {
 
 func getCommentsTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, GetCommentsArgs) (*mcp.CallToolResult, GetCommentsResult, error) {
 	return func(ctx context.Context, req *mcp.CallToolRequest, args GetCommentsArgs) (*mcp.CallToolResult, GetCommentsResult, error) {
-		path := filepath.Join(root.Root(), args.Path)
+		path, err := backlog.ResolveItemPath(root, args.Path)
+		if err != nil {
+			return nil, GetCommentsResult{}, err
+		}
 		item, err := backlog.LoadBacklogItem(path)
 		if err != nil {
 			return nil, GetCommentsResult{}, err
@@ -60,7 +62,10 @@ func addCommentTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.
 		if text == "" {
 			return nil, OkResult{}, fmt.Errorf("text is required")
 		}
-		path := filepath.Join(root.Root(), args.Path)
+		path, err := backlog.ResolveItemPath(root, args.Path)
+		if err != nil {
+			return nil, OkResult{}, err
+		}
 		item, err := backlog.LoadBacklogItem(path)
 		if err != nil {
 			return nil, OkResult{}, err
@@ -24,6 +24,16 @@ type DashboardResult struct {
 	CycleTimeHours  float64 `json:"cycle_time_median_hours"`
 	RejectionPct    float64 `json:"rejection_rate_latest_percent"`
 	StoriesAccepted int     `json:"stories_accepted_total"`
+
+	// Current iteration against its committed plan snapshot(s). Zero
+	// values and IterationSnapshot=false when no backlog ran
+	// `am sprint plan --commit` this iteration.
+	Iteration           int     `json:"iteration"`
+	IterationSnapshot   bool    `json:"iteration_snapshot"`
+	IterationPlanned    float64 `json:"iteration_planned_points"`
+	IterationAccepted   float64 `json:"iteration_accepted_points"`
+	IterationCarryOver  float64 `json:"iteration_remaining_points"`
+	IterationScopeAdded float64 `json:"iteration_scope_added_points"`
 }
 
 func dashboardTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, DashboardArgs) (*mcp.CallToolResult, DashboardResult, error) {
@@ -38,6 +48,15 @@ func dashboardTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.C
 		}
 		all := make([]*backlog.BacklogItem, 0, 64)
 		acceptedCount := 0
+		now := time.Now()
+		iterStart := backlog.IterationStartFor(now, cfg)
+		iterNumber := backlog.IterationNumberFor(iterStart, cfg)
+		plan, err := backlog.LoadIterationPlan(root.Root(), iterNumber)
+		if err != nil {
+			return nil, DashboardResult{}, err
+		}
+		var outcome backlog.IterationPlanOutcome
+		snapshot := false
 		for _, d := range dirs {
 			if args.Backlog != "" && filepath.Base(d) != args.Backlog {
 				continue
@@ -46,6 +65,14 @@ func dashboardTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.C
 			if err != nil {
 				return nil, DashboardResult{}, err
 			}
+			if b := plan.Backlog(filepath.Base(d)); b != nil {
+				o := backlog.EvaluateIterationPlan(b, bck.AllItems(), iterStart, backlog.IterationEndFor(now, cfg))
+				outcome.Planned += o.Planned
+				outcome.Accepted += o.Accepted
+				outcome.CarryOver += o.CarryOver
+				outcome.ScopeAdded += o.ScopeAdded
+				snapshot = true
+			}
 			for _, it := range bck.AllItems() {
 				all = append(all, it)
 				if strings.EqualFold(it.Status(), backlog.AcceptedStatus.Name) {
@@ -53,7 +80,6 @@ func dashboardTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.C
 				}
 			}
 		}
-		now := time.Now()
 		overrides, _ := backlog.LoadIterationOverrides(root.Root())
 		var feedAccepted []*backlog.BacklogItem
 		for _, it := range all {
@@ -76,6 +102,13 @@ func dashboardTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.C
 			CycleTimeHours:  median.Hours(),
 			RejectionPct:    latest,
 			StoriesAccepted: acceptedCount,
+
+			Iteration:           iterNumber,
+			IterationSnapshot:   snapshot,
+			IterationPlanned:    outcome.Planned,
+			IterationAccepted:   outcome.Accepted,
+			IterationCarryOver:  outcome.CarryOver,
+			IterationScopeAdded: outcome.ScopeAdded,
 		}, nil
 	}
 }
@@ -176,6 +209,9 @@ type VelocityHistoryRow struct {
 	Accepted     float64 `json:"accepted"`
 	LengthWeeks  int     `json:"length_weeks"`
 	TeamStrength float64 `json:"team_strength"`
+	Snapshot     bool    `json:"planned_from_snapshot"`
+	CarryOver    float64 `json:"carry_over,omitempty"`
+	ScopeAdded   float64 `json:"scope_added,omitempty"`
 }
 
 type VelocityHistoryResult struct {
@@ -203,6 +239,7 @@ func velocityHistoryTool(root *backlog.BacklogsStructure) func(context.Context,
 		now := time.Now()
 		overrides, _ := backlog.LoadIterationOverrides(root.Root())
 		rows := backlog.VelocityHistory(now, bck.AllItems(), cfg, overrides, count)
+		rows = backlog.ApplyIterationPlans(rows, root.Root(), filepath.Base(dir), bck.AllItems())
 		out := make([]VelocityHistoryRow, 0, len(rows))
 		for _, r := range rows {
 			out = append(out, VelocityHistoryRow{
@@ -212,6 +249,9 @@ func velocityHistoryTool(root *backlog.BacklogsStructure) func(context.Context,
 				Accepted:     r.Accepted,
 				LengthWeeks:  r.LengthWeeks,
 				TeamStrength: r.TeamStrength,
+				Snapshot:     r.Snapshot,
+				CarryOver:    r.CarryOver,
+				ScopeAdded:   r.ScopeAdded,
 			})
 		}
 		return nil, VelocityHistoryResult{Rows: out}, nil
@@ -219,7 +259,8 @@ func velocityHistoryTool(root *backlog.BacklogsStructure) func(context.Context,
 }
 
 type CFDArgs struct {
-	Days int `json:"days,omitempty" jsonschema:"lookback window in days; default 30"`
+	Days    int  `json:"days,omitempty" jsonschema:"lookback window in days; default 30"`
+	FromGit bool `json:"from_git,omitempty" jsonschema:"replay the git history of every item instead of reading today's timestamps; shows rejections, restarts and icebox time"`
 }
 
 type CFDRow struct {
@@ -239,21 +280,30 @@ func cfdTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToo
 		if days <= 0 {
 			days = 30
 		}
-		dirs, err := root.BacklogDirs()
-		if err != nil {
-			return nil, CFDResult{}, err
-		}
-		all := make([]*backlog.BacklogItem, 0, 64)
-		for _, d := range dirs {
-			bck, err := backlog.LoadBacklog(d)
+		end := time.Now().UTC()
+		start := end.AddDate(0, 0, -days)
+		var rows []backlog.CFDRow
+		if args.FromGit {
+			var err error
+			rows, err = backlog.CFDRowsFromGit(root, start, end)
 			if err != nil {
 				return nil, CFDResult{}, err
 			}
-			all = append(all, bck.AllItems()...)
+		} else {
+			dirs, err := root.BacklogDirs()
+			if err != nil {
+				return nil, CFDResult{}, err
+			}
+			all := make([]*backlog.BacklogItem, 0, 64)
+			for _, d := range dirs {
+				bck, err := backlog.LoadBacklog(d)
+				if err != nil {
+					return nil, CFDResult{}, err
+				}
+				all = append(all, bck.AllItems()...)
+			}
+			rows = backlog.CFDRows(all, start, end)
 		}
-		end := time.Now().UTC()
-		start := end.AddDate(0, 0, -days)
-		rows := backlog.CFDRows(all, start, end)
 		out := make([]CFDRow, 0, len(rows))
 		for _, r := range rows {
 			out = append(out, CFDRow{Day: r.Day.Format("2006-01-02"), Accepted: r.Accepted, InFlight: r.InFlight, Backlog: r.Backlog})
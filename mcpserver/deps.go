@@ -0,0 +1,88 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/mreider/agilemarkdown/backlog"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type DependencyGraphArgs struct {
+	Backlog string `json:"backlog,omitempty" jsonschema:"only edges touching this backlog"`
+	Epic    string `json:"epic,omitempty" jsonschema:"only edges touching stories with this epic slug"`
+	Format  string `json:"format,omitempty" jsonschema:"also render the graph as text: ascii, dot or mermaid"`
+}
+
+type DependencyNode struct {
+	ID           string `json:"id" jsonschema:"backlog/file.md, the form blocked_by and blocks use"`
+	Path         string `json:"path"`
+	Title        string `json:"title"`
+	Status       string `json:"status"`
+	OpenBlockers int    `json:"open_blockers"`
+}
+
+type DependencyEdgeRow struct {
+	Blocker string `json:"blocker"`
+	Blocked string `json:"blocked"`
+}
+
+type DependencyGraphResult struct {
+	Nodes  []DependencyNode    `json:"nodes"`
+	Edges  []DependencyEdgeRow `json:"edges"`
+	Cycles [][]string          `json:"cycles,omitempty"`
+	Text   string              `json:"text,omitempty"`
+}
+
+// dependencyGraphTool returns the blocked_by graph for the project, one
+// backlog or one epic, optionally rendered as DOT or Mermaid.
+func dependencyGraphTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, DependencyGraphArgs) (*mcp.CallToolResult, DependencyGraphResult, error) {
+	return func(ctx context.Context, req *mcp.CallToolRequest, args DependencyGraphArgs) (*mcp.CallToolResult, DependencyGraphResult, error) {
+		if args.Backlog != "" {
+			if _, err := resolveBacklogDir(root, args.Backlog); err != nil {
+				return nil, DependencyGraphResult{}, err
+			}
+		}
+		g, err := backlog.LoadDependencyGraph(root)
+		if err != nil {
+			return nil, DependencyGraphResult{}, err
+		}
+		edges := g.Edges(args.Backlog, args.Epic)
+		res := DependencyGraphResult{Nodes: []DependencyNode{}, Edges: []DependencyEdgeRow{}, Cycles: g.Cycles()}
+		seen := map[string]bool{}
+		addNode := func(id string) {
+			if seen[id] {
+				return
+			}
+			seen[id] = true
+			it := g.Item(id)
+			rel, _ := filepath.Rel(root.Root(), it.Path())
+			res.Nodes = append(res.Nodes, DependencyNode{
+				ID:           id,
+				Path:         rel,
+				Title:        it.Title(),
+				Status:       it.Status(),
+				OpenBlockers: len(g.OpenBlockers(it)),
+			})
+		}
+		for _, e := range edges {
+			addNode(e.Blocker)
+			addNode(e.Blocked)
+			res.Edges = append(res.Edges, DependencyEdgeRow{Blocker: e.Blocker, Blocked: e.Blocked})
+		}
+		switch args.Format {
+		case "":
+		case "ascii":
+			res.Text = backlog.DependencyASCII(g, edges)
+		case "dot":
+			res.Text = backlog.DependencyDOT(g, edges)
+		case "mermaid":
+			res.Text = backlog.DependencyMermaid(g, edges)
+		default:
+			return nil, DependencyGraphResult{}, fmt.Errorf("invalid format %q (valid: ascii, dot, mermaid)", args.Format)
+		}
+		return nil, res, nil
+	}
+}
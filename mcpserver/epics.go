@@ -0,0 +1,151 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/utils"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type EpicRow struct {
+	Slug            string   `json:"slug"`
+	Path            string   `json:"path"`
+	Title           string   `json:"title"`
+	Status          string   `json:"status"`
+	Owner           string   `json:"owner,omitempty"`
+	Release         string   `json:"release,omitempty"`
+	Labels          []string `json:"labels,omitempty"`
+	Description     string   `json:"description,omitempty"`
+	Stories         int      `json:"stories"`
+	AcceptedStories int      `json:"accepted_stories"`
+	Points          float64  `json:"points"`
+	AcceptedPoints  float64  `json:"accepted_points"`
+	RemainingPoints float64  `json:"remaining_points"`
+}
+
+func toEpicRow(root *backlog.BacklogsStructure, e *backlog.Epic) (EpicRow, error) {
+	items, err := backlog.EpicMembers(root, e.Slug())
+	if err != nil {
+		return EpicRow{}, err
+	}
+	stats := backlog.EpicStatsFor(items)
+	rel, _ := filepath.Rel(root.Root(), e.Path())
+	return EpicRow{
+		Slug:            e.Slug(),
+		Path:            rel,
+		Title:           e.Title(),
+		Status:          e.Status(),
+		Owner:           e.Owner(),
+		Release:         e.Release(),
+		Labels:          e.Labels(),
+		Description:     e.Description(),
+		Stories:         stats.Stories,
+		AcceptedStories: stats.AcceptedStories,
+		Points:          stats.Points,
+		AcceptedPoints:  stats.AcceptedPoints,
+		RemainingPoints: stats.RemainingPoints(),
+	}, nil
+}
+
+type CreateEpicArgs struct {
+	Slug        string   `json:"slug" jsonschema:"file name under epics/; lowercase letters, digits and dashes"`
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Owner       string   `json:"owner,omitempty"`
+	Release     string   `json:"release,omitempty" jsonschema:"slug of the target release marker"`
+	Labels      []string `json:"labels,omitempty"`
+}
+
+func createEpicTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, CreateEpicArgs) (*mcp.CallToolResult, EpicRow, error) {
+	return func(ctx context.Context, req *mcp.CallToolRequest, args CreateEpicArgs) (*mcp.CallToolResult, EpicRow, error) {
+		e, err := backlog.CreateEpic(root, args.Slug, args.Title)
+		if err != nil {
+			return nil, EpicRow{}, err
+		}
+		if args.Owner != "" {
+			e.SetOwner(strings.TrimSpace(args.Owner))
+		}
+		if args.Release != "" {
+			e.SetRelease(strings.TrimSpace(args.Release))
+		}
+		if len(args.Labels) > 0 {
+			e.SetLabels(args.Labels)
+		}
+		if args.Description != "" {
+			e.SetDescription(args.Description)
+		}
+		if err := e.Save(); err != nil {
+			return nil, EpicRow{}, err
+		}
+		row, err := toEpicRow(root, e)
+		return nil, row, err
+	}
+}
+
+type ListEpicsArgs struct {
+	Status string `json:"status,omitempty" jsonschema:"open or closed; empty lists both"`
+}
+
+type ListEpicsResult struct {
+	Epics []EpicRow `json:"epics"`
+	Count int       `json:"count"`
+}
+
+func listEpicsTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, ListEpicsArgs) (*mcp.CallToolResult, ListEpicsResult, error) {
+	return func(ctx context.Context, req *mcp.CallToolRequest, args ListEpicsArgs) (*mcp.CallToolResult, ListEpicsResult, error) {
+		epics, err := backlog.LoadEpics(root)
+		if err != nil {
+			return nil, ListEpicsResult{}, err
+		}
+		out := []EpicRow{}
+		for _, e := range epics {
+			if args.Status != "" && !strings.EqualFold(e.Status(), args.Status) {
+				continue
+			}
+			row, err := toEpicRow(root, e)
+			if err != nil {
+				return nil, ListEpicsResult{}, err
+			}
+			out = append(out, row)
+		}
+		return nil, ListEpicsResult{Epics: out, Count: len(out)}, nil
+	}
+}
+
+type CloseEpicArgs struct {
+	Slug  string `json:"slug"`
+	Force bool   `json:"force,omitempty" jsonschema:"close even though some stories are not accepted"`
+}
+
+func closeEpicTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, CloseEpicArgs) (*mcp.CallToolResult, EpicRow, error) {
+	return func(ctx context.Context, req *mcp.CallToolRequest, args CloseEpicArgs) (*mcp.CallToolResult, EpicRow, error) {
+		e, err := backlog.FindEpic(root, args.Slug)
+		if err != nil {
+			return nil, EpicRow{}, err
+		}
+		row, err := toEpicRow(root, e)
+		if err != nil {
+			return nil, EpicRow{}, err
+		}
+		if open := row.Stories - row.AcceptedStories; open > 0 && !args.Force {
+			return nil, EpicRow{}, fmt.Errorf("epic %q still has %d open stories; accept or move them first, or pass force", e.Slug(), open)
+		}
+		if e.IsClosed() {
+			return nil, row, nil
+		}
+		now := utils.GetCurrentTimestamp()
+		e.SetStatus(backlog.EpicStatusClosed)
+		e.SetClosed(now)
+		e.SetModified(now)
+		if err := e.Save(); err != nil {
+			return nil, EpicRow{}, err
+		}
+		row.Status = e.Status()
+		return nil, row, nil
+	}
+}
@@ -3,7 +3,6 @@ package mcpserver
 import (
 	"context"
 	"fmt"
-	"path/filepath"
 	"strings"
 
 	"github.com/mreider/agilemarkdown/actions"
@@ -20,7 +19,10 @@ type SetTagsArgs struct {
 
 func setTagsTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, SetTagsArgs) (*mcp.CallToolResult, OkResult, error) {
 	return func(ctx context.Context, req *mcp.CallToolRequest, args SetTagsArgs) (*mcp.CallToolResult, OkResult, error) {
-		path := filepath.Join(root.Root(), args.Path)
+		path, err := backlog.ResolveItemPath(root, args.Path)
+		if err != nil {
+			return nil, OkResult{}, err
+		}
 		item, err := backlog.LoadBacklogItem(path)
 		if err != nil {
 			return nil, OkResult{}, err
@@ -48,11 +50,17 @@ type SetEpicArgs struct {
 
 func setEpicTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, SetEpicArgs) (*mcp.CallToolResult, OkResult, error) {
 	return func(ctx context.Context, req *mcp.CallToolRequest, args SetEpicArgs) (*mcp.CallToolResult, OkResult, error) {
-		path := filepath.Join(root.Root(), args.Path)
+		path, err := backlog.ResolveItemPath(root, args.Path)
+		if err != nil {
+			return nil, OkResult{}, err
+		}
 		item, err := backlog.LoadBacklogItem(path)
 		if err != nil {
 			return nil, OkResult{}, err
 		}
+		if err := backlog.CheckEpicRef(root, strings.TrimSpace(args.Slug)); err != nil {
+			return nil, OkResult{}, err
+		}
 		item.SetEpic(strings.TrimSpace(args.Slug))
 		item.SetModified(utils.GetCurrentTimestamp())
 		if err := item.Save(); err != nil {
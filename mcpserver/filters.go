@@ -0,0 +1,79 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/mreider/agilemarkdown/backlog"
+)
+
+type ListFiltersArgs struct{}
+
+type SavedFilterInfo struct {
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	Query       string `json:"query"`
+	Description string `json:"description,omitempty"`
+	Archived    bool   `json:"archived,omitempty"`
+}
+
+type ListFiltersResult struct {
+	Filters []SavedFilterInfo `json:"filters"`
+	Count   int               `json:"count"`
+}
+
+func listFiltersTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, ListFiltersArgs) (*mcp.CallToolResult, ListFiltersResult, error) {
+	return func(ctx context.Context, req *mcp.CallToolRequest, args ListFiltersArgs) (*mcp.CallToolResult, ListFiltersResult, error) {
+		filters, err := backlog.LoadSavedFilters(root)
+		if err != nil {
+			return nil, ListFiltersResult{}, err
+		}
+		out := make([]SavedFilterInfo, 0, len(filters.Filters))
+		for _, f := range filters.Filters {
+			out = append(out, SavedFilterInfo{Name: f.Name, Slug: f.Slug(), Query: f.Query, Description: f.Description, Archived: f.Archived})
+		}
+		return nil, ListFiltersResult{Filters: out, Count: len(out)}, nil
+	}
+}
+
+type RunFilterArgs struct {
+	Name  string `json:"name" jsonschema:"filter name or slug from list_filters"`
+	Limit int    `json:"limit,omitempty" jsonschema:"max results to return; default all"`
+}
+
+type RunFilterResult struct {
+	Name  string      `json:"name"`
+	Query string      `json:"query"`
+	Hits  []SearchHit `json:"hits"`
+	Count int         `json:"count"`
+}
+
+// runFilterTool evaluates a saved filter for the current git user, so
+// "owner:me" means whoever runs it.
+func runFilterTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, RunFilterArgs) (*mcp.CallToolResult, RunFilterResult, error) {
+	return func(ctx context.Context, req *mcp.CallToolRequest, args RunFilterArgs) (*mcp.CallToolResult, RunFilterResult, error) {
+		filters, err := backlog.LoadSavedFilters(root)
+		if err != nil {
+			return nil, RunFilterResult{}, err
+		}
+		f := filters.Find(args.Name)
+		if f == nil {
+			return nil, RunFilterResult{}, fmt.Errorf("no saved filter %q", args.Name)
+		}
+		idx, err := backlog.UpdateSearchIndex(root)
+		if err != nil {
+			return nil, RunFilterResult{}, err
+		}
+		matches, err := f.Run(idx, backlog.NewUserList(root.UsersDirectory()).CurrentUserHandles())
+		if err != nil {
+			return nil, RunFilterResult{}, err
+		}
+		limit := args.Limit
+		if limit <= 0 {
+			limit = len(matches)
+		}
+		hits := searchHits(matches, limit)
+		return nil, RunFilterResult{Name: f.Name, Query: f.Query, Hits: hits, Count: len(hits)}, nil
+	}
+}
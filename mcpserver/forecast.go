@@ -0,0 +1,87 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/config"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type ForecastArgs struct {
+	Epic   string `json:"epic,omitempty" jsonschema:"forecast every open story with this epic slug"`
+	Until  string `json:"until,omitempty" jsonschema:"forecast everything ranked at or above this item path (relative to project root) in _priority.md"`
+	Trials int    `json:"trials,omitempty" jsonschema:"number of simulated futures; defaults to 10000"`
+	Seed   uint64 `json:"seed,omitempty" jsonschema:"random seed; defaults to 1 so results are repeatable"`
+}
+
+type ForecastPercentile struct {
+	Percent    int    `json:"percent"`
+	Iterations int    `json:"iterations"`
+	Iteration  int    `json:"iteration"`
+	Date       string `json:"date,omitempty"`
+}
+
+type ForecastResult struct {
+	Target      string               `json:"target"`
+	Remaining   float64              `json:"remaining_points"`
+	Stories     int                  `json:"open_stories"`
+	Unestimated int                  `json:"unestimated,omitempty"`
+	Samples     []float64            `json:"throughput_samples"`
+	Trials      int                  `json:"trials"`
+	Seed        uint64               `json:"seed"`
+	Unbounded   int                  `json:"unbounded_trials,omitempty"`
+	Initial     bool                 `json:"initial_velocity,omitempty"`
+	Percentiles []ForecastPercentile `json:"percentiles"`
+}
+
+func forecastTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, ForecastArgs) (*mcp.CallToolResult, ForecastResult, error) {
+	return func(ctx context.Context, req *mcp.CallToolRequest, args ForecastArgs) (*mcp.CallToolResult, ForecastResult, error) {
+		if (args.Epic == "") == (args.Until == "") {
+			return nil, ForecastResult{}, fmt.Errorf("set exactly one of epic or until")
+		}
+		cfg, err := config.LoadConfig(filepath.Join(root.Root(), ".am", "config.yaml"))
+		if err != nil {
+			return nil, ForecastResult{}, err
+		}
+		now := time.Now().In(cfg.IterationLocation())
+		opts := backlog.DeliveryForecastOptions{Trials: args.Trials, Seed: args.Seed}
+		var f *backlog.DeliveryForecast
+		if args.Epic != "" {
+			f, err = backlog.ForecastEpic(root, cfg, now, args.Epic, opts)
+		} else {
+			f, err = backlog.ForecastUntil(root, cfg, now, args.Until, opts)
+		}
+		if err != nil {
+			return nil, ForecastResult{}, err
+		}
+		res := ForecastResult{
+			Target:      f.Target,
+			Remaining:   f.Remaining,
+			Stories:     f.Stories,
+			Unestimated: f.Unestimated,
+			Samples:     f.Samples,
+			Trials:      f.Trials,
+			Seed:        f.Seed,
+			Unbounded:   f.Unbounded,
+			Initial:     f.InitialVelocity,
+		}
+		if res.Samples == nil {
+			res.Samples = []float64{}
+		}
+		for _, p := range f.Points {
+			row := ForecastPercentile{Percent: p.Percent, Iterations: p.Iterations, Iteration: p.Iteration}
+			if !p.Date.IsZero() {
+				row.Date = p.Date.Format("2006-01-02")
+			}
+			res.Percentiles = append(res.Percentiles, row)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: backlog.DeliveryForecastASCII(f)}},
+		}, res, nil
+	}
+}
@@ -0,0 +1,309 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/config"
+)
+
+// GateToolPrefix is how Claude Code names this server's tools in a hook
+// envelope: mcp__<server>__<tool>, with the server registered as
+// agilemarkdown.
+const GateToolPrefix = "mcp__agilemarkdown__"
+
+// Gate decisions, in the words of the PreToolUse hook response.
+const (
+	GateAllow = "allow"
+	GateAsk   = "ask"
+	GateDeny  = "deny"
+)
+
+// GateDecision is the coach's answer to a tool call an agent is about to
+// make. Allow means the coach has nothing to say and the host's own
+// permission rules apply; ask hands the call to the human with Reason;
+// deny stops it and tells the agent why.
+type GateDecision struct {
+	Decision string `json:"decision"`
+	Rule     string `json:"rule,omitempty" jsonschema:"slug of the rule behind an ask or deny"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// gateTool reviews one tool call before it runs. Tools that only read,
+// and writes the canon has no opinion on, are allowed. Overriding the
+// coach and undoing writes always go to the human.
+func gateTool(root *backlog.BacklogsStructure, tool string, input json.RawMessage) (GateDecision, error) {
+	name, ok := strings.CutPrefix(tool, GateToolPrefix)
+	if !ok {
+		return GateDecision{Decision: GateAllow}, nil
+	}
+	if len(input) == 0 {
+		input = json.RawMessage("{}")
+	}
+	switch name {
+	case "set_status":
+		var args SetStatusArgs
+		if err := json.Unmarshal(input, &args); err != nil {
+			return GateDecision{}, err
+		}
+		if args.Path == "" || args.Status == "" {
+			break
+		}
+		checks := []CoachCheckArgs{{Action: "set_status", Path: args.Path, Status: args.Status}}
+		// Starting a story is pulling it: gate on the pull check too.
+		if strings.EqualFold(strings.TrimSpace(args.Status), backlog.StartedStatus.Name) {
+			checks = append(checks, CoachCheckArgs{Action: "pull", Path: args.Path})
+		}
+		return gateCoach(root, name, checks...)
+	case "set_estimate":
+		var args SetEstimateArgs
+		if err := json.Unmarshal(input, &args); err != nil {
+			return GateDecision{}, err
+		}
+		if args.Path == "" || args.Estimate == "" {
+			break
+		}
+		return gateCoach(root, name, CoachCheckArgs{Action: "set_estimate", Path: args.Path, Estimate: args.Estimate})
+	case "create_item":
+		return gateCoach(root, name, CoachCheckArgs{Action: "create_item"})
+	case "reject_item":
+		var args RejectItemArgs
+		if err := json.Unmarshal(input, &args); err != nil {
+			return GateDecision{}, err
+		}
+		return gateReject(root, args)
+	case "set_acceptance_state":
+		var args SetAcceptanceStateArgs
+		if err := json.Unmarshal(input, &args); err != nil {
+			return GateDecision{}, err
+		}
+		if args.State != string(backlog.AcceptanceVerified) {
+			break
+		}
+		return GateDecision{
+			Decision: GateAsk,
+			Rule:     "coach-refuses-pm-accepts",
+			Reason:   fmt.Sprintf("Verifying bullet %d on %s is the PM's check, not the dev pair's. Go ahead only if the human verified it.", args.Index, args.Path),
+		}, nil
+	case "coach_override":
+		var args CoachOverrideArgs
+		if err := json.Unmarshal(input, &args); err != nil {
+			return GateDecision{}, err
+		}
+		target := args.Action
+		if args.Path != "" {
+			target += " on " + args.Path
+		}
+		return GateDecision{
+			Decision: GateAsk,
+			Reason:   fmt.Sprintf("Overriding the coach (%s, reason %q) is the human's call, not yours. Go ahead only if they made it.", target, args.Reason),
+		}, nil
+	case "undo_last":
+		var args UndoLastArgs
+		if err := json.Unmarshal(input, &args); err != nil {
+			return GateDecision{}, err
+		}
+		count := max(args.Count, 1)
+		reason := fmt.Sprintf("Undoing the last %d journaled change(s) rewrites backlog files.", count)
+		if args.Force {
+			reason += " With force it also overwrites edits made since."
+		}
+		return GateDecision{Decision: GateAsk, Reason: reason + " Go ahead only if the human asked for it."}, nil
+	case "rank_item":
+		var args RankItemArgs
+		if err := json.Unmarshal(input, &args); err != nil {
+			return GateDecision{}, err
+		}
+		return gateOrder(root, args.Backlog, []string{args.ItemPath}, func(pri, ice *backlog.OrderFile) error {
+			return rankInOrder(root, pri, ice, args)
+		})
+	case "move_to_priority":
+		var args MoveToPriorityArgs
+		if err := json.Unmarshal(input, &args); err != nil {
+			return GateDecision{}, err
+		}
+		return gateOrder(root, args.Backlog, args.ItemPaths, func(pri, ice *backlog.OrderFile) error {
+			return moveIntoPriority(root, pri, ice, args)
+		})
+	case "bulk_update":
+		var args BulkUpdateArgs
+		if err := json.Unmarshal(input, &args); err != nil {
+			return GateDecision{}, err
+		}
+		return gateBulk(root, args)
+	}
+	return GateDecision{Decision: GateAllow}, nil
+}
+
+// gateCoach runs coach checks in order. The first refusal denies; a
+// nudge asks the human. An override in its window passes like any other
+// allowed verdict.
+func gateCoach(root *backlog.BacklogsStructure, tool string, checks ...CoachCheckArgs) (GateDecision, error) {
+	var nudge *GateDecision
+	for _, args := range checks {
+		_, v, err := coachCheckTool(root)(context.Background(), nil, args)
+		if err != nil {
+			return GateDecision{}, err
+		}
+		switch {
+		case !v.Allowed:
+			return GateDecision{Decision: GateDeny, Rule: v.Source, Reason: gateReason("Coach refused", tool, v, args)}, nil
+		case v.Nudge && nudge == nil:
+			nudge = &GateDecision{Decision: GateAsk, Rule: v.Source, Reason: gateReason("Coach nudge on", tool, v, args)}
+		}
+	}
+	if nudge != nil {
+		return *nudge, nil
+	}
+	return GateDecision{Decision: GateAllow}, nil
+}
+
+// gateReason says what the coach found, what to do instead, and how the
+// human goes ahead anyway.
+func gateReason(lead, tool string, v CoachVerdict, args CoachCheckArgs) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s", lead, tool)
+	if args.Path != "" {
+		fmt.Fprintf(&b, " on %s", args.Path)
+	}
+	fmt.Fprintf(&b, " [%s]: %s.", v.Source, v.Rule)
+	if v.Next != "" {
+		fmt.Fprintf(&b, " Next: %s.", v.Next)
+	}
+	if args.Action == "" {
+		return b.String()
+	}
+	override := "am coach-check " + args.Action
+	for _, f := range [][2]string{{"path", args.Path}, {"status", args.Status}, {"estimate", args.Estimate}} {
+		if f[1] != "" {
+			override += fmt.Sprintf(" --%s %s", f[0], f[1])
+		}
+	}
+	fmt.Fprintf(&b, " To go ahead anyway the human runs `%s --override --reason \"...\"`.", override)
+	return b.String()
+}
+
+// gateReject denies rejecting a story that was never delivered, and
+// hands a delivered one to the human: rejection is the PM's answer in
+// the acceptance ceremony.
+func gateReject(root *backlog.BacklogsStructure, args RejectItemArgs) (GateDecision, error) {
+	if args.Path == "" {
+		return GateDecision{Decision: GateAllow}, nil
+	}
+	path, err := backlog.ResolveItemPath(root, args.Path)
+	if err != nil {
+		return GateDecision{}, err
+	}
+	item, err := backlog.LoadBacklogItem(path)
+	if err != nil {
+		return GateDecision{}, err
+	}
+	if status := item.Status(); !strings.EqualFold(status, backlog.DeliveredStatus.Name) {
+		return GateDecision{
+			Decision: GateDeny,
+			Rule:     "coach-refuses-pm-accepts",
+			Reason:   fmt.Sprintf("Coach refused reject_item on %s: it is %s, and only a delivered story goes through acceptance. Finish and deliver it, then render acceptance_prompt for the PM.", args.Path, status),
+		}, nil
+	}
+	return GateDecision{
+		Decision: GateAsk,
+		Rule:     "coach-refuses-pm-accepts",
+		Reason:   fmt.Sprintf("Rejecting %s is the PM's answer in the acceptance ceremony. Go ahead only if the human said no.", args.Path),
+	}, nil
+}
+
+// gateOrder asks when a reorder pulls items into the current iteration
+// and pushes others out of it: yesterday's weather says the iteration is
+// already full. apply makes the move on the order files in memory.
+func gateOrder(root *backlog.BacklogsStructure, backlogName string, moved []string, apply func(pri, ice *backlog.OrderFile) error) (GateDecision, error) {
+	allow := GateDecision{Decision: GateAllow}
+	if backlogName == "" || len(moved) == 0 {
+		return allow, nil
+	}
+	dir, err := resolveBacklogDir(root, backlogName)
+	if err != nil {
+		return GateDecision{}, err
+	}
+	cfg, err := config.LoadConfig(root.ConfigFile())
+	if err != nil {
+		return GateDecision{}, err
+	}
+	bck, err := backlog.LoadBacklog(dir)
+	if err != nil {
+		return GateDecision{}, err
+	}
+	velocity := computeVelocity(bck, cfg, root.Root())
+	if velocity <= 0 {
+		return allow, nil
+	}
+	pri, err := backlog.LoadPriority(dir)
+	if err != nil {
+		return GateDecision{}, err
+	}
+	ice, err := backlog.LoadIcebox(dir)
+	if err != nil {
+		return GateDecision{}, err
+	}
+	byPath := indexItems(bck)
+	before, planned := iterationBand(pri.Entries(), byPath, velocity)
+	if err := apply(pri, ice); err != nil {
+		return GateDecision{}, err
+	}
+	after, _ := iterationBand(pri.Entries(), byPath, velocity)
+	var entered, bumped []string
+	for _, m := range moved {
+		if b := itemBase(root, m); slices.Contains(after, b) && !slices.Contains(before, b) {
+			entered = append(entered, b)
+		}
+	}
+	for _, b := range before {
+		if !slices.Contains(after, b) {
+			bumped = append(bumped, b)
+		}
+	}
+	if len(entered) == 0 || len(bumped) == 0 {
+		return allow, nil
+	}
+	return GateDecision{
+		Decision: GateAsk,
+		Rule:     "yesterdays-weather",
+		Reason: fmt.Sprintf("The current iteration already holds %g of %g points of rolling velocity. Moving %s into it pushes %s to the next iteration. Go ahead only if the PM is trading them.",
+			planned, velocity, strings.Join(entered, ", "), strings.Join(bumped, ", ")),
+	}, nil
+}
+
+// gateBulk dry-runs a bulk_update: any refusal denies, any nudge asks.
+func gateBulk(root *backlog.BacklogsStructure, args BulkUpdateArgs) (GateDecision, error) {
+	if args.DryRun {
+		return GateDecision{Decision: GateAllow}, nil
+	}
+	args.DryRun = true
+	_, res, err := bulkUpdateTool(root)(context.Background(), nil, args)
+	if err != nil {
+		return GateDecision{}, err
+	}
+	var refusal, nudge *GateDecision
+	for _, it := range res.Items {
+		for _, v := range it.Coach {
+			check := CoachCheckArgs{Path: it.Path}
+			switch {
+			case !v.Allowed && refusal == nil:
+				refusal = &GateDecision{Decision: GateDeny, Rule: v.Source, Reason: gateReason("Coach refused", "bulk_update", v, check)}
+			case v.Nudge && nudge == nil:
+				nudge = &GateDecision{Decision: GateAsk, Rule: v.Source, Reason: gateReason("Coach nudge on", "bulk_update", v, check)}
+			}
+		}
+	}
+	switch {
+	case refusal != nil:
+		refusal.Reason = fmt.Sprintf("%d of %d items refused; nothing would be written. %s To go ahead anyway the human overrides each refusal with `am coach-check ... --override --reason \"...\"`.", res.Refused, res.Matched, refusal.Reason)
+		return *refusal, nil
+	case nudge != nil:
+		return *nudge, nil
+	}
+	return GateDecision{Decision: GateAllow}, nil
+}
@@ -0,0 +1,152 @@
+package mcpserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/mreider/agilemarkdown/backlog"
+)
+
+// AuthorHeader carries the identity of whoever is behind an HTTP
+// request, as "Name <email>". Sync commits with it as the git author and
+// create_item records its name as the story's author.
+const AuthorHeader = "X-Am-Author"
+
+// HTTPOptions configures `am mcp --http`.
+type HTTPOptions struct {
+	Addr           string
+	Token          string // when set, every request needs "Authorization: Bearer <Token>"
+	ReadOnly       bool   // register only the tools that don't write
+	InsecureNoAuth bool   // serve a non-loopback address without a token
+}
+
+// RunHTTP serves the MCP server over the streamable HTTP transport so a
+// team can share one checkout. All sessions share one server, so writes
+// from every agent go through the same lock and undo journal. Without a
+// token it only listens on loopback, unless InsecureNoAuth says otherwise.
+func RunHTTP(ctx context.Context, rootDir, version string, opts HTTPOptions) error {
+	abs, err := filepath.Abs(rootDir)
+	if err != nil {
+		return err
+	}
+	if opts.Token == "" && !isLoopbackAddr(opts.Addr) {
+		if !opts.InsecureNoAuth {
+			return fmt.Errorf("--http %s is reachable from other machines and there is no --token, so anyone on the network could write to this checkout; set --token (or AM_MCP_TOKEN), listen on 127.0.0.1, or pass --insecure-no-auth", opts.Addr)
+		}
+		fmt.Fprintf(os.Stderr, "am mcp: WARNING: %s accepts unauthenticated requests from the network; anyone who can reach it can write to %s\n", opts.Addr, abs)
+	}
+	backlog.EnableItemCache()
+	srv, resources := newServer(abs, version, opts.ReadOnly)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	watchProject(ctx, srv, resources)
+
+	httpSrv := &http.Server{Addr: opts.Addr, Handler: httpHandler(srv, opts.Token)}
+	go func() {
+		<-ctx.Done()
+		shutdown, done := context.WithTimeout(context.Background(), 5*time.Second)
+		defer done()
+		_ = httpSrv.Shutdown(shutdown)
+	}()
+	mode := "read-write"
+	if opts.ReadOnly {
+		mode = "read-only"
+	}
+	fmt.Fprintf(os.Stderr, "am mcp: serving %s on http://%s (%s)\n", abs, opts.Addr, mode)
+	if err := httpSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// isLoopbackAddr reports whether addr only listens on this machine. An
+// empty host (":7331") listens on every interface.
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func httpHandler(srv *mcp.Server, token string) http.Handler {
+	var h http.Handler = mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return srv }, nil)
+	h = checkAuthorHeader(h)
+	if token != "" {
+		h = auth.RequireBearerToken(func(ctx context.Context, got string, _ *http.Request) (*auth.TokenInfo, error) {
+			if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				return nil, auth.ErrInvalidToken
+			}
+			return &auth.TokenInfo{Expiration: time.Now().Add(time.Hour)}, nil
+		}, nil)(h)
+	}
+	return h
+}
+
+// checkAuthorHeader rejects a request whose author header isn't a
+// "Name <email>" address, rather than committing under a garbled name.
+func checkAuthorHeader(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if v := r.Header.Get(AuthorHeader); v != "" {
+			if _, err := mail.ParseAddress(v); err != nil {
+				http.Error(w, fmt.Sprintf("%s must look like \"Name <email>\": %v", AuthorHeader, err), http.StatusBadRequest)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestAuthor returns the name and email from the request's author
+// header; both are empty over stdio or when the header is absent, which
+// leaves the local git user in charge.
+func requestAuthor(req *mcp.CallToolRequest) (name, email string) {
+	if req == nil || req.Extra == nil || req.Extra.Header == nil {
+		return "", ""
+	}
+	addr, err := mail.ParseAddress(req.Extra.Header.Get(AuthorHeader))
+	if err != nil {
+		return "", ""
+	}
+	name = addr.Name
+	if name == "" {
+		name = addr.Address
+	}
+	return name, addr.Address
+}
+
+// requestActor names the agent behind a request for the undo journal:
+// its author header when it sent one, else its session ID. Both are
+// empty over stdio, where there is only one agent.
+func requestActor(req *mcp.CallToolRequest) string {
+	if author := requestGitAuthor(req); author != "" {
+		return author
+	}
+	if req == nil || req.Session == nil {
+		return ""
+	}
+	return req.Session.ID()
+}
+
+// requestGitAuthor formats requestAuthor for git's --author.
+func requestGitAuthor(req *mcp.CallToolRequest) string {
+	name, email := requestAuthor(req)
+	if email == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s <%s>", name, email)
+}
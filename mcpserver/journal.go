@@ -0,0 +1,72 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/mreider/agilemarkdown/backlog"
+)
+
+type UndoLastArgs struct {
+	Count int  `json:"count,omitempty" jsonschema:"how many changes to roll back, newest first (default 1)"`
+	Force bool `json:"force,omitempty" jsonschema:"overwrite files edited since the change; only with the human's go-ahead"`
+}
+
+// JournalSummary is one undo journal entry without the file contents.
+type JournalSummary struct {
+	Seq     int      `json:"seq"`
+	Time    string   `json:"time"`
+	Source  string   `json:"source" jsonschema:"cli, mcp or web"`
+	Command string   `json:"command"`
+	Args    string   `json:"args,omitempty"`
+	Actor   string   `json:"actor,omitempty" jsonschema:"the MCP agent behind it: author or session ID"`
+	Undone  bool     `json:"undone,omitempty"`
+	Files   []string `json:"files"`
+}
+
+type UndoLastResult struct {
+	Undone []JournalSummary `json:"undone"`
+}
+
+// SummarizeJournal drops the file contents from entries.
+func SummarizeJournal(entries []*backlog.JournalEntry) []JournalSummary {
+	out := make([]JournalSummary, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, JournalSummary{Seq: e.Seq, Time: e.Time, Source: e.Source, Command: e.Command, Args: e.Args, Actor: e.Actor, Undone: e.Undone, Files: e.Paths()})
+	}
+	return out
+}
+
+// undoLastTool rolls back the agent's own most recent changes. It only
+// undoes entries this agent recorded (see requestActor), passing over
+// other agents' on a shared server; a change made from the command line
+// or the web board is the human's to undo with `am undo`.
+func undoLastTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, UndoLastArgs) (*mcp.CallToolResult, UndoLastResult, error) {
+	return func(ctx context.Context, req *mcp.CallToolRequest, args UndoLastArgs) (*mcp.CallToolResult, UndoLastResult, error) {
+		n := args.Count
+		if n <= 0 {
+			n = 1
+		}
+		entries, err := backlog.LoadJournal(root)
+		if err != nil {
+			return nil, UndoLastResult{}, err
+		}
+		actor := requestActor(req)
+		var todo []*backlog.JournalEntry
+		for i := len(entries) - 1; i >= 0 && len(todo) < n; i-- {
+			e := entries[i]
+			switch {
+			case e.Undone:
+			case e.Source != "mcp":
+				return nil, UndoLastResult{}, fmt.Errorf("change #%d (%s) was made by a person (%s), not by an MCP tool; ask the human to run `am undo`", e.Seq, e.Command, e.Source)
+			case e.Actor == actor:
+				todo = append(todo, e)
+			}
+		}
+		if err := backlog.UndoJournalEntries(root, todo, args.Force); err != nil {
+			return nil, UndoLastResult{}, err
+		}
+		return nil, UndoLastResult{Undone: SummarizeJournal(todo)}, nil
+	}
+}
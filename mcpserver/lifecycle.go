@@ -7,9 +7,11 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mreider/agilemarkdown/backlog"
 	"github.com/mreider/agilemarkdown/config"
+	"github.com/mreider/agilemarkdown/utils"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -152,6 +154,81 @@ func sprintPlanTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.
 	}
 }
 
+// SprintCommitArgs freezes the committed slice of a backlog's sprint plan
+// into `.am/iterations/<n>.yaml` for the current iteration. An existing
+// snapshot for the same backlog is only replaced when Force is set.
+type SprintCommitArgs struct {
+	Backlog string `json:"backlog"`
+	Force   bool   `json:"force,omitempty" jsonschema:"replace an existing snapshot for this backlog and iteration"`
+}
+
+type SprintCommitResult struct {
+	Backlog   string                      `json:"backlog"`
+	Iteration int                         `json:"iteration"`
+	Start     string                      `json:"start"`
+	Path      string                      `json:"path"`
+	Velocity  float64                     `json:"velocity"`
+	Points    float64                     `json:"committed_points"`
+	Items     []backlog.IterationPlanItem `json:"items"`
+	Replaced  bool                        `json:"replaced,omitempty"`
+}
+
+func sprintCommitTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, SprintCommitArgs) (*mcp.CallToolResult, SprintCommitResult, error) {
+	return func(ctx context.Context, req *mcp.CallToolRequest, args SprintCommitArgs) (*mcp.CallToolResult, SprintCommitResult, error) {
+		_, planRes, err := sprintPlanTool(root)(ctx, req, SprintPlanArgs{Backlog: args.Backlog})
+		if err != nil {
+			return nil, SprintCommitResult{}, err
+		}
+		cfg, err := config.LoadConfig(filepath.Join(root.Root(), ".am", "config.yaml"))
+		if err != nil {
+			return nil, SprintCommitResult{}, err
+		}
+		start := backlog.IterationStartFor(time.Now(), cfg)
+		number := backlog.IterationNumberFor(start, cfg)
+		plan, err := backlog.LoadIterationPlan(root.Root(), number)
+		if err != nil {
+			return nil, SprintCommitResult{}, err
+		}
+		if plan == nil {
+			plan = &backlog.IterationPlan{Number: number, Start: start.Format("2006-01-02")}
+		}
+		existing := plan.Backlog(args.Backlog)
+		if existing != nil && !args.Force {
+			return nil, SprintCommitResult{}, fmt.Errorf("iteration %d already has a committed plan for %s (committed %s); pass force to re-freeze it", number, args.Backlog, existing.CommittedAt)
+		}
+		items := make([]backlog.IterationPlanItem, 0, len(planRes.Committed))
+		var pts float64
+		for _, row := range planRes.Committed {
+			if strings.EqualFold(row.Status, backlog.AcceptedStatus.Name) {
+				continue
+			}
+			est := parsePoints(row.Estimate)
+			pts += est
+			items = append(items, backlog.IterationPlanItem{Path: row.Path, Title: row.Title, Estimate: est})
+		}
+		plan.SetBacklog(backlog.IterationPlanBacklog{
+			Name:        args.Backlog,
+			CommittedAt: utils.GetCurrentTimestamp(),
+			Velocity:    planRes.Velocity,
+			Items:       items,
+		})
+		if err := plan.Save(root.Root()); err != nil {
+			return nil, SprintCommitResult{}, err
+		}
+		rel, _ := filepath.Rel(root.Root(), backlog.IterationPlanFile(root.Root(), number))
+		return nil, SprintCommitResult{
+			Backlog:   args.Backlog,
+			Iteration: number,
+			Start:     plan.Start,
+			Path:      rel,
+			Velocity:  planRes.Velocity,
+			Points:    pts,
+			Items:     items,
+			Replaced:  existing != nil,
+		}, nil
+	}
+}
+
 func buildSprintRow(idx int, it *backlog.BacklogItem, basename string) SprintPlanRow {
 	bullets := backlog.AcceptanceBulletTexts(it.Body())
 	row := SprintPlanRow{
@@ -24,13 +24,18 @@ type NextItemResult struct {
 }
 
 // nextItemTool returns the highest-ranked unstarted, unblocked item across
-// the project (or one backlog when filtered). The "next pull" answer.
+// the project (or one backlog when filtered). Stories whose blocked_by
+// lists an unaccepted story count as blocked. The "next pull" answer.
 func nextItemTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, NextItemArgs) (*mcp.CallToolResult, NextItemResult, error) {
 	return func(ctx context.Context, req *mcp.CallToolRequest, args NextItemArgs) (*mcp.CallToolResult, NextItemResult, error) {
 		dirs, err := root.BacklogDirs()
 		if err != nil {
 			return nil, NextItemResult{}, err
 		}
+		deps, err := backlog.LoadDependencyGraph(root)
+		if err != nil {
+			return nil, NextItemResult{}, err
+		}
 		for _, d := range dirs {
 			if args.Backlog != "" && filepath.Base(d) != args.Backlog {
 				continue
@@ -49,7 +54,7 @@ func nextItemTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.Ca
 				if !ok {
 					continue
 				}
-				if it.Blocked() {
+				if it.Blocked() || len(deps.OpenBlockers(it)) > 0 {
 					continue
 				}
 				if !strings.EqualFold(it.Status(), backlog.UnstartedStatus.Name) {
@@ -124,7 +124,7 @@ func iceboxListTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.
 
 type RankItemArgs struct {
 	Backlog  string `json:"backlog"`
-	ItemPath string `json:"item_path" jsonschema:"item file basename inside the backlog"`
+	ItemPath string `json:"item_path" jsonschema:"item file basename inside the backlog, or #ID"`
 	Position string `json:"position,omitempty" jsonschema:"top|bottom"`
 	After    string `json:"after,omitempty" jsonschema:"file basename to place this item after"`
 	Before   string `json:"before,omitempty" jsonschema:"file basename to place this item before"`
@@ -144,31 +144,11 @@ func rankItemTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.Ca
 		if err != nil {
 			return nil, OkResult{}, err
 		}
-		item := basename(args.ItemPath)
-		// pull from icebox if needed
-		if pri.IndexOf(item) < 0 {
-			if i := ice.IndexOf(item); i >= 0 {
-				e := ice.Entries()[i]
-				ice.Remove(item)
-				pri.InsertBottom(e)
-				if err := ice.Save(); err != nil {
-					return nil, OkResult{}, err
-				}
-			} else {
-				return nil, OkResult{}, fmt.Errorf("%s not in priority or icebox", item)
-			}
+		if err := rankInOrder(root, pri, ice, args); err != nil {
+			return nil, OkResult{}, err
 		}
-		switch {
-		case strings.EqualFold(args.Position, "top"):
-			pri.MoveTo(item, 0)
-		case strings.EqualFold(args.Position, "bottom"):
-			pri.MoveTo(item, pri.Len()-1)
-		case args.After != "":
-			pri.MoveAfter(item, basename(args.After))
-		case args.Before != "":
-			pri.MoveBefore(item, basename(args.Before))
-		default:
-			return nil, OkResult{}, fmt.Errorf("specify position (top|bottom) or after/before")
+		if err := ice.Save(); err != nil {
+			return nil, OkResult{}, err
 		}
 		if err := pri.Save(); err != nil {
 			return nil, OkResult{}, err
@@ -177,6 +157,36 @@ func rankItemTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.Ca
 	}
 }
 
+// rankInOrder applies a rank_item to the order files in memory, pulling
+// the item out of the icebox first when it is there. The PreToolUse
+// gate uses it to look at the list as it would be.
+func rankInOrder(root *backlog.BacklogsStructure, pri, ice *backlog.OrderFile, args RankItemArgs) error {
+	item := itemBase(root, args.ItemPath)
+	// pull from icebox if needed
+	if pri.IndexOf(item) < 0 {
+		if i := ice.IndexOf(item); i >= 0 {
+			e := ice.Entries()[i]
+			ice.Remove(item)
+			pri.InsertBottom(e)
+		} else {
+			return fmt.Errorf("%s not in priority or icebox", item)
+		}
+	}
+	switch {
+	case strings.EqualFold(args.Position, "top"):
+		pri.MoveTo(item, 0)
+	case strings.EqualFold(args.Position, "bottom"):
+		pri.MoveTo(item, pri.Len()-1)
+	case args.After != "":
+		pri.MoveAfter(item, itemBase(root, args.After))
+	case args.Before != "":
+		pri.MoveBefore(item, itemBase(root, args.Before))
+	default:
+		return fmt.Errorf("specify position (top|bottom) or after/before")
+	}
+	return nil
+}
+
 type MoveToIceboxArgs struct {
 	Backlog  string `json:"backlog"`
 	ItemPath string `json:"item_path"`
@@ -197,7 +207,7 @@ func moveToIceboxTool(root *backlog.BacklogsStructure) func(context.Context, *mc
 		if err != nil {
 			return nil, OkResult{}, err
 		}
-		item := basename(args.ItemPath)
+		item := itemBase(root, args.ItemPath)
 		idx := pri.IndexOf(item)
 		if idx < 0 {
 			return nil, OkResult{}, fmt.Errorf("%s not in priority", item)
@@ -240,41 +250,8 @@ func moveToPriorityTool(root *backlog.BacklogsStructure) func(context.Context, *
 		if err != nil {
 			return nil, OkResult{}, err
 		}
-		if len(args.ItemPaths) == 0 {
-			return nil, OkResult{}, fmt.Errorf("item_paths is required")
-		}
-		// Pluck entries from icebox in input order.
-		picked := make([]backlog.OrderEntry, 0, len(args.ItemPaths))
-		for _, p := range args.ItemPaths {
-			b := basename(p)
-			idx := ice.IndexOf(b)
-			if idx < 0 {
-				return nil, OkResult{}, fmt.Errorf("%s not in icebox", b)
-			}
-			picked = append(picked, ice.Entries()[idx])
-			ice.Remove(b)
-		}
-		// Single-item special case for `after`.
-		if len(picked) == 1 && args.After != "" {
-			anchor := basename(args.After)
-			ai := pri.IndexOf(anchor)
-			if ai < 0 {
-				pri.InsertBottom(picked[0])
-			} else {
-				pri.InsertAt(ai+1, picked[0])
-			}
-		} else {
-			top := strings.EqualFold(args.Position, "top")
-			if top {
-				// Insert in order at index 0..n.
-				for i, e := range picked {
-					pri.InsertAt(i, e)
-				}
-			} else {
-				for _, e := range picked {
-					pri.InsertBottom(e)
-				}
-			}
+		if err := moveIntoPriority(root, pri, ice, args); err != nil {
+			return nil, OkResult{}, err
 		}
 		if err := pri.Save(); err != nil {
 			return nil, OkResult{}, err
@@ -286,6 +263,48 @@ func moveToPriorityTool(root *backlog.BacklogsStructure) func(context.Context, *
 	}
 }
 
+// moveIntoPriority applies a move_to_priority to the order files in
+// memory.
+func moveIntoPriority(root *backlog.BacklogsStructure, pri, ice *backlog.OrderFile, args MoveToPriorityArgs) error {
+	if len(args.ItemPaths) == 0 {
+		return fmt.Errorf("item_paths is required")
+	}
+	// Pluck entries from icebox in input order.
+	picked := make([]backlog.OrderEntry, 0, len(args.ItemPaths))
+	for _, p := range args.ItemPaths {
+		b := itemBase(root, p)
+		idx := ice.IndexOf(b)
+		if idx < 0 {
+			return fmt.Errorf("%s not in icebox", b)
+		}
+		picked = append(picked, ice.Entries()[idx])
+		ice.Remove(b)
+	}
+	// Single-item special case for `after`.
+	if len(picked) == 1 && args.After != "" {
+		anchor := itemBase(root, args.After)
+		ai := pri.IndexOf(anchor)
+		if ai < 0 {
+			pri.InsertBottom(picked[0])
+		} else {
+			pri.InsertAt(ai+1, picked[0])
+		}
+	} else {
+		top := strings.EqualFold(args.Position, "top")
+		if top {
+			// Insert in order at index 0..n.
+			for i, e := range picked {
+				pri.InsertAt(i, e)
+			}
+		} else {
+			for _, e := range picked {
+				pri.InsertBottom(e)
+			}
+		}
+	}
+	return nil
+}
+
 type EpicProgressArgs struct {
 	Slug string `json:"slug"`
 }
@@ -380,7 +399,10 @@ type RejectItemArgs struct {
 
 func rejectItemTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, RejectItemArgs) (*mcp.CallToolResult, OkResult, error) {
 	return func(ctx context.Context, req *mcp.CallToolRequest, args RejectItemArgs) (*mcp.CallToolResult, OkResult, error) {
-		path := filepath.Join(root.Root(), args.Path)
+		path, err := backlog.ResolveItemPath(root, args.Path)
+		if err != nil {
+			return nil, OkResult{}, err
+		}
 		item, err := backlog.LoadBacklogItem(path)
 		if err != nil {
 			return nil, OkResult{}, err
@@ -442,6 +464,17 @@ func indexItems(bck *backlog.Backlog) map[string]*backlog.BacklogItem {
 	return out
 }
 
+// itemBase is basename for order-file lookups, resolving "#1234" by ID
+// first.
+func itemBase(root *backlog.BacklogsStructure, p string) string {
+	if backlog.ParseItemRef(p) > 0 {
+		if path, err := backlog.ResolveItemPath(root, p); err == nil {
+			return filepath.Base(path)
+		}
+	}
+	return basename(p)
+}
+
 func basename(p string) string {
 	p = strings.TrimSpace(p)
 	if !strings.HasSuffix(p, ".md") {
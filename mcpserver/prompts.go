@@ -0,0 +1,81 @@
+package mcpserver
+
+import (
+	"context"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/coach"
+)
+
+// skillArguments are the optional inputs each coach prompt takes. The
+// skill text explains what to do without them; given, they are appended
+// so the ceremony starts on the right story or backlog.
+var skillArguments = map[string][]*mcp.PromptArgument{
+	"accept":    {{Name: "item", Description: "path or #ID of the delivered story to accept"}},
+	"align":     {{Name: "item", Description: "path or #ID of the story about to be pulled"}},
+	"decompose": {{Name: "problem", Description: "the problem statement to break into stories"}},
+	"plan":      {{Name: "backlog", Description: "backlog to plan the iteration for"}},
+	"retro":     {{Name: "backlog", Description: "backlog whose iteration is ending"}},
+}
+
+// registerPrompts exposes the six coach skills, and the team's own from
+// .am/skills, as MCP prompts, so any client gets the same ceremonies
+// Claude Code loads from .claude/skills. A team skill is rendered with
+// live backlog data each time it is fetched. The built-ins are
+// registered even when a team skill does not load.
+func registerPrompts(srv *mcp.Server, root *backlog.BacklogsStructure) error {
+	skills, err := coach.Skills()
+	if err != nil {
+		return err
+	}
+	team, teamErr := coach.TeamSkills(root.Root())
+	for _, s := range append(skills, team...) {
+		title := "am-" + s.Name
+		if s.Source != "" {
+			title = s.Name
+		}
+		srv.AddPrompt(&mcp.Prompt{
+			Name:        s.Name,
+			Title:       title,
+			Description: s.Description,
+			Arguments:   promptArguments(s),
+		}, skillPrompt(root, s.Name))
+	}
+	return teamErr
+}
+
+// promptArguments are the inputs s takes: from skillArguments for a
+// built-in, from its frontmatter for a team skill.
+func promptArguments(s coach.Skill) []*mcp.PromptArgument {
+	if s.Source == "" {
+		return skillArguments[s.Name]
+	}
+	var out []*mcp.PromptArgument
+	for _, a := range s.Arguments {
+		out = append(out, &mcp.PromptArgument{Name: a.Name, Description: a.Description})
+	}
+	return out
+}
+
+func skillPrompt(root *backlog.BacklogsStructure, name string) mcp.PromptHandler {
+	return func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		args := map[string]string{}
+		for k, v := range req.Params.Arguments {
+			if strings.TrimSpace(v) != "" {
+				args[k] = v
+			}
+		}
+		r, err := runCeremony(root, CeremonyArgs{Name: name, Arguments: args})
+		if err != nil {
+			return nil, err
+		}
+		return &mcp.GetPromptResult{
+			Description: r.Description,
+			Messages: []*mcp.PromptMessage{
+				{Role: "user", Content: &mcp.TextContent{Text: r.Text}},
+			},
+		}, nil
+	}
+}
@@ -0,0 +1,89 @@
+package mcpserver
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/config"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type ReleaseForecastArgs struct {
+	Release string `json:"release,omitempty" jsonschema:"release marker slug (file name without .md) or path; empty forecasts every marker"`
+	Backlog string `json:"backlog,omitempty" jsonschema:"optional backlog filter"`
+}
+
+type ReleaseForecastRow struct {
+	Backlog          string  `json:"backlog"`
+	Path             string  `json:"path"`
+	Title            string  `json:"title"`
+	ReleaseDate      string  `json:"release_date,omitempty"`
+	PointsAbove      float64 `json:"points_above"`
+	PointsRemaining  float64 `json:"points_remaining"`
+	UnestimatedAbove int     `json:"unestimated_above,omitempty"`
+	Velocity         float64 `json:"velocity"`
+	Volatility       float64 `json:"volatility_percent"`
+	Iterations       int     `json:"iterations"`
+	Iteration        int     `json:"iteration"`
+	ExpectedDate     string  `json:"expected_date"`
+	OptimisticDate   string  `json:"optimistic_date"`
+	PessimisticDate  string  `json:"pessimistic_date"`
+	Status           string  `json:"status"`
+}
+
+type ReleaseForecastResult struct {
+	Releases []ReleaseForecastRow `json:"releases"`
+	Count    int                  `json:"count"`
+}
+
+func releaseForecastTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, ReleaseForecastArgs) (*mcp.CallToolResult, ReleaseForecastResult, error) {
+	return func(ctx context.Context, req *mcp.CallToolRequest, args ReleaseForecastArgs) (*mcp.CallToolResult, ReleaseForecastResult, error) {
+		cfg, err := config.LoadConfig(filepath.Join(root.Root(), ".am", "config.yaml"))
+		if err != nil {
+			return nil, ReleaseForecastResult{}, err
+		}
+		forecasts, err := backlog.FindReleaseForecasts(root, cfg, time.Now().In(cfg.IterationLocation()), args.Backlog, args.Release)
+		if err != nil {
+			return nil, ReleaseForecastResult{}, err
+		}
+		out := make([]ReleaseForecastRow, 0, len(forecasts))
+		var text strings.Builder
+		for i, f := range forecasts {
+			if i > 0 {
+				text.WriteString("\n")
+			}
+			text.WriteString(backlog.ReleaseForecastASCII(f))
+			out = append(out, toReleaseForecastRow(f))
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: text.String()}},
+		}, ReleaseForecastResult{Releases: out, Count: len(out)}, nil
+	}
+}
+
+func toReleaseForecastRow(f backlog.ReleaseForecast) ReleaseForecastRow {
+	row := ReleaseForecastRow{
+		Backlog:          f.Backlog,
+		Path:             f.Path,
+		Title:            f.Title,
+		PointsAbove:      f.PointsAbove,
+		PointsRemaining:  f.PointsRemaining,
+		UnestimatedAbove: f.UnestimatedAbove,
+		Velocity:         f.Velocity,
+		Volatility:       f.Volatility,
+		Iterations:       f.Iterations,
+		Iteration:        f.Iteration,
+		ExpectedDate:     f.ExpectedDate.Format("2006-01-02"),
+		OptimisticDate:   f.OptimisticDate.Format("2006-01-02"),
+		PessimisticDate:  f.PessimisticDate.Format("2006-01-02"),
+		Status:           f.Status,
+	}
+	if !f.ReleaseDate.IsZero() {
+		row.ReleaseDate = f.ReleaseDate.Format("2006-01-02")
+	}
+	return row
+}
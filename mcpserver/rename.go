@@ -0,0 +1,38 @@
+package mcpserver
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/mreider/agilemarkdown/backlog"
+)
+
+type RenameItemArgs struct {
+	Path  string `json:"path"`
+	Title string `json:"title" jsonschema:"new title; the file is renamed to match"`
+}
+
+type RenameItemResult struct {
+	ID   int    `json:"id,omitempty"`
+	Path string `json:"path"`
+}
+
+func renameItemTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, RenameItemArgs) (*mcp.CallToolResult, RenameItemResult, error) {
+	return func(ctx context.Context, req *mcp.CallToolRequest, args RenameItemArgs) (*mcp.CallToolResult, RenameItemResult, error) {
+		path, err := backlog.ResolveItemPath(root, args.Path)
+		if err != nil {
+			return nil, RenameItemResult{}, err
+		}
+		item, err := backlog.LoadBacklogItem(path)
+		if err != nil {
+			return nil, RenameItemResult{}, err
+		}
+		newPath, err := backlog.RenameItem(root, item, args.Title)
+		if err != nil {
+			return nil, RenameItemResult{}, err
+		}
+		rel, _ := filepath.Rel(root.Root(), newPath)
+		return nil, RenameItemResult{ID: item.ID(), Path: filepath.ToSlash(rel)}, nil
+	}
+}
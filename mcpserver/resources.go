@@ -0,0 +1,196 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/mreider/agilemarkdown/backlog"
+)
+
+// projectDocs are the files at the project root served as resources.
+var projectDocs = map[string]string{
+	"inception":       "Inception",
+	"team-agreements": "Team agreements",
+	"learnings":       "Learnings",
+}
+
+// projectResources keeps the server's resource list in step with the
+// files on disk: every active item, each backlog's _priority.md and
+// _icebox.md, and the project docs. Archived items are readable through
+// the archive template but not listed.
+type projectResources struct {
+	root   *backlog.BacklogsStructure
+	srv    *mcp.Server
+	mu     sync.Mutex
+	listed map[string]string // URI -> title
+}
+
+func registerResources(srv *mcp.Server, root *backlog.BacklogsStructure) *projectResources {
+	pr := &projectResources{root: root, srv: srv, listed: map[string]string{}}
+	read := readResource(root)
+	srv.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "item",
+		Title:       "Backlog file",
+		Description: "A story, _priority or _icebox in a backlog folder, as markdown with YAML frontmatter.",
+		MIMEType:    "text/markdown",
+		URITemplate: "am://backlog/{backlog}/{item}",
+	}, read)
+	srv.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "archived-item",
+		Title:       "Archived story",
+		Description: "A story under a backlog's archive/ folder.",
+		MIMEType:    "text/markdown",
+		URITemplate: "am://backlog/{backlog}/archive/{item}",
+	}, read)
+	srv.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "project-doc",
+		Title:       "Project document",
+		Description: "inception, team-agreements or learnings at the project root.",
+		MIMEType:    "text/markdown",
+		URITemplate: "am://project/{name}",
+	}, read)
+
+	var rels []string
+	for name := range projectDocs {
+		rels = append(rels, name+".md")
+	}
+	dirs, _ := root.BacklogDirs()
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if !e.IsDir() && strings.HasSuffix(e.Name(), ".md") {
+				rels = append(rels, filepath.Base(dir)+"/"+e.Name())
+			}
+		}
+	}
+	pr.refresh(rels)
+	return pr
+}
+
+// refresh adds, retitles or removes the resources for the given files
+// (relative to the root, forward slashes). Files that aren't resources
+// are ignored.
+func (pr *projectResources) refresh(rels []string) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	read := readResource(pr.root)
+	var gone []string
+	for _, rel := range rels {
+		uri := resourceURI(rel)
+		res, ok := pr.describe(rel)
+		if !ok {
+			if _, was := pr.listed[uri]; was {
+				gone = append(gone, uri)
+				delete(pr.listed, uri)
+			}
+			continue
+		}
+		if title, was := pr.listed[uri]; was && title == res.Title {
+			continue
+		}
+		pr.listed[uri] = res.Title
+		pr.srv.AddResource(res, read)
+	}
+	if len(gone) > 0 {
+		pr.srv.RemoveResources(gone...)
+	}
+}
+
+// describe returns the resource for rel when rel is a listed resource
+// that exists.
+func (pr *projectResources) describe(rel string) (*mcp.Resource, bool) {
+	name := strings.TrimSuffix(rel, ".md")
+	if name == rel {
+		return nil, false
+	}
+	var title string
+	if dir, base, nested := strings.Cut(name, "/"); !nested {
+		if title = projectDocs[name]; title == "" {
+			return nil, false
+		}
+	} else {
+		switch {
+		case !isBacklogName(dir) || strings.Contains(base, "/"):
+			return nil, false
+		case base == "_priority":
+			title = "Priority (" + dir + ")"
+		case base == "_icebox":
+			title = "Icebox (" + dir + ")"
+		case backlog.IsForbiddenItemName(base):
+			return nil, false
+		}
+	}
+	path := filepath.Join(pr.root.Root(), filepath.FromSlash(rel))
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return nil, false
+	}
+	if title == "" {
+		item, err := backlog.LoadBacklogItem(path)
+		if err != nil {
+			return nil, false
+		}
+		title = item.Title()
+	}
+	return &mcp.Resource{URI: resourceURI(rel), Name: name, Title: title, MIMEType: "text/markdown", Size: info.Size()}, true
+}
+
+// resourcePath maps an am:// URI back to a file under the root, refusing
+// anything outside the backlog folders and project docs.
+func resourcePath(root *backlog.BacklogsStructure, uri string) (string, error) {
+	var rel string
+	switch {
+	case strings.HasPrefix(uri, "am://backlog/"):
+		rel = strings.TrimPrefix(uri, "am://backlog/")
+	case strings.HasPrefix(uri, "am://project/"):
+		rel = strings.TrimPrefix(uri, "am://project/")
+		if _, ok := projectDocs[rel]; !ok {
+			return "", mcp.ResourceNotFoundError(uri)
+		}
+	default:
+		return "", mcp.ResourceNotFoundError(uri)
+	}
+	segments := strings.Split(rel, "/")
+	for i, s := range segments {
+		u, err := url.PathUnescape(s)
+		if err != nil || u == "" || u == "." || u == ".." || strings.ContainsAny(u, `/\`) {
+			return "", mcp.ResourceNotFoundError(uri)
+		}
+		segments[i] = u
+	}
+	if strings.HasPrefix(uri, "am://backlog/") {
+		if len(segments) < 2 || len(segments) > 3 || !isBacklogName(segments[0]) || (len(segments) == 3 && segments[1] != "archive") {
+			return "", mcp.ResourceNotFoundError(uri)
+		}
+	}
+	return filepath.Join(root.Root(), filepath.Join(segments...)+".md"), nil
+}
+
+func readResource(root *backlog.BacklogsStructure) mcp.ResourceHandler {
+	return func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		uri := req.Params.URI
+		path, err := resourcePath(root, uri)
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, mcp.ResourceNotFoundError(uri)
+			}
+			return nil, fmt.Errorf("read %s: %w", uri, err)
+		}
+		return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{
+			{URI: uri, MIMEType: "text/markdown", Text: string(data)},
+		}}, nil
+	}
+}
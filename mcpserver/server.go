@@ -2,6 +2,7 @@ package mcpserver
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -20,11 +21,14 @@ import (
 // stateMu serializes every state-mutating MCP tool call. The MCP SDK
 // dispatches requests concurrently; without a mutex two tools racing on
 // the same item file can clobber each other (e.g. change_tag and
-// delete_tag back-to-back). Wrapped via locked() in handler files.
+// delete_tag back-to-back). Wrapped via locked() or journaled() in
+// buildServer.
 var stateMu sync.Mutex
 
 // locked wraps a handler so it acquires stateMu for the duration of the
-// call. Use on every handler that writes to disk.
+// call. Only for writers that must stay out of the undo journal (sync
+// commits and pushes; undo_last replays the journal itself;
+// coach_override appends to a log undo must not erase).
 func locked[A, R any](h func(context.Context, *mcp.CallToolRequest, A) (*mcp.CallToolResult, R, error)) func(context.Context, *mcp.CallToolRequest, A) (*mcp.CallToolResult, R, error) {
 	return func(ctx context.Context, req *mcp.CallToolRequest, args A) (*mcp.CallToolResult, R, error) {
 		stateMu.Lock()
@@ -33,23 +37,71 @@ func locked[A, R any](h func(context.Context, *mcp.CallToolRequest, A) (*mcp.Cal
 	}
 }
 
+// journaled is locked plus an undo journal entry for whatever files the
+// call changed, so undo_last and `am undo` can roll it back. Use on
+// every other handler that writes to disk. A failed call is journaled
+// too: its partial writes are exactly what one would want to undo.
+func journaled[A, R any](root *backlog.BacklogsStructure, h func(context.Context, *mcp.CallToolRequest, A) (*mcp.CallToolResult, R, error)) func(context.Context, *mcp.CallToolRequest, A) (*mcp.CallToolResult, R, error) {
+	return func(ctx context.Context, req *mcp.CallToolRequest, args A) (*mcp.CallToolResult, R, error) {
+		stateMu.Lock()
+		defer stateMu.Unlock()
+		snap, snapErr := backlog.TakeSnapshot(root)
+		res, out, err := h(ctx, req, args)
+		if snapErr == nil {
+			entry := backlog.JournalEntry{Source: "mcp", Command: "tool", Actor: requestActor(req)}
+			if req != nil && req.Params != nil {
+				entry.Command = req.Params.Name
+			}
+			if data, jerr := json.Marshal(args); jerr == nil {
+				entry.Args = string(data)
+			}
+			if _, jerr := backlog.RecordJournal(root, snap, entry); jerr != nil {
+				fmt.Fprintf(os.Stderr, "am: undo journal: %v\n", jerr)
+			}
+		}
+		return res, out, err
+	}
+}
+
+// addWriteTool registers a tool that changes files. A read-only server
+// (`am mcp --read-only`) leaves it out, so clients never see it.
+func addWriteTool[A, R any](srv *mcp.Server, readOnly bool, t *mcp.Tool, h mcp.ToolHandlerFor[A, R]) {
+	if readOnly {
+		return
+	}
+	mcp.AddTool(srv, t, h)
+}
+
 // Run starts an MCP stdio server rooted at the given backlog root directory.
 // rootDir must contain backlog folders (or be the parent git repo).
-func Run(ctx context.Context, rootDir, version string) error {
+func Run(ctx context.Context, rootDir, version string, readOnly bool) error {
 	abs, err := filepath.Abs(rootDir)
 	if err != nil {
 		return err
 	}
-	srv := buildServer(abs, version)
+	backlog.EnableItemCache()
+	srv, resources := newServer(abs, version, readOnly)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	watchProject(ctx, srv, resources)
 	return srv.Run(ctx, &mcp.StdioTransport{})
 }
 
-// buildServer wires up the MCP server with all tools but does not bind a
-// transport. Exposed for tests that swap in an in-memory pair.
+// buildServer wires up the MCP server with all tools, resources and
+// prompts but does not bind a transport. Exposed for tests that swap in
+// an in-memory pair.
 func buildServer(rootDir, version string) *mcp.Server {
+	srv, _ := newServer(rootDir, version, false)
+	return srv
+}
+
+func newServer(rootDir, version string, readOnly bool) (*mcp.Server, *projectResources) {
 	root := backlog.NewBacklogsStructure(rootDir)
 
-	srv := mcp.NewServer(&mcp.Implementation{Name: "agilemarkdown", Version: version}, nil)
+	srv := mcp.NewServer(&mcp.Implementation{Name: "agilemarkdown", Version: version}, &mcp.ServerOptions{
+		SubscribeHandler:   subscribeResource,
+		UnsubscribeHandler: unsubscribeResource,
+	})
 
 	mcp.AddTool(srv, &mcp.Tool{
 		Name:        "list_backlogs",
@@ -66,32 +118,32 @@ func buildServer(rootDir, version string) *mcp.Server {
 		Description: "Read the full markdown body of a single backlog item by file path (relative to project root).",
 	}, getItem(root))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "create_item",
 		Description: "Create a new backlog item under a given backlog with a title.",
-	}, locked(createItem(root)))
+	}, journaled(root, createItem(root)))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "set_status",
 		Description: "Change the status of an item identified by its file path. Status must be one of: unstarted, started, finished, delivered, accepted, rejected. Auto-stamps `finished`/`delivered`/`accepted` timestamps on transitions; clears them on regression.",
-	}, locked(setStatus(root)))
+	}, journaled(root, setStatus(root)))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "set_assigned",
 		Description: "Assign an item to a user (name or email).",
-	}, locked(setAssigned(root)))
+	}, journaled(root, setAssigned(root)))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "set_estimate",
 		Description: "Set the story-point estimate on an item.",
-	}, locked(setEstimate(root)))
+	}, journaled(root, setEstimate(root)))
 
 	mcp.AddTool(srv, &mcp.Tool{
 		Name:        "validate",
 		Description: "Run schema validation across all items. Returns the list of validation errors.",
 	}, validateAll(root))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "sync",
 		Description: "Run the full sync: regenerate index, velocity, timeline, tag pages; commit; push if a remote is configured.",
 	}, locked(syncAction(root)))
@@ -106,55 +158,55 @@ func buildServer(rootDir, version string) *mcp.Server {
 		Description: "Render an ASCII Gantt for items carrying a given tag. Items must have a `timeline.start` and `timeline.end`.",
 	}, timelineChart(root))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "create_backlog",
 		Description: "Create a new backlog folder under the project root with sample feature/bug/chore items. The backlog name becomes a top-level directory plus an overview file (`<name>.md`).",
-	}, locked(createBacklogTool(root)))
+	}, journaled(root, createBacklogTool(root)))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "archive_items",
 		Description: "Archive every active item whose Modified date falls on or before `before` (YYYY-MM-DD). Items are flagged with `archive: true` and move to <backlog>/archive/ on next sync. Use to keep the active backlog small after a release.",
-	}, locked(archiveItemsTool(root)))
+	}, journaled(root, archiveItemsTool(root)))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "team_agreements",
 		Description: "Read (or with `set`, overwrite) team-agreements.md at the project root. Single source of truth for working agreements; the place to record rules an LLM agent should follow alongside the team.",
-	}, locked(teamAgreementsTool(root)))
+	}, journaled(root, teamAgreementsTool(root)))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "record_learning",
 		Description: "Append a one-line learning entry to learnings.md at the project root. Date is prefixed automatically. Use for removal-experiment outcomes, scratch-refactor takeaways, or retro one-liners.",
-	}, locked(recordLearningTool(root)))
+	}, journaled(root, recordLearningTool(root)))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "set_hypothesis",
 		Description: "Deprecated. Set the `hypothesis:` frontmatter on an item. The Pivotal way uses acceptance criteria in the body's `## Acceptance` section, not a hypothesis field; the coach reads those criteria. This tool is kept for back-compat.",
-	}, locked(setHypothesisTool(root)))
+	}, journaled(root, setHypothesisTool(root)))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "set_tags",
 		Description: "Replace the `tags:` list on an item. Pass an empty array to clear. Use `change_tag`/`delete_tag` for fleet-wide rename/drop.",
-	}, locked(setTagsTool(root)))
+	}, journaled(root, setTagsTool(root)))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "set_epic",
 		Description: "Set the `epic:` slug on an item. Pass an empty slug to clear. Stories sharing a slug roll up under `epic_progress`.",
-	}, locked(setEpicTool(root)))
+	}, journaled(root, setEpicTool(root)))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "change_tag",
 		Description: "Rename a tag fleet-wide: every item carrying `old` is rewritten to use `new`. Run `sync` afterwards to regenerate tag pages.",
-	}, locked(changeTagTool(root)))
+	}, journaled(root, changeTagTool(root)))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "delete_tag",
 		Description: "Remove a tag from every item that carries it. Returns the number of items modified. Run `sync` afterwards to regenerate tag pages.",
-	}, locked(deleteTagTool(root)))
+	}, journaled(root, deleteTagTool(root)))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "set_iteration_override",
 		Description: "Set per-iteration team-strength and/or length overrides in `.am/iterations.yaml`. Mirrors Pivotal Tracker's iteration_override resource. team_strength is a float (1.0 = full strength, 0 = excluded from velocity, >1.0 allowed). Pass `unset: true` to remove the record. Strength normalizes the velocity formula: SUM(points/strength)/SUM(length).",
-	}, locked(setIterationOverrideTool(root)))
+	}, journaled(root, setIterationOverrideTool(root)))
 
 	mcp.AddTool(srv, &mcp.Tool{
 		Name:        "list_iteration_overrides",
@@ -181,70 +233,85 @@ func buildServer(rootDir, version string) *mcp.Server {
 		Description: "Return the ordered contents of a backlog's _icebox.md (top = highest rank).",
 	}, iceboxListTool(root))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "rank_item",
 		Description: "Reorder an item inside _priority.md. Provide `position` (top|bottom) or `after`/`before` (a sibling file basename). If the item is currently in icebox, it is pulled into priority first.",
-	}, locked(rankItemTool(root)))
+	}, journaled(root, rankItemTool(root)))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "move_to_icebox",
 		Description: "Move an item from _priority.md to _icebox.md. `position` is top or bottom (default bottom).",
-	}, locked(moveToIceboxTool(root)))
+	}, journaled(root, moveToIceboxTool(root)))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "move_to_priority",
 		Description: "Bulk-move items from _icebox.md to _priority.md. Input order is preserved. With a single item, `after` lets you place it precisely; otherwise `position` (top|bottom) controls placement.",
-	}, locked(moveToPriorityTool(root)))
+	}, journaled(root, moveToPriorityTool(root)))
 
 	mcp.AddTool(srv, &mcp.Tool{
 		Name:        "epic_progress",
 		Description: "Render an ASCII burnup for an epic identified by slug. Walks every backlog and rolls up stories whose `epic:` frontmatter equals the slug.",
 	}, epicProgressTool(root))
 
+	addWriteTool(srv, readOnly, &mcp.Tool{
+		Name:        "create_epic",
+		Description: "Create epics/<slug>.md with title, description, owner, target release and labels. Once a project has epics/, every item's `epic:` must name one of these files (validate and sync enforce it).",
+	}, journaled(root, createEpicTool(root)))
+
+	mcp.AddTool(srv, &mcp.Tool{
+		Name:        "list_epics",
+		Description: "List epic files with status, owner, target release, labels and rolled-up story counts and points (total, accepted, remaining). Filter by status open|closed.",
+	}, listEpicsTool(root))
+
+	addWriteTool(srv, readOnly, &mcp.Tool{
+		Name:        "close_epic",
+		Description: "Mark an epic closed. Refuses while member stories are not accepted unless force is set.",
+	}, journaled(root, closeEpicTool(root)))
+
 	mcp.AddTool(srv, &mcp.Tool{
 		Name:        "iteration_view",
 		Description: "Render a single iteration window from _priority.md. Offset 0 is the current iteration; 1 is next; etc. Velocity-bounded.",
 	}, iterationViewTool(root))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "reject_item",
 		Description: "Reject an item: transitions status to `rejected` and (with `reason`) appends a dated note under '## Rejection notes' in the item body. Use this instead of set_status when capturing PM rejection rationale.",
-	}, locked(rejectItemTool(root)))
+	}, journaled(root, rejectItemTool(root)))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "block_item",
-		Description: "Mark an item as blocked. Stores `blocked: true` plus an optional `blocked_reason:` in frontmatter. The item still flows through the state machine; UIs render a blocked badge.",
-	}, locked(blockItemTool(root)))
+		Description: "Mark an item as blocked. Stores `blocked: true` plus an optional `blocked_reason:` in frontmatter. With `blocked_by`, records story dependencies instead (`blocked_by:` here, `blocks:` on the blocker); they clear themselves when the blocker is accepted. The item still flows through the state machine; UIs render a blocked badge.",
+	}, journaled(root, blockItemTool(root)))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "unblock_item",
-		Description: "Clear an item's blocked flag and reason.",
-	}, locked(unblockItemTool(root)))
+		Description: "Clear an item's blocked flag and reason. With `blocked_by`, drops only those story dependencies.",
+	}, journaled(root, unblockItemTool(root)))
 
 	mcp.AddTool(srv, &mcp.Tool{
 		Name:        "get_comments",
 		Description: "Return parsed comments for an item (author, when, text). Comments live under '## Comments' in the body; this tool returns one row per comment plus a count for badge rendering.",
 	}, getCommentsTool(root))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "add_comment",
 		Description: "Append a comment under '## Comments' in an item's body. Author defaults to the item's `author:` frontmatter or the literal 'user'. Date is stamped automatically.",
-	}, locked(addCommentTool(root)))
+	}, journaled(root, addCommentTool(root)))
 
 	mcp.AddTool(srv, &mcp.Tool{
 		Name:        "list_tasks",
 		Description: "Return parsed checkbox tasks under '## Tasks' in an item body. Index is 1-based and stable per parse.",
 	}, listTasksTool(root))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "add_task",
 		Description: "Append an unchecked task under '## Tasks' in an item body. Creates the section if missing.",
-	}, locked(addTaskTool(root)))
+	}, journaled(root, addTaskTool(root)))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "set_task_done",
 		Description: "Flip the checkbox of the task at 1-based index to `done` (true|false).",
-	}, locked(setTaskDoneTool(root)))
+	}, journaled(root, setTaskDoneTool(root)))
 
 	mcp.AddTool(srv, &mcp.Tool{
 		Name:        "burnup_chart",
@@ -258,17 +325,17 @@ func buildServer(rootDir, version string) *mcp.Server {
 
 	mcp.AddTool(srv, &mcp.Tool{
 		Name:        "cumulative_flow",
-		Description: "Per-day cumulative-flow rows: count of accepted stories vs open (not-yet-accepted) stories over the last N days (default 30). Project-level.",
+		Description: "Per-day cumulative-flow rows: count of accepted stories vs open (not-yet-accepted) stories over the last N days (default 30). Project-level. Set from_git to replay every committed revision of each item, so rejections, restarts and icebox time show up instead of being flattened into today's timestamps.",
 	}, cfdTool(root))
 
 	mcp.AddTool(srv, &mcp.Tool{
 		Name:        "search",
-		Description: "Substring search across all active stories. Scores title, tags, path, and body matches and returns the top hits with short snippets.",
+		Description: "Full-text search across stories, BM25-ranked with stemming and typo tolerance. Query mixes words, \"quoted phrases\" and field filters (status:started tag:auth owner:alice author:me epic:x backlog:x estimate:>3 estimate:none id:12 archived:true; comma-separate alternatives, me is the current git user), any of them negated with a leading -. Archived items are skipped unless archived is set. Returns the top hits with short snippets.",
 	}, searchTool(root))
 
 	mcp.AddTool(srv, &mcp.Tool{
 		Name:        "velocity_history",
-		Description: "Structured velocity history (one row per completed iteration in the lookback). Each row has iteration number, start date, planned points, accepted points, length_weeks and team_strength.",
+		Description: "Structured velocity history (one row per completed iteration in the lookback). Each row has iteration number, start date, planned points, accepted points, length_weeks and team_strength. Iterations with a sprint_commit snapshot report planned from the snapshot (planned_from_snapshot=true) plus carry_over and scope_added.",
 	}, velocityHistoryTool(root))
 
 	mcp.AddTool(srv, &mcp.Tool{
@@ -278,19 +345,24 @@ func buildServer(rootDir, version string) *mcp.Server {
 
 	mcp.AddTool(srv, &mcp.Tool{
 		Name:        "dashboard",
-		Description: "One-block project dashboard: latest velocity, volatility percent, median cycle time in hours, latest rejection-rate percent, total stories accepted.",
+		Description: "One-block project dashboard: latest velocity, volatility percent, median cycle time in hours, latest rejection-rate percent, total stories accepted, and the current iteration's planned/accepted/remaining/scope-added points when a sprint_commit snapshot exists.",
 	}, dashboardTool(root))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "set_description",
 		Description: "Replace the markdown body of an item. The frontmatter block is preserved. Use for full edits from a UI; comments and tasks live inside the body, so callers must include them.",
-	}, locked(setDescriptionTool(root)))
+	}, journaled(root, setDescriptionTool(root)))
 
 	mcp.AddTool(srv, &mcp.Tool{
 		Name:        "coach_check",
-		Description: "Preflight a planned action against Pivotal canon. Returns a structured verdict: allowed/refused, the rule, the canonical essay slug, and a suggested next move. Use before set_status to accepted, set_estimate, or create_item with an estimate.",
+		Description: "Preflight a planned action against Pivotal canon. Rules and custom checks are tuned in .am/coach.yaml. Returns a structured verdict: allowed/refused, the rule, its slug, and a suggested next move. Use before set_status to accepted, set_estimate, or create_item with an estimate.",
 	}, coachCheckTool(root))
 
+	addWriteTool(srv, readOnly, &mcp.Tool{
+		Name:        "coach_override",
+		Description: "Record that the human is going ahead despite a coach_check refusal or nudge, with their reason, in .am/coach-log.jsonl. For the next hour coach_check lets the same rule through for that item and action. Only call when the human has decided and given the reason; never override on your own. am retro summarizes overrides per rule.",
+	}, locked(coachOverrideTool(root, "mcp")))
+
 	mcp.AddTool(srv, &mcp.Tool{
 		Name:        "acceptance_prompt",
 		Description: "Render the PM acceptance ceremony for one delivered story. Returns title, type, hypothesis, estimate, and the verification bullets pulled from the body's Acceptance section. The agent shows this to the human and waits for an answer; the answer drives set_status or reject_item.",
@@ -301,32 +373,86 @@ func buildServer(rootDir, version string) *mcp.Server {
 		Description: "Return the parsed acceptance bullets for one story. Each bullet has a 1-based index, a state (open, claimed, verified), the bullet text, and an optional claim note left by the dev pair. Use before set_acceptance_state so the index references the body as it stands now.",
 	}, listAcceptanceTool(root))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "set_acceptance_state",
 		Description: "Flip one acceptance bullet's state. The agent marks bullets claimed at delivery time (optionally with a claim note); the PM ceremony marks them verified at acceptance time. Indices are 1-based and only valid against the body as it was when list_acceptance was called.",
-	}, setAcceptanceStateTool(root))
+	}, journaled(root, setAcceptanceStateTool(root)))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "append_acceptance_bullet",
 		Description: "Append a new open acceptance bullet to a story. Creates the Acceptance section if one does not exist. Used by skills that draft criteria (am-decompose, am-plan) into an existing body.",
-	}, appendAcceptanceBulletTool(root))
+	}, journaled(root, appendAcceptanceBulletTool(root)))
 
 	mcp.AddTool(srv, &mcp.Tool{
 		Name:        "iteration_fit",
 		Description: "Report whether the current iteration's planned points fit within the rolling velocity. Optionally adds a candidate item to the planned total to forecast the impact of pulling in another story.",
 	}, iterationFitTool(root))
 
-	mcp.AddTool(srv, &mcp.Tool{
+	addWriteTool(srv, readOnly, &mcp.Tool{
 		Name:        "inception_doc",
 		Description: "Read or write the project's inception.md (one-page narrative covering the user, the goal, the reason, success, constraints, out of scope). Empty body = read; non-empty = write. Returns existed/wrote flags.",
-	}, locked(inceptionDocTool(root)))
+	}, journaled(root, inceptionDocTool(root)))
 
 	mcp.AddTool(srv, &mcp.Tool{
 		Name:        "sprint_plan",
 		Description: "Render the iteration plan: top of priority up to rolling velocity, plus a below-line backlog. Flags stories missing `## Acceptance`, oversized features, unestimated features, and overcommit. The PM uses this at IPM to confirm the rank order before starting the iteration.",
 	}, sprintPlanTool(root))
 
-	return srv
+	addWriteTool(srv, readOnly, &mcp.Tool{
+		Name:        "sprint_commit",
+		Description: "Freeze the committed slice of sprint_plan (top of priority up to rolling velocity) into .am/iterations/<n>.yaml for the current iteration. velocity_history, dashboard and the retro then report planned vs. accepted, carry-over and scope added from this snapshot. Refuses to overwrite an existing snapshot unless force is set.",
+	}, journaled(root, sprintCommitTool(root)))
+
+	mcp.AddTool(srv, &mcp.Tool{
+		Name:        "activity_feed",
+		Description: "Pivotal-style activity feed rebuilt from the git history of item and order files, newest first: items added, status transitions, estimate and owner changes, comments, re-ranks, icebox moves, archiving and deletions. Each entry has when, author, commit, path, title, kind, from/to and a one-line text. Filter by since (date), user and backlog. Use for standups instead of reading raw diffs.",
+	}, activityFeedTool(root))
+
+	mcp.AddTool(srv, &mcp.Tool{
+		Name:        "release_forecast",
+		Description: "Go/no-go for `type: release` markers. Sums the points ranked above each marker in _priority.md, divides the unaccepted remainder by rolling velocity to project the finishing iteration, and widens it by volatility into an optimistic/pessimistic band. Status is on-track, at-risk (only the pessimistic date misses release_date), late, or done. Filter by release slug and backlog.",
+	}, releaseForecastTool(root))
+
+	mcp.AddTool(srv, &mcp.Tool{
+		Name:        "forecast",
+		Description: "Monte Carlo delivery forecast. Set epic (slug) or until (item path; everything ranked at or above it in _priority.md). Resamples per-iteration accepted points from velocity_history, normalized by team strength, and applies upcoming team-strength overrides over thousands of simulated futures. Returns the 50/85/95th percentile completion iteration and date. Seeded, so the same repo state gives the same answer.",
+	}, forecastTool(root))
+
+	mcp.AddTool(srv, &mcp.Tool{
+		Name:        "dependency_graph",
+		Description: "Story dependencies from `blocked_by:` / `blocks:` frontmatter, for the project, one backlog or one epic. Returns nodes (with how many unaccepted blockers each has), blocker -> blocked edges and any cycles. Set format to ascii, dot (Graphviz) or mermaid to also get a rendered graph.",
+	}, dependencyGraphTool(root))
+
+	addWriteTool(srv, readOnly, &mcp.Tool{
+		Name:        "rename_item",
+		Description: "Retitle an item and rename its file to match. Rewrites every reference: the _priority.md or _icebox.md entry (rank kept), the backlog overview, blocked_by/blocks in other stories, and markdown links in bodies and epic pages. The item's id does not change.",
+	}, journaled(root, renameItemTool(root)))
+
+	mcp.AddTool(srv, &mcp.Tool{
+		Name:        "list_filters",
+		Description: "Saved searches from .am/filters.yaml (or the default My Work / Needs estimate / Delivered awaiting me panels). Each has a name, a slug (its page is filters/<slug>.md) and a query in search syntax.",
+	}, listFiltersTool(root))
+
+	mcp.AddTool(srv, &mcp.Tool{
+		Name:        "run_filter",
+		Description: "Run a saved search by name or slug and return hits in the search shape. owner:me and author:me resolve to the current git user.",
+	}, runFilterTool(root))
+
+	addWriteTool(srv, readOnly, &mcp.Tool{
+		Name:        "bulk_update",
+		Description: "Edit every story matching a search query at once: set estimate/status/type/owner/epic, add or remove tags, move to icebox or priority. Each change runs the same coach checks as coach_check; any refusal aborts the batch. Writes all files or none. Use dry_run first and show the human the per-item changes.",
+	}, journaled(root, bulkUpdateTool(root)))
+
+	addWriteTool(srv, readOnly, &mcp.Tool{
+		Name:        "undo_last",
+		Description: "Roll back your own most recent change(s). Every write tool records the files it touched in .am/journal/; this restores their prior contents. Only undoes changes made through MCP tools (a human's CLI or web board change is theirs to undo with `am undo`), and refuses if a file was edited since unless force is set. Not for sync, which git already records.",
+	}, locked(undoLastTool(root)))
+
+	resources := registerResources(srv, root)
+	if err := registerPrompts(srv, root); err != nil {
+		fmt.Fprintf(os.Stderr, "am mcp: prompts: %v\n", err)
+	}
+	return srv, resources
 }
 
 // --- tool input/output types ---
@@ -344,6 +470,7 @@ type ListItemsArgs struct {
 }
 
 type ItemSummary struct {
+	ID         int      `json:"id,omitempty"`
 	Path       string   `json:"path"`
 	Title      string   `json:"title"`
 	Status     string   `json:"status"`
@@ -363,10 +490,11 @@ type ListItemsResult struct {
 }
 
 type GetItemArgs struct {
-	Path string `json:"path" jsonschema:"file path relative to project root"`
+	Path string `json:"path" jsonschema:"file path relative to project root, or #ID"`
 }
 
 type GetItemResult struct {
+	ID             int                   `json:"id,omitempty" jsonschema:"project-wide item number; pass #ID wherever a path is accepted"`
 	Path           string                `json:"path"`
 	Title          string                `json:"title"`
 	Status         string                `json:"status"`
@@ -392,10 +520,11 @@ type GetItemResult struct {
 type CreateItemArgs struct {
 	Backlog string `json:"backlog" jsonschema:"backlog folder name"`
 	Title   string `json:"title" jsonschema:"item title"`
-	User    string `json:"user,omitempty" jsonschema:"author user name or email (optional)"`
+	User    string `json:"user,omitempty" jsonschema:"author user name or email (optional; over HTTP defaults to the X-Am-Author name)"`
 }
 
 type CreateItemResult struct {
+	ID   int    `json:"id,omitempty"`
 	Path string `json:"path"`
 }
 
@@ -483,6 +612,7 @@ func listItems(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallT
 			rel, _ := filepath.Rel(root.Root(), item.Path())
 			assignees := item.Assignees()
 			out = append(out, ItemSummary{
+				ID:         item.ID(),
 				Path:       rel,
 				Title:      item.Title(),
 				Status:     item.Status(),
@@ -502,7 +632,10 @@ func listItems(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallT
 
 func getItem(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, GetItemArgs) (*mcp.CallToolResult, GetItemResult, error) {
 	return func(ctx context.Context, req *mcp.CallToolRequest, args GetItemArgs) (*mcp.CallToolResult, GetItemResult, error) {
-		path := filepath.Join(root.Root(), args.Path)
+		path, err := backlog.ResolveItemPath(root, args.Path)
+		if err != nil {
+			return nil, GetItemResult{}, err
+		}
 		item, err := backlog.LoadBacklogItem(path)
 		if err != nil {
 			return nil, GetItemResult{}, err
@@ -511,6 +644,7 @@ func getItem(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToo
 		if err != nil {
 			return nil, GetItemResult{}, err
 		}
+		rel, _ := filepath.Rel(root.Root(), path)
 		// Iteration is derived server-side from item timestamps when
 		// possible. Unstarted items in priority get a 0 here; the
 		// extension fills that in from priority position + velocity.
@@ -520,7 +654,8 @@ func getItem(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToo
 			iteration, iterationLabel = backlog.ItemIteration(item, cfg, 0, 0)
 		}
 		return nil, GetItemResult{
-			Path:           args.Path,
+			ID:             item.ID(),
+			Path:           rel,
 			Title:          item.Title(),
 			Status:         item.Status(),
 			Type:           item.Type(),
@@ -557,7 +692,11 @@ func createItem(root *backlog.BacklogsStructure) func(context.Context, *mcp.Call
 		if err != nil {
 			return nil, CreateItemResult{}, err
 		}
-		action := actions.NewCreateItemAction(dir, args.Title, args.User, false)
+		user := args.User
+		if user == "" {
+			user, _ = requestAuthor(req)
+		}
+		action := actions.NewCreateItemAction(dir, args.Title, user, false)
 		if err := action.Execute(); err != nil {
 			return nil, CreateItemResult{}, err
 		}
@@ -580,7 +719,11 @@ func createItem(root *backlog.BacklogsStructure) func(context.Context, *mcp.Call
 			}
 		}
 
-		return nil, CreateItemResult{Path: rel}, nil
+		id := 0
+		if item, err := backlog.LoadBacklogItem(filepath.Join(dir, fileName)); err == nil {
+			id = item.ID()
+		}
+		return nil, CreateItemResult{ID: id, Path: rel}, nil
 	}
 }
 
@@ -590,7 +733,10 @@ func setStatus(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallT
 		if st == nil {
 			return nil, OkResult{}, fmt.Errorf("invalid status %q (valid: %s)", args.Status, backlog.AllStatusesList())
 		}
-		path := filepath.Join(root.Root(), args.Path)
+		path, err := backlog.ResolveItemPath(root, args.Path)
+		if err != nil {
+			return nil, OkResult{}, err
+		}
 		item, err := backlog.LoadBacklogItem(path)
 		if err != nil {
 			return nil, OkResult{}, err
@@ -599,13 +745,19 @@ func setStatus(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallT
 		if err := item.Save(); err != nil {
 			return nil, OkResult{}, err
 		}
+		if _, err := backlog.ReleaseDependents(root, item); err != nil {
+			return nil, OkResult{}, err
+		}
 		return nil, OkResult{OK: true}, nil
 	}
 }
 
 func setAssigned(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, SetAssignedArgs) (*mcp.CallToolResult, OkResult, error) {
 	return func(ctx context.Context, req *mcp.CallToolRequest, args SetAssignedArgs) (*mcp.CallToolResult, OkResult, error) {
-		path := filepath.Join(root.Root(), args.Path)
+		path, err := backlog.ResolveItemPath(root, args.Path)
+		if err != nil {
+			return nil, OkResult{}, err
+		}
 		item, err := backlog.LoadBacklogItem(path)
 		if err != nil {
 			return nil, OkResult{}, err
@@ -630,7 +782,10 @@ func setAssigned(root *backlog.BacklogsStructure) func(context.Context, *mcp.Cal
 
 func setEstimate(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, SetEstimateArgs) (*mcp.CallToolResult, OkResult, error) {
 	return func(ctx context.Context, req *mcp.CallToolRequest, args SetEstimateArgs) (*mcp.CallToolResult, OkResult, error) {
-		path := filepath.Join(root.Root(), args.Path)
+		path, err := backlog.ResolveItemPath(root, args.Path)
+		if err != nil {
+			return nil, OkResult{}, err
+		}
 		item, err := backlog.LoadBacklogItem(path)
 		if err != nil {
 			return nil, OkResult{}, err
@@ -663,13 +818,25 @@ func validateAll(root *backlog.BacklogsStructure) func(context.Context, *mcp.Cal
 				}
 			}
 		}
+		epicErrs, err := backlog.ValidateEpicRefs(root)
+		if err != nil {
+			return nil, ValidateResult{}, err
+		}
+		depErrs, err := backlog.ValidateDependencies(root)
+		if err != nil {
+			return nil, ValidateResult{}, err
+		}
+		for _, e := range append(epicErrs, depErrs...) {
+			rel, _ := filepath.Rel(root.Root(), e.Path)
+			msgs = append(msgs, fmt.Sprintf("%s: %s: %s", rel, e.Key, e.Message))
+		}
 		return nil, ValidateResult{Errors: msgs}, nil
 	}
 }
 
 func syncAction(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, SyncArgs) (*mcp.CallToolResult, OkResult, error) {
 	return func(ctx context.Context, req *mcp.CallToolRequest, _ SyncArgs) (*mcp.CallToolResult, OkResult, error) {
-		err := actions.NewSyncAction(root.Root(), "", false).Execute()
+		err := actions.NewSyncAction(root.Root(), requestGitAuthor(req), false, false).Execute()
 		if err != nil {
 			return nil, OkResult{}, err
 		}
@@ -4,13 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/mreider/agilemarkdown/backlog"
+
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -20,21 +25,28 @@ import (
 // Run) trips this test.
 var expectedTools = []string{
 	"acceptance_prompt",
+	"activity_feed",
 	"add_comment",
 	"add_task",
 	"append_acceptance_bullet",
 	"archive_items",
 	"block_item",
+	"bulk_update",
 	"burnup_chart",
 	"change_tag",
+	"close_epic",
 	"coach_check",
+	"coach_override",
 	"create_backlog",
+	"create_epic",
 	"create_item",
 	"cycle_time_chart",
 	"dashboard",
 	"delete_tag",
+	"dependency_graph",
 	"epic_progress",
 	"cumulative_flow",
+	"forecast",
 	"get_comments",
 	"get_item",
 	"icebox_list",
@@ -43,6 +55,8 @@ var expectedTools = []string{
 	"iteration_view",
 	"list_acceptance",
 	"list_backlogs",
+	"list_epics",
+	"list_filters",
 	"list_items",
 	"list_iteration_overrides",
 	"list_tasks",
@@ -54,6 +68,9 @@ var expectedTools = []string{
 	"record_learning",
 	"reject_item",
 	"rejection_rate",
+	"release_forecast",
+	"rename_item",
+	"run_filter",
 	"search",
 	"set_acceptance_state",
 	"set_assigned",
@@ -65,12 +82,14 @@ var expectedTools = []string{
 	"set_status",
 	"set_tags",
 	"set_task_done",
+	"sprint_commit",
 	"sprint_plan",
 	"sync",
 	"team_agreements",
 	"timeline_chart",
 	"type_mix",
 	"unblock_item",
+	"undo_last",
 	"validate",
 	"velocity_chart",
 	"velocity_history",
@@ -340,10 +359,601 @@ func TestCreateAndAcceptanceLifecycle(t *testing.T) {
 	}
 }
 
+// TestSprintCommitSnapshot freezes the committed slice, checks that a
+// second commit is refused without force, and that the dashboard reads
+// the snapshot back as the current iteration's plan.
+func TestSprintCommitSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	mustInitRepo(t, dir)
+	mustWriteItem(t, dir, "alpha", map[string]string{"status": "unstarted", "type": "feature", "estimate": "3"})
+	mustWriteItem(t, dir, "beta", map[string]string{"status": "unstarted", "type": "feature", "estimate": "2"})
+	if err := os.WriteFile(filepath.Join(dir, "product", "_priority.md"), []byte("- [alpha](alpha.md)\n- [beta](beta.md)\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	res, err := SprintCommit(ctx, dir, SprintCommitArgs{Backlog: "product"})
+	if err != nil {
+		t.Fatalf("sprint_commit: %v", err)
+	}
+	if res.Points != 5 || len(res.Items) != 2 {
+		t.Fatalf("committed %v pts / %d items, want 5 / 2", res.Points, len(res.Items))
+	}
+	if _, err := os.Stat(filepath.Join(dir, res.Path)); err != nil {
+		t.Fatalf("snapshot file missing: %v", err)
+	}
+	if _, err := SprintCommit(ctx, dir, SprintCommitArgs{Backlog: "product"}); err == nil {
+		t.Errorf("second commit without force should be refused")
+	}
+	if res, err := SprintCommit(ctx, dir, SprintCommitArgs{Backlog: "product", Force: true}); err != nil || !res.Replaced {
+		t.Errorf("forced commit: replaced=%v err=%v", res.Replaced, err)
+	}
+
+	dash, err := Dashboard(ctx, dir, DashboardArgs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dash.IterationSnapshot || dash.IterationPlanned != 5 || dash.IterationCarryOver != 5 {
+		t.Errorf("dashboard did not read the snapshot: %+v", dash)
+	}
+}
+
+// TestBulkUpdate checks that a bulk edit reports without writing on a
+// dry run, writes nothing when any item is refused, and otherwise
+// updates every item and the order files together.
+func TestBulkUpdate(t *testing.T) {
+	dir := t.TempDir()
+	mustInitRepo(t, dir)
+	mustWriteItem(t, dir, "alpha", map[string]string{"status": "unstarted", "type": "feature", "estimate": "3"})
+	mustWriteItem(t, dir, "beta", map[string]string{"status": "unstarted", "type": "feature"})
+	mustWriteItem(t, dir, "gamma", map[string]string{"status": "unstarted", "type": "chore"})
+	if err := os.WriteFile(filepath.Join(dir, "product", "_priority.md"), []byte("- [alpha](alpha.md)\n- [beta](beta.md)\n- [gamma](gamma.md)\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	read := func(name string) string {
+		data, _ := os.ReadFile(filepath.Join(dir, "product", name))
+		return string(data)
+	}
+	snapshot := func() string { return read("alpha.md") + read("beta.md") + read("_priority.md") }
+	before := snapshot()
+
+	ctx := context.Background()
+	args := BulkUpdateArgs{Where: "type:feature", Set: map[string]string{"estimate": "2"}, AddTags: []string{"auth"}, Move: "icebox", DryRun: true}
+	res, err := BulkUpdate(ctx, dir, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Matched != 2 || res.Changed != 2 || res.Applied {
+		t.Fatalf("dry run = %+v", res)
+	}
+	if snapshot() != before {
+		t.Errorf("dry run wrote files")
+	}
+
+	res, err = BulkUpdate(ctx, dir, BulkUpdateArgs{Where: "type:feature", Set: map[string]string{"estimate": "13"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Refused != 2 || res.Applied {
+		t.Fatalf("13-point features should be refused: %+v", res)
+	}
+	if snapshot() != before {
+		t.Errorf("refused batch wrote files")
+	}
+
+	args.DryRun = false
+	if res, err = BulkUpdate(ctx, dir, args); err != nil || !res.Applied {
+		t.Fatalf("apply = %+v, %v", res, err)
+	}
+	for _, name := range []string{"alpha", "beta"} {
+		item, err := backlog.LoadBacklogItem(filepath.Join(dir, "product", name+".md"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if item.Estimate() != "2" || strings.Join(item.Tags(), ",") != "auth" {
+			t.Errorf("%s: estimate %q tags %v", name, item.Estimate(), item.Tags())
+		}
+	}
+	if got := read("_priority.md"); strings.Contains(got, "alpha") || !strings.Contains(got, "- [gamma](gamma.md)") {
+		t.Errorf("_priority.md = %q", got)
+	}
+	if got := read("_icebox.md"); !strings.Contains(got, "- [alpha](alpha.md)\n- [beta](beta.md)\n") {
+		t.Errorf("_icebox.md = %q", got)
+	}
+	// Accepting a blocker in bulk releases what it blocked.
+	mustWriteItem(t, dir, "delta", map[string]string{"status": "unstarted", "type": "chore", "blocked_by": "[gamma.md]"})
+	if err := os.WriteFile(filepath.Join(dir, ".am", "coach.yaml"), []byte("rules:\n  coach-refuses-pm-accepts:\n    enabled: false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if res, err = BulkUpdate(ctx, dir, BulkUpdateArgs{Where: "gamma", Set: map[string]string{"status": "accepted"}}); err != nil || !res.Applied || res.Changed != 1 {
+		t.Fatalf("bulk accept = %+v, %v", res, err)
+	}
+	delta, err := backlog.LoadBacklogItem(filepath.Join(dir, "product", "delta.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := delta.BlockedBy(); len(got) != 0 {
+		t.Errorf("delta still blocked by %v", got)
+	}
+}
+
+// TestCoachRules checks the canon defaults, then `.am/coach.yaml`
+// disabling, downgrading and parameterizing built-in rules and adding a
+// custom one.
+func TestCoachRules(t *testing.T) {
+	dir := t.TempDir()
+	mustInitRepo(t, dir)
+	mustWriteItem(t, dir, "alpha", map[string]string{"status": "unstarted", "type": "feature"})
+	mustWriteItem(t, dir, "fix", map[string]string{"status": "unstarted", "type": "bug"})
+	ctx := context.Background()
+	check := func(args CoachCheckArgs) CoachVerdict {
+		t.Helper()
+		v, err := CoachCheck(ctx, dir, args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	estimate := func(path, pts string) CoachVerdict {
+		return check(CoachCheckArgs{Action: "set_estimate", Path: path, Estimate: pts})
+	}
+	start := CoachCheckArgs{Action: "set_status", Path: "product/alpha.md", Status: "started"}
+
+	if v := estimate("product/alpha.md", "13"); v.Allowed || v.Source != "8-point-hard-cap" || !strings.Contains(v.Rule, "8 points") {
+		t.Fatalf("13 points = %+v", v)
+	}
+	if v := estimate("product/fix.md", "2"); v.Allowed || v.Source != "bugs-are-tax" {
+		t.Fatalf("pointed bug = %+v", v)
+	}
+	if v := check(start); !v.Allowed || !v.Nudge || v.Source != "acceptance-criteria" {
+		t.Fatalf("start without criteria = %+v", v)
+	}
+	if v := check(CoachCheckArgs{Action: "create_item", Type: "feature", Estimate: "5"}); !v.Allowed {
+		t.Fatalf("5-point create = %+v", v)
+	}
+
+	coach := `rules:
+  8-point-hard-cap:
+    max: 3
+  bugs-are-tax:
+    enabled: false
+  acceptance-criteria:
+    level: refuse
+  coach-refuses-pm-accepts:
+    level: nudge
+custom:
+  - slug: no-big-unowned
+    actions: [set_estimate, create_item]
+    when: estimate:>2 owner:none
+    rule: big stories need an owner to split them
+    next: assign someone first
+    level: nudge
+`
+	if err := os.WriteFile(filepath.Join(dir, ".am", "coach.yaml"), []byte(coach), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if v := estimate("product/alpha.md", "5"); v.Allowed || !strings.Contains(v.Rule, "over 3 points") {
+		t.Fatalf("cap of 3 = %+v", v)
+	}
+	if v := estimate("product/fix.md", "2"); !v.Allowed || v.Nudge {
+		t.Fatalf("disabled bugs-are-tax = %+v", v)
+	}
+	if v := check(start); v.Allowed || v.Source != "acceptance-criteria" {
+		t.Fatalf("acceptance-criteria as refusal = %+v", v)
+	}
+	if v := check(CoachCheckArgs{Action: "set_status", Path: "product/alpha.md", Status: "accepted"}); !v.Allowed || !v.Nudge {
+		t.Fatalf("accept downgraded to nudge = %+v", v)
+	}
+	if v := estimate("product/alpha.md", "3"); !v.Allowed || !v.Nudge || v.Source != "no-big-unowned" || v.Next != "assign someone first" {
+		t.Fatalf("custom rule = %+v", v)
+	}
+	if v := estimate("product/alpha.md", "2"); !v.Allowed || v.Nudge {
+		t.Fatalf("custom rule should not match 2 points = %+v", v)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ".am", "coach.yaml"), []byte("rules:\n  8-pt-cap:\n    max: 5\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CoachCheck(ctx, dir, start); err == nil || !strings.Contains(err.Error(), "8-pt-cap") {
+		t.Fatalf("unknown rule slug: %v", err)
+	}
+}
+
+// TestCoachOverride checks that an override needs a reason, is logged
+// with its rule and item, and lets only that rule through afterwards.
+func TestCoachOverride(t *testing.T) {
+	dir := t.TempDir()
+	mustInitRepo(t, dir)
+	mustWriteItem(t, dir, "alpha", map[string]string{"status": "delivered", "type": "feature"})
+	ctx := context.Background()
+	accept := CoachCheckArgs{Action: "set_status", Path: "product/alpha.md", Status: "accepted"}
+	overrideArgs := CoachOverrideArgs{Action: accept.Action, Path: accept.Path, Status: accept.Status}
+
+	if _, err := CoachOverride(ctx, dir, overrideArgs); err == nil {
+		t.Fatal("override without a reason should fail")
+	}
+	overrideArgs.Reason = "PM reviewed it on the call"
+	res, err := CoachOverride(ctx, dir, overrideArgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o := res.Override; o.Rule != "coach-refuses-pm-accepts" || o.Item != "product/alpha.md" || o.Source != "cli" || o.Time == "" {
+		t.Fatalf("override = %+v", o)
+	}
+	v, err := CoachCheck(ctx, dir, accept)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.Allowed || v.Nudge || !strings.Contains(v.Detail, "PM reviewed it on the call") {
+		t.Fatalf("overridden check = %+v", v)
+	}
+	if v, _ := CoachCheck(ctx, dir, CoachCheckArgs{Action: "set_estimate", Path: "product/alpha.md", Estimate: "13"}); v.Allowed {
+		t.Fatalf("override leaked to another rule: %+v", v)
+	}
+	if _, err := CoachOverride(ctx, dir, CoachOverrideArgs{Action: "set_estimate", Path: "product/alpha.md", Estimate: "3", Reason: "x"}); err == nil {
+		t.Fatal("overriding an allowed action should fail")
+	}
+	logged, err := backlog.LoadCoachOverrides(backlog.NewBacklogsStructure(dir))
+	if err != nil || len(logged) != 1 {
+		t.Fatalf("coach log = %+v, %v", logged, err)
+	}
+}
+
+// TestGateToolUse checks the PreToolUse gate's answers for the tools it
+// understands.
+func TestGateToolUse(t *testing.T) {
+	dir := t.TempDir()
+	mustInitRepo(t, dir)
+	mustWriteItem(t, dir, "alpha", map[string]string{"status": "delivered", "type": "feature"})
+	mustWriteItem(t, dir, "beta", map[string]string{"status": "unstarted", "type": "feature"})
+	ctx := context.Background()
+	cases := []struct {
+		tool, input, decision, rule string
+	}{
+		{"Bash", `{"command":"ls"}`, GateAllow, ""},
+		{"set_status", `{"path":"product/alpha.md","status":"accepted"}`, GateDeny, "coach-refuses-pm-accepts"},
+		{"set_status", `{"path":"product/beta.md","status":"started"}`, GateDeny, "acceptance-before-pull"},
+		{"set_estimate", `{"path":"product/beta.md","estimate":"13"}`, GateDeny, "8-point-hard-cap"},
+		{"set_estimate", `{"path":"product/beta.md","estimate":"3"}`, GateAllow, ""},
+		{"reject_item", `{"path":"product/beta.md"}`, GateDeny, "coach-refuses-pm-accepts"},
+		{"reject_item", `{"path":"product/alpha.md","reason":"no"}`, GateAsk, "coach-refuses-pm-accepts"},
+		{"set_acceptance_state", `{"path":"product/alpha.md","index":1,"state":"verified"}`, GateAsk, "coach-refuses-pm-accepts"},
+		{"set_acceptance_state", `{"path":"product/alpha.md","index":1,"state":"claimed"}`, GateAllow, ""},
+		{"bulk_update", `{"where":"type:feature","set":{"status":"accepted"}}`, GateDeny, "coach-refuses-pm-accepts"},
+		{"get_item", `{"path":"product/alpha.md"}`, GateAllow, ""},
+		{"coach_override", `{"action":"set_status","path":"product/alpha.md","status":"accepted","reason":"PM said so"}`, GateAsk, ""},
+		{"undo_last", `{"count":2}`, GateAsk, ""},
+	}
+	for _, c := range cases {
+		tool := c.tool
+		if tool != "Bash" {
+			tool = GateToolPrefix + tool
+		}
+		d, err := GateToolUse(ctx, dir, tool, json.RawMessage(c.input))
+		if err != nil {
+			t.Fatalf("%s %s: %v", c.tool, c.input, err)
+		}
+		if d.Decision != c.decision || d.Rule != c.rule {
+			t.Errorf("%s %s = %+v, want %s [%s]", c.tool, c.input, d, c.decision, c.rule)
+		}
+		if d.Decision != GateAllow && d.Reason == "" {
+			t.Errorf("%s %s: no reason", c.tool, c.input)
+		}
+	}
+	if _, err := CoachOverride(ctx, dir, CoachOverrideArgs{Action: "set_status", Path: "product/alpha.md", Status: "accepted", Reason: "PM accepted on the call"}); err != nil {
+		t.Fatal(err)
+	}
+	if d, _ := GateToolUse(ctx, dir, GateToolPrefix+"set_status", json.RawMessage(`{"path":"product/alpha.md","status":"accepted"}`)); d.Decision != GateAllow {
+		t.Errorf("overridden set_status = %+v", d)
+	}
+}
+
+// TestUndoLast checks that a write tool is journaled and undo_last puts
+// the file back, and that undo_last leaves a CLI change alone.
+func TestUndoLast(t *testing.T) {
+	dir := t.TempDir()
+	mustInitRepo(t, dir)
+	mustWriteItem(t, dir, "alpha", map[string]string{"status": "unstarted", "type": "feature", "estimate": "3"})
+	path := filepath.Join(dir, "product", "alpha.md")
+	before, _ := os.ReadFile(path)
+
+	res, err := callToolViaMemoryTransport(t, dir, "set_estimate", map[string]any{"path": "product/alpha.md", "estimate": "5"})
+	if err != nil || res.IsError {
+		t.Fatalf("set_estimate: %v %s", err, flattenContent(res))
+	}
+	if after, _ := os.ReadFile(path); string(after) == string(before) {
+		t.Fatalf("set_estimate did not write")
+	}
+	res, err = callToolViaMemoryTransport(t, dir, "undo_last", map[string]any{})
+	if err != nil || res.IsError {
+		t.Fatalf("undo_last: %v %s", err, flattenContent(res))
+	}
+	if after, _ := os.ReadFile(path); string(after) != string(before) {
+		t.Errorf("undo_last did not restore alpha.md:\n%s", after)
+	}
+
+	root := backlog.NewBacklogsStructure(dir)
+	snap, err := backlog.TakeSnapshot(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("---\ntitle: alpha\nestimate: 8\n---\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := backlog.RecordJournal(root, snap, backlog.JournalEntry{Source: "cli", Command: "am estimate alpha 8"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UndoLast(context.Background(), dir, UndoLastArgs{}); err == nil || !strings.Contains(err.Error(), "am undo") {
+		t.Errorf("undo_last should refuse a CLI change, got %v", err)
+	}
+}
+
+// TestUndoLastOwnChanges checks that on a shared server undo_last skips
+// another agent's newer change and rolls back the caller's own.
+func TestUndoLastOwnChanges(t *testing.T) {
+	dir := t.TempDir()
+	mustInitRepo(t, dir)
+	mustWriteItem(t, dir, "alpha", map[string]string{"status": "unstarted", "type": "feature", "estimate": "3"})
+	mustWriteItem(t, dir, "beta", map[string]string{"status": "unstarted", "type": "feature", "estimate": "3"})
+	root := backlog.NewBacklogsStructure(dir)
+	read := func(name string) string {
+		data, _ := os.ReadFile(filepath.Join(dir, "product", name+".md"))
+		return string(data)
+	}
+	alphaBefore := read("alpha")
+	change := func(name, actor string) {
+		t.Helper()
+		snap, err := backlog.TakeSnapshot(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		text := strings.Replace(read(name), "estimate: 3", "estimate: 5", 1)
+		if err := os.WriteFile(filepath.Join(dir, "product", name+".md"), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := backlog.RecordJournal(root, snap, backlog.JournalEntry{Source: "mcp", Command: "set_estimate", Actor: actor}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	change("alpha", "")
+	change("beta", "Bob <bob@example.com>")
+	betaAfter := read("beta")
+
+	res, err := UndoLast(context.Background(), dir, UndoLastArgs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Undone) != 1 || res.Undone[0].Seq != 1 {
+		t.Fatalf("undone = %+v", res.Undone)
+	}
+	if read("alpha") != alphaBefore || read("beta") != betaAfter {
+		t.Errorf("undo_last touched the other agent's change:\nalpha:\n%s\nbeta:\n%s", read("alpha"), read("beta"))
+	}
+	if _, err := UndoLast(context.Background(), dir, UndoLastArgs{}); err == nil {
+		t.Error("undo_last undid another agent's change")
+	}
+}
+
+func TestResourceURI(t *testing.T) {
+	for rel, want := range map[string]string{
+		"product/login.md":       "am://backlog/product/login",
+		"product/_priority.md":   "am://backlog/product/_priority",
+		"product/archive/old.md": "am://backlog/product/archive/old",
+		"team-agreements.md":     "am://project/team-agreements",
+		"epics/login.md":         "am://project/epics/login",
+		".am/filters.yaml":       "am://project/.am/filters.yaml",
+	} {
+		if got := resourceURI(rel); got != want {
+			t.Errorf("resourceURI(%s) = %s, want %s", rel, got, want)
+		}
+	}
+}
+
+// TestResourcesAndPrompts lists and reads the backlog as resources and
+// renders a coach skill as a prompt.
+func TestResourcesAndPrompts(t *testing.T) {
+	dir := t.TempDir()
+	mustInitRepo(t, dir)
+	mustWriteItem(t, dir, "alpha", map[string]string{"status": "delivered", "type": "feature"})
+	if err := os.WriteFile(filepath.Join(dir, "product", "_priority.md"), []byte("- [alpha](alpha.md)\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "team-agreements.md"), []byte("# Team agreements\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cs := connectServer(t, dir)
+	defer cs.Close()
+	ctx := context.Background()
+
+	list, err := cs.ListResources(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var uris []string
+	for _, r := range list.Resources {
+		uris = append(uris, r.URI)
+	}
+	sort.Strings(uris)
+	if got := strings.Join(uris, " "); got != "am://backlog/product/_priority am://backlog/product/alpha am://project/team-agreements" {
+		t.Errorf("resources = %s", got)
+	}
+	read, err := cs.ReadResource(ctx, &mcp.ReadResourceParams{URI: "am://backlog/product/alpha"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read.Contents) != 1 || !strings.Contains(read.Contents[0].Text, "status: delivered") {
+		t.Errorf("read alpha = %+v", read.Contents)
+	}
+	for _, uri := range []string{"am://backlog/product/..%2F..%2Fsecret", "am://project/.am%2Fconfig.yaml", "am://backlog/product/missing"} {
+		if _, err := cs.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri}); err == nil {
+			t.Errorf("read %s should fail", uri)
+		}
+	}
+
+	prompts, err := cs.ListPrompts(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, p := range prompts.Prompts {
+		names = append(names, p.Name)
+	}
+	if got := strings.Join(names, " "); got != "accept align decompose inception plan retro" {
+		t.Errorf("prompts = %s", got)
+	}
+	prompt, err := cs.GetPrompt(ctx, &mcp.GetPromptParams{Name: "accept", Arguments: map[string]string{"item": "product/alpha.md"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := prompt.Messages[0].Content.(*mcp.TextContent).Text
+	if !strings.Contains(text, "# am-accept") || !strings.Contains(text, "- item: product/alpha.md") {
+		t.Errorf("accept prompt = %s", text)
+	}
+}
+
+// TestTeamCeremonyPrompt serves a skill from .am/skills as a prompt and
+// renders its template against the backlog.
+func TestTeamCeremonyPrompt(t *testing.T) {
+	dir := t.TempDir()
+	mustInitRepo(t, dir)
+	mustWriteItem(t, dir, "crash", map[string]string{"status": "unstarted", "type": "bug"})
+	mustWriteItem(t, dir, "polish", map[string]string{"status": "unstarted", "type": "feature"})
+	skill := "---\ndescription: Weekly bug triage.\narguments:\n  backlog: backlog to triage\n---\n\n" +
+		"# Triage {{.backlog}}\n\n{{count \"type:bug\"}} open:\n\n{{search \"type:bug\"}}\n"
+	if err := os.MkdirAll(filepath.Join(dir, ".am", "skills", "bug-triage"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".am", "skills", "bug-triage", "SKILL.md"), []byte(skill), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cs := connectServer(t, dir)
+	defer cs.Close()
+	ctx := context.Background()
+
+	prompts, err := cs.ListPrompts(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var team *mcp.Prompt
+	for _, p := range prompts.Prompts {
+		if p.Name == "bug-triage" {
+			team = p
+		}
+	}
+	if team == nil || len(team.Arguments) != 1 || team.Arguments[0].Name != "backlog" {
+		t.Fatalf("bug-triage prompt = %+v", team)
+	}
+	prompt, err := cs.GetPrompt(ctx, &mcp.GetPromptParams{Name: "bug-triage", Arguments: map[string]string{"backlog": "product"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := prompt.Messages[0].Content.(*mcp.TextContent).Text
+	for _, want := range []string{"# Triage product", "1 open:", "- crash (product/crash.md): bug, unstarted", "- backlog: product"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("bug-triage prompt lacks %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "polish") {
+		t.Errorf("bug-triage prompt lists a feature:\n%s", text)
+	}
+}
+
 // extractItemPath pulls the path from a create_item result. The
 // MCP SDK serializes the typed return value into StructuredContent
 // as a map, so we read the "path" key directly without binding to
 // the CreateItemResult struct.
+func TestHTTPTeamMode(t *testing.T) {
+	dir := t.TempDir()
+	mustInitRepo(t, dir)
+	abs, _ := filepath.Abs(dir)
+	ctx := context.Background()
+
+	roSrv, _ := newServer(abs, "test", true)
+	ro := httptest.NewServer(httpHandler(roSrv, "s3cret"))
+	defer ro.Close()
+	resp, err := http.Post(ro.URL, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("no token: status %d, want 401", resp.StatusCode)
+	}
+
+	cs := connectHTTP(t, ro.URL, http.Header{"Authorization": {"Bearer s3cret"}})
+	var names []string
+	for tool, err := range cs.Tools(ctx, nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, tool.Name)
+	}
+	cs.Close()
+	if !slices.Contains(names, "list_items") || slices.Contains(names, "set_status") || slices.Contains(names, "create_item") {
+		t.Fatalf("read-only tools = %v", names)
+	}
+
+	rwSrv, _ := newServer(abs, "test", false)
+	rw := httptest.NewServer(httpHandler(rwSrv, ""))
+	defer rw.Close()
+	resp, err = http.DefaultClient.Do(mustRequest(t, rw.URL, http.Header{AuthorHeader: {"not an address"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("bad author: status %d, want 400", resp.StatusCode)
+	}
+
+	cs = connectHTTP(t, rw.URL, http.Header{AuthorHeader: {"Alice Doe <alice@example.com>"}})
+	defer cs.Close()
+	raw, _ := json.Marshal(map[string]any{"backlog": "product", "title": "Shared box"})
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "create_item", Arguments: json.RawMessage(raw)})
+	if err != nil || res.IsError {
+		t.Fatalf("create_item: %v %s", err, flattenContent(res))
+	}
+	item, err := backlog.LoadBacklogItem(filepath.Join(dir, "product", "Shared-box.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Author() != "Alice Doe" {
+		t.Fatalf("author = %q, want the X-Am-Author name", item.Author())
+	}
+}
+
+// connectHTTP connects a client to an `am mcp --http` endpoint, sending
+// header with every request.
+func connectHTTP(t *testing.T, url string, header http.Header) *mcp.ClientSession {
+	t.Helper()
+	client := &http.Client{Transport: headerTransport{header}}
+	c := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "0"}, nil)
+	cs, err := c.Connect(context.Background(), &mcp.StreamableClientTransport{Endpoint: url, HTTPClient: client, DisableStandaloneSSE: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cs
+}
+
+type headerTransport struct{ header http.Header }
+
+func (h headerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	for k, v := range h.header {
+		r.Header[k] = v
+	}
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func mustRequest(t *testing.T, url string, header http.Header) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header = header
+	return req
+}
+
 func extractItemPath(t *testing.T, res *mcp.CallToolResult) string {
 	t.Helper()
 	if res == nil {
@@ -475,3 +1085,17 @@ func mustWriteItem(t *testing.T, dir, basename string, frontmatter map[string]st
 		t.Fatal(err)
 	}
 }
+
+func TestHTTPRefusesOpenAddrWithoutToken(t *testing.T) {
+	dir := t.TempDir()
+	mustInitRepo(t, dir)
+	err := RunHTTP(context.Background(), dir, "test", HTTPOptions{Addr: ":0"})
+	if err == nil || !strings.Contains(err.Error(), "--insecure-no-auth") {
+		t.Fatalf("tokenless :0 = %v, want a refusal", err)
+	}
+	for addr, want := range map[string]bool{"127.0.0.1:7331": true, "localhost:7331": true, "[::1]:7331": true, ":7331": false, "0.0.0.0:7331": false, "10.0.0.5:7331": false} {
+		if got := isLoopbackAddr(addr); got != want {
+			t.Errorf("isLoopbackAddr(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
@@ -3,7 +3,6 @@ package mcpserver
 import (
 	"context"
 	"fmt"
-	"path/filepath"
 
 	"github.com/mreider/agilemarkdown/backlog"
 	"github.com/mreider/agilemarkdown/utils"
@@ -28,7 +27,10 @@ type ListTasksResult struct {
 
 func listTasksTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, ListTasksArgs) (*mcp.CallToolResult, ListTasksResult, error) {
 	return func(ctx context.Context, req *mcp.CallToolRequest, args ListTasksArgs) (*mcp.CallToolResult, ListTasksResult, error) {
-		path := filepath.Join(root.Root(), args.Path)
+		path, err := backlog.ResolveItemPath(root, args.Path)
+		if err != nil {
+			return nil, ListTasksResult{}, err
+		}
 		item, err := backlog.LoadBacklogItem(path)
 		if err != nil {
 			return nil, ListTasksResult{}, err
@@ -52,7 +54,10 @@ func addTaskTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.Cal
 		if args.Text == "" {
 			return nil, OkResult{}, fmt.Errorf("text is required")
 		}
-		path := filepath.Join(root.Root(), args.Path)
+		path, err := backlog.ResolveItemPath(root, args.Path)
+		if err != nil {
+			return nil, OkResult{}, err
+		}
 		item, err := backlog.LoadBacklogItem(path)
 		if err != nil {
 			return nil, OkResult{}, err
@@ -74,7 +79,10 @@ type SetTaskDoneArgs struct {
 
 func setTaskDoneTool(root *backlog.BacklogsStructure) func(context.Context, *mcp.CallToolRequest, SetTaskDoneArgs) (*mcp.CallToolResult, OkResult, error) {
 	return func(ctx context.Context, req *mcp.CallToolRequest, args SetTaskDoneArgs) (*mcp.CallToolResult, OkResult, error) {
-		path := filepath.Join(root.Root(), args.Path)
+		path, err := backlog.ResolveItemPath(root, args.Path)
+		if err != nil {
+			return nil, OkResult{}, err
+		}
 		item, err := backlog.LoadBacklogItem(path)
 		if err != nil {
 			return nil, OkResult{}, err
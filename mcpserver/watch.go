@@ -0,0 +1,66 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/mreider/agilemarkdown/backlog"
+)
+
+// resourceURI names a project file the way clients subscribe to it:
+// am://backlog/<backlog>/<item> for anything inside a backlog folder
+// (items, _priority, _icebox, archive/<item>), am://project/<path> for
+// everything else. The .md extension is dropped and each path segment
+// is escaped.
+func resourceURI(rel string) string {
+	rel = strings.TrimSuffix(rel, ".md")
+	segments := strings.Split(rel, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	if len(segments) > 1 && isBacklogName(segments[0]) {
+		return "am://backlog/" + strings.Join(segments, "/")
+	}
+	return "am://project/" + strings.Join(segments, "/")
+}
+
+func isBacklogName(name string) bool {
+	return name != "" && !strings.HasPrefix(name, ".") && !backlog.IsForbiddenBacklogName(name)
+}
+
+func subscribeResource(ctx context.Context, req *mcp.SubscribeRequest) error {
+	if !strings.HasPrefix(req.Params.URI, "am://") {
+		return fmt.Errorf("unknown resource %q; agilemarkdown resources start with am://", req.Params.URI)
+	}
+	return nil
+}
+
+func unsubscribeResource(ctx context.Context, req *mcp.UnsubscribeRequest) error {
+	return nil
+}
+
+// watchProject watches the project until ctx is done. Every changed
+// file is evicted from the item cache, its resource listing is refreshed
+// and subscribed clients get notifications/resources/updated. Without a
+// watcher the server still works; clients just get no notifications.
+func watchProject(ctx context.Context, srv *mcp.Server, resources *projectResources) {
+	w, err := backlog.NewWatcher(resources.root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "am mcp: not watching for changes: %v\n", err)
+		return
+	}
+	go w.Run(ctx.Done(), func(changed []string) {
+		resources.refresh(changed)
+		for _, rel := range changed {
+			uri := resourceURI(path.Clean(rel))
+			if err := srv.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: uri}); err != nil {
+				fmt.Fprintf(os.Stderr, "am mcp: notify %s: %v\n", uri, err)
+			}
+		}
+	})
+}
@@ -70,7 +70,6 @@ fi
 # the stale projection.
 LOCAL_PAIRS=(
   ".claude/agilemarkdown-coach.md::coach/agilemarkdown-coach.md"
-  ".claude/hooks/coach-gate.sh::coach/hooks/coach-gate.sh"
 )
 
 for pair in "${LOCAL_PAIRS[@]}"; do
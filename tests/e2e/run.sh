@@ -124,48 +124,34 @@ test_create_backlog() {
   assert_file .claude/skills/am-decompose/SKILL.md
   assert_file .claude/skills/am-retro/SKILL.md
   assert_grep .claude/skills/am-accept/SKILL.md "PM acceptance ceremony"
-  # Coach gate hook + settings.
-  assert_file .claude/hooks/coach-gate.sh
+  # Coach gate: settings wire `am hook pre-tool-use` to every tool.
   assert_file .claude/settings.json
   assert_grep .claude/settings.json "PreToolUse"
-  assert_grep .claude/settings.json "mcp__agilemarkdown__set_status"
-  if [ ! -x .claude/hooks/coach-gate.sh ]; then
-    echo "coach-gate.sh is not executable" >&2
-    return 1
-  fi
+  assert_grep .claude/settings.json "mcp__agilemarkdown__"
+  assert_grep .claude/settings.json "am hook pre-tool-use"
 }
 
 test_coach_hook_refuses_self_accept() {
   cd "${REPO_DIR}"
-  set +e
-  AM_BIN="${AM_BIN}" bash .claude/hooks/coach-gate.sh <<JSON 2>/tmp/am-hook.err
-{"tool_name":"mcp__agilemarkdown__set_status","tool_input":{"path":"product/Search-relevance.md","status":"accepted"}}
+  "${AM_BIN}" hook pre-tool-use >/tmp/am-hook.out <<JSON
+{"hook_event_name":"PreToolUse","tool_name":"mcp__agilemarkdown__set_status","tool_input":{"path":"product/Search-relevance.md","status":"accepted"}}
 JSON
-  rc=$?
-  set -e
-  if [ "${rc}" -ne 2 ]; then
-    echo "expected hook exit 2, got ${rc}" >&2
-    cat /tmp/am-hook.err >&2 || true
-    return 1
-  fi
-  grep -q "coach-refuses-pm-accepts" /tmp/am-hook.err
-  rm -f /tmp/am-hook.err
+  assert_grep /tmp/am-hook.out '"permissionDecision":"deny"' || return 1
+  assert_grep /tmp/am-hook.out "coach-refuses-pm-accepts" || return 1
+  rm -f /tmp/am-hook.out
 }
 
 test_coach_hook_allows_legitimate_transitions() {
   cd "${REPO_DIR}"
-  set +e
-  AM_BIN="${AM_BIN}" bash .claude/hooks/coach-gate.sh <<JSON 2>/tmp/am-hook.err
-{"tool_name":"mcp__agilemarkdown__set_status","tool_input":{"path":"product/Search-relevance.md","status":"started"}}
+  "${AM_BIN}" hook pre-tool-use >/tmp/am-hook.out <<JSON
+{"hook_event_name":"PreToolUse","tool_name":"mcp__agilemarkdown__set_status","tool_input":{"path":"product/Search-relevance.md","status":"started"}}
 JSON
-  rc=$?
-  set -e
-  if [ "${rc}" -ne 0 ]; then
-    echo "hook should allow started transition; got rc=${rc}" >&2
-    cat /tmp/am-hook.err >&2 || true
+  if [ -s /tmp/am-hook.out ]; then
+    echo "hook should allow started transition silently; got:" >&2
+    cat /tmp/am-hook.out >&2
     return 1
   fi
-  rm -f /tmp/am-hook.err
+  rm -f /tmp/am-hook.out
 }
 
 test_coach_hook_refuses_pull_no_acceptance() {
@@ -173,19 +159,21 @@ test_coach_hook_refuses_pull_no_acceptance() {
   # it is a feature with no `## Acceptance` section. The hook should
   # refuse set_status started on it.
   cd "${REPO_DIR}"
-  set +e
-  AM_BIN="${AM_BIN}" bash .claude/hooks/coach-gate.sh <<JSON 2>/tmp/am-hook.err
-{"tool_name":"mcp__agilemarkdown__set_status","tool_input":{"path":"product/Pull-without-acceptance.md","status":"started"}}
+  "${AM_BIN}" hook pre-tool-use >/tmp/am-hook.out <<JSON
+{"hook_event_name":"PreToolUse","tool_name":"mcp__agilemarkdown__set_status","tool_input":{"path":"product/Pull-without-acceptance.md","status":"started"}}
 JSON
-  rc=$?
-  set -e
-  if [ "${rc}" -ne 2 ]; then
-    echo "expected hook exit 2 for feature without acceptance; got ${rc}" >&2
-    cat /tmp/am-hook.err >&2 || true
-    return 1
-  fi
-  grep -q "acceptance-before-pull" /tmp/am-hook.err
-  rm -f /tmp/am-hook.err
+  assert_grep /tmp/am-hook.out '"permissionDecision":"deny"' || return 1
+  assert_grep /tmp/am-hook.out "acceptance-before-pull" || return 1
+  rm -f /tmp/am-hook.out
+}
+
+test_coach_hook_asks_before_verify() {
+  cd "${REPO_DIR}"
+  "${AM_BIN}" hook pre-tool-use >/tmp/am-hook.out <<JSON
+{"hook_event_name":"PreToolUse","tool_name":"mcp__agilemarkdown__set_acceptance_state","tool_input":{"path":"product/Search-relevance.md","index":1,"state":"verified"}}
+JSON
+  assert_grep /tmp/am-hook.out '"permissionDecision":"ask"' || return 1
+  rm -f /tmp/am-hook.out
 }
 
 test_team_agreements_add() {
@@ -290,6 +278,24 @@ test_retro_cli() {
   echo "${out}" | grep -q "rejection rate"
 }
 
+test_team_ceremony_cli() {
+  tmp="$(mktemp -d)"
+  cd "${tmp}"
+  git init -q && git config user.email a@b.com && git config user.name "T"
+  "${AM_BIN}" init >/dev/null
+  mkdir -p .am/skills/bug-triage
+  printf -- '---\ndescription: Weekly bug triage.\narguments:\n  backlog: backlog to triage\n---\n\n# Triage {{.backlog}} on {{today}}\n' > .am/skills/bug-triage/SKILL.md
+  "${AM_BIN}" init >/dev/null
+  assert_file .claude/skills/bug-triage/SKILL.md
+  assert_file .cursor/rules/bug-triage.mdc
+  assert_grep AGENTS.md '`bug-triage`: Weekly bug triage.'
+  out="$("${AM_BIN}" ceremony bug-triage backlog=product 2>&1)"
+  echo "${out}" | grep -q "# Triage product on $(date +%Y-%m-%d)"
+  "${AM_BIN}" ceremony | grep -q "bug-triage backlog="
+  cd "${REPO_DIR}"
+  rm -rf "${tmp}"
+}
+
 test_mcp_inception_doc() {
   cd "${REPO_DIR}"
   rm -f inception.md
@@ -1772,6 +1778,7 @@ run_step "cli coach-check refuses"    test_cli_coach_check_refuses
 run_step "coach hook refuses self-accept" test_coach_hook_refuses_self_accept
 run_step "coach hook allows started"  test_coach_hook_allows_legitimate_transitions
 run_step "coach hook refuses pull without acceptance" test_coach_hook_refuses_pull_no_acceptance
+run_step "coach hook asks before verifying a bullet" test_coach_hook_asks_before_verify
 run_step "am init idempotent"         test_am_init_reinstalls_idempotent
 run_step "coach status CLI"           test_coach_status_cli
 run_step "team-agreements --add"      test_team_agreements_add
@@ -1782,6 +1789,7 @@ run_step "estimate --advise"          test_estimate_advise
 run_step "inception CLI"              test_inception_cli
 run_step "sprint plan CLI"            test_sprint_plan_cli
 run_step "retro CLI"                  test_retro_cli
+run_step "team ceremony CLI"          test_team_ceremony_cli
 run_step "mcp inception_doc"          test_mcp_inception_doc
 run_step "mcp sprint_plan"            test_mcp_sprint_plan
 
@@ -22,6 +22,8 @@ import {
   GetItemResult,
   HistoryResult,
   SearchResult,
+  ListFiltersResult,
+  RunFilterResult,
 } from './shared/types';
 
 export class AmClient {
@@ -83,6 +85,14 @@ export class AmClient {
     return this.readJSON(['search', '--limit', String(limit), query]);
   }
 
+  listFilters(): Promise<ListFiltersResult> {
+    return this.readJSON(['filter', 'list', '--json']);
+  }
+
+  runFilter(name: string): Promise<RunFilterResult> {
+    return this.readJSON(['filter', 'run', '--json', name]);
+  }
+
   getItem(itemPath: string): Promise<GetItemResult> {
     return this.readJSON(['get-item', itemPath]);
   }
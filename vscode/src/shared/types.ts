@@ -117,9 +117,11 @@ export interface HistoryResult {
 
 export interface SearchHit {
   path: string;
+  id?: number;
   title: string;
   status?: string;
   type?: string;
+  archived?: boolean;
   snippet?: string;
   score: number;
 }
@@ -130,6 +132,26 @@ export interface SearchResult {
   count: number;
 }
 
+export interface SavedFilter {
+  name: string;
+  slug: string;
+  query: string;
+  description?: string;
+  archived?: boolean;
+}
+
+export interface ListFiltersResult {
+  filters: SavedFilter[];
+  count: number;
+}
+
+export interface RunFilterResult {
+  name: string;
+  query: string;
+  hits: SearchHit[];
+  count: number;
+}
+
 export interface TypeMixRow {
   type: string;
   count: number;